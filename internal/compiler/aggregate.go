@@ -0,0 +1,154 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+)
+
+// aggregateFunctions are the names of all functions, that aggregate the
+// values of a group of datasets.
+var aggregateFunctions = map[string]struct{}{
+	"COUNT": {},
+	"SUM":   {},
+	"AVG":   {},
+	"MIN":   {},
+	"MAX":   {},
+}
+
+// isAggregateFunction returns true if the given function expression is a call
+// of an aggregate function.
+func isAggregateFunction(fn command.FunctionExpr) bool {
+	_, ok := aggregateFunctions[strings.ToUpper(fn.Name)]
+	return ok
+}
+
+// collectAggregates appends all aggregate function calls in the given
+// expression, that are not already contained in the given aggregates, to the
+// aggregates.
+func collectAggregates(aggregates []command.FunctionExpr, expr command.Expr) []command.FunctionExpr {
+	transformExpr(expr, func(e command.Expr) (command.Expr, bool) {
+		fn, ok := e.(command.FunctionExpr)
+		if !ok || !isAggregateFunction(fn) {
+			return e, false
+		}
+		for _, aggregate := range aggregates {
+			if aggregate.String() == fn.String() {
+				return e, true
+			}
+		}
+		aggregates = append(aggregates, fn)
+		return e, true
+	})
+	return aggregates
+}
+
+// aggregatedExpr rewrites the given expression, so that it can be evaluated
+// on the result of an aggregate with the given group expressions. Aggregate
+// function calls and group expressions are replaced by references to the
+// respective columns of the aggregate.
+func aggregatedExpr(expr command.Expr, groupBy []command.Expr) command.Expr {
+	return transformExpr(expr, func(e command.Expr) (command.Expr, bool) {
+		if fn, ok := e.(command.FunctionExpr); ok && isAggregateFunction(fn) {
+			return command.ColumnReference{Name: fn.String()}, true
+		}
+		if _, ok := e.(command.ColumnReference); ok {
+			// column references keep the name of the referenced column
+			return e, true
+		}
+		for _, group := range groupBy {
+			if group.String() == e.String() {
+				return command.ColumnReference{Name: group.String()}, true
+			}
+		}
+		return e, false
+	})
+}
+
+// transformExpr applies the given function to the given expression and all of
+// its sub-expressions, top to bottom. If the function returns true, the
+// returned expression replaces the expression that was passed into the
+// function, and its sub-expressions are not visited.
+func transformExpr(expr command.Expr, fn func(command.Expr) (command.Expr, bool)) command.Expr {
+	if expr == nil {
+		return nil
+	}
+	if replaced, ok := fn(expr); ok {
+		return replaced
+	}
+
+	binary := func(b command.BinaryBase) command.BinaryBase {
+		return command.BinaryBase{
+			Left:  transformExpr(b.Left, fn),
+			Right: transformExpr(b.Right, fn),
+		}
+	}
+	unary := func(u command.UnaryBase) command.UnaryBase {
+		return command.UnaryBase{
+			Value: transformExpr(u.Value, fn),
+		}
+	}
+
+	switch e := expr.(type) {
+	case command.EqualityExpr:
+		return command.EqualityExpr{BinaryBase: binary(e.BinaryBase), Invert: e.Invert}
+	case command.LessThanExpr:
+		return command.LessThanExpr{BinaryBase: binary(e.BinaryBase)}
+	case command.GreaterThanExpr:
+		return command.GreaterThanExpr{BinaryBase: binary(e.BinaryBase)}
+	case command.LessThanOrEqualToExpr:
+		return command.LessThanOrEqualToExpr{BinaryBase: binary(e.BinaryBase)}
+	case command.GreaterThanOrEqualToExpr:
+		return command.GreaterThanOrEqualToExpr{BinaryBase: binary(e.BinaryBase)}
+	case command.AddExpression:
+		return command.AddExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.SubExpression:
+		return command.SubExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.MulExpression:
+		return command.MulExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.DivExpression:
+		return command.DivExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.ModExpression:
+		return command.ModExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.PowExpression:
+		return command.PowExpression{BinaryBase: binary(e.BinaryBase)}
+	case command.UnaryNegativeExpr:
+		return command.UnaryNegativeExpr{UnaryBase: unary(e.UnaryBase)}
+	case command.UnaryBitwiseNegationExpr:
+		return command.UnaryBitwiseNegationExpr{UnaryBase: unary(e.UnaryBase)}
+	case command.UnaryNegationExpr:
+		return command.UnaryNegationExpr{UnaryBase: unary(e.UnaryBase)}
+	case command.RangeExpr:
+		return command.RangeExpr{
+			Needle: transformExpr(e.Needle, fn),
+			Lo:     transformExpr(e.Lo, fn),
+			Hi:     transformExpr(e.Hi, fn),
+			Invert: e.Invert,
+		}
+	case command.LikeExpr:
+		like := command.LikeExpr{
+			Value:   transformExpr(e.Value, fn),
+			Pattern: transformExpr(e.Pattern, fn),
+			Invert:  e.Invert,
+		}
+		if e.Escape != nil {
+			like.Escape = transformExpr(e.Escape, fn)
+		}
+		return like
+	case command.InExpr:
+		// the query of the expression is not a sub-expression, since it is
+		// evaluated on its own
+		return command.InExpr{
+			Needle: transformExpr(e.Needle, fn),
+			Query:  e.Query,
+			Invert: e.Invert,
+		}
+	case command.FunctionExpr:
+		args := make([]command.Expr, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = transformExpr(arg, fn)
+		}
+		return command.FunctionExpr{Name: e.Name, Distinct: e.Distinct, Args: args}
+	}
+	return expr
+}
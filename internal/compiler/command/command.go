@@ -17,12 +17,22 @@ var _ Command = (*Project)(nil)
 var _ Command = (*Delete)(nil)
 var _ Command = (*DropTable)(nil)
 var _ Command = (*DropIndex)(nil)
+var _ Command = (*CreateIndex)(nil)
+var _ Command = (*CreateView)(nil)
+var _ Command = (*CreateTrigger)(nil)
+var _ Command = (*AddColumn)(nil)
+var _ Command = (*RenameColumn)(nil)
+var _ Command = (*RenameTable)(nil)
+var _ Command = (*DropColumn)(nil)
 var _ Command = (*DropTrigger)(nil)
 var _ Command = (*DropView)(nil)
 var _ Command = (*Update)(nil)
 var _ Command = (*Insert)(nil)
 var _ Command = (*Join)(nil)
+var _ Command = (*Compound)(nil)
 var _ Command = (*Limit)(nil)
+var _ Command = (*Sort)(nil)
+var _ Command = (*Aggregate)(nil)
 
 // Command describes a structure that can be executed by the database executor.
 // Instead of using bytecode, we use a hierarchical structure for the executor.
@@ -45,6 +55,21 @@ const (
 	JoinCross
 )
 
+//go:generate stringer -type=CompoundOperator
+
+// CompoundOperator is the operator of a compound list, that determines how the
+// datasets of the left and right list are combined.
+type CompoundOperator uint8
+
+// Known compound operators.
+const (
+	CompoundUnknown CompoundOperator = iota
+	CompoundUnion
+	CompoundUnionAll
+	CompoundIntersect
+	CompoundExcept
+)
+
 //go:generate stringer -type=UpdateOr
 
 // UpdateOr is the type of update alternative that is specified in an update
@@ -77,12 +102,73 @@ const (
 	InsertOrIgnore
 )
 
+//go:generate stringer -type=ForeignKeyAction
+
+// ForeignKeyAction is the action of a foreign key, that is performed on the
+// referencing rows, when a referenced row is deleted or its key is updated.
+type ForeignKeyAction uint8
+
+// Known ForeignKeyActions
+const (
+	// ForeignKeyNoAction fails the statement or the transaction, if a
+	// referencing row is left without a referenced row at the end of the
+	// statement, or at the commit of the transaction, if the foreign key is
+	// deferred. This is the default action.
+	ForeignKeyNoAction ForeignKeyAction = iota
+	// ForeignKeyRestrict fails the statement as soon as a referenced row is
+	// deleted or updated, even if the foreign key is deferred.
+	ForeignKeyRestrict
+	// ForeignKeySetNull sets the columns of the referencing rows to NULL.
+	ForeignKeySetNull
+	// ForeignKeyCascade deletes the referencing rows, if the referenced row
+	// is deleted, or updates their columns to the new key of the referenced
+	// row.
+	ForeignKeyCascade
+)
+
+//go:generate stringer -type=TriggerTiming
+
+// TriggerTiming determines, when the statements of a trigger are executed,
+// relative to the change of the row, that fires the trigger.
+type TriggerTiming uint8
+
+// Known TriggerTimings
+const (
+	// TriggerBefore executes the statements of a trigger before a row is
+	// changed. This is the default timing.
+	TriggerBefore TriggerTiming = iota
+	// TriggerAfter executes the statements of a trigger after a row was
+	// changed.
+	TriggerAfter
+	// TriggerInsteadOf executes the statements of a trigger instead of
+	// changing a row of a view.
+	TriggerInsteadOf
+)
+
+//go:generate stringer -type=TriggerEvent
+
+// TriggerEvent is the kind of change of a row, that fires a trigger.
+type TriggerEvent uint8
+
+// Known TriggerEvents
+const (
+	TriggerDelete TriggerEvent = iota
+	TriggerInsert
+	TriggerUpdate
+)
+
 type (
 	// Explain instructs the executor to explain the nested command instead of
 	// executing it.
 	Explain struct {
 		// Command is the command that will be explained, but not executed.
 		Command Command
+		// QueryPlan indicates, that the query plan of the command should be
+		// explained, instead of the operators of the command tree.
+		QueryPlan bool
+		// Optimizations are the names of the optimizations, that were applied
+		// to the command.
+		Optimizations []string
 	}
 
 	// List is a marker interface that facilitates creating a type hierarchy for
@@ -128,6 +214,22 @@ type (
 		// Index is the name of the index that indexed this table, or empty, if
 		// Indexed is false.
 		Index string
+		// NotIndexed indicates, that the executor must not use any index to
+		// access this table. If this is true, Indexed must be false.
+		NotIndexed bool
+	}
+
+	// DerivedTable is a table, whose rows are the result of a query, and
+	// whose columns are qualified with the alias of the table.
+	//
+	// DerivedTable represents the second grammar production of
+	// table-or-subquery.
+	DerivedTable struct {
+		// Input is the query, that produces the rows of this table.
+		Input List
+		// Alias name of this table. May be empty, in which case the columns
+		// of the input are not qualified.
+		Alias string
 	}
 
 	// Select represents a selection that should be performed by the executor
@@ -161,6 +263,10 @@ type (
 		// deleted. This must not be nil. If all datasets from the table have to
 		// be deleted, the filter will be a constant true expression.
 		Filter Expr
+		// Returning are the columns of the RETURNING clause, which are
+		// projected from the deleted datasets. If this is empty, the amount
+		// of deleted datasets is returned.
+		Returning []Column
 	}
 
 	// drop instructs the executor to drop the component that is specified by
@@ -192,24 +298,211 @@ type (
 	// definition.
 	CreateTable struct {
 		// Overwrite determines whether an existing table with that name should be
-		// replaced.
+		// replaced. The SQLite grammar has no syntax for this, so the compiler
+		// never sets it, and only commands that are built programmatically can
+		// overwrite a table.
 		Overwrite bool
+		// IfNotExists determines whether the executor should ignore an existing
+		// table with that name, instead of returning an error. If this is set,
+		// Overwrite has no effect.
+		IfNotExists bool
 		// Name is the name of the table to be created.
 		Name string
 		// ColumnDefs are the column definitions of the new table.
 		ColumnDefs []ColumnDef
+		// PrimaryKey are the names of the columns of the primary key, that is
+		// defined by a PRIMARY KEY table constraint. If the primary key is
+		// defined by a column constraint, this is nil.
+		PrimaryKey []string
+		// UniqueKeys are the keys, that are defined by UNIQUE table
+		// constraints. Every key is a list of the names of its columns. Keys
+		// that are defined by a column constraint are not included.
+		UniqueKeys [][]string
+		// Checks are the CHECK constraints of the new table, including the
+		// CHECK constraints of the columns.
+		Checks []Check
+		// ForeignKeys are the foreign keys of the new table, including the
+		// foreign keys of the columns.
+		ForeignKeys []ForeignKey
+		// WithoutRowID determines whether the rows of the new table are
+		// clustered by its primary key, instead of being keyed by a row ID.
+		// If this is set, the table must have a primary key.
+		WithoutRowID bool
+	}
+
+	// CreateIndex instructs the executor to create an index over the columns
+	// of a table.
+	CreateIndex struct {
+		// IfNotExists determines whether the executor should ignore an existing
+		// index with that name, instead of returning an error.
+		IfNotExists bool
+		// Unique determines whether the values of the indexed columns must be
+		// unique across all rows of the table.
+		Unique bool
+		// Name is the name of the index to be created.
+		Name string
+		// Table is the name of the indexed table.
+		Table string
+		// Cols are the names of the indexed columns.
+		Cols []string
+	}
+
+	// CreateView instructs the executor to create a view, that expands to the
+	// result of a select statement whenever it is referenced.
+	CreateView struct {
+		// IfNotExists determines whether the executor should ignore an existing
+		// view with that name, instead of returning an error.
+		IfNotExists bool
+		// Name is the name of the view to be created.
+		Name string
+		// Columns are the explicitly given names of the columns of the view. If
+		// this is empty, the columns are named like the columns of the query.
+		Columns []string
+		// Query is the SQL source of the select statement of the view, which is
+		// stored as the definition of the view.
+		Query string
+		// Select is the compiled select statement of the view, which is used
+		// to determine the columns of the view.
+		Select List
+	}
+
+	// CreateTrigger instructs the executor to create a trigger, whose
+	// statements are executed for every row of a table or view, that is
+	// changed by an event.
+	CreateTrigger struct {
+		// IfNotExists determines whether the executor should ignore an existing
+		// trigger with that name, instead of returning an error.
+		IfNotExists bool
+		// Name is the name of the trigger to be created.
+		Name string
+		// Table is the name of the table or view, whose changes fire the
+		// trigger.
+		Table string
+		// Timing determines, when the statements of the trigger are executed.
+		Timing TriggerTiming
+		// Event is the kind of change, that fires the trigger.
+		Event TriggerEvent
+		// Cols are the names of the columns, of which at least one must be
+		// updated to fire the trigger. This is only used for update triggers.
+		// If this is empty, every update fires the trigger.
+		Cols []string
+		// When is the condition of the trigger, under which the statements of
+		// the trigger are executed, or nil, if the trigger has no condition.
+		When Expr
+		// WhenSource is the SQL source of the condition of the trigger, which
+		// is stored in the definition of the trigger.
+		WhenSource string
+		// Statements are the compiled statements of the trigger, in the order
+		// in which they are executed.
+		Statements []Command
+		// StatementSources are the SQL sources of the statements, which are
+		// stored in the definition of the trigger.
+		StatementSources []string
+	}
+
+	// AddColumn instructs the executor to add a column to a table. The new
+	// column holds its default value in all existing rows of the table.
+	AddColumn struct {
+		// Table is the name of the table, to which the column is added.
+		Table string
+		// Column is the definition of the new column. The new column must not
+		// be part of a key of the table.
+		Column ColumnDef
+		// Checks are the CHECK constraints of the new column.
+		Checks []Check
+		// ForeignKeys are the foreign keys of the new column.
+		ForeignKeys []ForeignKey
+	}
+
+	// RenameColumn instructs the executor to rename a column of a table.
+	RenameColumn struct {
+		// Table is the name of the table, that holds the column.
+		Table string
+		// Column is the name of the column to be renamed.
+		Column string
+		// NewName is the new name of the column.
+		NewName string
+	}
+
+	// RenameTable instructs the executor to rename a table.
+	RenameTable struct {
+		// Table is the name of the table to be renamed.
+		Table string
+		// NewName is the new name of the table.
+		NewName string
+	}
+
+	// DropColumn instructs the executor to drop a column of a table. The
+	// column must not be part of a key, an index or a constraint of the table.
+	DropColumn struct {
+		// Table is the name of the table, that holds the column.
+		Table string
+		// Column is the name of the column to be dropped.
+		Column string
+	}
+
+	// ForeignKey is a FOREIGN KEY constraint. The values of the columns of
+	// every row of the table, that holds no NULL values in these columns, must
+	// be the values of a key of a row in the foreign table.
+	ForeignKey struct {
+		// Name is the name of the constraint, or empty, if the constraint is
+		// not named.
+		Name string
+		// Cols are the names of the referencing columns.
+		Cols []string
+		// ForeignTable is the name of the referenced table.
+		ForeignTable string
+		// ForeignCols are the names of the referenced columns, which must be
+		// a key of the foreign table. If this is nil, the primary key of the
+		// foreign table is referenced.
+		ForeignCols []string
+		// OnDelete is the action, that is performed when a referenced row is
+		// deleted.
+		OnDelete ForeignKeyAction
+		// OnUpdate is the action, that is performed when the key of a
+		// referenced row is updated.
+		OnUpdate ForeignKeyAction
+		// Deferred indicates, that the foreign key is only checked when the
+		// transaction is committed, instead of at the end of every statement.
+		Deferred bool
+	}
+
+	// Check is a CHECK constraint, whose expression must not evaluate to false
+	// for any row of the table.
+	Check struct {
+		// Name is the name of the constraint, or empty, if the constraint is
+		// not named.
+		Name string
+		// Expr is the expression of the constraint.
+		Expr Expr
+		// Source is the SQL source of the expression, which is stored in the
+		// schema of the table.
+		Source string
 	}
 
 	// ColumnDef is a column definition.
 	ColumnDef struct {
 		Name string
 		Type types.Type
+		// Default is the expression of the default value of the column, or
+		// nil, if the column has no default value.
+		Default Expr
+		// PrimaryKey indicates, that the column is the primary key of the
+		// table. The values of the primary key column identify the rows of
+		// the table, and thus must be unique.
+		PrimaryKey bool
+		// Unique indicates, that the values of the column must be unique.
+		Unique bool
+		// NotNull indicates, that the column must not hold NULL values.
+		NotNull bool
 	}
 
 	// Update instructs the executor to update all datasets, for which the
 	// filter expression evaluates to true, with the defined updates.
 	Update struct {
-		// UpdateOr is the OR clause in an update statement.
+		// UpdateOr is the OR clause in an update statement. If the statement
+		// has no OR clause, this is UpdateOrUnknown, which is executed like
+		// UpdateOrAbort.
 		UpdateOr UpdateOr
 		// Table is the table on which the update should be performed.
 		Table Table
@@ -218,6 +511,10 @@ type (
 		// Filter is the filter expression, that determines, which datasets are
 		// to be updated.
 		Filter Expr
+		// Returning are the columns of the RETURNING clause, which are
+		// projected from the updated datasets. If this is empty, the amount
+		// of updated datasets is returned.
+		Returning []Column
 	}
 
 	// UpdateSetter is an update that can be applied to a value in a dataset.
@@ -259,6 +556,65 @@ type (
 		Right List
 	}
 
+	// Compound instructs the executor to combine the datasets of the left and
+	// right input list with a set operation. Both lists must have the same
+	// amount of columns.
+	Compound struct {
+		// Operator is the set operation, that combines the two lists.
+		Operator CompoundOperator
+		// Left is the left input list.
+		Left List
+		// Right is the right input list.
+		Right List
+	}
+
+	// With makes its common tables available to its input, which references
+	// them by their names, like tables. With is a list, but its input may also
+	// be an insert, update or delete.
+	With struct {
+		// Tables are the common tables, in the order of their definition. A
+		// common table can reference the common tables, that are defined
+		// before it.
+		Tables []CommonTable
+		// Input is the command, that references the common tables.
+		Input Command
+	}
+
+	// CommonTable is a named query of a WITH clause, that can be referenced
+	// by its name any number of times.
+	CommonTable struct {
+		// Name is the name of this table.
+		Name string
+		// Cols are the names of the columns of this table. If this is empty,
+		// the columns are named after the columns of the input.
+		Cols []string
+		// Input is the query, that produces the rows of this table. The
+		// input of a recursive common table is a Recursion.
+		Input List
+		// Materialized determines, whether the input is evaluated only once,
+		// when the table is scanned for the first time, and its rows are
+		// kept for all further scans. Otherwise, the input is evaluated
+		// again for every scan of the table.
+		Materialized bool
+	}
+
+	// Recursion is the input of a recursive common table. Its rows are
+	// computed iteratively. The first iteration produces the rows of the
+	// initial list. Every further iteration evaluates the step, in which the
+	// common table holds the rows of the previous iteration, until an
+	// iteration produces no rows.
+	Recursion struct {
+		// Initial is the list, that produces the rows of the first
+		// iteration.
+		Initial List
+		// Step is the list, that produces the rows of an iteration from the
+		// rows of the previous iteration.
+		Step List
+		// Distinct determines, whether rows, that were already produced, are
+		// discarded, as in a UNION, instead of a UNION ALL.
+		Distinct bool
+	}
+
 	// Limit instructs the executor to only respect the first Limit datasets
 	// from the input list.
 	Limit struct {
@@ -281,6 +637,45 @@ type (
 		Input List
 	}
 
+	// Sort instructs the executor to sort the input list by the given ordering
+	// terms. Datasets are ordered by the first term, datasets that are equal
+	// with respect to the first term are ordered by the second term and so on.
+	Sort struct {
+		// Terms are the ordering terms that the input list is sorted by.
+		Terms []OrderingTerm
+		// Input is the input list to sort.
+		Input List
+	}
+
+	// OrderingTerm is an expression, by which a list of datasets is sorted.
+	OrderingTerm struct {
+		// Expr is the expression that is evaluated for every dataset. The
+		// results are used to order the datasets.
+		Expr Expr
+		// Desc indicates, that the datasets are sorted in descending order.
+		Desc bool
+		// NullsFirst indicates, that datasets for which the expression
+		// evaluates to NULL come before all other datasets.
+		NullsFirst bool
+	}
+
+	// Aggregate instructs the executor to group the datasets of the input list
+	// by the given expressions, and to compute the given aggregate functions
+	// for every group. The resulting list contains one dataset per group,
+	// consisting of the values of the group expressions, followed by the
+	// results of the aggregate functions.
+	Aggregate struct {
+		// GroupBy are the expressions, by which the datasets are grouped. If
+		// this is empty, all datasets form a single group.
+		GroupBy []Expr
+		// Aggregates are the aggregate function calls, that are computed for
+		// every group. The column of an aggregate function in the resulting
+		// list is named after the string representation of the function call.
+		Aggregates []FunctionExpr
+		// Input is the input list of datasets, that are grouped.
+		Input List
+	}
+
 	// Empty instructs the executor to consider an empty list of datasets.
 	Empty struct {
 		// Cols are the columns in this empty list. This may be empty to
@@ -320,19 +715,52 @@ type (
 		DefaultValues bool
 		// Input is the input list of datasets, that will be inserted.
 		Input List
+		// Upsert is the ON CONFLICT clause of the insert, or nil, if the insert
+		// has no such clause.
+		Upsert *Upsert
+		// Returning are the columns of the RETURNING clause, which are
+		// projected from the inserted or updated datasets. If this is empty,
+		// nothing is returned.
+		Returning []Column
+	}
+
+	// Upsert determines, how an insert handles rows, that conflict with
+	// existing rows of the table.
+	Upsert struct {
+		// Target are the columns of the key, whose conflicts are handled by
+		// this upsert. If there are no target columns, conflicts on any key
+		// are handled.
+		Target []string
+		// DoNothing indicates, that conflicting rows are not inserted. If this
+		// is false, the conflicting existing row is updated with the updates.
+		DoNothing bool
+		// Updates are the updates, that are applied to the conflicting
+		// existing row. The row that was meant to be inserted, can be
+		// referenced in the update expressions through the table name
+		// "excluded".
+		Updates []UpdateSetter
+		// Filter determines, whether a conflicting existing row is updated.
+		// If the filter is nil, conflicting rows are always updated.
+		Filter Expr
 	}
 )
 
-func (Scan) _list()     {}
-func (Select) _list()   {}
-func (Project) _list()  {}
-func (Join) _list()     {}
-func (Limit) _list()    {}
-func (Offset) _list()   {}
-func (Distinct) _list() {}
-func (Values) _list()   {}
+func (Scan) _list()      {}
+func (Select) _list()    {}
+func (Project) _list()   {}
+func (Join) _list()      {}
+func (Compound) _list()  {}
+func (Limit) _list()     {}
+func (Offset) _list()    {}
+func (Sort) _list()      {}
+func (Aggregate) _list() {}
+func (Distinct) _list()  {}
+func (Values) _list()    {}
+func (With) _list()      {}
+func (Recursion) _list() {}
 
-func (SimpleTable) _table() {}
+func (SimpleTable) _table()  {}
+func (DerivedTable) _table() {}
 
 // QualifiedName returns '<Schema>.<TableName>', or only '<TableName>' if no
 // schema is specified.
@@ -344,6 +772,11 @@ func (t SimpleTable) QualifiedName() string {
 	return qualifiedName
 }
 
+// QualifiedName returns the alias of this table.
+func (t DerivedTable) QualifiedName() string {
+	return t.Alias
+}
+
 func (e Explain) String() string {
 	return fmt.Sprintf("explanation: %v", e.Command)
 }
@@ -365,6 +798,9 @@ func (p Project) String() string {
 }
 
 func (d Delete) String() string {
+	if len(d.Returning) != 0 {
+		return fmt.Sprintf("Delete[filter=%v,returning=(%v)](%v)", d.Filter, joinColumns(d.Returning), d.Table)
+	}
 	return fmt.Sprintf("Delete[filter=%v](%v)", d.Filter, d.Table)
 }
 
@@ -405,7 +841,39 @@ func (c CreateTable) String() string {
 	for _, def := range c.ColumnDefs {
 		cols = append(cols, def.Name+"("+def.Type.String()+")")
 	}
-	return fmt.Sprintf("CreateTable[name=%v,overwrite=%v,cols=[%v]]()", c.Name, c.Overwrite, strings.Join(cols, ","))
+	return fmt.Sprintf("CreateTable[name=%v,overwrite=%v,ifnotexists=%v,cols=[%v]]()", c.Name, c.Overwrite, c.IfNotExists, strings.Join(cols, ","))
+}
+
+func (c CreateIndex) String() string {
+	return fmt.Sprintf("CreateIndex[name=%v,table=%v,unique=%v,ifnotexists=%v,cols=[%v]]()", c.Name, c.Table, c.Unique, c.IfNotExists, strings.Join(c.Cols, ","))
+}
+
+func (c CreateView) String() string {
+	return fmt.Sprintf("CreateView[name=%v,ifnotexists=%v,cols=[%v]](%v)", c.Name, c.IfNotExists, strings.Join(c.Columns, ","), c.Select)
+}
+
+func (c CreateTrigger) String() string {
+	statements := make([]string, len(c.Statements))
+	for i, statement := range c.Statements {
+		statements[i] = statement.String()
+	}
+	return fmt.Sprintf("CreateTrigger[name=%v,table=%v,timing=%v,event=%v,ifnotexists=%v,cols=[%v],when=%v](%v)", c.Name, c.Table, c.Timing, c.Event, c.IfNotExists, strings.Join(c.Cols, ","), c.When, strings.Join(statements, ";"))
+}
+
+func (a AddColumn) String() string {
+	return fmt.Sprintf("AddColumn[table=%v,col=%v(%v)]()", a.Table, a.Column.Name, a.Column.Type)
+}
+
+func (r RenameColumn) String() string {
+	return fmt.Sprintf("RenameColumn[table=%v,col=%v,newname=%v]()", r.Table, r.Column, r.NewName)
+}
+
+func (r RenameTable) String() string {
+	return fmt.Sprintf("RenameTable[table=%v,newname=%v]()", r.Table, r.NewName)
+}
+
+func (d DropColumn) String() string {
+	return fmt.Sprintf("DropColumn[table=%v,col=%v]()", d.Table, d.Column)
 }
 
 func (u Update) String() string {
@@ -413,6 +881,9 @@ func (u Update) String() string {
 	for _, set := range u.Updates {
 		sets = append(sets, set.String())
 	}
+	if len(u.Returning) != 0 {
+		return fmt.Sprintf("Update[or=%v,table=%v,sets=(%v),filter=%v,returning=(%v)]", u.UpdateOr, u.Table, strings.Join(sets, ","), u.Filter, joinColumns(u.Returning))
+	}
 	return fmt.Sprintf("Update[or=%v,table=%v,sets=(%v),filter=%v]", u.UpdateOr, u.Table, strings.Join(sets, ","), u.Filter)
 }
 
@@ -421,10 +892,14 @@ func (u UpdateSetter) String() string {
 }
 
 func (c Column) String() string {
+	expr := c.Expr.String()
+	if c.Table != "" {
+		expr = c.Table + "." + expr
+	}
 	if c.Alias == "" {
-		return c.Expr.String()
+		return expr
 	}
-	return fmt.Sprintf("%v AS %v", c.Expr, c.Alias)
+	return fmt.Sprintf("%v AS %v", expr, c.Alias)
 }
 
 func (j Join) String() string {
@@ -445,6 +920,35 @@ func (j Join) String() string {
 	return buf.String()
 }
 
+func (c Compound) String() string {
+	return fmt.Sprintf("Compound[operator=%v](%v,%v)", c.Operator, c.Left, c.Right)
+}
+
+func (w With) String() string {
+	tables := make([]string, len(w.Tables))
+	for i, tbl := range w.Tables {
+		tables[i] = tbl.String()
+	}
+	return fmt.Sprintf("With[tables=(%v)](%v)", strings.Join(tables, ","), w.Input)
+}
+
+func (t CommonTable) String() string {
+	var buf strings.Builder
+	buf.WriteString(t.Name)
+	if len(t.Cols) != 0 {
+		buf.WriteString("(" + strings.Join(t.Cols, ",") + ")")
+	}
+	if t.Materialized {
+		buf.WriteString(" MATERIALIZED")
+	}
+	buf.WriteString(" AS " + t.Input.String())
+	return buf.String()
+}
+
+func (r Recursion) String() string {
+	return fmt.Sprintf("Recursion[distinct=%v](%v,%v)", r.Distinct, r.Initial, r.Step)
+}
+
 func (l Limit) String() string {
 	return fmt.Sprintf("Limit[limit=%v](%v)", l.Limit, l.Input)
 }
@@ -453,6 +957,38 @@ func (o Offset) String() string {
 	return fmt.Sprintf("Offset[offset=%v](%v)", o.Offset, o.Input)
 }
 
+func (s Sort) String() string {
+	terms := make([]string, len(s.Terms))
+	for i, term := range s.Terms {
+		terms[i] = term.String()
+	}
+	return fmt.Sprintf("Sort[by=%v](%v)", strings.Join(terms, ","), s.Input)
+}
+
+func (t OrderingTerm) String() string {
+	order := "ASC"
+	if t.Desc {
+		order = "DESC"
+	}
+	nulls := "LAST"
+	if t.NullsFirst {
+		nulls = "FIRST"
+	}
+	return fmt.Sprintf("%v %v NULLS %v", t.Expr, order, nulls)
+}
+
+func (a Aggregate) String() string {
+	groupBy := make([]string, len(a.GroupBy))
+	for i, expr := range a.GroupBy {
+		groupBy[i] = expr.String()
+	}
+	aggregates := make([]string, len(a.Aggregates))
+	for i, fn := range a.Aggregates {
+		aggregates[i] = fn.String()
+	}
+	return fmt.Sprintf("Aggregate[groupby=(%v),aggregates=(%v)](%v)", strings.Join(groupBy, ","), strings.Join(aggregates, ","), a.Input)
+}
+
 func (e Empty) String() string {
 	colStrs := make([]string, len(e.Cols))
 	for i, col := range e.Cols {
@@ -474,9 +1010,21 @@ func (t SimpleTable) String() string {
 	if t.Alias != "" {
 		buf.WriteString(" AS " + t.Alias)
 	}
+	if t.Indexed {
+		buf.WriteString(" INDEXED BY " + t.Index)
+	} else if t.NotIndexed {
+		buf.WriteString(" NOT INDEXED")
+	}
 	return buf.String()
 }
 
+func (t DerivedTable) String() string {
+	if t.Alias != "" {
+		return fmt.Sprintf("(%v) AS %v", t.Input, t.Alias)
+	}
+	return fmt.Sprintf("(%v)", t.Input)
+}
+
 func (v Values) String() string {
 	var values []string
 	for _, val := range v.Values {
@@ -494,5 +1042,33 @@ func (i Insert) String() string {
 	for _, col := range i.Cols {
 		cols = append(cols, col.String())
 	}
-	return fmt.Sprintf("Insert[table=%v,cols=%v](%v)", i.Table, strings.Join(cols, ","), i.Input)
+	args := fmt.Sprintf("table=%v,cols=%v", i.Table, strings.Join(cols, ","))
+	if i.Upsert != nil {
+		args += fmt.Sprintf(",upsert=%v", i.Upsert)
+	}
+	if len(i.Returning) != 0 {
+		args += fmt.Sprintf(",returning=(%v)", joinColumns(i.Returning))
+	}
+	return fmt.Sprintf("Insert[%v](%v)", args, i.Input)
+}
+
+func (u Upsert) String() string {
+	if u.DoNothing {
+		return fmt.Sprintf("Upsert[target=(%v),nothing]", strings.Join(u.Target, ","))
+	}
+	var sets []string
+	for _, set := range u.Updates {
+		sets = append(sets, set.String())
+	}
+	return fmt.Sprintf("Upsert[target=(%v),sets=(%v),filter=%v]", strings.Join(u.Target, ","), strings.Join(sets, ","), u.Filter)
+}
+
+// joinColumns returns the string representations of the given columns,
+// separated by commas.
+func joinColumns(cols []Column) string {
+	strs := make([]string, len(cols))
+	for i, col := range cols {
+		strs[i] = col.String()
+	}
+	return strings.Join(strs, ",")
 }
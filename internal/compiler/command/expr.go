@@ -6,6 +6,28 @@ import (
 	"strings"
 )
 
+//go:generate stringer -type=RaiseAction
+
+// RaiseAction is the action of a RAISE function, which determines how the
+// evaluation of a trigger is aborted.
+type RaiseAction uint8
+
+// Known RaiseActions
+const (
+	// RaiseIgnore abandons the evaluation of the trigger and the change of
+	// the row, that fired the trigger, without an error.
+	RaiseIgnore RaiseAction = iota
+	// RaiseRollback fails the statement, that fired the trigger, and rolls
+	// back the transaction.
+	RaiseRollback
+	// RaiseAbort fails the statement, that fired the trigger, and undoes
+	// the changes of the statement.
+	RaiseAbort
+	// RaiseFail fails the statement, that fired the trigger, but keeps the
+	// changes that the statement made before.
+	RaiseFail
+)
+
 type (
 	// Expr is a marker interface for anything that is an expression. Different
 	// implementations of this interface represent different productions of the
@@ -56,6 +78,75 @@ type (
 		Args []Expr
 	}
 
+	// RaiseExpr is a call of the RAISE function, which can only be evaluated in
+	// the statements of a trigger. Unless the action is RaiseIgnore, the
+	// evaluation fails with the message of the expression.
+	RaiseExpr struct {
+		// Action determines, how the evaluation of the trigger and the
+		// statement, that fired the trigger, is aborted.
+		Action RaiseAction
+		// Message is the error message, which is empty for RaiseIgnore.
+		Message string
+	}
+
+	// SubqueryExpr is a scalar subquery, which evaluates to the value of the
+	// single column of the first row of its query, or to NULL, if the query
+	// returns no rows. The query may reference the columns of the row, for
+	// which the expression is evaluated.
+	SubqueryExpr struct {
+		// Query is the query, whose first value is the value of this
+		// expression.
+		Query List
+		// Source is the SQL source of this expression, after which a
+		// column, that holds the value of this expression, is named.
+		Source string
+	}
+
+	// ExistsExpr is an expression, that evaluates to true, if its query
+	// returns at least one row, or if the query returns no rows and the
+	// expression is inverted. The query may reference the columns of the row,
+	// for which the expression is evaluated.
+	ExistsExpr struct {
+		// Query is the query, whose rows are checked.
+		Query List
+		// Invert determines, whether this is a NOT EXISTS expression.
+		Invert bool
+	}
+
+	// InExpr is an expression, that evaluates to true, if the needle is equal
+	// to any value of the single column of its query, or if the needle is not
+	// equal to any of those values and the expression is inverted. If no
+	// equal value is found, but the needle or any of the values is NULL, the
+	// expression evaluates to NULL, even if it is inverted. The query may
+	// reference the columns of the row, for which the expression is
+	// evaluated.
+	InExpr struct {
+		// Needle is the value, that is searched in the values of the query.
+		Needle Expr
+		// Query is the query, whose values are searched.
+		Query List
+		// Invert determines, whether this is a NOT IN expression.
+		Invert bool
+	}
+
+	// LikeExpr is an expression, that evaluates to true, if the value matches
+	// the pattern, or if the value doesn't match the pattern and the
+	// expression is inverted. In the pattern, '%' matches any sequence of
+	// characters, '_' matches any single character, and all other characters
+	// match themselves, with matching case.
+	LikeExpr struct {
+		// Value is the value, that is matched against the pattern.
+		Value Expr
+		// Pattern is the pattern, that the value must match.
+		Pattern Expr
+		// Escape is the character, that makes the following character of the
+		// pattern match itself, or nil, if the expression has no ESCAPE
+		// clause.
+		Escape Expr
+		// Invert determines, whether this is a NOT LIKE expression.
+		Invert bool
+	}
+
 	// RangeExpr is an expression with a needle, an upper and a lower bound. It
 	// must be evaluated to true, if needle is within the lower and upper bound,
 	// or if the needle is not between the bounds and the range is inverted.
@@ -75,6 +166,11 @@ type (
 func (ConstantBooleanExpr) _expr() {}
 func (RangeExpr) _expr()           {}
 func (FunctionExpr) _expr()        {}
+func (RaiseExpr) _expr()           {}
+func (SubqueryExpr) _expr()        {}
+func (ExistsExpr) _expr()          {}
+func (InExpr) _expr()              {}
+func (LikeExpr) _expr()            {}
 
 func (ConstantLiteral) _expr()                  {}
 func (ConstantLiteralOrColumnReference) _expr() {}
@@ -118,9 +214,45 @@ func (b ConstantBooleanExpr) String() string {
 
 func (r RangeExpr) String() string {
 	if r.Invert {
-		return fmt.Sprintf("![%v;%v]", r.Lo, r.Hi)
+		return fmt.Sprintf("%v not in [%v;%v]", r.Needle, r.Lo, r.Hi)
+	}
+	return fmt.Sprintf("%v in [%v;%v]", r.Needle, r.Lo, r.Hi)
+}
+
+func (s SubqueryExpr) String() string {
+	return fmt.Sprintf("(%v)", s.Query)
+}
+
+func (e ExistsExpr) String() string {
+	if e.Invert {
+		return fmt.Sprintf("not exists(%v)", e.Query)
+	}
+	return fmt.Sprintf("exists(%v)", e.Query)
+}
+
+func (e InExpr) String() string {
+	if e.Invert {
+		return fmt.Sprintf("%v not in (%v)", e.Needle, e.Query)
+	}
+	return fmt.Sprintf("%v in (%v)", e.Needle, e.Query)
+}
+
+func (e LikeExpr) String() string {
+	op := "like"
+	if e.Invert {
+		op = "not like"
+	}
+	if e.Escape != nil {
+		return fmt.Sprintf("%v %v %v escape %v", e.Value, op, e.Pattern, e.Escape)
+	}
+	return fmt.Sprintf("%v %v %v", e.Value, op, e.Pattern)
+}
+
+func (r RaiseExpr) String() string {
+	if r.Action == RaiseIgnore {
+		return fmt.Sprintf("RAISE(%v)", r.Action)
 	}
-	return fmt.Sprintf("[%v;%v]", r.Lo, r.Hi)
+	return fmt.Sprintf("RAISE(%v,%v)", r.Action, r.Message)
 }
 
 func (f FunctionExpr) String() string {
@@ -0,0 +1,158 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/parser/ast"
+)
+
+// compileCompound compiles the given select core and combines it with the
+// given left list, using the given compound operator. If the columns of both
+// lists are known at compile time, they are checked for compatibility.
+func (c *simpleCompiler) compileCompound(op *ast.CompoundOperator, left command.List, rightCore *ast.SelectCore) (command.List, error) {
+	if op == nil {
+		return nil, fmt.Errorf("missing compound operator")
+	}
+
+	var operator command.CompoundOperator
+	switch {
+	case op.Union != nil && op.All != nil:
+		operator = command.CompoundUnionAll
+	case op.Union != nil:
+		operator = command.CompoundUnion
+	case op.Intersect != nil:
+		operator = command.CompoundIntersect
+	case op.Except != nil:
+		operator = command.CompoundExcept
+	default:
+		return nil, fmt.Errorf("unknown compound operator: %w", ErrUnsupported)
+	}
+
+	right, err := c.compileSelectCore(rightCore)
+	if err != nil {
+		return nil, fmt.Errorf("core: %w", err)
+	}
+	rightList := right.(command.List)
+
+	if err := checkCompoundColumns(left, rightList); err != nil {
+		return nil, err
+	}
+
+	return command.Compound{
+		Operator: operator,
+		Left:     left,
+		Right:    rightList,
+	}, nil
+}
+
+// checkCompoundColumns checks, whether the columns of the given lists can be
+// combined in a compound. The lists must have the same amount of columns, and
+// constant values in the same column must both be numeric or both be
+// non-numeric. Columns that are not known at compile time, because they are
+// selected with an asterisk, are checked by the engine.
+func checkCompoundColumns(left, right command.List) error {
+	leftExprs, ok := compoundColumns(left)
+	if !ok {
+		return nil
+	}
+	rightExprs, ok := compoundColumns(right)
+	if !ok {
+		return nil
+	}
+
+	if len(leftExprs) != len(rightExprs) {
+		return fmt.Errorf("compound lists have %d and %d columns", len(leftExprs), len(rightExprs))
+	}
+	for i := range leftExprs {
+		leftLiteral, ok := leftExprs[i].(command.ConstantLiteral)
+		if !ok {
+			continue
+		}
+		rightLiteral, ok := rightExprs[i].(command.ConstantLiteral)
+		if !ok {
+			continue
+		}
+		if leftLiteral.Numeric != rightLiteral.Numeric {
+			return fmt.Errorf("column %d: incompatible values %v and %v", i+1, leftLiteral, rightLiteral)
+		}
+	}
+	return nil
+}
+
+// compoundColumns returns the expressions of the columns of the given list, if
+// they are known at compile time. For lists of values, the expressions of the
+// first dataset are returned.
+func compoundColumns(list command.List) ([]command.Expr, bool) {
+	switch l := list.(type) {
+	case command.Project:
+		exprs := make([]command.Expr, len(l.Cols))
+		for i, col := range l.Cols {
+			if ref, ok := col.Expr.(command.ColumnReference); ok && ref.Name == "*" {
+				return nil, false
+			}
+			exprs[i] = col.Expr
+		}
+		return exprs, true
+	case command.Values:
+		if len(l.Values) == 0 {
+			return nil, false
+		}
+		return l.Values[0], true
+	case command.Distinct:
+		return compoundColumns(l.Input)
+	case command.Compound:
+		return compoundColumns(l.Left)
+	}
+	return nil, false
+}
+
+// resolveCompoundColumn resolves ordering terms that reference a (1-based)
+// position of a column of the given compound. The columns of a compound are
+// the columns of its leftmost list, and a position is resolved to a reference
+// to the column at that position. Terms that are not a position are returned
+// unchanged.
+func resolveCompoundColumn(compound command.Compound, expr command.Expr) (command.Expr, error) {
+	literal, ok := expr.(command.ConstantLiteral)
+	if !ok || !literal.Numeric {
+		return expr, nil
+	}
+
+	exprs, ok := compoundColumns(compound)
+	if !ok {
+		return nil, fmt.Errorf("term by position with unknown columns: %w", ErrUnsupported)
+	}
+	pos, err := strconv.Atoi(literal.Value)
+	if err != nil || pos < 1 || pos > len(exprs) {
+		return nil, fmt.Errorf("term out of range: %v", literal.Value)
+	}
+
+	project, ok := leftmostList(compound).(command.Project)
+	if ok && project.Cols[pos-1].Alias != "" {
+		return command.ColumnReference{Name: project.Cols[pos-1].Alias}, nil
+	}
+	switch col := exprs[pos-1].(type) {
+	case command.ColumnReference:
+		return col, nil
+	case command.ConstantLiteralOrColumnReference:
+		return command.ColumnReference{Name: col.ValueOrName}, nil
+	}
+	return nil, fmt.Errorf("term by position of unnamed column: %w", ErrUnsupported)
+}
+
+// leftmostList returns the leftmost list of the given compound, which is
+// neither a compound nor a distinct list.
+func leftmostList(compound command.Compound) command.List {
+	left := compound.Left
+	for {
+		switch next := left.(type) {
+		case command.Compound:
+			left = next.Left
+		case command.Distinct:
+			left = next.Input
+		default:
+			return left
+		}
+	}
+}
@@ -1,5 +1,19 @@
 package compiler
 
+import "github.com/xqueries/xdb/internal/compiler/optimization"
+
 // Option is a functional option that can be applied to a compiler. If the
 // option is applicable to the compiler, is determined by the compiler itself.
 type Option func(*simpleCompiler)
+
+// OptionEnableOptimization enables the given optimization in the compiler.
+// Optimizations are applied in the order in which they were enabled. The name
+// is used to refer to the optimization, e.g. when explaining a command.
+func OptionEnableOptimization(name string, opt optimization.Optimization) Option {
+	return func(c *simpleCompiler) {
+		c.optimizations = append(c.optimizations, namedOptimization{
+			name: name,
+			opt:  opt,
+		})
+	}
+}
@@ -2,6 +2,8 @@ package compiler
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,7 +15,14 @@ import (
 )
 
 type simpleCompiler struct {
-	optimizations []optimization.Optimization
+	optimizations []namedOptimization
+}
+
+// namedOptimization is an optimization, together with the name that is used
+// to refer to the optimization.
+type namedOptimization struct {
+	name string
+	opt  optimization.Optimization
 }
 
 // New creates a new, ready to use compiler with the given options applied.
@@ -34,14 +43,18 @@ func (c *simpleCompiler) Compile(ast *ast.SQLStmt) (command.Command, error) {
 		return nil, err
 	}
 	// apply optimizations
+	var applied []string
 	for _, opt := range c.optimizations {
-		if optimized, ok := opt(cmd); ok {
+		if optimized, ok := opt.opt(cmd); ok {
 			cmd = optimized
+			applied = append(applied, opt.name)
 		}
 	}
 	if ast.Explain != nil {
 		return command.Explain{
-			Command: cmd,
+			Command:       cmd,
+			QueryPlan:     ast.Query != nil && ast.Plan != nil,
+			Optimizations: applied,
 		}, nil
 	}
 	return cmd, nil
@@ -61,8 +74,36 @@ func (c *simpleCompiler) compileInternal(ast *ast.SQLStmt) (command.Command, err
 			return nil, fmt.Errorf("create table: %w", err)
 		}
 		return cmd, nil
+	case ast.AlterTableStmt != nil:
+		cmd, err := c.compileAlterTable(ast.AlterTableStmt)
+		if err != nil {
+			return nil, fmt.Errorf("alter table: %w", err)
+		}
+		return cmd, nil
+	case ast.CreateIndexStmt != nil:
+		cmd, err := c.compileCreateIndex(ast.CreateIndexStmt)
+		if err != nil {
+			return nil, fmt.Errorf("create index: %w", err)
+		}
+		return cmd, nil
+	case ast.CreateViewStmt != nil:
+		cmd, err := c.compileCreateView(ast.CreateViewStmt)
+		if err != nil {
+			return nil, fmt.Errorf("create view: %w", err)
+		}
+		return cmd, nil
+	case ast.CreateTriggerStmt != nil:
+		cmd, err := c.compileCreateTrigger(ast.CreateTriggerStmt)
+		if err != nil {
+			return nil, fmt.Errorf("create trigger: %w", err)
+		}
+		return cmd, nil
 	case ast.DeleteStmt != nil:
-		cmd, err := c.compileDelete(ast.DeleteStmt)
+		compiled, err := c.compileDelete(ast.DeleteStmt)
+		if err != nil {
+			return nil, fmt.Errorf("delete: %w", err)
+		}
+		cmd, err := c.compileWith(ast.DeleteStmt.WithClause, ast.DeleteStmt, compiled)
 		if err != nil {
 			return nil, fmt.Errorf("delete: %w", err)
 		}
@@ -92,13 +133,21 @@ func (c *simpleCompiler) compileInternal(ast *ast.SQLStmt) (command.Command, err
 		}
 		return cmd, nil
 	case ast.UpdateStmt != nil:
-		cmd, err := c.compileUpdate(ast.UpdateStmt)
+		compiled, err := c.compileUpdate(ast.UpdateStmt)
+		if err != nil {
+			return nil, fmt.Errorf("update: %w", err)
+		}
+		cmd, err := c.compileWith(ast.UpdateStmt.WithClause, ast.UpdateStmt, compiled)
 		if err != nil {
 			return nil, fmt.Errorf("update: %w", err)
 		}
 		return cmd, nil
 	case ast.InsertStmt != nil:
-		cmd, err := c.compileInsert(ast.InsertStmt)
+		compiled, err := c.compileInsert(ast.InsertStmt)
+		if err != nil {
+			return nil, fmt.Errorf("insert: %w", err)
+		}
+		cmd, err := c.compileWith(ast.InsertStmt.WithClause, ast.InsertStmt, compiled)
 		if err != nil {
 			return nil, fmt.Errorf("insert: %w", err)
 		}
@@ -107,6 +156,9 @@ func (c *simpleCompiler) compileInternal(ast *ast.SQLStmt) (command.Command, err
 	return nil, fmt.Errorf("statement type: %w", ErrUnsupported)
 }
 
+// compileCreateTable compiles the given CREATE TABLE statement. Since there is
+// no SQL syntax for replacing an existing table, the compiled command never has
+// Overwrite set.
 func (c *simpleCompiler) compileCreateTable(stmt *ast.CreateTableStmt) (command.CreateTable, error) {
 	if stmt.Temp != nil || stmt.Temporary != nil {
 		return command.CreateTable{}, fmt.Errorf("temporary table: %w", ErrUnsupported)
@@ -119,65 +171,367 @@ func (c *simpleCompiler) compileCreateTable(stmt *ast.CreateTableStmt) (command.
 		tableName = stmt.SchemaName.Value() + "." + tableName
 	}
 
-	if len(stmt.TableConstraint) != 0 {
-		return command.CreateTable{}, fmt.Errorf("table constraint: %w", ErrUnsupported)
-	}
-	if stmt.Without != nil {
-		return command.CreateTable{}, fmt.Errorf("without ROWID: %w", ErrUnsupported)
-	}
 	if stmt.As != nil {
 		return command.CreateTable{}, fmt.Errorf("AS: %w", ErrUnsupported)
 	}
 
 	var columnDefs []command.ColumnDef
+	var checks []command.Check
+	var foreignKeys []command.ForeignKey
+	var hasPrimaryKey bool
 	for _, def := range stmt.ColumnDef {
-		if def.TypeName == nil {
-			return command.CreateTable{}, fmt.Errorf("column '%v' does not declare a type", def.ColumnName.Value())
-		}
-		if len(def.ColumnConstraint) != 0 {
-			return command.CreateTable{}, fmt.Errorf("column constraint: %w", ErrUnsupported)
-		}
-		if def.TypeName.LeftParen != nil {
-			return command.CreateTable{}, fmt.Errorf("parameterized type: %w", ErrUnsupported)
-		}
-		if len(def.TypeName.Name) != 1 {
-			return command.CreateTable{}, fmt.Errorf("multiple type names: %w", ErrUnsupported)
-		}
-
-		var colType types.Type
-		switch strings.ToLower(def.TypeName.Name[0].Value()) {
-		case "integer":
-			colType = types.Integer
-		case "real":
-			colType = types.Real
-		case "text":
-			colType = types.String
-		case "date":
-			colType = types.Date
-		case "string":
-			colType = types.String
+		columnDef, columnChecks, columnForeignKeys, err := c.compileColumnDef(def)
+		if err != nil {
+			return command.CreateTable{}, err
+		}
+		if columnDef.PrimaryKey {
+			if hasPrimaryKey {
+				return command.CreateTable{}, fmt.Errorf("table '%v' has more than one primary key", tableName)
+			}
+			hasPrimaryKey = true
+		}
+		columnDefs = append(columnDefs, columnDef)
+		checks = append(checks, columnChecks...)
+		foreignKeys = append(foreignKeys, columnForeignKeys...)
+	}
+
+	var primaryKey []string
+	var uniqueKeys [][]string
+	for _, constraint := range stmt.TableConstraint {
+		if constraint.ConflictClause != nil && constraint.ConflictClause.On != nil {
+			return command.CreateTable{}, fmt.Errorf("conflict clause: %w", ErrUnsupported)
+		}
+		switch {
+		case constraint.Primary != nil:
+			if hasPrimaryKey {
+				return command.CreateTable{}, fmt.Errorf("table '%v' has more than one primary key", tableName)
+			}
+			hasPrimaryKey = true
+			key, err := compileKeyColumns(constraint.IndexedColumn, columnDefs)
+			if err != nil {
+				return command.CreateTable{}, fmt.Errorf("primary key: %w", err)
+			}
+			primaryKey = key
+		case constraint.Unique != nil:
+			key, err := compileKeyColumns(constraint.IndexedColumn, columnDefs)
+			if err != nil {
+				return command.CreateTable{}, fmt.Errorf("unique: %w", err)
+			}
+			uniqueKeys = append(uniqueKeys, key)
+		case constraint.Check != nil:
+			check, err := c.compileCheck(constraint.Name, constraint.Expr)
+			if err != nil {
+				return command.CreateTable{}, fmt.Errorf("check: %w", err)
+			}
+			checks = append(checks, check)
+		case constraint.Foreign != nil:
+			cols := make([]string, len(constraint.ColumnName))
+			for i, col := range constraint.ColumnName {
+				cols[i] = col.Value()
+			}
+			if err := checkKeyColumns(cols, columnDefs); err != nil {
+				return command.CreateTable{}, fmt.Errorf("foreign key: %w", err)
+			}
+			foreignKey, err := compileForeignKey(constraint.Name, cols, constraint.ForeignKeyClause)
+			if err != nil {
+				return command.CreateTable{}, fmt.Errorf("foreign key: %w", err)
+			}
+			foreignKeys = append(foreignKeys, foreignKey)
 		default:
-			return command.CreateTable{}, fmt.Errorf("unknown type '%v'", def.TypeName.Name[0].Value())
+			return command.CreateTable{}, fmt.Errorf("table constraint: %w", ErrUnsupported)
 		}
+	}
 
-		columnDefs = append(columnDefs, command.ColumnDef{
-			Name: def.ColumnName.Value(),
-			Type: colType,
-		})
+	// a table without row ID is clustered by its primary key
+	if stmt.Without != nil && !hasPrimaryKey {
+		return command.CreateTable{}, fmt.Errorf("table '%v' without ROWID has no primary key", tableName)
 	}
 
 	return command.CreateTable{
-		Overwrite:  stmt.If != nil,
-		Name:       tableName,
-		ColumnDefs: columnDefs,
+		IfNotExists:  stmt.If != nil,
+		Name:         tableName,
+		ColumnDefs:   columnDefs,
+		PrimaryKey:   primaryKey,
+		UniqueKeys:   uniqueKeys,
+		Checks:       checks,
+		ForeignKeys:  foreignKeys,
+		WithoutRowID: stmt.Without != nil,
 	}, nil
 }
 
-func (c *simpleCompiler) compileInsert(stmt *ast.InsertStmt) (command.Insert, error) {
-	if stmt.Replace != nil {
-		return command.Insert{}, fmt.Errorf("replace: %w", ErrUnsupported)
+// compileAlterTable compiles the given statement into a command, that adds,
+// renames or drops a column, or renames the table.
+func (c *simpleCompiler) compileAlterTable(stmt *ast.AlterTableStmt) (command.Command, error) {
+	if stmt.TableName == nil {
+		return nil, fmt.Errorf("no table name given")
+	}
+	tableName := stmt.TableName.Value()
+	if stmt.SchemaName != nil {
+		tableName = stmt.SchemaName.Value() + "." + tableName
+	}
+
+	switch {
+	case stmt.Add != nil:
+		if stmt.ColumnDef == nil || stmt.ColumnDef.ColumnName == nil {
+			return nil, fmt.Errorf("no column definition given")
+		}
+		def, checks, foreignKeys, err := c.compileColumnDef(stmt.ColumnDef)
+		if err != nil {
+			return nil, err
+		}
+		// existing rows would all hold the same value in the new column
+		if def.PrimaryKey || def.Unique {
+			return nil, fmt.Errorf("cannot add a key column '%v'", def.Name)
+		}
+		if def.NotNull && def.Default == nil {
+			return nil, fmt.Errorf("cannot add a NOT NULL column '%v' without default value", def.Name)
+		}
+		return command.AddColumn{
+			Table:       tableName,
+			Column:      def,
+			Checks:      checks,
+			ForeignKeys: foreignKeys,
+		}, nil
+	case stmt.Drop != nil:
+		if stmt.ColumnName == nil {
+			return nil, fmt.Errorf("no column name given")
+		}
+		return command.DropColumn{
+			Table:  tableName,
+			Column: stmt.ColumnName.Value(),
+		}, nil
+	case stmt.Rename != nil && stmt.NewTableName != nil:
+		// the renamed table stays in its schema
+		newName := stmt.NewTableName.Value()
+		if stmt.SchemaName != nil {
+			newName = stmt.SchemaName.Value() + "." + newName
+		}
+		return command.RenameTable{
+			Table:   tableName,
+			NewName: newName,
+		}, nil
+	case stmt.Rename != nil:
+		if stmt.ColumnName == nil || stmt.NewColumnName == nil {
+			return nil, fmt.Errorf("no column name given")
+		}
+		return command.RenameColumn{
+			Table:   tableName,
+			Column:  stmt.ColumnName.Value(),
+			NewName: stmt.NewColumnName.Value(),
+		}, nil
 	}
+	return nil, fmt.Errorf("no alteration given")
+}
+
+// compileColumnDef compiles the given column definition, together with the
+// CHECK constraints and foreign keys, that are defined by its column
+// constraints.
+func (c *simpleCompiler) compileColumnDef(def *ast.ColumnDef) (command.ColumnDef, []command.Check, []command.ForeignKey, error) {
+	if def.TypeName == nil {
+		return command.ColumnDef{}, nil, nil, fmt.Errorf("column '%v' does not declare a type", def.ColumnName.Value())
+	}
+	if def.TypeName.LeftParen != nil {
+		return command.ColumnDef{}, nil, nil, fmt.Errorf("parameterized type: %w", ErrUnsupported)
+	}
+	if len(def.TypeName.Name) != 1 {
+		return command.ColumnDef{}, nil, nil, fmt.Errorf("multiple type names: %w", ErrUnsupported)
+	}
+
+	var colType types.Type
+	switch strings.ToLower(def.TypeName.Name[0].Value()) {
+	case "integer":
+		colType = types.Integer
+	case "real":
+		colType = types.Real
+	case "text":
+		colType = types.String
+	case "date":
+		colType = types.Date
+	case "string":
+		colType = types.String
+	default:
+		return command.ColumnDef{}, nil, nil, fmt.Errorf("unknown type '%v'", def.TypeName.Name[0].Value())
+	}
+
+	columnDef := command.ColumnDef{
+		Name: def.ColumnName.Value(),
+		Type: colType,
+	}
+	var checks []command.Check
+	var foreignKeys []command.ForeignKey
+	for _, constraint := range def.ColumnConstraint {
+		if constraint.ConflictClause != nil && constraint.ConflictClause.On != nil {
+			return command.ColumnDef{}, nil, nil, fmt.Errorf("conflict clause: %w", ErrUnsupported)
+		}
+		switch {
+		case constraint.Default != nil:
+			defaultValue, err := c.compileColumnDefault(constraint)
+			if err != nil {
+				return command.ColumnDef{}, nil, nil, fmt.Errorf("default of column '%v': %w", columnDef.Name, err)
+			}
+			columnDef.Default = defaultValue
+		case constraint.Primary != nil:
+			if constraint.Autoincrement != nil {
+				return command.ColumnDef{}, nil, nil, fmt.Errorf("autoincrement: %w", ErrUnsupported)
+			}
+			columnDef.PrimaryKey = true
+		case constraint.Unique != nil:
+			columnDef.Unique = true
+		case constraint.Not != nil && constraint.Null != nil:
+			columnDef.NotNull = true
+		case constraint.Check != nil:
+			check, err := c.compileCheck(constraint.Name, constraint.Expr)
+			if err != nil {
+				return command.ColumnDef{}, nil, nil, fmt.Errorf("check of column '%v': %w", columnDef.Name, err)
+			}
+			checks = append(checks, check)
+		case constraint.ForeignKeyClause != nil:
+			foreignKey, err := compileForeignKey(constraint.Name, []string{columnDef.Name}, constraint.ForeignKeyClause)
+			if err != nil {
+				return command.ColumnDef{}, nil, nil, fmt.Errorf("foreign key of column '%v': %w", columnDef.Name, err)
+			}
+			foreignKeys = append(foreignKeys, foreignKey)
+		default:
+			return command.ColumnDef{}, nil, nil, fmt.Errorf("column constraint: %w", ErrUnsupported)
+		}
+	}
+	return columnDef, checks, foreignKeys, nil
+}
 
+// compileCheck compiles the expression of a CHECK constraint with the given
+// name, which may be nil.
+func (c *simpleCompiler) compileCheck(name token.Token, expr *ast.Expr) (command.Check, error) {
+	compiled, err := c.compileExpr(expr)
+	if err != nil {
+		return command.Check{}, err
+	}
+	check := command.Check{
+		Expr:   compiled,
+		Source: sourceText(expr),
+	}
+	if name != nil {
+		check.Name = name.Value()
+	}
+	return check, nil
+}
+
+// compileKeyColumns compiles the given indexed columns of a key constraint to
+// the names of the columns of the key. All columns must be defined by the
+// given column definitions, and every column must only occur once.
+func compileKeyColumns(indexedColumns []*ast.IndexedColumn, columnDefs []command.ColumnDef) ([]string, error) {
+	var key []string
+	for _, col := range indexedColumns {
+		if col.ColumnName == nil || col.Collate != nil || col.Asc != nil || col.Desc != nil {
+			return nil, fmt.Errorf("indexed column: %w", ErrUnsupported)
+		}
+		key = append(key, col.ColumnName.Value())
+	}
+	if err := checkKeyColumns(key, columnDefs); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKeyColumns returns an error, if any of the given columns of a key is
+// not defined by the given column definitions, or occurs more than once.
+func checkKeyColumns(key []string, columnDefs []command.ColumnDef) error {
+	seen := make(map[string]bool)
+	for _, name := range key {
+		defined := false
+		for _, def := range columnDefs {
+			defined = defined || def.Name == name
+		}
+		if !defined {
+			return fmt.Errorf("no column with name '%v'", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("column '%v' occurs more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// compileForeignKey compiles the given foreign key clause of a foreign key
+// with the given name, which may be nil, and the given referencing columns.
+func compileForeignKey(name token.Token, cols []string, clause *ast.ForeignKeyClause) (command.ForeignKey, error) {
+	foreignKey := command.ForeignKey{
+		Cols:         cols,
+		ForeignTable: clause.ForeignTable.Value(),
+	}
+	if name != nil {
+		foreignKey.Name = name.Value()
+	}
+	for _, col := range clause.ColumnName {
+		foreignKey.ForeignCols = append(foreignKey.ForeignCols, col.Value())
+	}
+	if foreignKey.ForeignCols != nil && len(foreignKey.ForeignCols) != len(cols) {
+		return command.ForeignKey{}, fmt.Errorf("%v columns reference %v columns", len(cols), len(foreignKey.ForeignCols))
+	}
+
+	for _, core := range clause.ForeignKeyClauseCore {
+		if core.Match != nil {
+			return command.ForeignKey{}, fmt.Errorf("match: %w", ErrUnsupported)
+		}
+		action, err := compileForeignKeyAction(core)
+		if err != nil {
+			return command.ForeignKey{}, err
+		}
+		if core.Delete != nil {
+			foreignKey.OnDelete = action
+		} else {
+			foreignKey.OnUpdate = action
+		}
+	}
+
+	// only DEFERRABLE INITIALLY DEFERRED defers the checks, a foreign key
+	// that is DEFERRABLE INITIALLY IMMEDIATE is checked immediately, since
+	// checks can't be deferred by the transaction
+	foreignKey.Deferred = clause.Not == nil && clause.Deferrable != nil && clause.Deferred != nil
+	return foreignKey, nil
+}
+
+// compileForeignKeyAction compiles the action of the given ON DELETE or ON
+// UPDATE clause of a foreign key.
+func compileForeignKeyAction(core *ast.ForeignKeyClauseCore) (command.ForeignKeyAction, error) {
+	switch {
+	case core.Set != nil && core.Null != nil:
+		return command.ForeignKeySetNull, nil
+	case core.Cascade != nil:
+		return command.ForeignKeyCascade, nil
+	case core.Restrict != nil:
+		return command.ForeignKeyRestrict, nil
+	case core.No != nil:
+		return command.ForeignKeyNoAction, nil
+	}
+	return command.ForeignKeyNoAction, fmt.Errorf("foreign key action: %w", ErrUnsupported)
+}
+
+// compileColumnDefault compiles the default value of the given DEFAULT column
+// constraint, which is a signed number, a literal value or a parenthesized
+// expression.
+func (c *simpleCompiler) compileColumnDefault(constraint *ast.ColumnConstraint) (command.Expr, error) {
+	switch {
+	case constraint.SignedNumber != nil:
+		number := command.ConstantLiteral{
+			Value:   constraint.SignedNumber.NumericLiteral.Value(),
+			Numeric: true,
+		}
+		if constraint.SignedNumber.Sign != nil && constraint.SignedNumber.Sign.Value() == "-" {
+			return command.UnaryNegativeExpr{
+				UnaryBase: command.UnaryBase{Value: number},
+			}, nil
+		}
+		return number, nil
+	case constraint.LiteralValue != nil:
+		return c.compileExpr(&ast.Expr{LiteralValue: constraint.LiteralValue})
+	case constraint.Expr != nil:
+		return c.compileExpr(constraint.Expr)
+	}
+	return nil, fmt.Errorf("no default value given")
+}
+
+func (c *simpleCompiler) compileInsert(stmt *ast.InsertStmt) (command.Insert, error) {
 	// compile insertOr
 	var insertOr command.InsertOr
 	switch {
@@ -236,17 +590,78 @@ func (c *simpleCompiler) compileInsert(stmt *ast.InsertStmt) (command.Insert, er
 		}
 	}
 
+	var upsert *command.Upsert
+	if stmt.UpsertClause != nil {
+		compiled, err := c.compileUpsertClause(stmt.UpsertClause)
+		if err != nil {
+			return command.Insert{}, fmt.Errorf("upsert: %w", err)
+		}
+		upsert = &compiled
+	}
+
+	returning, err := c.compileReturningClause(stmt.ReturningClause)
+	if err != nil {
+		return command.Insert{}, fmt.Errorf("returning: %w", err)
+	}
+
 	return command.Insert{
 		InsertOr:      insertOr,
 		Table:         table,
 		Cols:          cols,
 		DefaultValues: stmt.Default != nil,
 		Input:         vals,
+		Upsert:        upsert,
+		Returning:     returning,
+	}, nil
+}
+
+func (c *simpleCompiler) compileUpsertClause(clause *ast.UpsertClause) (command.Upsert, error) {
+	if clause.Where1 != nil {
+		return command.Upsert{}, fmt.Errorf("conflict target filter: %w", ErrUnsupported)
+	}
+
+	var target []string
+	for _, col := range clause.IndexedColumn {
+		if col.ColumnName == nil || col.Collate != nil {
+			return command.Upsert{}, fmt.Errorf("conflict target: %w", ErrUnsupported)
+		}
+		target = append(target, col.ColumnName.Value())
+	}
+
+	if clause.Nothing != nil {
+		return command.Upsert{
+			Target:    target,
+			DoNothing: true,
+		}, nil
+	}
+
+	var sets []command.UpdateSetter
+	for _, set := range clause.UpdateSetter {
+		compiledSet, err := c.compileUpdateSetter(set)
+		if err != nil {
+			return command.Upsert{}, fmt.Errorf("update setter: %w", err)
+		}
+		sets = append(sets, compiledSet)
+	}
+
+	var filter command.Expr
+	if clause.Where2 != nil {
+		filterExpr, err := c.compileExpr(clause.Expr2)
+		if err != nil {
+			return command.Upsert{}, fmt.Errorf("expr: %w", err)
+		}
+		filter = filterExpr
+	}
+
+	return command.Upsert{
+		Target:  target,
+		Updates: sets,
+		Filter:  filter,
 	}, nil
 }
 
 func (c *simpleCompiler) compileUpdate(stmt *ast.UpdateStmt) (command.Update, error) {
-	updateOr := command.UpdateOrIgnore // ignore as default or
+	var updateOr command.UpdateOr
 	switch {
 	case stmt.Rollback != nil:
 		updateOr = command.UpdateOrRollback
@@ -285,11 +700,17 @@ func (c *simpleCompiler) compileUpdate(stmt *ast.UpdateStmt) (command.Update, er
 		filter = command.ConstantBooleanExpr{Value: true}
 	}
 
+	returning, err := c.compileReturningClause(stmt.ReturningClause)
+	if err != nil {
+		return command.Update{}, fmt.Errorf("returning: %w", err)
+	}
+
 	return command.Update{
-		UpdateOr: updateOr,
-		Table:    qtn,
-		Updates:  sets,
-		Filter:   filter,
+		UpdateOr:  updateOr,
+		Table:     qtn,
+		Updates:   sets,
+		Filter:    filter,
+		Returning: returning,
 	}, nil
 }
 
@@ -323,6 +744,48 @@ func (c *simpleCompiler) compileDropTable(stmt *ast.DropTableStmt) (command.Drop
 	return cmd, nil
 }
 
+// compileCreateIndex compiles the given CREATE INDEX statement. Only indexes
+// over plain columns are supported. The sort order of the indexed columns is
+// ignored, since an index can be scanned in both directions.
+func (c *simpleCompiler) compileCreateIndex(stmt *ast.CreateIndexStmt) (command.CreateIndex, error) {
+	if stmt.Where != nil {
+		return command.CreateIndex{}, fmt.Errorf("partial index: %w", ErrUnsupported)
+	}
+	if stmt.IndexName == nil {
+		return command.CreateIndex{}, fmt.Errorf("no index name given")
+	}
+	if stmt.TableName == nil {
+		return command.CreateIndex{}, fmt.Errorf("no table name given")
+	}
+
+	cmd := command.CreateIndex{
+		IfNotExists: stmt.If != nil,
+		Unique:      stmt.Unique != nil,
+		Name:        stmt.IndexName.Value(),
+		Table:       stmt.TableName.Value(),
+	}
+	if stmt.SchemaName != nil {
+		cmd.Table = stmt.SchemaName.Value() + "." + cmd.Table
+	}
+
+	seen := make(map[string]bool)
+	for _, col := range stmt.IndexedColumns {
+		if col.ColumnName == nil {
+			return command.CreateIndex{}, fmt.Errorf("index on expression: %w", ErrUnsupported)
+		}
+		if col.Collate != nil {
+			return command.CreateIndex{}, fmt.Errorf("collate: %w", ErrUnsupported)
+		}
+		name := col.ColumnName.Value()
+		if seen[name] {
+			return command.CreateIndex{}, fmt.Errorf("column '%v' occurs more than once", name)
+		}
+		seen[name] = true
+		cmd.Cols = append(cmd.Cols, name)
+	}
+	return cmd, nil
+}
+
 func (c *simpleCompiler) compileDropIndex(stmt *ast.DropIndexStmt) (command.DropIndex, error) {
 	cmd := command.DropIndex{
 		IfExists: stmt.If != nil,
@@ -334,6 +797,95 @@ func (c *simpleCompiler) compileDropIndex(stmt *ast.DropIndexStmt) (command.Drop
 	return cmd, nil
 }
 
+// compileCreateTrigger compiles the given CREATE TRIGGER statement. Besides
+// the compiled condition and statements, the SQL source of them is kept in
+// the command, so that the trigger can be stored and compiled again whenever
+// it fires.
+func (c *simpleCompiler) compileCreateTrigger(stmt *ast.CreateTriggerStmt) (command.CreateTrigger, error) {
+	if stmt.Temp != nil || stmt.Temporary != nil {
+		return command.CreateTrigger{}, fmt.Errorf("temporary trigger: %w", ErrUnsupported)
+	}
+	if stmt.TriggerName == nil {
+		return command.CreateTrigger{}, fmt.Errorf("no trigger name given")
+	}
+	if stmt.TableName == nil {
+		return command.CreateTrigger{}, fmt.Errorf("no table name given")
+	}
+
+	cmd := command.CreateTrigger{
+		IfNotExists: stmt.If != nil,
+		Name:        stmt.TriggerName.Value(),
+		Table:       stmt.TableName.Value(),
+	}
+	if stmt.SchemaName != nil {
+		cmd.Table = stmt.SchemaName.Value() + "." + cmd.Table
+	}
+
+	switch {
+	case stmt.Instead != nil:
+		cmd.Timing = command.TriggerInsteadOf
+	case stmt.After != nil:
+		cmd.Timing = command.TriggerAfter
+	default:
+		cmd.Timing = command.TriggerBefore
+	}
+
+	switch {
+	case stmt.Delete != nil:
+		cmd.Event = command.TriggerDelete
+	case stmt.Insert != nil:
+		cmd.Event = command.TriggerInsert
+	case stmt.Update != nil:
+		cmd.Event = command.TriggerUpdate
+		for _, col := range stmt.ColumnName {
+			cmd.Cols = append(cmd.Cols, col.Value())
+		}
+	default:
+		return command.CreateTrigger{}, fmt.Errorf("no event given")
+	}
+
+	if stmt.Expr != nil {
+		when, err := c.compileExpr(stmt.Expr)
+		if err != nil {
+			return command.CreateTrigger{}, fmt.Errorf("when: %w", err)
+		}
+		cmd.When = when
+		cmd.WhenSource = sourceText(stmt.Expr)
+	}
+
+	// the parser collects the statements by their kind, so the original order
+	// is restored from their position in the source
+	var body []*ast.SQLStmt
+	for _, update := range stmt.UpdateStmt {
+		body = append(body, &ast.SQLStmt{UpdateStmt: update})
+	}
+	for _, insert := range stmt.InsertStmt {
+		body = append(body, &ast.SQLStmt{InsertStmt: insert})
+	}
+	for _, del := range stmt.DeleteStmt {
+		body = append(body, &ast.SQLStmt{DeleteStmt: del})
+	}
+	for _, sel := range stmt.SelectStmt {
+		body = append(body, &ast.SQLStmt{SelectStmt: sel})
+	}
+	if len(body) == 0 {
+		return command.CreateTrigger{}, fmt.Errorf("trigger has no statements")
+	}
+	sort.SliceStable(body, func(i, j int) bool {
+		return sourceOffset(body[i]) < sourceOffset(body[j])
+	})
+
+	for _, bodyStmt := range body {
+		compiled, err := c.compileInternal(bodyStmt)
+		if err != nil {
+			return command.CreateTrigger{}, err
+		}
+		cmd.Statements = append(cmd.Statements, compiled)
+		cmd.StatementSources = append(cmd.StatementSources, sourceText(bodyStmt))
+	}
+	return cmd, nil
+}
+
 func (c *simpleCompiler) compileDropTrigger(stmt *ast.DropTriggerStmt) (command.DropTrigger, error) {
 	cmd := command.DropTrigger{
 		IfExists: stmt.If != nil,
@@ -345,6 +897,44 @@ func (c *simpleCompiler) compileDropTrigger(stmt *ast.DropTriggerStmt) (command.
 	return cmd, nil
 }
 
+func (c *simpleCompiler) compileCreateView(stmt *ast.CreateViewStmt) (command.CreateView, error) {
+	if stmt.Temp != nil || stmt.Temporary != nil {
+		return command.CreateView{}, fmt.Errorf("temporary view: %w", ErrUnsupported)
+	}
+	if stmt.ViewName == nil {
+		return command.CreateView{}, fmt.Errorf("no view name given")
+	}
+	if stmt.SelectStmt == nil {
+		return command.CreateView{}, fmt.Errorf("no select statement given")
+	}
+	viewName := stmt.ViewName.Value()
+	if stmt.SchemaName != nil {
+		viewName = stmt.SchemaName.Value() + "." + viewName
+	}
+
+	var cols []string
+	for _, col := range stmt.ColumnName {
+		cols = append(cols, col.Value())
+	}
+
+	selectCmd, err := c.compileSelect(stmt.SelectStmt)
+	if err != nil {
+		return command.CreateView{}, fmt.Errorf("select: %w", err)
+	}
+	list, ok := selectCmd.(command.List)
+	if !ok {
+		return command.CreateView{}, fmt.Errorf("select compiled to %T, which is not a list", selectCmd)
+	}
+
+	return command.CreateView{
+		IfNotExists: stmt.If != nil,
+		Name:        viewName,
+		Columns:     cols,
+		Query:       sourceText(stmt.SelectStmt),
+		Select:      list,
+	}, nil
+}
+
 func (c *simpleCompiler) compileDropView(stmt *ast.DropViewStmt) (command.DropView, error) {
 	cmd := command.DropView{
 		IfExists: stmt.If != nil,
@@ -357,10 +947,6 @@ func (c *simpleCompiler) compileDropView(stmt *ast.DropViewStmt) (command.DropVi
 }
 
 func (c *simpleCompiler) compileDelete(stmt *ast.DeleteStmt) (command.Delete, error) {
-	if stmt.WithClause != nil {
-		return command.Delete{}, fmt.Errorf("with: %w", ErrUnsupported)
-	}
-
 	var filter command.Expr
 	if stmt.Where != nil {
 		compiled, err := c.compileExpr(stmt.Expr)
@@ -376,12 +962,37 @@ func (c *simpleCompiler) compileDelete(stmt *ast.DeleteStmt) (command.Delete, er
 	if err != nil {
 		return command.Delete{}, fmt.Errorf("qualified table name: %w", err)
 	}
+
+	returning, err := c.compileReturningClause(stmt.ReturningClause)
+	if err != nil {
+		return command.Delete{}, fmt.Errorf("returning: %w", err)
+	}
+
 	return command.Delete{
-		Table:  table,
-		Filter: filter,
+		Table:     table,
+		Filter:    filter,
+		Returning: returning,
 	}, nil
 }
 
+// compileReturningClause compiles the result columns of the given RETURNING
+// clause. If the clause is nil, no columns are returned.
+func (c *simpleCompiler) compileReturningClause(clause *ast.ReturningClause) ([]command.Column, error) {
+	if clause == nil {
+		return nil, nil
+	}
+
+	var cols []command.Column
+	for _, resultColumn := range clause.ResultColumn {
+		col, err := c.compileResultColumn(resultColumn)
+		if err != nil {
+			return nil, fmt.Errorf("result column: %w", err)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
 func (c *simpleCompiler) compileQualifiedTableName(tableName *ast.QualifiedTableName) (command.Table, error) {
 	table := command.SimpleTable{
 		Table: tableName.TableName.Value(),
@@ -395,15 +1006,13 @@ func (c *simpleCompiler) compileQualifiedTableName(tableName *ast.QualifiedTable
 	if tableName.By != nil {
 		table.Indexed = true
 		table.Index = tableName.IndexName.Value()
+	} else if tableName.Not != nil {
+		table.NotIndexed = true
 	}
 	return table, nil
 }
 
 func (c *simpleCompiler) compileSelect(stmt *ast.SelectStmt) (command.Command, error) {
-	if len(stmt.SelectCore) != 1 {
-		return nil, fmt.Errorf("compound select: %w", ErrUnsupported)
-	}
-
 	var cmd command.Command
 	// compile the select core
 	core, err := c.compileSelectCore(stmt.SelectCore[0])
@@ -412,9 +1021,22 @@ func (c *simpleCompiler) compileSelect(stmt *ast.SelectStmt) (command.Command, e
 	}
 	cmd = core
 
+	// compile compound selects, which are evaluated from left to right
+	for i := 1; i < len(stmt.SelectCore); i++ {
+		compound, err := c.compileCompound(stmt.SelectCore[i-1].CompoundOperator, cmd.(command.List), stmt.SelectCore[i])
+		if err != nil {
+			return nil, fmt.Errorf("compound: %w", err)
+		}
+		cmd = compound
+	}
+
 	// compile ORDER BY
 	if stmt.Order != nil {
-		return nil, fmt.Errorf("order: %w", ErrUnsupported)
+		sorted, err := c.compileOrderBy(stmt.OrderingTerm, cmd.(command.List))
+		if err != nil {
+			return nil, fmt.Errorf("order: %w", err)
+		}
+		cmd = sorted
 	}
 
 	// compile LIMIT
@@ -442,14 +1064,223 @@ func (c *simpleCompiler) compileSelect(stmt *ast.SelectStmt) (command.Command, e
 		}
 	}
 
-	return cmd, nil
+	return c.compileWith(stmt.WithClause, stmt, cmd)
 }
 
-func (c *simpleCompiler) compileSelectCore(core *ast.SelectCore) (command.Command, error) {
-	if core.CompoundOperator != nil {
-		return nil, fmt.Errorf("compound statements: %w", ErrUnsupported)
+// compileWith compiles the common tables of the given WITH clause of the given
+// statement, and wraps the given compiled statement into a With, that makes
+// the common tables available to it. If the clause is nil, the compiled
+// statement is returned unchanged. A common table is materialized, if it is
+// recursive, or if it is referenced more than once, so that its query is
+// evaluated only once.
+func (c *simpleCompiler) compileWith(clause *ast.WithClause, stmt interface{}, cmd command.Command) (command.Command, error) {
+	if clause == nil {
+		return cmd, nil
+	}
+
+	var tables []command.CommonTable
+	for _, cte := range clause.RecursiveCte {
+		name := cte.CteTableName.TableName.Value()
+		var cols []string
+		for _, col := range cte.CteTableName.ColumnName {
+			cols = append(cols, col.Value())
+		}
+
+		// references of the table in its own query are not counted
+		selfReferences := countTableReferences(reflect.ValueOf(cte.SelectStmt), name)
+		references := countTableReferences(reflect.ValueOf(stmt), name) - selfReferences
+
+		var input command.List
+		recursive := clause.Recursive != nil && selfReferences > 0
+		if recursive {
+			recursion, err := c.compileRecursion(name, cte.SelectStmt)
+			if err != nil {
+				return nil, fmt.Errorf("common table %v: %w", name, err)
+			}
+			input = recursion
+		} else {
+			query, err := c.compileSubquery(cte.SelectStmt)
+			if err != nil {
+				return nil, fmt.Errorf("common table %v: %w", name, err)
+			}
+			input = query
+		}
+
+		tables = append(tables, command.CommonTable{
+			Name:         name,
+			Cols:         cols,
+			Input:        input,
+			Materialized: recursive || references > 1,
+		})
+	}
+	return command.With{
+		Tables: tables,
+		Input:  cmd,
+	}, nil
+}
+
+// compileRecursion compiles the given select statement of the recursive common
+// table with the given name. The statement must be a compound select, whose
+// last select core is the recursive step, which is combined with the preceding
+// initial select cores by UNION or UNION ALL. Only the step may reference the
+// common table.
+func (c *simpleCompiler) compileRecursion(name string, stmt *ast.SelectStmt) (command.Recursion, error) {
+	if stmt.WithClause != nil || stmt.Order != nil || stmt.Limit != nil {
+		return command.Recursion{}, fmt.Errorf("with, order or limit in recursive select: %w", ErrUnsupported)
+	}
+	cores := len(stmt.SelectCore)
+	if cores < 2 {
+		return command.Recursion{}, fmt.Errorf("recursive select is not a compound select")
+	}
+	op := stmt.SelectCore[cores-2].CompoundOperator
+	if op.Union == nil {
+		return command.Recursion{}, fmt.Errorf("recursive select must be combined with UNION or UNION ALL")
+	}
+
+	initialStmt := &ast.SelectStmt{SelectCore: stmt.SelectCore[:cores-1]}
+	if countTableReferences(reflect.ValueOf(initialStmt), name) > 0 {
+		return command.Recursion{}, fmt.Errorf("initial select must not reference %v", name)
+	}
+	initial, err := c.compileSubquery(initialStmt)
+	if err != nil {
+		return command.Recursion{}, fmt.Errorf("initial: %w", err)
+	}
+	step, err := c.compileSubquery(&ast.SelectStmt{SelectCore: stmt.SelectCore[cores-1:]})
+	if err != nil {
+		return command.Recursion{}, fmt.Errorf("step: %w", err)
+	}
+	return command.Recursion{
+		Initial:  initial,
+		Step:     step,
+		Distinct: op.All == nil,
+	}, nil
+}
+
+// compileOrderBy wraps the given list into a sort with the given ordering
+// terms. If the list is a projection, the sort is performed on the input of the
+// projection, so that columns which are not projected can be used in the
+// ordering terms. Terms that reference the alias or the (1-based) position of a
+// projected column are replaced with the expression of that column.
+func (c *simpleCompiler) compileOrderBy(orderingTerms []*ast.OrderingTerm, list command.List) (command.List, error) {
+	terms := make([]command.OrderingTerm, len(orderingTerms))
+	for i, orderingTerm := range orderingTerms {
+		term, err := c.compileOrderingTerm(orderingTerm)
+		if err != nil {
+			return nil, err
+		}
+		terms[i] = term
+	}
+
+	if compound, ok := list.(command.Compound); ok {
+		for i, term := range terms {
+			expr, err := resolveCompoundColumn(compound, term.Expr)
+			if err != nil {
+				return nil, err
+			}
+			terms[i].Expr = expr
+		}
 	}
 
+	project, ok := list.(command.Project)
+	if !ok {
+		return command.Sort{
+			Terms: terms,
+			Input: list,
+		}, nil
+	}
+
+	for i, term := range terms {
+		expr, err := resolveProjectedColumn(project.Cols, term.Expr)
+		if err != nil {
+			return nil, err
+		}
+		terms[i].Expr = expr
+	}
+	project.Input = command.Sort{
+		Terms: terms,
+		Input: sortAggregated(terms, project.Input),
+	}
+	return project, nil
+}
+
+// sortAggregated prepares the given input list, which is the input of a sort
+// with the given terms, for sorting, if the list is an aggregate (possibly
+// filtered by a HAVING clause). The terms are rewritten to reference the
+// columns of the aggregate, and aggregate function calls in the terms, that
+// are not computed by the aggregate yet, are added to the aggregate.
+func sortAggregated(terms []command.OrderingTerm, input command.List) command.List {
+	switch list := input.(type) {
+	case command.Select:
+		list.Input = sortAggregated(terms, list.Input)
+		return list
+	case command.Aggregate:
+		for i, term := range terms {
+			list.Aggregates = collectAggregates(list.Aggregates, term.Expr)
+			terms[i].Expr = aggregatedExpr(term.Expr, list.GroupBy)
+		}
+		return list
+	}
+	return input
+}
+
+// resolveProjectedColumn returns the expression of the projected column, that
+// the given expression references by alias or by position. If the expression
+// does not reference a projected column, it is returned unchanged.
+func resolveProjectedColumn(cols []command.Column, expr command.Expr) (command.Expr, error) {
+	var name string
+	switch e := expr.(type) {
+	case command.ColumnReference:
+		name = e.Name
+	case command.ConstantLiteralOrColumnReference:
+		name = e.ValueOrName
+	case command.ConstantLiteral:
+		if !e.Numeric {
+			return expr, nil
+		}
+		pos, err := strconv.Atoi(e.Value)
+		if err != nil || pos < 1 || pos > len(cols) {
+			return nil, fmt.Errorf("term out of range: %v", e.Value)
+		}
+		for _, col := range cols {
+			if ref, ok := col.Expr.(command.ColumnReference); ok && ref.Name == "*" {
+				return nil, fmt.Errorf("term by position with asterisk: %w", ErrUnsupported)
+			}
+		}
+		return cols[pos-1].Expr, nil
+	default:
+		return expr, nil
+	}
+
+	for _, col := range cols {
+		if col.Alias != "" && col.Alias == name {
+			return col.Expr, nil
+		}
+	}
+	return expr, nil
+}
+
+func (c *simpleCompiler) compileOrderingTerm(term *ast.OrderingTerm) (command.OrderingTerm, error) {
+	expr, err := c.compileExpr(term.Expr)
+	if err != nil {
+		return command.OrderingTerm{}, fmt.Errorf("expr: %w", err)
+	}
+
+	desc := term.Desc != nil
+	// NULL is smaller than any other value, so unless specified otherwise,
+	// NULLs come first in ascending and last in descending order
+	nullsFirst := !desc
+	if term.Nulls != nil {
+		nullsFirst = term.First != nil
+	}
+
+	return command.OrderingTerm{
+		Expr:       expr,
+		Desc:       desc,
+		NullsFirst: nullsFirst,
+	}, nil
+}
+
+func (c *simpleCompiler) compileSelectCore(core *ast.SelectCore) (command.Command, error) {
 	if core.Values != nil {
 		return c.compileSelectCoreValues(core)
 	}
@@ -510,28 +1341,27 @@ func (c *simpleCompiler) compileSelectCoreSelect(core *ast.SelectCore) (command.
 			return nil, fmt.Errorf("table or subquery: %w", err)
 		}
 
-		if tbl, ok := table.(command.Table); ok {
-			selectionInput = command.Scan{
-				Table: tbl,
-			}
-		} else if subQ, ok := table.(command.List); ok {
-			selectionInput = subQ
-		} else {
-			return nil, fmt.Errorf("unsupported subquery: %T", table)
+		selectionInput = command.Scan{
+			Table: table,
 		}
 	} else if len(core.TableOrSubquery) == 0 {
 		if core.JoinClause == nil {
-			return command.Project{
-				Cols:  cols,
-				Input: command.Values{Values: [][]command.Expr{}},
-			}, nil
-		}
-
-		join, err := c.compileJoin(core.JoinClause)
-		if err != nil {
-			return nil, fmt.Errorf("join: %w", err)
+			if core.Expr1 == nil {
+				return command.Project{
+					Cols:  cols,
+					Input: command.Values{Values: [][]command.Expr{}},
+				}, nil
+			}
+			// without a table, a WHERE clause filters a single row, that
+			// holds no meaningful values
+			selectionInput = command.Values{Values: [][]command.Expr{{command.ConstantBooleanExpr{Value: true}}}}
+		} else {
+			join, err := c.compileJoin(core.JoinClause)
+			if err != nil {
+				return nil, fmt.Errorf("join: %w", err)
+			}
+			selectionInput = join
 		}
-		selectionInput = join
 	} else {
 		return nil, fmt.Errorf("table and join constellation: %w", ErrUnsupported)
 	}
@@ -556,6 +1386,48 @@ func (c *simpleCompiler) compileSelectCoreSelect(core *ast.SelectCore) (command.
 		}
 	}
 
+	// wrap input into an aggregate if there is a GROUP BY or HAVING clause,
+	// or if any of the projected columns calls an aggregate function
+	var aggregates []command.FunctionExpr
+	for _, col := range cols {
+		aggregates = collectAggregates(aggregates, col.Expr)
+	}
+	if core.Group != nil || core.Having != nil || len(aggregates) > 0 {
+		var groupBy []command.Expr
+		for _, expr := range core.Expr2 { // GROUP BY expr2...
+			compiled, err := c.compileExpr(expr)
+			if err != nil {
+				return nil, fmt.Errorf("group by: %w", err)
+			}
+			groupBy = append(groupBy, compiled)
+		}
+
+		var having command.Expr
+		if core.Expr3 != nil { // HAVING expr3
+			compiled, err := c.compileExpr(core.Expr3)
+			if err != nil {
+				return nil, fmt.Errorf("having: %w", err)
+			}
+			having = compiled
+			aggregates = collectAggregates(aggregates, having)
+		}
+
+		input = command.Aggregate{
+			GroupBy:    groupBy,
+			Aggregates: aggregates,
+			Input:      input,
+		}
+		if having != nil {
+			input = command.Select{
+				Filter: aggregatedExpr(having, groupBy),
+				Input:  input,
+			}
+		}
+		for i := range cols {
+			cols[i].Expr = aggregatedExpr(cols[i].Expr, groupBy)
+		}
+	}
+
 	// wrap columns and input into projection
 	var list command.List
 	list = command.Project{
@@ -614,7 +1486,7 @@ func (c *simpleCompiler) compileExpr(expr *ast.Expr) (command.Expr, error) {
 			}
 			return command.ConstantLiteralOrColumnReference{ValueOrName: unquoted}, nil
 		} else if strings.HasPrefix(literalValue, "'") {
-			unquoted, err := strconv.Unquote(literalValue)
+			unquoted, err := unquoteSingleQuoted(literalValue)
 			if err != nil {
 				return nil, fmt.Errorf("unquote: %w", err)
 			}
@@ -623,6 +1495,15 @@ func (c *simpleCompiler) compileExpr(expr *ast.Expr) (command.Expr, error) {
 			return command.ConstantLiteral{Value: literalValue, Numeric: true}, nil
 		}
 		return command.ColumnReference{Name: literalValue}, nil
+	case expr.ColumnName != nil:
+		if expr.SchemaName != nil {
+			return nil, fmt.Errorf("schema qualified column: %w", ErrUnsupported)
+		}
+		name := expr.ColumnName.Value()
+		if expr.TableName != nil {
+			name = expr.TableName.Value() + "." + name
+		}
+		return command.ColumnReference{Name: name}, nil
 	case expr.UnaryOperator != nil:
 		val, err := c.compileExpr(expr.Expr1)
 		if err != nil {
@@ -715,11 +1596,13 @@ func (c *simpleCompiler) compileExpr(expr *ast.Expr) (command.Expr, error) {
 		if !(expr.FilterClause == nil && expr.OverClause == nil) {
 			return nil, fmt.Errorf("filter or over on function: %w", ErrUnsupported)
 		}
+		var args []command.Expr
 		if expr.Asterisk != nil {
-			return nil, fmt.Errorf("function_name(*): %w", ErrUnsupported)
+			if !strings.EqualFold(expr.FunctionName.Value(), "COUNT") {
+				return nil, fmt.Errorf("function_name(*): %w", ErrUnsupported)
+			}
+			args = append(args, command.ColumnReference{Name: "*"})
 		}
-
-		var args []command.Expr
 		for _, arg := range expr.Expr {
 			compiledArg, err := c.compileExpr(arg)
 			if err != nil {
@@ -733,26 +1616,139 @@ func (c *simpleCompiler) compileExpr(expr *ast.Expr) (command.Expr, error) {
 			Distinct: expr.Distinct != nil,
 			Args:     args,
 		}, nil
+	case expr.Between != nil:
+		needle, err := c.compileExpr(expr.Expr1)
+		if err != nil {
+			return nil, fmt.Errorf("expr1: %w", err)
+		}
+		lo, err := c.compileExpr(expr.Expr2)
+		if err != nil {
+			return nil, fmt.Errorf("expr2: %w", err)
+		}
+		hi, err := c.compileExpr(expr.Expr3)
+		if err != nil {
+			return nil, fmt.Errorf("expr3: %w", err)
+		}
+		return command.RangeExpr{
+			Needle: needle,
+			Lo:     lo,
+			Hi:     hi,
+			Invert: expr.Not != nil,
+		}, nil
+	case expr.Like != nil:
+		value, err := c.compileExpr(expr.Expr1)
+		if err != nil {
+			return nil, fmt.Errorf("expr1: %w", err)
+		}
+		pattern, err := c.compileExpr(expr.Expr2)
+		if err != nil {
+			return nil, fmt.Errorf("expr2: %w", err)
+		}
+		var escape command.Expr
+		if expr.Escape != nil {
+			if escape, err = c.compileExpr(expr.Expr3); err != nil {
+				return nil, fmt.Errorf("expr3: %w", err)
+			}
+		}
+		return command.LikeExpr{
+			Value:   value,
+			Pattern: pattern,
+			Escape:  escape,
+			Invert:  expr.Not != nil,
+		}, nil
+	case expr.RaiseFunction != nil:
+		return c.compileRaiseFunction(expr.RaiseFunction)
+	case expr.Exists != nil:
+		query, err := c.compileSubquery(expr.SelectStmt)
+		if err != nil {
+			return nil, err
+		}
+		return command.ExistsExpr{
+			Query:  query,
+			Invert: expr.Not != nil,
+		}, nil
+	case expr.In != nil:
+		if expr.SelectStmt == nil {
+			return nil, fmt.Errorf("in without select: %w", ErrUnsupported)
+		}
+		needle, err := c.compileExpr(expr.Expr1)
+		if err != nil {
+			return nil, fmt.Errorf("expr1: %w", err)
+		}
+		query, err := c.compileSubquery(expr.SelectStmt)
+		if err != nil {
+			return nil, err
+		}
+		return command.InExpr{
+			Needle: needle,
+			Query:  query,
+			Invert: expr.Not != nil,
+		}, nil
+	case expr.SelectStmt != nil:
+		query, err := c.compileSubquery(expr.SelectStmt)
+		if err != nil {
+			return nil, err
+		}
+		return command.SubqueryExpr{
+			Query:  query,
+			Source: sourceText(expr),
+		}, nil
 	}
 
 	return nil, ErrUnsupported
 }
 
+// compileSubquery compiles the given select statement, which is nested in
+// another statement, into a list.
+func (c *simpleCompiler) compileSubquery(stmt *ast.SelectStmt) (command.List, error) {
+	compiled, err := c.compileSelect(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("subquery: %w", err)
+	}
+	list, ok := compiled.(command.List)
+	if !ok {
+		return nil, fmt.Errorf("subquery is not a list but %T", compiled)
+	}
+	return list, nil
+}
+
+func (c *simpleCompiler) compileRaiseFunction(raise *ast.RaiseFunction) (command.Expr, error) {
+	if raise.Ignore != nil {
+		return command.RaiseExpr{Action: command.RaiseIgnore}, nil
+	}
+
+	var action command.RaiseAction
+	switch {
+	case raise.Rollback != nil:
+		action = command.RaiseRollback
+	case raise.Abort != nil:
+		action = command.RaiseAbort
+	case raise.Fail != nil:
+		action = command.RaiseFail
+	default:
+		return nil, fmt.Errorf("no raise action given")
+	}
+	if raise.ErrorMessage == nil {
+		return nil, fmt.Errorf("no error message given")
+	}
+	message, err := unquoteSingleQuoted(raise.ErrorMessage.Value())
+	if err != nil {
+		return nil, fmt.Errorf("unquote: %w", err)
+	}
+	return command.RaiseExpr{
+		Action:  action,
+		Message: message,
+	}, nil
+}
+
 func (c *simpleCompiler) compileJoin(join *ast.JoinClause) (command.List, error) {
 	left, err := c.compileTableOrSubquery(join.TableOrSubquery)
 	if err != nil {
 		return command.Join{}, fmt.Errorf("table or subquery: %w", err)
 	}
 
-	var prev command.List
-	if tbl, ok := left.(command.Table); ok {
-		prev = command.Scan{
-			Table: tbl,
-		}
-	} else if subQ, ok := left.(command.List); ok {
-		prev = subQ
-	} else {
-		return nil, fmt.Errorf("unsupported subquery: %T", left)
+	var prev command.List = command.Scan{
+		Table: left,
 	}
 
 	for _, part := range join.JoinClausePart {
@@ -783,7 +1779,7 @@ func (c *simpleCompiler) compileJoin(join *ast.JoinClause) (command.List, error)
 		if part.JoinConstraint != nil && part.JoinConstraint.On != nil {
 			filter, err = c.compileExpr(part.JoinConstraint.Expr)
 			if err != nil {
-				return nil, fmt.Errorf("expressoin: %w", err)
+				return nil, fmt.Errorf("expression: %w", err)
 			}
 		}
 
@@ -792,36 +1788,34 @@ func (c *simpleCompiler) compileJoin(join *ast.JoinClause) (command.List, error)
 			return command.Join{}, fmt.Errorf("table or subquery: %w", err)
 		}
 
-		var right command.List
-		if tbl, ok := table.(command.Table); ok {
-			right = command.Scan{
-				Table: tbl,
-			}
-		} else if subQ, ok := table.(command.List); ok {
-			right = subQ
-		} else {
-			return nil, fmt.Errorf("unsupported subquery: %T", table)
-		}
-
 		prev = command.Join{
 			Natural: natural,
 			Type:    typ,
 			Filter:  filter,
 			Left:    prev,
-			Right:   right,
+			Right: command.Scan{
+				Table: table,
+			},
 		}
 	}
 
 	return prev, nil
 }
 
-func (c *simpleCompiler) compileTableOrSubquery(tos *ast.TableOrSubquery) (command.Command, error) {
+func (c *simpleCompiler) compileTableOrSubquery(tos *ast.TableOrSubquery) (command.Table, error) {
 	if tos.SelectStmt != nil {
-		result, err := c.compileSelect(tos.SelectStmt)
+		input, err := c.compileSubquery(tos.SelectStmt)
 		if err != nil {
-			return nil, fmt.Errorf("select: %w", err)
+			return nil, err
 		}
-		return result, nil
+		var alias string
+		if tos.TableAlias != nil {
+			alias = tos.TableAlias.Value()
+		}
+		return command.DerivedTable{
+			Input: input,
+			Alias: alias,
+		}, nil
 	}
 
 	if tos.TableName == nil {
@@ -836,10 +1830,49 @@ func (c *simpleCompiler) compileTableOrSubquery(tos *ast.TableOrSubquery) (comma
 	if tos.SchemaName != nil {
 		schema = tos.SchemaName.Value()
 	}
+	var alias string
+	if tos.TableAlias != nil {
+		alias = tos.TableAlias.Value()
+	}
 	return command.SimpleTable{
-		Schema:  schema,
-		Table:   tos.TableName.Value(),
-		Indexed: tos.By != nil,
-		Index:   index,
+		Schema:     schema,
+		Table:      tos.TableName.Value(),
+		Alias:      alias,
+		Indexed:    tos.By != nil,
+		Index:      index,
+		NotIndexed: tos.Not != nil,
 	}, nil
 }
+
+// unquoteSingleQuoted removes the single quotes around the given string
+// literal, and resolves characters that are escaped with a backslash.
+func unquoteSingleQuoted(literal string) (string, error) {
+	if len(literal) < 2 || !strings.HasPrefix(literal, "'") || !strings.HasSuffix(literal, "'") {
+		return "", fmt.Errorf("invalid string literal %v", literal)
+	}
+
+	var buf strings.Builder
+	escaped := false
+	for _, r := range literal[1 : len(literal)-1] {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		if escaped {
+			switch r {
+			case 'n':
+				r = '\n'
+			case 't':
+				r = '\t'
+			case 'r':
+				r = '\r'
+			}
+			escaped = false
+		}
+		buf.WriteRune(r)
+	}
+	if escaped {
+		return "", fmt.Errorf("invalid string literal %v", literal)
+	}
+	return buf.String(), nil
+}
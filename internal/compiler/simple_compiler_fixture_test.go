@@ -82,6 +82,11 @@ func _TestCompileSelect(t *testing.T) {
 		"SELECT AVG(price) AS avg_price FROM items LEFT JOIN prices",
 		"SELECT AVG(DISTINCT price) AS avg_price FROM items LEFT JOIN prices",
 		"VALUES (1,2,3),(4,5,6),(7,8,9)",
+		"SELECT * FROM a JOIN b ON a.id = b.id",
+		"SELECT name FROM myTable ORDER BY id DESC",
+		"SELECT COUNT(*) FROM myTable",
+		"SELECT name, SUM(amount) AS total FROM myTable GROUP BY name HAVING COUNT(DISTINCT id) > 1 ORDER BY MAX(amount)",
+		"SELECT name FROM a UNION SELECT name FROM b INTERSECT SELECT DISTINCT name FROM c",
 	}
 	for _, test := range tests {
 		RunGolden(t, test)
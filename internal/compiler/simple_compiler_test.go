@@ -5,7 +5,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/types"
 	"github.com/xqueries/xdb/internal/parser"
+	"github.com/xqueries/xdb/internal/parser/ast"
 )
 
 type testcase struct {
@@ -21,9 +23,54 @@ func TestSimpleCompiler_Compile_NoOptimizations(t *testing.T) {
 	t.Run("drop", _TestSimpleCompilerCompileDropNoOptimizations)
 	t.Run("update", _TestSimpleCompilerCompileUpdateNoOptimizations)
 	t.Run("insert", _TestSimpleCompilerCompileInsertNoOptimizations)
+	t.Run("create table", _TestSimpleCompilerCompileCreateTableNoOptimizations)
+	t.Run("create index", _TestSimpleCompilerCompileCreateIndexNoOptimizations)
+	t.Run("alter table", _TestSimpleCompilerCompileAlterTableNoOptimizations)
+	t.Run("create view", _TestSimpleCompilerCompileCreateViewNoOptimizations)
+	t.Run("create trigger", _TestSimpleCompilerCompileCreateTriggerNoOptimizations)
+	t.Run("subquery", _TestSimpleCompilerCompileSubqueryNoOptimizations)
+	t.Run("with", _TestSimpleCompilerCompileWithNoOptimizations)
 	t.Run("negative test", _TestSimpleCompilerNegativeTests)
 }
 
+func TestSimpleCompiler_Compile_Explain(t *testing.T) {
+	assert := assert.New(t)
+
+	limitToOne := func(cmd command.Command) (command.Command, bool) {
+		return command.Limit{
+			Limit: command.ConstantLiteral{Value: "1", Numeric: true},
+			Input: cmd.(command.List),
+		}, true
+	}
+	notApplicable := func(command.Command) (command.Command, bool) {
+		return nil, false
+	}
+	c := New(
+		OptionEnableOptimization("limit to one", limitToOne),
+		OptionEnableOptimization("not applicable", notApplicable),
+	)
+
+	p, err := parser.New("EXPLAIN QUERY PLAN SELECT * FROM myTable")
+	assert.NoError(err)
+	stmt, errs, ok := p.Next()
+	assert.Len(errs, 0)
+	assert.True(ok)
+
+	got, err := c.Compile(stmt)
+	assert.NoError(err)
+	assert.Equal(command.Explain{
+		Command: command.Limit{
+			Limit: command.ConstantLiteral{Value: "1", Numeric: true},
+			Input: command.Project{
+				Cols:  []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+				Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+			},
+		},
+		QueryPlan:     true,
+		Optimizations: []string{"limit to one"},
+	}, got)
+}
+
 func _TestSimpleCompilerNegativeTests(t *testing.T) {
 	tests := []testcase{
 		{
@@ -32,6 +79,742 @@ func _TestSimpleCompilerNegativeTests(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:    "order by position out of range",
+			input:   `SELECT name FROM myTable ORDER BY 2`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "compound with different column counts",
+			input:   `SELECT a, b FROM myTable UNION SELECT a FROM otherTable`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "compound with incompatible values",
+			input:   `VALUES (1) UNION VALUES ('a')`,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileCreateTableNoOptimizations(t *testing.T) {
+	tests := []testcase{
+		{
+			"simple create table",
+			"CREATE TABLE myTable (col1 INTEGER, col2 STRING)",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer},
+					{Name: "col2", Type: types.String},
+				},
+			},
+			false,
+		},
+		{
+			"create table if not exists",
+			"CREATE TABLE IF NOT EXISTS myTable (col1 INTEGER)",
+			command.CreateTable{
+				IfNotExists: true,
+				Name:        "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer},
+				},
+			},
+			false,
+		},
+		{
+			"create table with defaults",
+			"CREATE TABLE myTable (col1 INTEGER DEFAULT -1, col2 STRING DEFAULT 'it\\'s', col3 REAL DEFAULT (1 + 2))",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{
+						Name: "col1",
+						Type: types.Integer,
+						Default: command.UnaryNegativeExpr{
+							UnaryBase: command.UnaryBase{Value: command.ConstantLiteral{Value: "1", Numeric: true}},
+						},
+					},
+					{
+						Name:    "col2",
+						Type:    types.String,
+						Default: command.ConstantLiteral{Value: "it's"},
+					},
+					{
+						Name: "col3",
+						Type: types.Real,
+						Default: command.AddExpression{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ConstantLiteral{Value: "1", Numeric: true},
+								Right: command.ConstantLiteral{Value: "2", Numeric: true},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"create table with keys",
+			"CREATE TABLE myTable (col1 INTEGER PRIMARY KEY, col2 STRING UNIQUE)",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer, PrimaryKey: true},
+					{Name: "col2", Type: types.String, Unique: true},
+				},
+			},
+			false,
+		},
+		{
+			"create table with multiple primary keys",
+			"CREATE TABLE myTable (col1 INTEGER PRIMARY KEY, col2 STRING PRIMARY KEY)",
+			nil,
+			true,
+		},
+		{
+			"create table with constraints",
+			"CREATE TABLE myTable (col1 INTEGER NOT NULL CHECK (col1 >= 0), col2 STRING, PRIMARY KEY (col1, col2), CONSTRAINT positive CHECK (col1 > 0))",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer, NotNull: true},
+					{Name: "col2", Type: types.String},
+				},
+				PrimaryKey: []string{"col1", "col2"},
+				Checks: []command.Check{
+					{
+						Expr: command.GreaterThanOrEqualToExpr{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ColumnReference{Name: "col1"},
+								Right: command.ConstantLiteral{Value: "0", Numeric: true},
+							},
+						},
+						Source: "col1 >= 0",
+					},
+					{
+						Name: "positive",
+						Expr: command.GreaterThanExpr{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ColumnReference{Name: "col1"},
+								Right: command.ConstantLiteral{Value: "0", Numeric: true},
+							},
+						},
+						Source: "col1 > 0",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"create table with unique constraints",
+			"CREATE TABLE myTable (col1 INTEGER UNIQUE, col2 STRING, UNIQUE (col1, col2), UNIQUE (col2))",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer, Unique: true},
+					{Name: "col2", Type: types.String},
+				},
+				UniqueKeys: [][]string{
+					{"col1", "col2"},
+					{"col2"},
+				},
+			},
+			false,
+		},
+		{
+			"create table with duplicate unique column",
+			"CREATE TABLE myTable (col1 INTEGER, UNIQUE (col1, col1))",
+			nil,
+			true,
+		},
+		{
+			"create table with foreign keys",
+			"CREATE TABLE myTable (col1 INTEGER REFERENCES other ON DELETE CASCADE, col2 STRING, CONSTRAINT fk FOREIGN KEY (col1, col2) REFERENCES other (a, b) ON UPDATE SET NULL ON DELETE RESTRICT DEFERRABLE INITIALLY DEFERRED)",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer},
+					{Name: "col2", Type: types.String},
+				},
+				ForeignKeys: []command.ForeignKey{
+					{
+						Cols:         []string{"col1"},
+						ForeignTable: "other",
+						OnDelete:     command.ForeignKeyCascade,
+					},
+					{
+						Name:         "fk",
+						Cols:         []string{"col1", "col2"},
+						ForeignTable: "other",
+						ForeignCols:  []string{"a", "b"},
+						OnDelete:     command.ForeignKeyRestrict,
+						OnUpdate:     command.ForeignKeySetNull,
+						Deferred:     true,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"create table with foreign key with mismatching columns",
+			"CREATE TABLE myTable (col1 INTEGER, FOREIGN KEY (col1) REFERENCES other (a, b))",
+			nil,
+			true,
+		},
+		{
+			"create table with foreign key on unknown column",
+			"CREATE TABLE myTable (col1 INTEGER, FOREIGN KEY (col2) REFERENCES other)",
+			nil,
+			true,
+		},
+		{
+			"create table with foreign key with set default",
+			"CREATE TABLE myTable (col1 INTEGER REFERENCES other ON DELETE SET DEFAULT)",
+			nil,
+			true,
+		},
+		{
+			"create table with primary key constraint and primary key column",
+			"CREATE TABLE myTable (col1 INTEGER PRIMARY KEY, col2 STRING, PRIMARY KEY (col2))",
+			nil,
+			true,
+		},
+		{
+			"create table with primary key on unknown column",
+			"CREATE TABLE myTable (col1 INTEGER, PRIMARY KEY (col2))",
+			nil,
+			true,
+		},
+		{
+			"create table without rowid",
+			"CREATE TABLE myTable (col1 INTEGER, col2 STRING, PRIMARY KEY (col2, col1)) WITHOUT ROWID",
+			command.CreateTable{
+				Name: "myTable",
+				ColumnDefs: []command.ColumnDef{
+					{Name: "col1", Type: types.Integer},
+					{Name: "col2", Type: types.String},
+				},
+				PrimaryKey:   []string{"col2", "col1"},
+				WithoutRowID: true,
+			},
+			false,
+		},
+		{
+			"create table without rowid and primary key",
+			"CREATE TABLE myTable (col1 INTEGER UNIQUE) WITHOUT ROWID",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileCreateIndexNoOptimizations(t *testing.T) {
+	tests := []testcase{
+		{
+			"simple create index",
+			"CREATE INDEX myIndex ON myTable (col1)",
+			command.CreateIndex{
+				Name:  "myIndex",
+				Table: "myTable",
+				Cols:  []string{"col1"},
+			},
+			false,
+		},
+		{
+			"create unique index if not exists",
+			"CREATE UNIQUE INDEX IF NOT EXISTS myIndex ON myTable (col1, col2 DESC)",
+			command.CreateIndex{
+				IfNotExists: true,
+				Unique:      true,
+				Name:        "myIndex",
+				Table:       "myTable",
+				Cols:        []string{"col1", "col2"},
+			},
+			false,
+		},
+		{
+			"qualified create index",
+			"CREATE INDEX mySchema.myIndex ON myTable (col1)",
+			command.CreateIndex{
+				Name:  "myIndex",
+				Table: "mySchema.myTable",
+				Cols:  []string{"col1"},
+			},
+			false,
+		},
+		{
+			"create index with duplicate column",
+			"CREATE INDEX myIndex ON myTable (col1, col1)",
+			nil,
+			true,
+		},
+		{
+			"create partial index",
+			"CREATE INDEX myIndex ON myTable (col1) WHERE col1 > 5",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileAlterTableNoOptimizations(t *testing.T) {
+	tests := []testcase{
+		{
+			"add column",
+			"ALTER TABLE myTable ADD COLUMN col2 INTEGER NOT NULL DEFAULT 5 CHECK (col2 > 0)",
+			command.AddColumn{
+				Table: "myTable",
+				Column: command.ColumnDef{
+					Name:    "col2",
+					Type:    types.Integer,
+					Default: command.ConstantLiteral{Value: "5", Numeric: true},
+					NotNull: true,
+				},
+				Checks: []command.Check{
+					{
+						Expr: command.GreaterThanExpr{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ColumnReference{Name: "col2"},
+								Right: command.ConstantLiteral{Value: "0", Numeric: true},
+							},
+						},
+						Source: "col2 > 0",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"add column implicit",
+			"ALTER TABLE mySchema.myTable ADD col2 STRING",
+			command.AddColumn{
+				Table: "mySchema.myTable",
+				Column: command.ColumnDef{
+					Name: "col2",
+					Type: types.String,
+				},
+			},
+			false,
+		},
+		{
+			"add primary key column",
+			"ALTER TABLE myTable ADD COLUMN col2 INTEGER PRIMARY KEY",
+			nil,
+			true,
+		},
+		{
+			"add not null column without default",
+			"ALTER TABLE myTable ADD COLUMN col2 INTEGER NOT NULL",
+			nil,
+			true,
+		},
+		{
+			"rename column",
+			"ALTER TABLE myTable RENAME COLUMN col1 TO col2",
+			command.RenameColumn{
+				Table:   "myTable",
+				Column:  "col1",
+				NewName: "col2",
+			},
+			false,
+		},
+		{
+			"rename table",
+			"ALTER TABLE myTable RENAME TO otherTable",
+			command.RenameTable{
+				Table:   "myTable",
+				NewName: "otherTable",
+			},
+			false,
+		},
+		{
+			"rename table with schema",
+			"ALTER TABLE mySchema.myTable RENAME TO otherTable",
+			command.RenameTable{
+				Table:   "mySchema.myTable",
+				NewName: "mySchema.otherTable",
+			},
+			false,
+		},
+		{
+			"drop column",
+			"ALTER TABLE myTable DROP COLUMN col1",
+			command.DropColumn{
+				Table:  "myTable",
+				Column: "col1",
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileCreateViewNoOptimizations(t *testing.T) {
+	tests := []testcase{
+		{
+			"simple create view",
+			"CREATE VIEW myView AS SELECT col1, col2 FROM myTable WHERE col1 > 5",
+			command.CreateView{
+				Name:  "myView",
+				Query: "SELECT col1 , col2 FROM myTable WHERE col1 > 5",
+				Select: command.Project{
+					Cols: []command.Column{
+						{Expr: command.ColumnReference{Name: "col1"}},
+						{Expr: command.ColumnReference{Name: "col2"}},
+					},
+					Input: command.Select{
+						Filter: command.GreaterThanExpr{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ColumnReference{Name: "col1"},
+								Right: command.ConstantLiteral{Value: "5", Numeric: true},
+							},
+						},
+						Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"create view with columns",
+			"CREATE VIEW IF NOT EXISTS mySchema.myView (a, b) AS SELECT * FROM myTable",
+			command.CreateView{
+				IfNotExists: true,
+				Name:        "mySchema.myView",
+				Columns:     []string{"a", "b"},
+				Query:       "SELECT * FROM myTable",
+				Select: command.Project{
+					Cols:  []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+					Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+				},
+			},
+			false,
+		},
+		{
+			"create temporary view",
+			"CREATE TEMP VIEW myView AS SELECT * FROM myTable",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileCreateTriggerNoOptimizations(t *testing.T) {
+	tests := []testcase{
+		{
+			"before update trigger",
+			"CREATE TRIGGER IF NOT EXISTS checkQty BEFORE UPDATE OF qty, price ON myTable WHEN NEW.qty < 0 BEGIN DELETE FROM log WHERE id = OLD.id; SELECT RAISE(ABORT, 'negative quantity'); END",
+			command.CreateTrigger{
+				IfNotExists: true,
+				Name:        "checkQty",
+				Table:       "myTable",
+				Timing:      command.TriggerBefore,
+				Event:       command.TriggerUpdate,
+				Cols:        []string{"qty", "price"},
+				When: command.LessThanExpr{
+					BinaryBase: command.BinaryBase{
+						Left:  command.ColumnReference{Name: "NEW.qty"},
+						Right: command.ConstantLiteral{Value: "0", Numeric: true},
+					},
+				},
+				WhenSource: "NEW . qty < 0",
+				Statements: []command.Command{
+					command.Delete{
+						Table: command.SimpleTable{Table: "log"},
+						Filter: command.EqualityExpr{
+							BinaryBase: command.BinaryBase{
+								Left:  command.ColumnReference{Name: "id"},
+								Right: command.ColumnReference{Name: "OLD.id"},
+							},
+						},
+					},
+					command.Project{
+						Cols: []command.Column{
+							{Expr: command.RaiseExpr{Action: command.RaiseAbort, Message: "negative quantity"}},
+						},
+						Input: command.Values{Values: [][]command.Expr{}},
+					},
+				},
+				StatementSources: []string{
+					"DELETE FROM log WHERE id = OLD . id",
+					"SELECT RAISE ( ABORT , 'negative quantity' )",
+				},
+			},
+			false,
+		},
+		{
+			"after insert trigger",
+			"CREATE TRIGGER logInsert AFTER INSERT ON myTable BEGIN INSERT INTO log VALUES (NEW.id); END",
+			command.CreateTrigger{
+				Name:   "logInsert",
+				Table:  "myTable",
+				Timing: command.TriggerAfter,
+				Event:  command.TriggerInsert,
+				Statements: []command.Command{
+					command.Insert{
+						Table: command.SimpleTable{Table: "log"},
+						Input: command.Values{Values: [][]command.Expr{{command.ColumnReference{Name: "NEW.id"}}}},
+					},
+				},
+				StatementSources: []string{"INSERT INTO log VALUES ( NEW . id )"},
+			},
+			false,
+		},
+		{
+			"instead of trigger with raise in where",
+			"CREATE TRIGGER ignoreDelete INSTEAD OF DELETE ON myView BEGIN SELECT RAISE(IGNORE) WHERE OLD.id > 5; END",
+			command.CreateTrigger{
+				Name:   "ignoreDelete",
+				Table:  "myView",
+				Timing: command.TriggerInsteadOf,
+				Event:  command.TriggerDelete,
+				Statements: []command.Command{
+					command.Project{
+						Cols: []command.Column{
+							{Expr: command.RaiseExpr{Action: command.RaiseIgnore}},
+						},
+						Input: command.Select{
+							Filter: command.GreaterThanExpr{
+								BinaryBase: command.BinaryBase{
+									Left:  command.ColumnReference{Name: "OLD.id"},
+									Right: command.ConstantLiteral{Value: "5", Numeric: true},
+								},
+							},
+							Input: command.Values{Values: [][]command.Expr{{command.ConstantBooleanExpr{Value: true}}}},
+						},
+					},
+				},
+				StatementSources: []string{"SELECT RAISE ( IGNORE ) WHERE OLD . id > 5"},
+			},
+			false,
+		},
+		{
+			"create temporary trigger",
+			"CREATE TEMP TRIGGER myTrigger AFTER INSERT ON myTable BEGIN DELETE FROM log; END",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileSubqueryNoOptimizations(t *testing.T) {
+	innerQuery := command.Project{
+		Cols: []command.Column{{Expr: command.ColumnReference{Name: "id"}}},
+		Input: command.Select{
+			Filter: command.EqualityExpr{
+				BinaryBase: command.BinaryBase{
+					Left:  command.ColumnReference{Name: "b.ref"},
+					Right: command.ColumnReference{Name: "a.id"},
+				},
+			},
+			Input: command.Scan{Table: command.SimpleTable{Table: "b"}},
+		},
+	}
+	tests := []testcase{
+		{
+			"derived table",
+			"SELECT t.id FROM (SELECT id FROM b WHERE b.ref = a.id) AS t",
+			command.Project{
+				Cols: []command.Column{{Expr: command.ColumnReference{Name: "t.id"}}},
+				Input: command.Scan{
+					Table: command.DerivedTable{Input: innerQuery, Alias: "t"},
+				},
+			},
+			false,
+		},
+		{
+			"derived table without alias in join",
+			"SELECT * FROM a JOIN (SELECT id FROM b WHERE b.ref = a.id)",
+			command.Project{
+				Cols: []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+				Input: command.Join{
+					Left:  command.Scan{Table: command.SimpleTable{Table: "a"}},
+					Right: command.Scan{Table: command.DerivedTable{Input: innerQuery}},
+				},
+			},
+			false,
+		},
+		{
+			"scalar subquery",
+			"SELECT (SELECT id FROM b WHERE b.ref = a.id) AS firstID FROM a",
+			command.Project{
+				Cols: []command.Column{
+					{Expr: command.SubqueryExpr{Query: innerQuery, Source: "( SELECT id FROM b WHERE b . ref = a . id )"}, Alias: "firstID"},
+				},
+				Input: command.Scan{Table: command.SimpleTable{Table: "a"}},
+			},
+			false,
+		},
+		{
+			"not exists",
+			"SELECT * FROM a WHERE NOT EXISTS (SELECT id FROM b WHERE b.ref = a.id)",
+			command.Project{
+				Cols: []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+				Input: command.Select{
+					Filter: command.ExistsExpr{Query: innerQuery, Invert: true},
+					Input:  command.Scan{Table: command.SimpleTable{Table: "a"}},
+				},
+			},
+			false,
+		},
+		{
+			"in",
+			"SELECT * FROM a WHERE a.id IN (SELECT id FROM b WHERE b.ref = a.id)",
+			command.Project{
+				Cols: []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+				Input: command.Select{
+					Filter: command.InExpr{
+						Needle: command.ColumnReference{Name: "a.id"},
+						Query:  innerQuery,
+					},
+					Input: command.Scan{Table: command.SimpleTable{Table: "a"}},
+				},
+			},
+			false,
+		},
+		{
+			"not in",
+			"DELETE FROM a WHERE id NOT IN (SELECT id FROM b WHERE b.ref = a.id)",
+			command.Delete{
+				Table: command.SimpleTable{Table: "a"},
+				Filter: command.InExpr{
+					Needle: command.ColumnReference{Name: "id"},
+					Query:  innerQuery,
+					Invert: true,
+				},
+			},
+			false,
+		},
+		{
+			"in list",
+			"SELECT * FROM a WHERE id IN (1, 2)",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, _TestCompile(tt))
+	}
+}
+
+func _TestSimpleCompilerCompileWithNoOptimizations(t *testing.T) {
+	scanAll := func(name string) command.List {
+		return command.Project{
+			Cols:  []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+			Input: command.Scan{Table: command.SimpleTable{Table: name}},
+		}
+	}
+	counter := command.Recursion{
+		Initial: command.Values{Values: [][]command.Expr{{command.ConstantLiteral{Value: "1", Numeric: true}}}},
+		Step: command.Project{
+			Cols: []command.Column{
+				{Expr: command.AddExpression{
+					BinaryBase: command.BinaryBase{
+						Left:  command.ColumnReference{Name: "x"},
+						Right: command.ConstantLiteral{Value: "1", Numeric: true},
+					},
+				}},
+			},
+			Input: command.Select{
+				Filter: command.LessThanExpr{
+					BinaryBase: command.BinaryBase{
+						Left:  command.ColumnReference{Name: "x"},
+						Right: command.ConstantLiteral{Value: "10", Numeric: true},
+					},
+				},
+				Input: command.Scan{Table: command.SimpleTable{Table: "cnt"}},
+			},
+		},
+	}
+	tests := []testcase{
+		{
+			"inline",
+			"WITH t AS (SELECT * FROM a) SELECT * FROM t",
+			command.With{
+				Tables: []command.CommonTable{
+					{Name: "t", Input: scanAll("a")},
+				},
+				Input: scanAll("t"),
+			},
+			false,
+		},
+		{
+			"materialized",
+			"WITH t (id) AS (SELECT * FROM a), u AS (SELECT * FROM t) SELECT * FROM t JOIN u",
+			command.With{
+				Tables: []command.CommonTable{
+					{Name: "t", Cols: []string{"id"}, Input: scanAll("a"), Materialized: true},
+					{Name: "u", Input: scanAll("t")},
+				},
+				Input: command.Project{
+					Cols: []command.Column{{Expr: command.ColumnReference{Name: "*"}}},
+					Input: command.Join{
+						Left:  command.Scan{Table: command.SimpleTable{Table: "t"}},
+						Right: command.Scan{Table: command.SimpleTable{Table: "u"}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"recursive",
+			"WITH RECURSIVE cnt (x) AS (VALUES (1) UNION ALL SELECT x + 1 FROM cnt WHERE x < 10) SELECT * FROM cnt",
+			command.With{
+				Tables: []command.CommonTable{
+					{Name: "cnt", Cols: []string{"x"}, Input: counter, Materialized: true},
+				},
+				Input: scanAll("cnt"),
+			},
+			false,
+		},
+		{
+			"recursive keyword without recursion",
+			"WITH RECURSIVE t AS (SELECT * FROM a) DELETE FROM t",
+			command.With{
+				Tables: []command.CommonTable{
+					{Name: "t", Input: scanAll("a")},
+				},
+				Input: command.Delete{
+					Table:  command.SimpleTable{Table: "t"},
+					Filter: command.ConstantBooleanExpr{Value: true},
+				},
+			},
+			false,
+		},
+		{
+			"recursion in initial select",
+			"WITH RECURSIVE cnt (x) AS (SELECT x FROM cnt UNION ALL VALUES (1)) SELECT * FROM cnt",
+			nil,
+			true,
+		},
+		{
+			"recursion without union",
+			"WITH RECURSIVE cnt (x) AS (SELECT x + 1 FROM cnt) SELECT * FROM cnt",
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, _TestCompile(tt))
@@ -67,68 +850,146 @@ func _TestSimpleCompilerCompileInsertNoOptimizations(t *testing.T) {
 				},
 				Input: command.Values{
 					Values: [][]command.Expr{
-						{
-							command.ConstantLiteral{Value: "1", Numeric: true},
-							command.ConstantLiteral{Value: "2", Numeric: true},
-							command.ConstantLiteral{Value: "3", Numeric: true},
-						},
+						{
+							command.ConstantLiteral{Value: "1", Numeric: true},
+							command.ConstantLiteral{Value: "2", Numeric: true},
+							command.ConstantLiteral{Value: "3", Numeric: true},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"insert expression list",
+			"INSERT INTO mySchema.myTable VALUES (1, 2, 3), (4, 5, 6)",
+			command.Insert{
+				Table: command.SimpleTable{
+					Schema: "mySchema",
+					Table:  "myTable",
+				},
+				Input: command.Values{
+					Values: [][]command.Expr{
+						{
+							command.ConstantLiteral{Value: "1", Numeric: true},
+							command.ConstantLiteral{Value: "2", Numeric: true},
+							command.ConstantLiteral{Value: "3", Numeric: true},
+						},
+						{
+							command.ConstantLiteral{Value: "4", Numeric: true},
+							command.ConstantLiteral{Value: "5", Numeric: true},
+							command.ConstantLiteral{Value: "6", Numeric: true},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"insert select list",
+			"INSERT INTO mySchema.myTable SELECT * FROM myOtherTable",
+			command.Insert{
+				Table: command.SimpleTable{
+					Schema: "mySchema",
+					Table:  "myTable",
+				},
+				Input: command.Project{
+					Cols: []command.Column{
+						{
+							Expr: command.ColumnReference{Name: "*"},
+						},
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{Table: "myOtherTable"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"insert default values",
+			"INSERT INTO myTable DEFAULT VALUES",
+			command.Insert{
+				Table:         command.SimpleTable{Table: "myTable"},
+				DefaultValues: true,
+			},
+			false,
+		},
+		{
+			"insert or replace",
+			"INSERT OR REPLACE INTO myTable VALUES (1)",
+			command.Insert{
+				InsertOr: command.InsertOrReplace,
+				Table:    command.SimpleTable{Table: "myTable"},
+				Input: command.Values{
+					Values: [][]command.Expr{
+						{command.ConstantLiteral{Value: "1", Numeric: true}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"insert on conflict do nothing",
+			"INSERT INTO myTable VALUES (1) ON CONFLICT DO NOTHING",
+			command.Insert{
+				Table: command.SimpleTable{Table: "myTable"},
+				Input: command.Values{
+					Values: [][]command.Expr{
+						{command.ConstantLiteral{Value: "1", Numeric: true}},
 					},
 				},
+				Upsert: &command.Upsert{
+					DoNothing: true,
+				},
 			},
 			false,
 		},
 		{
-			"insert expression list",
-			"INSERT INTO mySchema.myTable VALUES (1, 2, 3), (4, 5, 6)",
+			"insert on conflict do update",
+			"INSERT INTO myTable VALUES (1, 2) ON CONFLICT (col1) DO UPDATE SET col2 = excluded.col2 WHERE col2 < 5",
 			command.Insert{
-				Table: command.SimpleTable{
-					Schema: "mySchema",
-					Table:  "myTable",
-				},
+				Table: command.SimpleTable{Table: "myTable"},
 				Input: command.Values{
 					Values: [][]command.Expr{
 						{
 							command.ConstantLiteral{Value: "1", Numeric: true},
 							command.ConstantLiteral{Value: "2", Numeric: true},
-							command.ConstantLiteral{Value: "3", Numeric: true},
-						},
-						{
-							command.ConstantLiteral{Value: "4", Numeric: true},
-							command.ConstantLiteral{Value: "5", Numeric: true},
-							command.ConstantLiteral{Value: "6", Numeric: true},
 						},
 					},
 				},
-			},
-			false,
-		},
-		{
-			"insert select list",
-			"INSERT INTO mySchema.myTable SELECT * FROM myOtherTable",
-			command.Insert{
-				Table: command.SimpleTable{
-					Schema: "mySchema",
-					Table:  "myTable",
-				},
-				Input: command.Project{
-					Cols: []command.Column{
+				Upsert: &command.Upsert{
+					Target: []string{"col1"},
+					Updates: []command.UpdateSetter{
 						{
-							Expr: command.ColumnReference{Name: "*"},
+							Cols:  []string{"col2"},
+							Value: command.ColumnReference{Name: "excluded.col2"},
 						},
 					},
-					Input: command.Scan{
-						Table: command.SimpleTable{Table: "myOtherTable"},
+					Filter: command.LessThanExpr{
+						BinaryBase: command.BinaryBase{
+							Left:  command.ColumnReference{Name: "col2"},
+							Right: command.ConstantLiteral{Value: "5", Numeric: true},
+						},
 					},
 				},
 			},
 			false,
 		},
 		{
-			"insert default values",
-			"INSERT INTO myTable DEFAULT VALUES",
+			"insert with returning",
+			"INSERT INTO myTable VALUES (1) RETURNING *, col1 AS c",
 			command.Insert{
-				Table:         command.SimpleTable{Table: "myTable"},
-				DefaultValues: true,
+				Table: command.SimpleTable{Table: "myTable"},
+				Input: command.Values{
+					Values: [][]command.Expr{
+						{command.ConstantLiteral{Value: "1", Numeric: true}},
+					},
+				},
+				Returning: []command.Column{
+					{Expr: command.ColumnReference{Name: "*"}},
+					{Expr: command.ColumnReference{Name: "col1"}, Alias: "c"},
+				},
 			},
 			false,
 		},
@@ -144,7 +1005,7 @@ func _TestSimpleCompilerCompileUpdateNoOptimizations(t *testing.T) {
 			"simple update",
 			"UPDATE myTable SET myCol = 7",
 			command.Update{
-				UpdateOr: command.UpdateOrIgnore, // default
+				UpdateOr: command.UpdateOrUnknown, // default
 				Table: command.SimpleTable{
 					Table: "myTable",
 				},
@@ -162,7 +1023,7 @@ func _TestSimpleCompilerCompileUpdateNoOptimizations(t *testing.T) {
 			"filtered update",
 			"UPDATE myTable SET myCol = 7 WHERE myOtherCol == 9",
 			command.Update{
-				UpdateOr: command.UpdateOrIgnore, // default
+				UpdateOr: command.UpdateOrUnknown, // default
 				Table: command.SimpleTable{
 					Table: "myTable",
 				},
@@ -360,6 +1221,27 @@ func _TestSimpleCompilerCompileDropNoOptimizations(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"update with returning",
+			"UPDATE myTable SET myCol = 7 RETURNING myCol",
+			command.Update{
+				UpdateOr: command.UpdateOrUnknown, // default
+				Table: command.SimpleTable{
+					Table: "myTable",
+				},
+				Filter: command.ConstantBooleanExpr{Value: true},
+				Updates: []command.UpdateSetter{
+					{
+						Cols:  []string{"myCol"},
+						Value: command.ConstantLiteral{Value: "7", Numeric: true},
+					},
+				},
+				Returning: []command.Column{
+					{Expr: command.ColumnReference{Name: "myCol"}},
+				},
+			},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, _TestCompile(tt))
@@ -407,6 +1289,37 @@ func _TestSimpleCompilerCompileDeleteNoOptimizations(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"delete not indexed",
+			"DELETE FROM myTable NOT INDEXED WHERE col1 == 1",
+			command.Delete{
+				Table: command.SimpleTable{
+					Table:      "myTable",
+					NotIndexed: true,
+				},
+				Filter: command.EqualityExpr{
+					BinaryBase: command.BinaryBase{
+						Left:  command.ColumnReference{Name: "col1"},
+						Right: command.ConstantLiteral{Value: "1", Numeric: true},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"delete with returning",
+			"DELETE FROM myTable RETURNING myTable.*",
+			command.Delete{
+				Table: command.SimpleTable{
+					Table: "myTable",
+				},
+				Filter: command.ConstantBooleanExpr{Value: true},
+				Returning: []command.Column{
+					{Table: "myTable", Expr: command.ColumnReference{Name: "*"}},
+				},
+			},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, _TestCompile(tt))
@@ -542,6 +1455,58 @@ func _TestSimpleCompilerCompileSelectNoOptimizations(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"select order by",
+			"SELECT name AS n FROM myTable ORDER BY id DESC, n, 1 NULLS LAST",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr:  command.ColumnReference{Name: "name"},
+						Alias: "n",
+					},
+				},
+				Input: command.Sort{
+					Terms: []command.OrderingTerm{
+						{Expr: command.ColumnReference{Name: "id"}, Desc: true},
+						{Expr: command.ColumnReference{Name: "name"}, NullsFirst: true},
+						{Expr: command.ColumnReference{Name: "name"}},
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{
+							Table: "myTable",
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select distinct order by",
+			"SELECT DISTINCT * FROM myTable ORDER BY id LIMIT 5",
+			command.Limit{
+				Limit: command.ConstantLiteral{Value: "5", Numeric: true},
+				Input: command.Sort{
+					Terms: []command.OrderingTerm{
+						{Expr: command.ColumnReference{Name: "id"}, NullsFirst: true},
+					},
+					Input: command.Distinct{
+						Input: command.Project{
+							Cols: []command.Column{
+								{
+									Expr: command.ColumnReference{Name: "*"},
+								},
+							},
+							Input: command.Scan{
+								Table: command.SimpleTable{
+									Table: "myTable",
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			"select distinct",
 			"SELECT DISTINCT * FROM myTable WHERE true",
@@ -687,23 +1652,28 @@ func _TestSimpleCompilerCompileSelectNoOptimizations(t *testing.T) {
 			command.Project{
 				Cols: []command.Column{
 					{
-						Expr: command.FunctionExpr{
+						Expr:  command.ColumnReference{Name: "AVG(price)"},
+						Alias: "avg_price",
+					},
+				},
+				Input: command.Aggregate{
+					Aggregates: []command.FunctionExpr{
+						{
 							Name:     "AVG",
 							Distinct: false,
 							Args: []command.Expr{
 								command.ColumnReference{Name: "price"},
 							},
 						},
-						Alias: "avg_price",
 					},
-				},
-				Input: command.Join{
-					Type: command.JoinLeft,
-					Left: command.Scan{
-						Table: command.SimpleTable{Table: "items"},
-					},
-					Right: command.Scan{
-						Table: command.SimpleTable{Table: "prices"},
+					Input: command.Join{
+						Type: command.JoinLeft,
+						Left: command.Scan{
+							Table: command.SimpleTable{Table: "items"},
+						},
+						Right: command.Scan{
+							Table: command.SimpleTable{Table: "prices"},
+						},
 					},
 				},
 			},
@@ -715,23 +1685,182 @@ func _TestSimpleCompilerCompileSelectNoOptimizations(t *testing.T) {
 			command.Project{
 				Cols: []command.Column{
 					{
-						Expr: command.FunctionExpr{
+						Expr:  command.ColumnReference{Name: "AVG(DISTINCT price)"},
+						Alias: "avg_price",
+					},
+				},
+				Input: command.Aggregate{
+					Aggregates: []command.FunctionExpr{
+						{
 							Name:     "AVG",
 							Distinct: true,
 							Args: []command.Expr{
 								command.ColumnReference{Name: "price"},
 							},
 						},
-						Alias: "avg_price",
+					},
+					Input: command.Join{
+						Type: command.JoinLeft,
+						Left: command.Scan{
+							Table: command.SimpleTable{Table: "items"},
+						},
+						Right: command.Scan{
+							Table: command.SimpleTable{Table: "prices"},
+						},
 					},
 				},
-				Input: command.Join{
-					Type: command.JoinLeft,
-					Left: command.Scan{
-						Table: command.SimpleTable{Table: "items"},
+			},
+			false,
+		},
+		{
+			"select between",
+			"SELECT * FROM myTable WHERE col1 BETWEEN 1 AND 5",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
 					},
-					Right: command.Scan{
-						Table: command.SimpleTable{Table: "prices"},
+				},
+				Input: command.Select{
+					Filter: command.RangeExpr{
+						Needle: command.ColumnReference{Name: "col1"},
+						Lo:     command.ConstantLiteral{Value: "1", Numeric: true},
+						Hi:     command.ConstantLiteral{Value: "5", Numeric: true},
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{Table: "myTable"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select not between",
+			"SELECT * FROM myTable WHERE col1 NOT BETWEEN 1 AND 5",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
+					},
+				},
+				Input: command.Select{
+					Filter: command.RangeExpr{
+						Needle: command.ColumnReference{Name: "col1"},
+						Lo:     command.ConstantLiteral{Value: "1", Numeric: true},
+						Hi:     command.ConstantLiteral{Value: "5", Numeric: true},
+						Invert: true,
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{Table: "myTable"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select like",
+			"SELECT * FROM myTable WHERE col1 LIKE 'a%'",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
+					},
+				},
+				Input: command.Select{
+					Filter: command.LikeExpr{
+						Value:   command.ColumnReference{Name: "col1"},
+						Pattern: command.ConstantLiteral{Value: "a%"},
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{Table: "myTable"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select not like with escape",
+			"SELECT * FROM myTable WHERE col1 NOT LIKE 'a!%%' ESCAPE '!'",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
+					},
+				},
+				Input: command.Select{
+					Filter: command.LikeExpr{
+						Value:   command.ColumnReference{Name: "col1"},
+						Pattern: command.ConstantLiteral{Value: "a!%%"},
+						Escape:  command.ConstantLiteral{Value: "!"},
+						Invert:  true,
+					},
+					Input: command.Scan{
+						Table: command.SimpleTable{Table: "myTable"},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select indexed by",
+			"SELECT * FROM myTable INDEXED BY myIndex",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
+					},
+				},
+				Input: command.Scan{
+					Table: command.SimpleTable{
+						Table:   "myTable",
+						Indexed: true,
+						Index:   "myIndex",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select not indexed",
+			"SELECT * FROM myTable NOT INDEXED",
+			command.Project{
+				Cols: []command.Column{
+					{
+						Expr: command.ColumnReference{Name: "*"},
+					},
+				},
+				Input: command.Scan{
+					Table: command.SimpleTable{
+						Table:      "myTable",
+						NotIndexed: true,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"select compound",
+			"SELECT name FROM a UNION ALL SELECT name FROM b EXCEPT SELECT name FROM c ORDER BY 1 DESC",
+			command.Sort{
+				Terms: []command.OrderingTerm{
+					{Expr: command.ColumnReference{Name: "name"}, Desc: true},
+				},
+				Input: command.Compound{
+					Operator: command.CompoundExcept,
+					Left: command.Compound{
+						Operator: command.CompoundUnionAll,
+						Left: command.Project{
+							Cols:  []command.Column{{Expr: command.ColumnReference{Name: "name"}}},
+							Input: command.Scan{Table: command.SimpleTable{Table: "a"}},
+						},
+						Right: command.Project{
+							Cols:  []command.Column{{Expr: command.ColumnReference{Name: "name"}}},
+							Input: command.Scan{Table: command.SimpleTable{Table: "b"}},
+						},
+					},
+					Right: command.Project{
+						Cols:  []command.Column{{Expr: command.ColumnReference{Name: "name"}}},
+						Input: command.Scan{Table: command.SimpleTable{Table: "c"}},
 					},
 				},
 			},
@@ -765,3 +1894,39 @@ func _TestCompile(tt testcase) func(t *testing.T) {
 		assert.Equal(tt.want, got)
 	}
 }
+
+func TestSimpleCompiler_CompileCreateIndexMissingNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		strip func(*ast.CreateIndexStmt)
+	}{
+		{
+			"no index name",
+			func(stmt *ast.CreateIndexStmt) { stmt.IndexName = nil },
+		},
+		{
+			"no table name",
+			func(stmt *ast.CreateIndexStmt) { stmt.TableName = nil },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := parser.New("CREATE INDEX myIndex ON myTable (col1)")
+			assert.NoError(err)
+			stmt, errs, ok := p.Next()
+			assert.Len(errs, 0)
+			assert.True(ok)
+
+			// the parser never produces these statements, but the compiler
+			// must not rely on that
+			tt.strip(stmt.CreateIndexStmt)
+
+			c := &simpleCompiler{}
+			got, gotErr := c.Compile(stmt)
+			assert.Error(gotErr)
+			assert.Nil(got)
+		})
+	}
+}
@@ -0,0 +1,594 @@
+package compiler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/parser"
+	"github.com/xqueries/xdb/internal/parser/ast"
+	"github.com/xqueries/xdb/internal/parser/scanner/token"
+)
+
+var (
+	tokenType = reflect.TypeOf((*token.Token)(nil)).Elem()
+	exprType  = reflect.TypeOf(ast.Expr{})
+
+	tableOrSubqueryType    = reflect.TypeOf(ast.TableOrSubquery{})
+	qualifiedTableNameType = reflect.TypeOf(ast.QualifiedTableName{})
+	insertStmtType         = reflect.TypeOf(ast.InsertStmt{})
+	updateStmtType         = reflect.TypeOf(ast.UpdateStmt{})
+	resultColumnType       = reflect.TypeOf(ast.ResultColumn{})
+	cteTableNameType       = reflect.TypeOf(ast.CteTableName{})
+)
+
+// unseparatedLists are the lists of the AST, whose elements are not separated
+// by commas, by the name of the node type and the name of the list field.
+var unseparatedLists = map[string]bool{
+	"ColumnDef.ColumnConstraint":            true,
+	"Expr.WhenThenClause":                   true,
+	"ForeignKeyClause.ForeignKeyClauseCore": true,
+	"JoinClause.JoinClausePart":             true,
+	"SelectStmt.SelectCore":                 true,
+	"TypeName.Name":                         true,
+}
+
+// terminatedLists are the lists of the AST, whose elements are terminated by
+// semicolons, by the name of the node type and the name of the list field.
+var terminatedLists = map[string]bool{
+	"CreateTriggerStmt.DeleteStmt": true,
+	"CreateTriggerStmt.InsertStmt": true,
+	"CreateTriggerStmt.SelectStmt": true,
+	"CreateTriggerStmt.UpdateStmt": true,
+}
+
+// sourceText reconstructs the SQL source of the given AST node from its
+// tokens. The tokens are separated by a single space, so the formatting of
+// the original source is not preserved, but the returned text is parsed to
+// an equivalent AST.
+func sourceText(node interface{}) string {
+	return replacedSourceText(node, nil)
+}
+
+// replacedSourceText reconstructs the SQL source of the given AST node like
+// sourceText, but the values of the tokens at the offsets in the given map are
+// replaced with the values in the map.
+func replacedSourceText(node interface{}, replacements map[int]string) string {
+	var tokens []token.Token
+	collectTokens(reflect.ValueOf(node), &tokens)
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].Offset() != tokens[j].Offset() {
+			return tokens[i].Offset() < tokens[j].Offset()
+		}
+		// a separator precedes the token at its offset
+		return tokens[i].Length() == 0 && tokens[j].Length() != 0
+	})
+
+	values := make([]string, len(tokens))
+	for i, tk := range tokens {
+		if replacement, ok := replacements[tk.Offset()]; ok && tk.Length() != 0 {
+			values[i] = replacement
+		} else {
+			values[i] = tk.Value()
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// collectTokens appends all tokens, that are contained in the given value, to
+// the given slice.
+func collectTokens(v reflect.Value, tokens *[]token.Token) {
+	if v.Kind() == reflect.Interface && v.Type() == tokenType {
+		if !v.IsNil() {
+			*tokens = append(*tokens, v.Interface().(token.Token))
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectTokens(v.Elem(), tokens)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			list := v.Type().Name() + "." + field.Name
+			switch {
+			case field.Type.Kind() != reflect.Slice || unseparatedLists[list]:
+				collectTokens(v.Field(i), tokens)
+			case terminatedLists[list]:
+				collectTerminatedTokens(v.Field(i), tokens)
+			default:
+				collectSeparatedTokens(v.Field(i), tokens)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectTokens(v.Index(i), tokens)
+		}
+	}
+}
+
+// collectSeparatedTokens appends all tokens, that are contained in the elements
+// of the given slice, to the given slice, like collectTokens. Since the parser
+// doesn't keep the commas, that separate the elements of a list, a comma with
+// the offset of the first token of every element but the first is appended as
+// well. The length of such a comma is 0.
+func collectSeparatedTokens(v reflect.Value, tokens *[]token.Token) {
+	for i := 0; i < v.Len(); i++ {
+		var elem []token.Token
+		collectTokens(v.Index(i), &elem)
+		if len(elem) == 0 {
+			continue
+		}
+		if i > 0 {
+			first := elem[0]
+			for _, tk := range elem {
+				if tk.Offset() < first.Offset() {
+					first = tk
+				}
+			}
+			*tokens = append(*tokens, token.New(first.Line(), first.Col(), first.Offset(), 0, token.Delimiter, ","))
+		}
+		*tokens = append(*tokens, elem...)
+	}
+}
+
+// collectTerminatedTokens appends all tokens, that are contained in the
+// elements of the given slice, to the given slice, like collectTokens. Since
+// the parser doesn't keep the semicolons, that terminate the elements of a
+// list, a semicolon with the offset after the last token of every element is
+// appended as well. The length of such a semicolon is 0.
+func collectTerminatedTokens(v reflect.Value, tokens *[]token.Token) {
+	for i := 0; i < v.Len(); i++ {
+		var elem []token.Token
+		collectTokens(v.Index(i), &elem)
+		if len(elem) == 0 {
+			continue
+		}
+		last := elem[0]
+		for _, tk := range elem {
+			if tk.Offset() > last.Offset() {
+				last = tk
+			}
+		}
+		*tokens = append(*tokens, elem...)
+		*tokens = append(*tokens, token.New(last.Line(), last.Col()+last.Length(), last.Offset()+last.Length(), 0, token.StatementSeparator, ";"))
+	}
+}
+
+// sourceOffset returns the offset of the first token of the given AST node,
+// or -1, if the node doesn't contain any tokens.
+func sourceOffset(node interface{}) int {
+	var tokens []token.Token
+	collectTokens(reflect.ValueOf(node), &tokens)
+	offset := -1
+	for _, tk := range tokens {
+		if offset == -1 || tk.Offset() < offset {
+			offset = tk.Offset()
+		}
+	}
+	return offset
+}
+
+// CompileExpr parses and compiles the given SQL expression. This is used to
+// compile expressions, that are stored as SQL source, such as the expressions
+// of CHECK constraints.
+func CompileExpr(expr string) (command.Expr, error) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return (&simpleCompiler{}).compileExpr(parsed)
+}
+
+// CompileQuery parses and compiles the given SQL select statement. This is
+// used to compile queries, that are stored as SQL source, such as the queries
+// of views.
+func CompileQuery(query string) (command.List, error) {
+	stmt, err := parseStatement(query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.SelectStmt == nil {
+		return nil, fmt.Errorf("'%v' is not a select statement", query)
+	}
+
+	cmd, err := (&simpleCompiler{}).compileSelect(stmt.SelectStmt)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+	list, ok := cmd.(command.List)
+	if !ok {
+		return nil, fmt.Errorf("select compiled to %T, which is not a list", cmd)
+	}
+	return list, nil
+}
+
+// CompileStatement parses and compiles the given SQL statement, without
+// applying any optimizations. This is used to compile statements, that are
+// stored as SQL source, such as the statements of triggers.
+func CompileStatement(statement string) (command.Command, error) {
+	stmt, err := parseStatement(statement)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Explain != nil {
+		return nil, fmt.Errorf("explain: %w", ErrUnsupported)
+	}
+	return (&simpleCompiler{}).compileInternal(stmt)
+}
+
+// RenameColumn returns the given SQL expression, in which all references to
+// the column with the given name refer to the column with the given new name
+// instead. This is used to update expressions, that are stored as SQL source,
+// when a column is renamed. The returned source is formatted like the source
+// of the expressions of CHECK constraints.
+func RenameColumn(expr, name, newName string) (string, error) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	replacements := make(map[int]string)
+	collectColumnReferences(reflect.ValueOf(parsed), name, func(tk token.Token) {
+		replacements[tk.Offset()] = newName
+	})
+	return replacedSourceText(parsed, replacements), nil
+}
+
+// ReferencesColumn returns whether the given SQL expression references the
+// column with the given name.
+func ReferencesColumn(expr, name string) (bool, error) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return false, err
+	}
+	var found bool
+	collectColumnReferences(reflect.ValueOf(parsed), name, func(token.Token) {
+		found = true
+	})
+	return found, nil
+}
+
+// RenameTable returns the given SQL statement, in which all references to the
+// table with the given name refer to the table with the given new name
+// instead. This is used to update statements, that are stored as SQL source,
+// such as the queries of views, when a table is renamed. Column references,
+// that are qualified with the name of the table, are updated as well, unless
+// the name is also used as an alias in the statement. If the statement
+// contains a common table expression with the name of the table, an error is
+// returned, since its references can't be told apart from references to the
+// table.
+func RenameTable(statement, name, newName string) (string, error) {
+	stmt, err := parseStatement(statement)
+	if err != nil {
+		return "", err
+	}
+	return renameTable(stmt, name, newName)
+}
+
+// RenameTableInExpr returns the given SQL expression, in which all references
+// to the table with the given name refer to the table with the given new name
+// instead, like RenameTable. The returned source is formatted like the source
+// of the conditions of triggers.
+func RenameTableInExpr(expr, name, newName string) (string, error) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	return renameTable(parsed, name, newName)
+}
+
+// RenameTableColumn returns the given SQL statement, in which all references
+// to the column with the given name of the table with the given name refer to
+// the column with the given new name instead. This is used to update
+// statements, that are stored as SQL source, such as the queries of views,
+// when a column is renamed. A column reference refers to the table, if it is
+// qualified with the name of the table, an alias of the table or, ignoring
+// case, one of the given qualifiers, or if it is not qualified, and the
+// statement reads from or writes to the table. The columns of inserts into and
+// updates of the table are updated as well.
+func RenameTableColumn(statement, tableName, name, newName string, qualifiers ...string) (string, error) {
+	stmt, err := parseStatement(statement)
+	if err != nil {
+		return "", err
+	}
+	return replacedSourceText(stmt, tableColumnReplacements(stmt, tableName, name, newName, qualifiers)), nil
+}
+
+// RenameTableColumnInExpr returns the given SQL expression, in which all
+// references to the column with the given name of the table with the given
+// name refer to the column with the given new name instead, like
+// RenameTableColumn. The returned source is formatted like the source of the
+// conditions of triggers.
+func RenameTableColumnInExpr(expr, tableName, name, newName string, qualifiers ...string) (string, error) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	return replacedSourceText(parsed, tableColumnReplacements(parsed, tableName, name, newName, qualifiers)), nil
+}
+
+// renameTable returns the source of the given AST node, in which all
+// references to the table with the given name refer to the table with the
+// given new name instead, see RenameTable.
+func renameTable(node interface{}, name, newName string) (string, error) {
+	var tables, qualifiers []token.Token
+	aliased := false
+	var err error
+	walkStructs(reflect.ValueOf(node), func(v reflect.Value) {
+		switch v.Type() {
+		case tableOrSubqueryType:
+			tos := v.Interface().(ast.TableOrSubquery)
+			tables = appendTableName(tables, tos.SchemaName, tos.TableName, name)
+			aliased = aliased || isName(tos.TableAlias, name)
+		case qualifiedTableNameType:
+			qtn := v.Interface().(ast.QualifiedTableName)
+			tables = appendTableName(tables, qtn.SchemaName, qtn.TableName, name)
+			aliased = aliased || isName(qtn.Alias, name)
+		case insertStmtType:
+			insert := v.Interface().(ast.InsertStmt)
+			tables = appendTableName(tables, insert.SchemaName, insert.TableName, name)
+			aliased = aliased || isName(insert.Alias, name)
+		case exprType:
+			expr := v.Interface().(ast.Expr)
+			if expr.ColumnName != nil {
+				qualifiers = appendTableName(qualifiers, expr.SchemaName, expr.TableName, name)
+			}
+		case resultColumnType:
+			col := v.Interface().(ast.ResultColumn)
+			qualifiers = appendTableName(qualifiers, nil, col.TableName, name)
+		case cteTableNameType:
+			if isName(v.Interface().(ast.CteTableName).TableName, name) {
+				err = fmt.Errorf("common table expression %v shadows the table %v", name, name)
+			}
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	replacements := make(map[int]string)
+	for _, tk := range tables {
+		replacements[tk.Offset()] = newName
+	}
+	if !aliased {
+		for _, tk := range qualifiers {
+			replacements[tk.Offset()] = newName
+		}
+	}
+	return replacedSourceText(node, replacements), nil
+}
+
+// tableColumnReplacements returns the replacements of the tokens of the given
+// AST node, that rename the column with the given name of the table with the
+// given name to the given new name, see RenameTableColumn.
+func tableColumnReplacements(node interface{}, tableName, name, newName string, qualifiers []string) map[int]string {
+	// the names, that refer to the table, and whether the table is read from
+	// or written to
+	names := map[string]bool{tableName: true}
+	var referenced bool
+	walkStructs(reflect.ValueOf(node), func(v reflect.Value) {
+		var schemaName, table, alias token.Token
+		switch v.Type() {
+		case tableOrSubqueryType:
+			tos := v.Interface().(ast.TableOrSubquery)
+			schemaName, table, alias = tos.SchemaName, tos.TableName, tos.TableAlias
+		case qualifiedTableNameType:
+			qtn := v.Interface().(ast.QualifiedTableName)
+			schemaName, table, alias = qtn.SchemaName, qtn.TableName, qtn.Alias
+		case insertStmtType:
+			insert := v.Interface().(ast.InsertStmt)
+			schemaName, table, alias = insert.SchemaName, insert.TableName, insert.Alias
+		default:
+			return
+		}
+		if schemaName == nil && isName(table, tableName) {
+			referenced = true
+			if alias != nil {
+				names[alias.Value()] = true
+			}
+		}
+	})
+
+	replacements := make(map[int]string)
+	rename := func(tk token.Token) {
+		if isName(tk, name) {
+			replacements[tk.Offset()] = newName
+		}
+	}
+	walkStructs(reflect.ValueOf(node), func(v reflect.Value) {
+		switch v.Type() {
+		case exprType:
+			expr := v.Interface().(ast.Expr)
+			if expr.ColumnName != nil && expr.SchemaName == nil {
+				if (expr.TableName == nil && referenced) || (expr.TableName != nil && isQualifier(expr.TableName.Value(), names, qualifiers)) {
+					rename(expr.ColumnName)
+				}
+			}
+			if expr.LiteralValue != nil && expr.LiteralValue.Type() == token.Literal && referenced {
+				rename(expr.LiteralValue)
+			}
+		case insertStmtType:
+			insert := v.Interface().(ast.InsertStmt)
+			if insert.SchemaName == nil && isName(insert.TableName, tableName) {
+				for _, col := range insert.ColumnName {
+					rename(col)
+				}
+			}
+		case updateStmtType:
+			update := v.Interface().(ast.UpdateStmt)
+			if qtn := update.QualifiedTableName; qtn != nil && qtn.SchemaName == nil && isName(qtn.TableName, tableName) {
+				for _, setter := range update.UpdateSetter {
+					if setter.ColumnName != nil {
+						rename(setter.ColumnName)
+					}
+					if setter.ColumnNameList != nil {
+						for _, col := range setter.ColumnNameList.ColumnName {
+							rename(col)
+						}
+					}
+				}
+			}
+		}
+	})
+	return replacements
+}
+
+// appendTableName appends the given table name token to the given tokens, if
+// it is not qualified with a schema, and its value is the given name.
+func appendTableName(tokens []token.Token, schemaName, tableName token.Token, name string) []token.Token {
+	if schemaName == nil && isName(tableName, name) {
+		return append(tokens, tableName)
+	}
+	return tokens
+}
+
+// isName returns whether the given token is not nil, and its value is the
+// given name.
+func isName(tk token.Token, name string) bool {
+	return tk != nil && tk.Value() == name
+}
+
+// isQualifier returns whether the given qualifier of a column reference is one
+// of the given names, or, ignoring case, one of the given qualifiers.
+func isQualifier(qualifier string, names map[string]bool, qualifiers []string) bool {
+	if names[qualifier] {
+		return true
+	}
+	for _, q := range qualifiers {
+		if strings.EqualFold(qualifier, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkStructs calls the given function with all structs, that are contained
+// in the given value, including the value itself.
+func walkStructs(v reflect.Value, fn func(reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkStructs(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		fn(v)
+		for i := 0; i < v.NumField(); i++ {
+			walkStructs(v.Field(i), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStructs(v.Index(i), fn)
+		}
+	}
+}
+
+// parseStatement parses the given SQL source, which must consist of a single
+// statement.
+func parseStatement(source string) (*ast.SQLStmt, error) {
+	p, err := parser.New(source)
+	if err != nil {
+		return nil, fmt.Errorf("parser: %w", err)
+	}
+	stmt, errs, ok := p.Next()
+	if !ok {
+		return nil, fmt.Errorf("no statement")
+	}
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("parse: %v", errs)
+	}
+	if _, _, ok := p.Next(); ok {
+		return nil, fmt.Errorf("'%v' contains more than one statement", source)
+	}
+	return stmt, nil
+}
+
+// parseExpr parses the given SQL expression.
+func parseExpr(expr string) (*ast.Expr, error) {
+	stmt, err := parseStatement("SELECT " + expr)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := resultColumns(stmt)
+	if len(cols) != 1 || cols[0].Expr == nil || cols[0].ColumnAlias != nil {
+		return nil, fmt.Errorf("'%v' is not a single expression", expr)
+	}
+	return cols[0].Expr, nil
+}
+
+// collectColumnReferences calls the given function with the tokens of all
+// references to the column with the given name, that are contained in the
+// given value. A column reference is either a column name, that may be
+// qualified, or a literal, that is not a string or numeric literal.
+func collectColumnReferences(v reflect.Value, name string, fn func(token.Token)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectColumnReferences(v.Elem(), name, fn)
+		}
+	case reflect.Struct:
+		if v.Type() == exprType {
+			expr := v.Interface().(ast.Expr)
+			if expr.ColumnName != nil && expr.ColumnName.Value() == name {
+				fn(expr.ColumnName)
+			}
+			if expr.LiteralValue != nil && expr.LiteralValue.Type() == token.Literal && expr.LiteralValue.Value() == name {
+				fn(expr.LiteralValue)
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			collectColumnReferences(v.Field(i), name, fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectColumnReferences(v.Index(i), name, fn)
+		}
+	}
+}
+
+// countTableReferences returns the amount of references to the table with
+// the given name, that are contained in the given value. A table reference is
+// a table name in a FROM or JOIN clause, that is not qualified with a schema.
+func countTableReferences(v reflect.Value, name string) int {
+	count := 0
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			count += countTableReferences(v.Elem(), name)
+		}
+	case reflect.Struct:
+		if v.Type() == tableOrSubqueryType {
+			tos := v.Interface().(ast.TableOrSubquery)
+			if tos.SchemaName == nil && tos.TableName != nil && tos.TableName.Value() == name {
+				count++
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			count += countTableReferences(v.Field(i), name)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			count += countTableReferences(v.Index(i), name)
+		}
+	}
+	return count
+}
+
+// resultColumns returns the result columns of the given statement, if it is
+// a simple select statement, or nil otherwise.
+func resultColumns(stmt *ast.SQLStmt) []*ast.ResultColumn {
+	selectStmt := stmt.SelectStmt
+	if selectStmt == nil || selectStmt.WithClause != nil || selectStmt.Order != nil || selectStmt.Limit != nil || len(selectStmt.SelectCore) != 1 {
+		return nil
+	}
+	core := selectStmt.SelectCore[0]
+	if core.From != nil || core.Where != nil || core.Group != nil || core.Values != nil {
+		return nil
+	}
+	return core.ResultColumn
+}
@@ -0,0 +1,135 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xqueries/xdb/internal/parser"
+)
+
+func TestRenameColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"column", "col1 > 0", "col2 > 0"},
+		{"qualified column", "myTable.col1 > 0", "myTable . col2 > 0"},
+		{"other column", "col3 > 0", "col3 > 0"},
+		{"string literal", "col1 = 'col1'", "col2 = 'col1'"},
+		{"function arguments", "MAX(col3, col1) > 0", "MAX ( col3 , col2 ) > 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenameColumn(tt.expr, "col1", "col2")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReferencesColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"column", "col1 > 0", true},
+		{"qualified column", "myTable.col1 > 0", true},
+		{"other column", "col3 > 0", false},
+		{"string literal", "col3 = 'col1'", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReferencesColumn(tt.expr, "col1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenameTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+		wantErr   bool
+	}{
+		{"from", "SELECT a FROM t1 WHERE a > 0", "SELECT a FROM t2 WHERE a > 0", false},
+		{"qualified columns", "SELECT t1.a, t1.* FROM t1", "SELECT t2 . a , t2 . * FROM t2", false},
+		{"join", "SELECT * FROM u JOIN t1 ON u.a = t1.a", "SELECT * FROM u JOIN t2 ON u . a = t2 . a", false},
+		{"subquery", "SELECT a FROM u WHERE EXISTS (SELECT * FROM t1)", "SELECT a FROM u WHERE EXISTS ( SELECT * FROM t2 )", false},
+		{"alias", "SELECT t1.a FROM u AS t1, t1 AS v", "SELECT t1 . a FROM u AS t1 , t2 AS v", false},
+		{"other table", "SELECT t1 FROM t3", "SELECT t1 FROM t3", false},
+		{"insert", "INSERT INTO t1 (a) VALUES (1)", "INSERT INTO t2 ( a ) VALUES ( 1 )", false},
+		{"update", "UPDATE t1 SET a = 1", "UPDATE t2 SET a = 1", false},
+		{"delete", "DELETE FROM t1 WHERE a = 1", "DELETE FROM t2 WHERE a = 1", false},
+		{"common table expression", "WITH t1 AS (SELECT 1) SELECT * FROM t1", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenameTable(tt.statement, "t1", "t2")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenameTableColumn(t *testing.T) {
+	tests := []struct {
+		name       string
+		statement  string
+		qualifiers []string
+		want       string
+	}{
+		{"column", "SELECT col1 FROM t WHERE col1 > 0", nil, "SELECT col2 FROM t WHERE col2 > 0"},
+		{"qualified column", "SELECT t.col1 FROM t", nil, "SELECT t . col2 FROM t"},
+		{"alias", "SELECT x.col1, u.col1 FROM t AS x, u", nil, "SELECT x . col2 , u . col1 FROM t AS x , u"},
+		{"other table", "SELECT col1 FROM u", nil, "SELECT col1 FROM u"},
+		{"qualifiers", "INSERT INTO u (col1) VALUES (NEW.col1, OLD.col1)", []string{"new"}, "INSERT INTO u ( col1 ) VALUES ( NEW . col2 , OLD . col1 )"},
+		{"insert", "INSERT INTO t (col3, col1) VALUES (1, 2)", nil, "INSERT INTO t ( col3 , col2 ) VALUES ( 1 , 2 )"},
+		{"update", "UPDATE t SET col1 = col1 + 1", nil, "UPDATE t SET col2 = col2 + 1"},
+		{"update other table", "UPDATE u SET col1 = 1", nil, "UPDATE u SET col1 = 1"},
+		{"string literal", "SELECT 'col1' FROM t", nil, "SELECT 'col1' FROM t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenameTableColumn(tt.statement, "t", "col1", "col2", tt.qualifiers...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSourceText(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"result columns", "SELECT a, b AS c FROM t", "SELECT a , b AS c FROM t"},
+		{"function arguments", "SELECT MAX(a, b) FROM t", "SELECT MAX ( a , b ) FROM t"},
+		{"in list", "SELECT * FROM t WHERE a IN (1, 2, 3)", "SELECT * FROM t WHERE a IN ( 1 , 2 , 3 )"},
+		{"join", "SELECT * FROM t, u JOIN v ON u.a = v.a", "SELECT * FROM t , u JOIN v ON u . a = v . a"},
+		{"compound", "SELECT a FROM t UNION SELECT a, b FROM u", "SELECT a FROM t UNION SELECT a , b FROM u"},
+		{"group and order", "SELECT a, COUNT(*) FROM t GROUP BY a, b ORDER BY a, b DESC", "SELECT a , COUNT ( * ) FROM t GROUP BY a , b ORDER BY a , b DESC"},
+		{"values", "VALUES (1, 2), (3, 4)", "VALUES ( 1 , 2 ) , ( 3 , 4 )"},
+		{"case", "SELECT CASE a WHEN 1 THEN 'x' WHEN 2 THEN 'y' END FROM t", "SELECT CASE a WHEN 1 THEN 'x' WHEN 2 THEN 'y' END FROM t"},
+		{"trigger", "CREATE TRIGGER tr AFTER INSERT ON t BEGIN DELETE FROM u; SELECT 1; END", "CREATE TRIGGER tr AFTER INSERT ON t BEGIN DELETE FROM u ; SELECT 1 ; END"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parser.New(tt.query)
+			assert.NoError(t, err)
+			stmt, errs, ok := p.Next()
+			assert.True(t, ok)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.want, sourceText(stmt))
+		})
+	}
+}
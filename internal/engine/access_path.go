@@ -0,0 +1,348 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// accessPath describes how the records of a table are read. If index is nil,
+// all records of the table are read in the order of the data pages, or in the
+// order of the primary key, if the table is clustered by its primary key.
+// Otherwise, the records in the range of the index are read in the order of
+// the index. If primary is set, the records in the range of the primary key of
+// a clustered table are read directly from the tree of the table.
+type accessPath struct {
+	index   *tableIndex
+	primary bool
+	rng     indexRange
+}
+
+// indexPredicate is a predicate of a filter, that restricts the values of a
+// single column to a single value or a range of values, and can thus be
+// evaluated with an index over that column.
+type indexPredicate struct {
+	// col is the index of the restricted column.
+	col int
+	// equal is the value, that the column must be equal to, or nil, if the
+	// column is restricted to the range between lower and upper.
+	equal        types.Value
+	lower, upper *indexBound
+}
+
+// chooseAccessPath chooses the access path for reading the records of the
+// given table, whose rows match the given filter. If the given simple table is
+// indexed by an index, that index is used, and an error is returned if there
+// is no such index. If the simple table must not be indexed, all records are
+// read. Otherwise, the primary key of a clustered table, or the first index is
+// used, whose leading column is restricted by the filter, see matchPredicate.
+// If no leading column is restricted, all records are read. The chosen access
+// path may read records, that don't match the filter, so the filter must still
+// be applied to the read records.
+func (e Engine) chooseAccessPath(ctx ExecutionContext, tbl *Table, simple command.SimpleTable, filter command.Expr) (accessPath, error) {
+	if simple.NotIndexed {
+		return accessPath{}, nil
+	}
+
+	cols, err := tbl.Cols()
+	if err != nil {
+		return accessPath{}, fmt.Errorf("cols: %w", err)
+	}
+	pred, restricted := e.indexPredicate(ctx, cols, filter)
+
+	if simple.Indexed {
+		idx, ok, err := tbl.index(simple.Index)
+		if err != nil {
+			return accessPath{}, err
+		} else if !ok {
+			return accessPath{}, fmt.Errorf("%v: %w", simple.Index, ErrNoSuchIndex)
+		}
+		// if the leading column of the index is not restricted, the whole
+		// index is read
+		var rng indexRange
+		if restricted {
+			rng, _ = matchPredicate(idx.cols, pred)
+		}
+		return accessPath{index: idx, rng: rng}, nil
+	}
+	if !restricted {
+		return accessPath{}, nil
+	}
+
+	// the primary key of a clustered table is preferred over indexes
+	if _, clustered, err := tbl.clusteredTree(); err != nil {
+		return accessPath{}, err
+	} else if clustered {
+		sf, err := tbl.tx.SchemaFile(tbl.name)
+		if err != nil {
+			return accessPath{}, fmt.Errorf("schema file: %w", err)
+		}
+		key, err := columnIndices(cols, sf.PrimaryKey)
+		if err != nil {
+			return accessPath{}, err
+		}
+		if rng, ok := matchPredicate(key, pred); ok {
+			return accessPath{primary: true, rng: rng}, nil
+		}
+	}
+	indexes, err := tbl.indexes()
+	if err != nil {
+		return accessPath{}, err
+	}
+	for _, idx := range indexes {
+		if rng, ok := matchPredicate(idx.cols, pred); ok {
+			return accessPath{index: idx, rng: rng}, nil
+		}
+	}
+	return accessPath{}, nil
+}
+
+// matchPredicate returns the range of an index over the given columns, that
+// holds all entries, that match the given predicate. If the predicate doesn't
+// restrict the leading column of the index, false is returned. An equality
+// restricts the prefix of the range to its value, so that an index over
+// multiple columns can be used with a predicate over its first column.
+func matchPredicate(indexed []int, pred indexPredicate) (indexRange, bool) {
+	if len(indexed) == 0 || indexed[0] != pred.col {
+		return indexRange{}, false
+	}
+	if pred.equal != nil {
+		return indexRange{prefix: []types.Value{pred.equal}}, true
+	}
+	return indexRange{lower: pred.lower, upper: pred.upper}, true
+}
+
+// scan calls the given function with every record of the given table, that is
+// read through this access path. The scan stops at the first error, that the
+// function returns.
+func (p accessPath) scan(tbl *Table, fn func(record) error) error {
+	var next func() (record, error)
+	if p.isFullScan() {
+		it, err := newTableRowIterator(tbl)
+		if err != nil {
+			return fmt.Errorf("rows: %w", err)
+		}
+		defer func() {
+			_ = it.Close()
+		}()
+		next = it.nextRecord
+	} else {
+		it, err := newRangeTableIterator(tbl, p)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = it.Close()
+		}()
+		next = it.nextRecord
+	}
+
+	for {
+		rec, err := next()
+		if err == table.ErrEOT {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// isFullScan returns whether this access path reads all records of a table in
+// the order of the table.
+func (p accessPath) isFullScan() bool {
+	return p.index == nil && !p.primary
+}
+
+// tree returns the tree, from which the records in the range of this access
+// path are read, which must not be a full scan.
+func (p accessPath) tree(tbl *Table) (*btree.Tree, error) {
+	if p.index != nil {
+		return p.index.tree, nil
+	}
+	tree, _, err := tbl.clusteredTree()
+	return tree, err
+}
+
+// indexPredicate returns the predicate of the given filter, that can be
+// evaluated with an index over one of the given columns, or false, if there is
+// no such predicate. Comparisons of a column with a constant of the type of the
+// column, ranges of such constants, and LIKE expressions with a constant
+// pattern, are such predicates.
+func (e Engine) indexPredicate(ctx ExecutionContext, cols []table.Col, filter command.Expr) (indexPredicate, bool) {
+	switch f := filter.(type) {
+	case command.EqualityExpr:
+		if f.Invert {
+			return indexPredicate{}, false
+		}
+		if col, value, _, ok := e.columnComparison(ctx, cols, f.Left, f.Right); ok {
+			return indexPredicate{col: col, equal: value}, true
+		}
+	case command.LessThanExpr:
+		return e.boundPredicate(ctx, cols, f.BinaryBase, false, false)
+	case command.LessThanOrEqualToExpr:
+		return e.boundPredicate(ctx, cols, f.BinaryBase, false, true)
+	case command.GreaterThanExpr:
+		return e.boundPredicate(ctx, cols, f.BinaryBase, true, false)
+	case command.GreaterThanOrEqualToExpr:
+		return e.boundPredicate(ctx, cols, f.BinaryBase, true, true)
+	case command.LikeExpr:
+		return e.likePredicate(ctx, cols, f)
+	case command.RangeExpr:
+		if f.Invert {
+			return indexPredicate{}, false
+		}
+		col, ok := columnOperand(cols, f.Needle)
+		if !ok {
+			return indexPredicate{}, false
+		}
+		lo, ok := e.constantOperand(ctx, cols, f.Lo, cols[col].Type)
+		if !ok {
+			return indexPredicate{}, false
+		}
+		hi, ok := e.constantOperand(ctx, cols, f.Hi, cols[col].Type)
+		if !ok {
+			return indexPredicate{}, false
+		}
+		return indexPredicate{
+			col:   col,
+			lower: &indexBound{value: lo, inclusive: true},
+			upper: &indexBound{value: hi, inclusive: true},
+		}, true
+	}
+	return indexPredicate{}, false
+}
+
+// likePredicate returns the predicate of the given LIKE expression, which
+// restricts a string column to the strings, that start with the prefix of a
+// constant pattern, see likePrefix. If the prefix is empty, because the
+// pattern starts with a wildcard or a letter, false is returned.
+func (e Engine) likePredicate(ctx ExecutionContext, cols []table.Col, like command.LikeExpr) (indexPredicate, bool) {
+	if like.Invert {
+		return indexPredicate{}, false
+	}
+	col, ok := columnOperand(cols, like.Value)
+	if !ok || cols[col].Type != types.String {
+		return indexPredicate{}, false
+	}
+	if !isConstant(cols, like.Pattern) || (like.Escape != nil && !isConstant(cols, like.Escape)) {
+		return indexPredicate{}, false
+	}
+	pattern, ok, err := e.evaluateLikePattern(ctx, like)
+	if err != nil || !ok {
+		return indexPredicate{}, false
+	}
+	prefix := likePrefix(pattern)
+	if prefix == "" {
+		return indexPredicate{}, false
+	}
+
+	pred := indexPredicate{
+		col:   col,
+		lower: &indexBound{value: types.NewString(prefix), inclusive: true},
+	}
+	// the smallest string, that is greater than all strings with the prefix
+	if end := btree.PrefixEnd([]byte(prefix)); end != nil {
+		pred.upper = &indexBound{value: types.NewString(string(end))}
+	}
+	return pred, true
+}
+
+// boundPredicate returns the predicate of the given comparison, which bounds a
+// column from below, if lower is true, or from above otherwise. If the column
+// is the right operand of the comparison, the bound is reversed.
+func (e Engine) boundPredicate(ctx ExecutionContext, cols []table.Col, cmp command.BinaryBase, lower, inclusive bool) (indexPredicate, bool) {
+	col, value, swapped, ok := e.columnComparison(ctx, cols, cmp.Left, cmp.Right)
+	if !ok {
+		return indexPredicate{}, false
+	}
+	pred := indexPredicate{col: col}
+	bound := &indexBound{value: value, inclusive: inclusive}
+	if lower != swapped {
+		pred.lower = bound
+	} else {
+		pred.upper = bound
+	}
+	return pred, true
+}
+
+// columnComparison returns the column and the constant value, that are
+// compared by a comparison with the given operands. If the column is the right
+// operand, swapped is true. If the operands are not a column and a constant of
+// the type of the column, false is returned.
+func (e Engine) columnComparison(ctx ExecutionContext, cols []table.Col, left, right command.Expr) (col int, value types.Value, swapped bool, ok bool) {
+	if col, ok := columnOperand(cols, left); ok {
+		if value, ok := e.constantOperand(ctx, cols, right, cols[col].Type); ok {
+			return col, value, false, true
+		}
+	}
+	if col, ok := columnOperand(cols, right); ok {
+		if value, ok := e.constantOperand(ctx, cols, left, cols[col].Type); ok {
+			return col, value, true, true
+		}
+	}
+	return 0, nil, false, false
+}
+
+// columnOperand returns the index of the column of the given columns, that the
+// given expression references, or false, if the expression is not a column
+// reference.
+func columnOperand(cols []table.Col, expr command.Expr) (int, bool) {
+	var name string
+	switch expr := expr.(type) {
+	case command.ColumnReference:
+		name = expr.Name
+	case command.ConstantLiteralOrColumnReference:
+		name = expr.ValueOrName
+	default:
+		return 0, false
+	}
+	for i, col := range cols {
+		if col.MatchesName(name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// constantOperand evaluates the given expression, if it doesn't reference any
+// of the given columns. If the expression references a column, or if it
+// doesn't evaluate to a value of the given type, false is returned. NULL
+// values are never returned, since they are not equal to any value.
+func (e Engine) constantOperand(ctx ExecutionContext, cols []table.Col, expr command.Expr, typ types.Type) (types.Value, bool) {
+	if !isConstant(cols, expr) {
+		return nil, false
+	}
+	value, err := e.evaluateExpression(ctx, expr)
+	if err != nil || value.IsNull() || !value.Is(typ) {
+		return nil, false
+	}
+	return value, true
+}
+
+// isConstant returns whether the given expression evaluates to the same value
+// for every row with the given columns. Function calls are not considered
+// constant, since functions may return different values on every call.
+func isConstant(cols []table.Col, expr command.Expr) bool {
+	switch expr := expr.(type) {
+	case command.ConstantLiteral, command.ConstantBooleanExpr:
+		return true
+	case command.ConstantLiteralOrColumnReference:
+		_, ok := columnOperand(cols, expr)
+		return !ok
+	case command.UnaryNegativeExpr:
+		return isConstant(cols, expr.Value)
+	case command.UnaryNegationExpr:
+		return isConstant(cols, expr.Value)
+	case command.UnaryBitwiseNegationExpr:
+		return isConstant(cols, expr.Value)
+	case command.BinaryExpression:
+		return isConstant(cols, expr.LeftExpr()) && isConstant(cols, expr.RightExpr())
+	}
+	return false
+}
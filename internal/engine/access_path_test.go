@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler"
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+	"github.com/xqueries/xdb/internal/parser"
+)
+
+func TestAccessPathSuite(t *testing.T) {
+	suite.Run(t, new(AccessPathSuite))
+}
+
+type AccessPathSuite struct {
+	EngineSuite
+}
+
+func (suite *AccessPathSuite) TestChooseIndex() {
+	suite.setupItems()
+
+	for _, tt := range []struct {
+		filter string
+		index  string
+		rng    indexRange
+	}{
+		{"id = 3", "items_id", indexRange{prefix: []types.Value{types.NewInteger(3)}}},
+		{"3 = id", "items_id", indexRange{prefix: []types.Value{types.NewInteger(3)}}},
+		{"id > 3", "items_id", indexRange{lower: &indexBound{value: types.NewInteger(3)}}},
+		{"id <= -3", "items_id", indexRange{upper: &indexBound{value: types.NewInteger(-3), inclusive: true}}},
+		{"3 > id", "items_id", indexRange{upper: &indexBound{value: types.NewInteger(3)}}},
+		{"id BETWEEN 2 AND 4", "items_id", indexRange{
+			lower: &indexBound{value: types.NewInteger(2), inclusive: true},
+			upper: &indexBound{value: types.NewInteger(4), inclusive: true},
+		}},
+		// the equality is a prefix of the composite index
+		{"items.grp = 1", "items_grp_name", indexRange{prefix: []types.Value{types.NewInteger(1)}}},
+		{"name = 'b'", "", indexRange{}},
+		{"id NOT BETWEEN 2 AND 4", "", indexRange{}},
+		{"id = grp", "", indexRange{}},
+		{"id = 'a'", "", indexRange{}},
+		{"id = 1.5", "", indexRange{}},
+	} {
+		path := suite.chooseAccessPath(command.SimpleTable{Table: "items"}, tt.filter)
+		if tt.index == "" {
+			suite.Nil(path.index, tt.filter)
+			continue
+		}
+		if suite.NotNil(path.index, tt.filter) {
+			suite.Equal(tt.index, path.index.def.Name, tt.filter)
+			suite.Equal(tt.rng, path.rng, tt.filter)
+		}
+	}
+}
+
+func (suite *AccessPathSuite) TestMatchPredicate() {
+	// the predicates are matched against an index over grp and name
+	grp := indexPredicate{col: 1, equal: types.NewInteger(1)}
+	grpRange := indexPredicate{col: 1, lower: &indexBound{value: types.NewInteger(1)}}
+	name := indexPredicate{col: 2, equal: types.NewString("b")}
+
+	for _, tt := range []struct {
+		pred indexPredicate
+		rng  indexRange
+		ok   bool
+	}{
+		{grp, indexRange{prefix: []types.Value{types.NewInteger(1)}}, true},
+		{grpRange, indexRange{lower: grpRange.lower}, true},
+		// name is no leading column of the index
+		{name, indexRange{}, false},
+	} {
+		rng, ok := matchPredicate([]int{1, 2}, tt.pred)
+		suite.Equal(tt.rng, rng)
+		suite.Equal(tt.ok, ok)
+	}
+}
+
+func (suite *AccessPathSuite) TestLikePrefix() {
+	suite.setupItems()
+	suite.Require().NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (7, 3, '1-b'), (8, 3, '1-B'), (9, 3, '2-b'), (10, 3, '1b')`))
+
+	// the prefix ends before the first letter, since letters match
+	// regardless of their case
+	path := suite.chooseAccessPath(command.SimpleTable{Table: "items"}, "name LIKE '1-b%'")
+	suite.Require().NotNil(path.index)
+	suite.Equal("items_name", path.index.def.Name)
+	suite.Equal(indexRange{
+		lower: &indexBound{value: types.NewString("1-"), inclusive: true},
+		upper: &indexBound{value: types.NewString("1.")},
+	}, path.rng)
+
+	for _, filter := range []string{"name LIKE 'b%'", "name LIKE '%b'", "name NOT LIKE '1%'", "id LIKE '1%'"} {
+		suite.Nil(suite.chooseAccessPath(command.SimpleTable{Table: "items"}, filter).index, filter)
+	}
+
+	suite.ElementsMatch([]table.Row{
+		intRow(7),
+		intRow(8),
+	}, suite.selectRows(`SELECT id FROM items WHERE name LIKE '1-b%'`))
+	suite.Equal([]table.Row{
+		intRow(10),
+	}, suite.selectRows(`SELECT id FROM items WHERE name LIKE '1B'`))
+}
+
+func (suite *AccessPathSuite) TestHints() {
+	suite.setupItems()
+
+	path := suite.chooseAccessPath(command.SimpleTable{Table: "items", NotIndexed: true}, "id = 3")
+	suite.Nil(path.index)
+
+	// the given index is read entirely, if the filter can not be evaluated
+	// with it
+	path = suite.chooseAccessPath(command.SimpleTable{Table: "items", Indexed: true, Index: "items_grp_name"}, "id = 3")
+	suite.Require().NotNil(path.index)
+	suite.Equal("items_grp_name", path.index.def.Name)
+	suite.Equal(indexRange{}, path.rng)
+
+	_, err := suite.engine.chooseAccessPath(suite.ctx, suite.items(), command.SimpleTable{Table: "items", Indexed: true, Index: "unknown"}, command.ConstantBooleanExpr{Value: true})
+	suite.ErrorIs(err, ErrNoSuchIndex)
+}
+
+func (suite *AccessPathSuite) TestSelect() {
+	suite.setupItems()
+
+	for _, query := range []string{
+		`SELECT id FROM items WHERE id BETWEEN 2 AND 4`,
+		`SELECT id FROM items WHERE id > 4`,
+		`SELECT id FROM items WHERE grp = 1`,
+		// NULL is less than any other value
+		`SELECT id FROM items WHERE grp < 1`,
+	} {
+		indexed, err := suite.evaluateStatement(query)
+		suite.Require().NoError(err, query)
+		scanned, err := suite.evaluateStatement(fmt.Sprintf("SELECT id FROM items NOT INDEXED %v", query[len("SELECT id FROM items "):]))
+		suite.Require().NoError(err, query)
+		suite.ElementsMatch(suite.rows(scanned), suite.rows(indexed), query)
+	}
+
+	// rows are returned in the order of the index
+	tbl, err := suite.evaluateStatement(`SELECT id FROM items INDEXED BY items_grp_name`)
+	suite.Require().NoError(err)
+	suite.Equal([]table.Row{
+		intRow(6),
+		intRow(1),
+		intRow(4),
+		intRow(3),
+		intRow(5),
+		intRow(2),
+	}, suite.rows(tbl))
+
+	_, err = suite.evaluateStatement(`SELECT id FROM items INDEXED BY unknown WHERE id = 1`)
+	suite.ErrorIs(err, ErrNoSuchIndex)
+}
+
+func (suite *AccessPathSuite) TestRangeIsReadLazily() {
+	suite.setupItems()
+	values := make([]string, 2*rangeBatchSize)
+	for i := range values {
+		values[i] = fmt.Sprintf("(%v, 3, 'd')", 10+i)
+	}
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES ` + strings.Join(values, ", ")))
+
+	suite.Equal([]table.Row{
+		intRow(10),
+		intRow(11),
+		intRow(12),
+	}, suite.selectRows(`SELECT id FROM items WHERE id >= 10 LIMIT 3`))
+
+	// only the first batch is read for the first row
+	it, err := newRangeTableIterator(suite.items(), suite.chooseAccessPath(command.SimpleTable{Table: "items"}, "grp = 3"))
+	suite.Require().NoError(err)
+	_, err = it.Next()
+	suite.Require().NoError(err)
+	suite.Len(it.buffered, rangeBatchSize-1)
+	suite.False(it.done)
+
+	count := 1
+	for {
+		_, err := it.Next()
+		if err == table.ErrEOT {
+			break
+		}
+		suite.Require().NoError(err)
+		count++
+	}
+	suite.Equal(2*rangeBatchSize, count)
+}
+
+func (suite *AccessPathSuite) TestDelete() {
+	suite.setupItems()
+
+	suite.NoError(suite.exec(`DELETE FROM items WHERE id BETWEEN 2 AND 5`))
+	tbl, err := suite.evaluateStatement(`SELECT id FROM items`)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]table.Row{
+		intRow(1),
+		intRow(6),
+	}, suite.rows(tbl))
+
+	suite.ErrorIs(suite.exec(`DELETE FROM items INDEXED BY unknown WHERE id = 1`), ErrNoSuchIndex)
+}
+
+func (suite *AccessPathSuite) TestUpdate() {
+	suite.setupItems()
+
+	// the updated column is the indexed column, through which the rows are
+	// read, and every row is still updated once
+	suite.NoError(suite.exec(`UPDATE items SET id = id + 1 WHERE id >= 2`))
+	suite.NoError(suite.exec(`UPDATE items INDEXED BY items_grp_name SET name = 'd' WHERE grp = 2`))
+	tbl, err := suite.evaluateStatement(`SELECT id, name FROM items`)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("d")}},
+		{Values: []types.Value{types.NewInteger(4), types.NewString("c")}},
+		{Values: []types.Value{types.NewInteger(5), types.NewString("b")}},
+		{Values: []types.Value{types.NewInteger(6), types.NewString("d")}},
+		{Values: []types.Value{types.NewInteger(7), types.NewString("c")}},
+	}, suite.rows(tbl))
+
+	suite.ErrorIs(suite.exec(`UPDATE items INDEXED BY unknown SET id = 1`), ErrNoSuchIndex)
+	suite.NoError(suite.exec(`UPDATE items NOT INDEXED SET id = 0 WHERE id = 1`))
+}
+
+// setupItems creates a table items with an index over its id, and a
+// composite index over its group and name.
+func (suite *AccessPathSuite) setupItems() {
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER, grp INTEGER, name STRING)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 1, 'a'), (2, 2, 'b'), (3, 1, 'c'), (4, 1, 'b'), (5, 2, 'a')`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items (id, name) VALUES (6, 'c')`))
+	suite.Require().NoError(suite.exec(`CREATE INDEX items_id ON items (id)`))
+	suite.Require().NoError(suite.exec(`CREATE INDEX items_grp_name ON items (grp, name)`))
+}
+
+// chooseAccessPath chooses the access path for the given table and the filter
+// of the given WHERE clause.
+func (suite *AccessPathSuite) chooseAccessPath(simple command.SimpleTable, where string) accessPath {
+	p, err := parser.New(`DELETE FROM items WHERE ` + where)
+	suite.Require().NoError(err)
+	stmt, errs, ok := p.Next()
+	suite.Require().True(ok)
+	suite.Require().Len(errs, 0)
+	cmd, err := compiler.New().Compile(stmt)
+	suite.Require().NoError(err)
+	path, err := suite.engine.chooseAccessPath(suite.ctx, suite.items(), simple, cmd.(command.Delete).Filter)
+	suite.Require().NoError(err)
+	return path
+}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// affectedRows returns a table with a single row and a single column, which
+// holds the given amount of rows, that were affected by a command that
+// modifies data.
+//
+//	affected (Integer)
+//	3
+func affectedRows(n int) table.Table {
+	return table.NewInMemory(
+		[]table.Col{
+			{
+				QualifiedName: "affected",
+				Type:          types.Integer,
+			},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(int64(n))}},
+		},
+	)
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// aggregateGroup is a group of rows of an aggregate, that have the same
+// values for all group expressions.
+type aggregateGroup struct {
+	values      []types.Value
+	aggregators []aggregator
+}
+
+// evaluateAggregate evaluates the given aggregate, by reading all rows of the
+// input list, and assigning them to a group by the values of the group
+// expressions, which are hashed. The aggregate functions are computed for every
+// group while the rows are read. The result contains one row per group, in the
+// order in which the groups were first encountered.
+func (e Engine) evaluateAggregate(ctx ExecutionContext, agg command.Aggregate) (table.Table, error) {
+	defer e.profiler.Enter("aggregate").Exit()
+
+	origin, err := e.evaluateList(ctx, agg.Input)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	originCols, err := origin.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	newGroup := func(values []types.Value) (*aggregateGroup, error) {
+		group := &aggregateGroup{values: values}
+		for _, fn := range agg.Aggregates {
+			aggregator, err := e.newAggregator(fn, originCols)
+			if err != nil {
+				return nil, err
+			}
+			group.aggregators = append(group.aggregators, aggregator)
+		}
+		return group, nil
+	}
+
+	it, err := origin.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	defer func() {
+		_ = it.Close()
+	}()
+
+	groupsByKey := make(map[string]*aggregateGroup)
+	var groups []*aggregateGroup
+	for {
+		next, err := it.Next()
+		if err == table.ErrEOT {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		rowCtx := ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: originCols,
+			Row:  next,
+		})
+		values, err := e.evaluateMultipleExpressions(rowCtx, agg.GroupBy)
+		if err != nil {
+			return nil, fmt.Errorf("group by: %w", err)
+		}
+		key := valuesKey(values)
+		group, ok := groupsByKey[key]
+		if !ok {
+			group, err = newGroup(values)
+			if err != nil {
+				return nil, err
+			}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+
+		for i, aggregator := range group.aggregators {
+			if err := aggregator.add(rowCtx); err != nil {
+				return nil, fmt.Errorf("%v: %w", agg.Aggregates[i], err)
+			}
+		}
+	}
+
+	// without group expressions, all rows form a single group, even if
+	// there are no rows at all
+	if len(agg.GroupBy) == 0 && len(groups) == 0 {
+		group, err := newGroup(nil)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	rows := make([]table.Row, len(groups))
+	for i, group := range groups {
+		values := append([]types.Value{}, group.values...)
+		for _, aggregator := range group.aggregators {
+			values = append(values, aggregator.result())
+		}
+		rows[i] = table.Row{Values: values}
+	}
+
+	var cols []table.Col
+	for i, expr := range agg.GroupBy {
+		if ref, ok := expr.(command.ColumnReference); ok {
+			if col, ok := table.FindColumnForNameOrAlias(origin, ref.Name); ok {
+				col.Alias = ""
+				cols = append(cols, col)
+				continue
+			}
+		}
+		cols = append(cols, table.Col{
+			QualifiedName: expr.String(),
+			Type:          columnType(rows, i, types.Integer),
+		})
+	}
+	for i, fn := range agg.Aggregates {
+		cols = append(cols, table.Col{
+			QualifiedName: fn.String(),
+			Type:          columnType(rows, len(agg.GroupBy)+i, types.Integer),
+		})
+	}
+
+	return table.NewInMemory(cols, rows), nil
+}
+
+// columnType returns the type of the values in the column with the given index
+// of the given rows. If there are no rows, the given fallback type is returned.
+func columnType(rows []table.Row, index int, fallback types.Type) types.Type {
+	for _, row := range rows {
+		if !row.Values[index].IsNull() {
+			return row.Values[index].Type()
+		}
+	}
+	if len(rows) > 0 {
+		return rows[0].Values[index].Type()
+	}
+	return fallback
+}
+
+// valuesKey computes a key for the given values, that is equal for two lists
+// of values exactly if all values are equal. Null values are considered equal
+// to each other.
+func valuesKey(values []types.Value) string {
+	var buf strings.Builder
+	for _, val := range values {
+		if val.IsNull() {
+			buf.WriteString("NULL")
+		} else {
+			buf.WriteString(val.Type().Name())
+			buf.WriteByte(':')
+			buf.WriteString(val.String())
+		}
+		buf.WriteByte(0)
+	}
+	return buf.String()
+}
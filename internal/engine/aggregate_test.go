@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestAggregateSuite(t *testing.T) {
+	suite.Run(t, new(AggregateSuite))
+}
+
+type AggregateSuite struct {
+	EngineSuite
+}
+
+func (suite *AggregateSuite) TestAggregateGroupBy() {
+	suite.RunScript(`
+CREATE TABLE myTable (name STRING, amount INTEGER);
+INSERT INTO myTable VALUES ("a", 1), ("b", 2), ("a", 3), ("a", 3)`)
+
+	amount := command.ColumnReference{Name: "amount"}
+	result, err := suite.engine.evaluateAggregate(suite.ctx, command.Aggregate{
+		GroupBy: []command.Expr{command.ColumnReference{Name: "name"}},
+		Aggregates: []command.FunctionExpr{
+			{Name: "COUNT", Args: []command.Expr{command.ColumnReference{Name: "*"}}},
+			{Name: "count", Distinct: true, Args: []command.Expr{amount}},
+			{Name: "SUM", Args: []command.Expr{amount}},
+			{Name: "SUM", Distinct: true, Args: []command.Expr{amount}},
+			{Name: "AVG", Args: []command.Expr{amount}},
+			{Name: "MIN", Args: []command.Expr{amount}},
+			{Name: "MAX", Args: []command.Expr{amount}},
+		},
+		Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "name", Type: types.String},
+			{QualifiedName: "COUNT(*)", Type: types.Integer},
+			{QualifiedName: "count(DISTINCT amount)", Type: types.Integer},
+			{QualifiedName: "SUM(amount)", Type: types.Integer},
+			{QualifiedName: "SUM(DISTINCT amount)", Type: types.Integer},
+			{QualifiedName: "AVG(amount)", Type: types.Real},
+			{QualifiedName: "MIN(amount)", Type: types.Integer},
+			{QualifiedName: "MAX(amount)", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewString("a"), types.NewInteger(3), types.NewInteger(2), types.NewInteger(7), types.NewInteger(4), types.NewReal(7.0 / 3), types.NewInteger(1), types.NewInteger(3)}},
+			{Values: []types.Value{types.NewString("b"), types.NewInteger(1), types.NewInteger(1), types.NewInteger(2), types.NewInteger(2), types.NewReal(2), types.NewInteger(2), types.NewInteger(2)}},
+		},
+	), result)
+}
+
+func (suite *AggregateSuite) TestAggregateWithoutRows() {
+	suite.RunScript(`CREATE TABLE myTable (name STRING, amount INTEGER)`)
+
+	amount := command.ColumnReference{Name: "amount"}
+	input := command.Scan{Table: command.SimpleTable{Table: "myTable"}}
+	aggregates := []command.FunctionExpr{
+		{Name: "COUNT", Args: []command.Expr{command.ColumnReference{Name: "*"}}},
+		{Name: "SUM", Args: []command.Expr{amount}},
+		{Name: "MAX", Args: []command.Expr{amount}},
+	}
+
+	// without group expressions, there is exactly one group
+	result, err := suite.engine.evaluateAggregate(suite.ctx, command.Aggregate{
+		Aggregates: aggregates,
+		Input:      input,
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "COUNT(*)", Type: types.Integer},
+			{QualifiedName: "SUM(amount)", Type: types.Integer},
+			{QualifiedName: "MAX(amount)", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(0), types.NewNull(types.Integer), types.NewNull(types.Integer)}},
+		},
+	), result)
+
+	// with group expressions, there are no groups
+	result, err = suite.engine.evaluateAggregate(suite.ctx, command.Aggregate{
+		GroupBy:    []command.Expr{command.ColumnReference{Name: "name"}},
+		Aggregates: aggregates,
+		Input:      input,
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "name", Type: types.String},
+			{QualifiedName: "COUNT(*)", Type: types.Integer},
+			{QualifiedName: "SUM(amount)", Type: types.Integer},
+			{QualifiedName: "MAX(amount)", Type: types.Integer},
+		},
+		nil,
+	), result)
+}
+
+func (suite *AggregateSuite) TestAggregateUnknownFunction() {
+	suite.RunScript(`CREATE TABLE myTable (name STRING, amount INTEGER)`)
+
+	_, err := suite.engine.evaluateAggregate(suite.ctx, command.Aggregate{
+		Aggregates: []command.FunctionExpr{
+			{Name: "MEDIAN", Args: []command.Expr{command.ColumnReference{Name: "amount"}}},
+		},
+		Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.Equal(ErrNoSuchFunction("MEDIAN"), err)
+}
+
+func (suite *AggregateSuite) TestGroupByClause() {
+	suite.createMyTable(`name STRING, amount INTEGER`, `('a', 1), ('b', 2), ('a', 3), ('c', 3)`)
+
+	countRow := func(name string, count int64) table.Row {
+		return table.Row{Values: []types.Value{types.NewString(name), types.NewInteger(count)}}
+	}
+	// GROUP BY at the end of the statement
+	suite.ElementsMatch([]table.Row{
+		countRow("a", 2),
+		countRow("b", 1),
+		countRow("c", 1),
+	}, suite.selectRows(`SELECT name, COUNT(*) FROM myTable GROUP BY name`))
+	// GROUP BY followed by ORDER BY and LIMIT
+	suite.Equal([]table.Row{
+		countRow("c", 1),
+		countRow("b", 1),
+	}, suite.selectRows(`SELECT name, COUNT(*) FROM myTable GROUP BY name ORDER BY name DESC LIMIT 2`))
+	suite.Equal([]table.Row{
+		countRow("a", 2),
+	}, suite.selectRows(`SELECT name, COUNT(*) FROM myTable GROUP BY name LIMIT 1`))
+}
@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// aggregator computes the result of an aggregate function over the rows of a
+// group, one row at a time.
+type aggregator interface {
+	// add evaluates the argument of the aggregate function with the given
+	// context, and adds the result to the aggregation.
+	add(ctx ExecutionContext) error
+	// result returns the result of the aggregate function over all added
+	// rows.
+	result() types.Value
+}
+
+// valueAggregator aggregates single values. Null values are never passed into
+// a valueAggregator.
+type valueAggregator interface {
+	addValue(types.Value) error
+	result() types.Value
+}
+
+// newAggregator creates a new aggregator for the given aggregate function call.
+// The given columns are the columns of the rows that are aggregated.
+func (e Engine) newAggregator(fn command.FunctionExpr, cols []table.Col) (aggregator, error) {
+	if len(fn.Args) != 1 {
+		return nil, fmt.Errorf("%v: expected 1 argument, got %v", fn.Name, len(fn.Args))
+	}
+	arg := fn.Args[0]
+
+	name := strings.ToUpper(fn.Name)
+	if ref, ok := arg.(command.ColumnReference); ok && ref.Name == "*" {
+		if name != "COUNT" || fn.Distinct {
+			return nil, fmt.Errorf("cannot use * as argument of %v", fn)
+		}
+		return &countStarAggregator{}, nil
+	}
+
+	// the type of the argument is used for NULL results
+	typ := types.Type(types.Integer)
+	if ref, ok := arg.(command.ColumnReference); ok {
+		for _, col := range cols {
+			if col.MatchesName(ref.Name) {
+				typ = col.Type
+				break
+			}
+		}
+	}
+
+	var values valueAggregator
+	switch name {
+	case "COUNT":
+		values = &countAggregator{}
+	case "SUM":
+		values = &sumAggregator{typ: typ}
+	case "AVG":
+		values = &avgAggregator{}
+	case "MIN":
+		values = &extremumAggregator{typ: typ, pick: e.builtinMin}
+	case "MAX":
+		values = &extremumAggregator{typ: typ, pick: e.builtinMax}
+	default:
+		return nil, ErrNoSuchFunction(fn.Name)
+	}
+
+	agg := &argumentAggregator{
+		e:      e,
+		arg:    arg,
+		values: values,
+	}
+	if fn.Distinct {
+		agg.seen = make(map[string]struct{})
+	}
+	return agg, nil
+}
+
+// argumentAggregator evaluates the argument of an aggregate function for every
+// row, and passes all values that are not null to a valueAggregator. If seen
+// is not nil, every value is only passed once.
+type argumentAggregator struct {
+	e      Engine
+	arg    command.Expr
+	values valueAggregator
+	seen   map[string]struct{}
+}
+
+func (a *argumentAggregator) add(ctx ExecutionContext) error {
+	val, err := a.e.evaluateExpression(ctx, a.arg)
+	if err != nil {
+		return err
+	}
+	if val.IsNull() {
+		return nil
+	}
+	if a.seen != nil {
+		key := valuesKey([]types.Value{val})
+		if _, ok := a.seen[key]; ok {
+			return nil
+		}
+		a.seen[key] = struct{}{}
+	}
+	return a.values.addValue(val)
+}
+
+func (a *argumentAggregator) result() types.Value {
+	return a.values.result()
+}
+
+// countStarAggregator counts all rows, as in COUNT(*).
+type countStarAggregator struct {
+	count int64
+}
+
+func (a *countStarAggregator) add(ExecutionContext) error {
+	a.count++
+	return nil
+}
+
+func (a *countStarAggregator) result() types.Value {
+	return types.NewInteger(a.count)
+}
+
+// countAggregator counts all values.
+type countAggregator struct {
+	count int64
+}
+
+func (a *countAggregator) addValue(types.Value) error {
+	a.count++
+	return nil
+}
+
+func (a *countAggregator) result() types.Value {
+	return types.NewInteger(a.count)
+}
+
+// sumAggregator sums up integer and real values. The result is an integer, if
+// all values are integers, and a real value otherwise. The sum of no values is
+// null.
+type sumAggregator struct {
+	typ     types.Type
+	seen    bool
+	isReal  bool
+	intSum  int64
+	realSum float64
+}
+
+func (a *sumAggregator) addValue(val types.Value) error {
+	a.seen = true
+	switch v := val.(type) {
+	case types.IntegerValue:
+		a.intSum += v.Value
+	case types.RealValue:
+		a.isReal = true
+		a.realSum += v.Value
+	default:
+		return fmt.Errorf("cannot sum up values of type %v", val.Type())
+	}
+	return nil
+}
+
+func (a *sumAggregator) result() types.Value {
+	if !a.seen {
+		if a.typ == types.Real {
+			return types.NewNull(types.Real)
+		}
+		return types.NewNull(types.Integer)
+	}
+	if a.isReal {
+		return types.NewReal(float64(a.intSum) + a.realSum)
+	}
+	return types.NewInteger(a.intSum)
+}
+
+// avgAggregator computes the average of integer and real values. The result is
+// always a real value. The average of no values is null.
+type avgAggregator struct {
+	count int64
+	sum   float64
+}
+
+func (a *avgAggregator) addValue(val types.Value) error {
+	switch v := val.(type) {
+	case types.IntegerValue:
+		a.sum += float64(v.Value)
+	case types.RealValue:
+		a.sum += v.Value
+	default:
+		return fmt.Errorf("cannot average values of type %v", val.Type())
+	}
+	a.count++
+	return nil
+}
+
+func (a *avgAggregator) result() types.Value {
+	if a.count == 0 {
+		return types.NewNull(types.Real)
+	}
+	return types.NewReal(a.sum / float64(a.count))
+}
+
+// extremumAggregator keeps the value, that is picked out of the current and the
+// next value by the pick function, such as the smallest or largest value.
+type extremumAggregator struct {
+	typ     types.Type
+	pick    func(...types.Value) (types.Value, error)
+	current types.Value
+}
+
+func (a *extremumAggregator) addValue(val types.Value) error {
+	if a.current == nil {
+		a.current = val
+		return nil
+	}
+	picked, err := a.pick(a.current, val)
+	if err != nil {
+		return err
+	}
+	a.current = picked
+	return nil
+}
+
+func (a *extremumAggregator) result() types.Value {
+	if a.current == nil {
+		return types.NewNull(a.typ)
+	}
+	return a.current
+}
@@ -0,0 +1,471 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler"
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/page"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// evaluateAddColumn adds the column from the given command to its table. The
+// rows of the table are not rewritten. Rows, that were stored before the
+// column was added, hold the default value of the column, see deserializeRow.
+// An error is returned, if an existing row violates a constraint of the new
+// column, in which case the schema of the table is not changed.
+func (e Engine) evaluateAddColumn(ctx ExecutionContext, cmd command.AddColumn) (table.Table, error) {
+	defer e.profiler.Enter("add column").Exit()
+
+	sf, err := e.alteredSchema(ctx, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+	if columnIndex(sf.Columns, cmd.Column.Name) != -1 {
+		return nil, fmt.Errorf("%v.%v: %w", cmd.Table, cmd.Column.Name, ErrAlreadyExists)
+	}
+
+	original := *sf
+	if err := e.addColumn(ctx, cmd, sf); err != nil {
+		*sf = original
+		return nil, err
+	}
+	return table.Empty, nil
+}
+
+// addColumn adds the column from the given command to the given schema of its
+// table, and checks the existing rows of the table against the constraints of
+// the column. The slices of the schema are only appended to, and the defaults
+// of the schema are replaced, so that the original schema can be restored
+// from a shallow copy.
+func (e Engine) addColumn(ctx ExecutionContext, cmd command.AddColumn, sf *dbfs.SchemaFile) error {
+	def := cmd.Column
+	var defaultValue types.Value
+	if def.Default != nil {
+		var err error
+		if defaultValue, err = e.evaluateColumnDefault(ctx, def); err != nil {
+			return fmt.Errorf("default of column %v: %w", def.Name, err)
+		}
+	}
+	for _, check := range cmd.Checks {
+		if _, err := compiler.CompileExpr(check.Source); err != nil {
+			return fmt.Errorf("check %v: %w", check.Source, err)
+		}
+	}
+
+	sf.Columns = append(sf.Columns, table.Col{
+		QualifiedName: def.Name,
+		Type:          def.Type,
+	})
+	if def.NotNull {
+		sf.NotNull = append(sf.NotNull, def.Name)
+	}
+	if defaultValue != nil {
+		defaults := make(map[string]types.Value, len(sf.Defaults)+1)
+		for name, value := range sf.Defaults {
+			defaults[name] = value
+		}
+		defaults[def.Name] = defaultValue
+		sf.Defaults = defaults
+	}
+	for _, check := range cmd.Checks {
+		sf.Checks = append(sf.Checks, dbfs.Check{
+			Name: check.Name,
+			Expr: check.Source,
+		})
+	}
+	var foreignKeys []dbfs.ForeignKey
+	for _, foreignKey := range cmd.ForeignKeys {
+		stored, err := e.createForeignKey(ctx, cmd.Table, sf, foreignKey)
+		if err != nil {
+			return fmt.Errorf("foreign key (%v): %w", strings.Join(foreignKey.Cols, ","), err)
+		}
+		foreignKeys = append(foreignKeys, stored)
+	}
+	sf.ForeignKeys = append(sf.ForeignKeys, foreignKeys...)
+
+	// existing rows hold the default value of the new column, which must
+	// satisfy the constraints of the column
+	loaded, err := e.LoadTable(ctx.tx, cmd.Table)
+	if err != nil {
+		return fmt.Errorf("load table: %w", err)
+	}
+	tbl := loaded.(*Table)
+	records, err := e.selectRecords(ctx, tbl, command.ConstantBooleanExpr{Value: true})
+	if err != nil {
+		return err
+	}
+	rows := make([]table.Row, len(records))
+	for i, rec := range records {
+		if err := tbl.checkConstraints(rec.row); err != nil {
+			return err
+		}
+		rows[i] = rec.row
+	}
+	for _, foreignKey := range foreignKeys {
+		if e.isDeferred(foreignKey) {
+			continue
+		}
+		if err := e.checkReferences(ctx, cmd.Table, foreignKey, rows); err != nil {
+			return err
+		}
+	}
+	for _, foreignKey := range foreignKeys {
+		if e.isDeferred(foreignKey) {
+			e.deferForeignKeyCheck(ctx, cmd.Table, foreignKey)
+		}
+	}
+	return nil
+}
+
+// evaluateRenameColumn renames the column from the given command. All
+// constraints and indexes of the table, the foreign keys of all tables, that
+// reference the column, and the queries of views and the statements of
+// triggers, that reference the column, are updated.
+func (e Engine) evaluateRenameColumn(ctx ExecutionContext, cmd command.RenameColumn) (table.Table, error) {
+	defer e.profiler.Enter("rename column").Exit()
+
+	sf, err := e.alteredSchema(ctx, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+	index := columnIndex(sf.Columns, cmd.Column)
+	if index == -1 {
+		return nil, ErrNoSuchColumn(cmd.Column)
+	}
+	if columnIndex(sf.Columns, cmd.NewName) != -1 {
+		return nil, fmt.Errorf("%v.%v: %w", cmd.Table, cmd.NewName, ErrAlreadyExists)
+	}
+	name := sf.Columns[index].QualifiedName
+
+	checks := make([]dbfs.Check, len(sf.Checks))
+	for i, check := range sf.Checks {
+		renamed, err := compiler.RenameColumn(check.Expr, name, cmd.NewName)
+		if err != nil {
+			return nil, fmt.Errorf("check %v: %w", check.Expr, err)
+		}
+		checks[i] = dbfs.Check{
+			Name: check.Name,
+			Expr: renamed,
+		}
+	}
+	views, err := renamedViews(ctx, func(query string) (string, error) {
+		return compiler.RenameTableColumn(query, cmd.Table, name, cmd.NewName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := renamedTriggers(ctx, func(trigger dbfs.Trigger) (dbfs.Trigger, error) {
+		// the NEW and OLD rows of a trigger on the table reference its columns
+		var qualifiers []string
+		if trigger.Table == cmd.Table {
+			qualifiers = []string{newTable, oldTable}
+			columns := make([]string, len(trigger.Columns))
+			copy(columns, trigger.Columns)
+			renameColumn(columns, name, cmd.NewName)
+			trigger.Columns = columns
+		}
+		return renameTriggerSources(trigger, func(expr string) (string, error) {
+			return compiler.RenameTableColumnInExpr(expr, cmd.Table, name, cmd.NewName, qualifiers...)
+		}, func(statement string) (string, error) {
+			return compiler.RenameTableColumn(statement, cmd.Table, name, cmd.NewName, qualifiers...)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sf.Checks = checks
+
+	sf.Columns[index].QualifiedName = cmd.NewName
+	renameColumn(sf.PrimaryKey, name, cmd.NewName)
+	for _, key := range sf.UniqueKeys {
+		renameColumn(key, name, cmd.NewName)
+	}
+	renameColumn(sf.NotNull, name, cmd.NewName)
+	if defaultValue, ok := sf.Defaults[name]; ok {
+		delete(sf.Defaults, name)
+		sf.Defaults[cmd.NewName] = defaultValue
+	}
+	for _, def := range sf.Indexes {
+		renameColumn(def.Columns, name, cmd.NewName)
+	}
+	for _, foreignKey := range sf.ForeignKeys {
+		renameColumn(foreignKey.Columns, name, cmd.NewName)
+	}
+
+	// update the foreign keys, that reference the column
+	if err := e.updateForeignKeys(ctx, cmd.Table, func(foreignKey *dbfs.ForeignKey) {
+		renameColumn(foreignKey.ForeignColumns, name, cmd.NewName)
+	}); err != nil {
+		return nil, err
+	}
+	if err := storeViews(ctx, views); err != nil {
+		return nil, err
+	}
+	if err := storeTriggers(ctx, triggers); err != nil {
+		return nil, err
+	}
+
+	return table.Empty, nil
+}
+
+// evaluateRenameTable renames the table from the given command. The foreign
+// keys of all tables, that reference the table, the names of the indexes of
+// its keys, and the queries of views and the definitions of triggers, that
+// reference the table, are updated. If a view or a trigger can't be updated,
+// the table is not renamed.
+func (e Engine) evaluateRenameTable(ctx ExecutionContext, cmd command.RenameTable) (table.Table, error) {
+	defer e.profiler.Enter("rename table").Exit()
+	tx := ctx.tx
+
+	if _, err := e.alteredSchema(ctx, cmd.Table); err != nil {
+		return nil, err
+	}
+	if ok, err := tx.HasTable(cmd.NewName); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if ok {
+		return nil, fmt.Errorf("%v: %w", cmd.NewName, ErrAlreadyExists)
+	}
+	if _, ok, err := tx.View(cmd.NewName); err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	} else if ok {
+		return nil, fmt.Errorf("view %v: %w", cmd.NewName, ErrAlreadyExists)
+	}
+
+	views, err := renamedViews(ctx, func(query string) (string, error) {
+		return compiler.RenameTable(query, cmd.Table, cmd.NewName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := renamedTriggers(ctx, func(trigger dbfs.Trigger) (dbfs.Trigger, error) {
+		if trigger.Table == cmd.Table {
+			trigger.Table = cmd.NewName
+		}
+		return renameTriggerSources(trigger, func(expr string) (string, error) {
+			return compiler.RenameTableInExpr(expr, cmd.Table, cmd.NewName)
+		}, func(statement string) (string, error) {
+			return compiler.RenameTable(statement, cmd.Table, cmd.NewName)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.RenameTable(cmd.Table, cmd.NewName); err != nil {
+		return nil, fmt.Errorf("rename table: %w", err)
+	}
+	sf, err := tx.SchemaFile(cmd.NewName)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	autoIndexes := 0
+	for i, def := range sf.Indexes {
+		if def.Auto {
+			autoIndexes++
+			sf.Indexes[i].Name = autoIndexName(cmd.NewName, autoIndexes)
+		}
+	}
+
+	if err := e.updateForeignKeys(ctx, cmd.Table, func(foreignKey *dbfs.ForeignKey) {
+		foreignKey.ForeignTable = cmd.NewName
+	}); err != nil {
+		return nil, err
+	}
+	if err := storeViews(ctx, views); err != nil {
+		return nil, err
+	}
+	if err := storeTriggers(ctx, triggers); err != nil {
+		return nil, err
+	}
+
+	return table.Empty, nil
+}
+
+// evaluateDropColumn drops the column from the given command. Unlike adding a
+// column, all rows of the table are rewritten without the values of the
+// column, since the frames of a serialized row are not named. A column, that
+// is part of a key, an index, a foreign key or a CHECK constraint, can not be
+// dropped, and neither can the only column of a table.
+func (e Engine) evaluateDropColumn(ctx ExecutionContext, cmd command.DropColumn) (table.Table, error) {
+	defer e.profiler.Enter("drop column").Exit()
+
+	sf, err := e.alteredSchema(ctx, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+	index := columnIndex(sf.Columns, cmd.Column)
+	if index == -1 {
+		return nil, ErrNoSuchColumn(cmd.Column)
+	}
+	if len(sf.Columns) == 1 {
+		return nil, fmt.Errorf("cannot drop the only column %v of table %v", cmd.Column, cmd.Table)
+	}
+	name := sf.Columns[index].QualifiedName
+	if err := e.checkColumnUnused(ctx, cmd.Table, sf, name); err != nil {
+		return nil, err
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, cmd.Table)
+	if err != nil {
+		return nil, fmt.Errorf("load table: %w", err)
+	}
+	tbl := loaded.(*Table)
+	records, err := e.selectRecords(ctx, tbl, command.ConstantBooleanExpr{Value: true})
+	if err != nil {
+		return nil, err
+	}
+	// all records are deleted with the old schema, and stored again with the
+	// new schema, since the indexes of the table reference columns by their
+	// position in a row
+	for _, rec := range records {
+		if err := tbl.deleteRecord(rec); err != nil {
+			return nil, err
+		}
+	}
+
+	sf.Columns = append(sf.Columns[:index:index], sf.Columns[index+1:]...)
+	sf.NotNull = removeColumn(sf.NotNull, name)
+	delete(sf.Defaults, name)
+
+	tree, clustered, err := tbl.clusteredTree()
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		rec.row.Values = append(rec.row.Values[:index:index], rec.row.Values[index+1:]...)
+		if clustered {
+			if rec, err = tbl.storeClusteredRecord(tree, rec.row); err != nil {
+				return nil, err
+			}
+		} else {
+			serializedRow, err := serializeRow(rec.row)
+			if err != nil {
+				return nil, fmt.Errorf("serialize row: %w", err)
+			}
+			if rec.page, err = tbl.storeRecordCell(page.RecordCell{
+				Key:    rec.key,
+				Record: serializedRow,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		if err := tbl.addToIndexes(rec); err != nil {
+			return nil, err
+		}
+	}
+
+	return table.Empty, nil
+}
+
+// alteredSchema returns the schema of the table with the given name, which is
+// altered by a command. If the table does not exist, an error is returned.
+func (e Engine) alteredSchema(ctx ExecutionContext, tableName string) (*dbfs.SchemaFile, error) {
+	if ok, err := ctx.tx.HasTable(tableName); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if !ok {
+		return nil, fmt.Errorf("%v: %w", tableName, ErrNoSuchTable)
+	}
+	sf, err := ctx.tx.SchemaFile(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	return sf, nil
+}
+
+// checkColumnUnused returns an error, if the column with the given name of the
+// table with the given name and schema is part of a key, an index, a foreign
+// key or a CHECK constraint of any table.
+func (e Engine) checkColumnUnused(ctx ExecutionContext, tableName string, sf *dbfs.SchemaFile, name string) error {
+	for _, key := range sf.Keys() {
+		if containsColumn(key, name) {
+			return fmt.Errorf("column %v is part of the key (%v)", name, strings.Join(key, ","))
+		}
+	}
+	for _, def := range sf.Indexes {
+		if containsColumn(def.Columns, name) {
+			return fmt.Errorf("column %v is indexed by %v", name, def.Name)
+		}
+	}
+	for _, foreignKey := range sf.ForeignKeys {
+		if containsColumn(foreignKey.Columns, name) {
+			return fmt.Errorf("column %v is part of the foreign key (%v)", name, strings.Join(foreignKey.Columns, ","))
+		}
+	}
+	for _, check := range sf.Checks {
+		if ok, err := compiler.ReferencesColumn(check.Expr, name); err != nil {
+			return fmt.Errorf("check %v: %w", check.Expr, err)
+		} else if ok {
+			return fmt.Errorf("column %v is referenced by CHECK(%v)", name, check.Expr)
+		}
+	}
+
+	refs, err := e.referencingKeys(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if containsColumn(ref.key.ForeignColumns, name) {
+			return fmt.Errorf("column %v is referenced by %v(%v)", name, ref.table, strings.Join(ref.key.Columns, ","))
+		}
+	}
+	return nil
+}
+
+// updateForeignKeys calls the given function with all foreign keys of all
+// tables, that reference the table with the given name. The function may
+// modify the foreign keys.
+func (e Engine) updateForeignKeys(ctx ExecutionContext, tableName string, update func(*dbfs.ForeignKey)) error {
+	tables, err := ctx.tx.Tables()
+	if err != nil {
+		return fmt.Errorf("tables: %w", err)
+	}
+	for _, name := range tables {
+		sf, err := ctx.tx.SchemaFile(name)
+		if err != nil {
+			return fmt.Errorf("schema file of %v: %w", name, err)
+		}
+		for i := range sf.ForeignKeys {
+			if sf.ForeignKeys[i].ForeignTable == tableName {
+				update(&sf.ForeignKeys[i])
+			}
+		}
+	}
+	return nil
+}
+
+// renameColumn replaces the given name with the given new name in the given
+// column names.
+func renameColumn(names []string, name, newName string) {
+	for i := range names {
+		if names[i] == name {
+			names[i] = newName
+		}
+	}
+}
+
+// removeColumn returns the given column names without the given name.
+func removeColumn(names []string, name string) []string {
+	var result []string
+	for _, n := range names {
+		if n != name {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// containsColumn returns whether the given column names contain the given
+// name.
+func containsColumn(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestAlterSuite(t *testing.T) {
+	suite.Run(t, new(AlterSuite))
+}
+
+type AlterSuite struct {
+	EngineSuite
+}
+
+func (suite *AlterSuite) TestAddColumn() {
+	suite.setupItems("")
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE items ADD COLUMN grp INTEGER NOT NULL DEFAULT 7`))
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (3, 'c', 1)`))
+	suite.NoError(suite.exec(`INSERT INTO items (id, name) VALUES (4, 'd')`))
+	suite.ErrorIs(suite.exec(`ALTER TABLE items ADD COLUMN grp STRING`), ErrAlreadyExists)
+
+	// existing rows are not rewritten, but hold the default value
+	expected := []table.Row{
+		itemRow(1, 7, "a"),
+		itemRow(2, 7, "b"),
+		itemRow(3, 1, "c"),
+		itemRow(4, 7, "d"),
+	}
+	suite.Equal(expected, suite.selectRows(`SELECT id, grp, name FROM items`))
+	suite.commit()
+	suite.Equal(expected, suite.selectRows(`SELECT id, grp, name FROM items`))
+	suite.Equal([]table.Row{
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT id, grp, name FROM items WHERE grp = 1`))
+}
+
+func (suite *AlterSuite) TestAddColumnConstraints() {
+	suite.setupItems("")
+
+	suite.ErrorIs(suite.exec(`ALTER TABLE items ADD COLUMN grp INTEGER DEFAULT 0 CHECK (grp > 0)`), ErrCheckViolation)
+	suite.ErrorIs(suite.exec(`ALTER TABLE items ADD COLUMN other INTEGER DEFAULT 1 REFERENCES missing (id)`), ErrNoSuchTable)
+
+	suite.NoError(suite.exec(`CREATE TABLE categories (id INTEGER PRIMARY KEY)`))
+	suite.NoError(suite.exec(`INSERT INTO categories VALUES (1)`))
+	suite.ErrorIs(suite.exec(`ALTER TABLE items ADD COLUMN category INTEGER DEFAULT 2 REFERENCES categories (id)`), ErrForeignKeyViolation)
+
+	// the schema is not changed by a failed statement
+	cols, err := suite.items().Cols()
+	suite.NoError(err)
+	suite.Len(cols, 2)
+	suite.NoError(suite.exec(`ALTER TABLE items ADD COLUMN grp INTEGER DEFAULT 1 CHECK (grp > 0) REFERENCES categories (id)`))
+	suite.ErrorIs(suite.exec(`INSERT INTO items VALUES (3, 'c', 0)`), ErrCheckViolation)
+}
+
+func (suite *AlterSuite) TestRenameColumn() {
+	suite.setupItems("CHECK (name > '')")
+	suite.NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+	suite.NoError(suite.exec(`CREATE TABLE children (id INTEGER PRIMARY KEY, item INTEGER REFERENCES items (id))`))
+	suite.NoError(suite.exec(`INSERT INTO children VALUES (1, 1)`))
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME COLUMN id TO itemID`))
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME name TO label`))
+	suite.ErrorIs(suite.exec(`ALTER TABLE items RENAME COLUMN label TO itemID`), ErrAlreadyExists)
+	suite.Error(suite.exec(`ALTER TABLE items RENAME COLUMN name TO other`))
+	suite.commit()
+
+	cols, err := suite.items().Cols()
+	suite.NoError(err)
+	suite.Equal([]table.Col{
+		{QualifiedName: "itemID", Type: types.Integer},
+		{QualifiedName: "label", Type: types.String},
+	}, cols)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+	}, suite.selectRows(`SELECT * FROM items INDEXED BY items_name WHERE label = 'b'`))
+
+	suite.ErrorIs(suite.exec(`INSERT INTO items VALUES (3, '')`), ErrCheckViolation)
+	suite.ErrorIs(suite.exec(`INSERT INTO items (label) VALUES ('c')`), ErrNotNullViolation)
+	suite.ErrorIs(suite.exec(`INSERT INTO children VALUES (2, 5)`), ErrForeignKeyViolation)
+	suite.ErrorIs(suite.exec(`DELETE FROM items WHERE itemID = 1`), ErrForeignKeyViolation)
+}
+
+func (suite *AlterSuite) TestRenameTable() {
+	suite.setupItems("")
+	suite.NoError(suite.exec(`CREATE TABLE children (id INTEGER PRIMARY KEY, item INTEGER REFERENCES items (id))`))
+	suite.NoError(suite.exec(`INSERT INTO children VALUES (1, 1)`))
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME TO things`))
+	suite.ErrorIs(suite.exec(`ALTER TABLE items RENAME TO other_items`), ErrNoSuchTable)
+	suite.ErrorIs(suite.exec(`ALTER TABLE things RENAME TO children`), ErrAlreadyExists)
+	suite.NoError(suite.exec(`INSERT INTO things VALUES (3, 'c')`))
+	// the old name is free again within the transaction
+	suite.NoError(suite.exec(`CREATE TABLE items (id INTEGER)`))
+	suite.commit()
+
+	suite.Empty(suite.selectRows(`SELECT * FROM items`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.selectRows(`SELECT * FROM things`))
+	suite.ErrorIs(suite.exec(`INSERT INTO children VALUES (2, 5)`), ErrForeignKeyViolation)
+	suite.NoError(suite.exec(`INSERT INTO children VALUES (3, 3)`))
+}
+
+func (suite *AlterSuite) TestRenameReferencedTable() {
+	suite.setupItems("")
+	suite.NoError(suite.exec(`CREATE TABLE log (id INTEGER, name STRING)`))
+	suite.NoError(suite.exec(`CREATE VIEW names AS SELECT items.name FROM items WHERE id > 1`))
+	suite.NoError(suite.exec(`CREATE VIEW counts AS SELECT COUNT(*) FROM log WHERE EXISTS (SELECT * FROM items)`))
+	suite.NoError(suite.exec(`CREATE TRIGGER logInsert AFTER INSERT ON items BEGIN INSERT INTO log VALUES (NEW.id, NEW.name); END`))
+	suite.NoError(suite.exec(`CREATE TRIGGER copyLog AFTER INSERT ON log WHEN NEW.id > 5 BEGIN DELETE FROM items WHERE id = NEW.id - 5; END`))
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME TO things`))
+	suite.commit()
+
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("b")}},
+	}, suite.selectRows(`SELECT * FROM names`))
+	suite.Equal([]table.Row{intRow(0)}, suite.selectRows(`SELECT * FROM counts`))
+	suite.NoError(suite.exec(`INSERT INTO things VALUES (6, 'f')`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(6), types.NewString("f")}},
+	}, suite.selectRows(`SELECT * FROM log`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+		{Values: []types.Value{types.NewInteger(6), types.NewString("f")}},
+	}, suite.selectRows(`SELECT * FROM things`))
+}
+
+func (suite *AlterSuite) TestRenameTableShadowedInView() {
+	suite.setupItems("")
+	suite.NoError(suite.exec(`CREATE VIEW shadowed AS WITH items AS (SELECT 1) SELECT * FROM items`))
+	suite.commit()
+
+	suite.Error(suite.exec(`ALTER TABLE items RENAME TO things`))
+	ok, err := suite.ctx.tx.HasTable("items")
+	suite.NoError(err)
+	suite.True(ok)
+}
+
+func (suite *AlterSuite) TestRenameReferencedColumn() {
+	suite.setupItems("")
+	suite.NoError(suite.exec(`CREATE TABLE log (id INTEGER, name STRING)`))
+	suite.NoError(suite.exec(`CREATE VIEW names AS SELECT name, i.name AS copy FROM items AS i WHERE name > 'a'`))
+	suite.NoError(suite.exec(`CREATE VIEW logNames AS SELECT name FROM log`))
+	suite.NoError(suite.exec(`CREATE TRIGGER logUpdate AFTER UPDATE OF name ON items WHEN NEW.name > OLD.name BEGIN INSERT INTO log (id, name) VALUES (NEW.id, NEW.name); UPDATE items SET name = name WHERE id = 0; END`))
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME COLUMN name TO label`))
+	suite.commit()
+
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("b"), types.NewString("b")}},
+	}, suite.selectRows(`SELECT * FROM names`))
+	suite.Empty(suite.selectRows(`SELECT * FROM logNames`))
+	suite.NoError(suite.exec(`UPDATE items SET label = 'c' WHERE id = 1`))
+	suite.NoError(suite.exec(`UPDATE items SET label = 'a' WHERE id = 2`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("c")}},
+	}, suite.selectRows(`SELECT * FROM logNames`))
+}
+
+func (suite *AlterSuite) TestSwapTables() {
+	suite.NoError(suite.exec(`CREATE TABLE a (value INTEGER)`))
+	suite.NoError(suite.exec(`CREATE TABLE b (value INTEGER)`))
+	suite.NoError(suite.exec(`INSERT INTO a VALUES (1)`))
+	suite.NoError(suite.exec(`INSERT INTO b VALUES (2)`))
+	suite.commit()
+
+	suite.NoError(suite.exec(`ALTER TABLE a RENAME TO tmp`))
+	suite.NoError(suite.exec(`ALTER TABLE b RENAME TO a`))
+	suite.NoError(suite.exec(`ALTER TABLE tmp RENAME TO b`))
+	suite.NoError(suite.exec(`INSERT INTO b VALUES (3)`))
+	suite.commit()
+
+	suite.Equal([]table.Row{intRow(2)}, suite.selectRows(`SELECT * FROM a`))
+	suite.Equal([]table.Row{intRow(1), intRow(3)}, suite.selectRows(`SELECT * FROM b`))
+	tables, err := suite.ctx.tx.Tables()
+	suite.NoError(err)
+	suite.Equal([]string{"a", "b"}, tables)
+}
+
+func (suite *AlterSuite) TestDropColumn() {
+	for _, clauses := range []string{"", "WITHOUT ROWID"} {
+		suite.Run(clauses, func() {
+			suite.SetupTest()
+			suite.NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, tmp STRING, grp INTEGER, name STRING CHECK (name > '')) ` + clauses))
+			suite.NoError(suite.exec(`INSERT INTO items VALUES (1, 'tmp', 2, 'a'), (2, 'tmp', 1, 'b')`))
+			suite.NoError(suite.exec(`CREATE INDEX items_grp ON items (grp)`))
+			suite.commit()
+
+			suite.Error(suite.exec(`ALTER TABLE items DROP COLUMN id`))
+			suite.Error(suite.exec(`ALTER TABLE items DROP COLUMN grp`))
+			suite.Error(suite.exec(`ALTER TABLE items DROP COLUMN name`))
+			suite.Error(suite.exec(`ALTER TABLE items DROP COLUMN other`))
+			suite.NoError(suite.exec(`ALTER TABLE items DROP COLUMN tmp`))
+			suite.commit()
+
+			suite.Equal([]table.Row{
+				itemRow(1, 2, "a"),
+				itemRow(2, 1, "b"),
+			}, suite.selectRows(`SELECT * FROM items`))
+			suite.Equal([]table.Row{
+				itemRow(2, 1, "b"),
+			}, suite.selectRows(`SELECT * FROM items INDEXED BY items_grp WHERE grp = 1`))
+
+			// a column can be added again under the name of a dropped column
+			suite.NoError(suite.exec(`ALTER TABLE items ADD COLUMN tmp STRING DEFAULT 'new'`))
+			suite.Equal([]table.Row{
+				{Values: []types.Value{types.NewInteger(1), types.NewString("new")}},
+				{Values: []types.Value{types.NewInteger(2), types.NewString("new")}},
+			}, suite.selectRows(`SELECT id, tmp FROM items`))
+		})
+	}
+}
+
+func (suite *AlterSuite) TestDropOnlyColumn() {
+	suite.NoError(suite.exec(`CREATE TABLE single (value INTEGER)`))
+	suite.Error(suite.exec(`ALTER TABLE single DROP COLUMN value`))
+}
+
+// setupItems creates a table items, with the columns id and name, and with
+// the given additional clauses. The table holds the rows (1, 'a') and
+// (2, 'b').
+func (suite *AlterSuite) setupItems(clauses string) {
+	stmt := `CREATE TABLE items (id INTEGER PRIMARY KEY, name STRING`
+	if clauses != "" {
+		stmt += ", " + clauses
+	}
+	suite.Require().NoError(suite.exec(stmt + `)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 'a'), (2, 'b')`))
+}
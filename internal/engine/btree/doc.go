@@ -0,0 +1,5 @@
+// Package btree implements a B+tree on top of pages. Leaf pages hold record
+// cells, inner pages hold pointer cells, that point to the child pages. Keys are
+// compared byte-wise, so that the order of the keys is the order of the
+// encoded values.
+package btree
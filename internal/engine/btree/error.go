@@ -0,0 +1,14 @@
+package btree
+
+// Error is a sentinel error.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Sentinel errors.
+const (
+	// ErrKeyTooLarge indicates, that a key is larger than MaxKeySize.
+	ErrKeyTooLarge = Error("key too large")
+	// ErrDuplicateKey indicates, that a key is already stored in a tree.
+	ErrDuplicateKey = Error("duplicate key")
+)
@@ -0,0 +1,367 @@
+package btree
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/xqueries/xdb/internal/engine/page"
+)
+
+// MaxKeySize is the maximum size of a key in bytes. Keys are limited, so that
+// every page, that is split, holds enough cells to be split into two pages.
+const MaxKeySize = 1 << 10
+
+// Pager provides access to the pages of a tree.
+type Pager interface {
+	// Page returns the page with the given ID. Changes to the returned page
+	// are persisted by the pager.
+	Page(page.ID) (*page.Page, error)
+	// Allocate allocates a new, empty page.
+	Allocate() (*page.Page, error)
+}
+
+// Tree is a B+tree, whose cells are stored in the pages of a pager. All
+// records are stored in record cells in the leaf pages of the tree. Inner
+// pages hold a pointer cell for every child page, whose key is the smallest
+// key that can be stored in the child page, except for the first child of the
+// root, whose key is empty.
+//
+// The root of the tree is always stored in the same page, so that a tree can
+// be opened with the ID of its root page. Pages are split when they are full,
+// but are never merged, so pages may become empty when cells are deleted.
+type Tree struct {
+	pager Pager
+	root  page.ID
+}
+
+// Create creates a new, empty tree, whose root page is allocated from the given
+// pager.
+func Create(pager Pager) (*Tree, error) {
+	root, err := pager.Allocate()
+	if err != nil {
+		return nil, fmt.Errorf("allocate: %w", err)
+	}
+	return Open(pager, root.ID()), nil
+}
+
+// Open returns the tree, whose root is stored in the page with the given ID.
+func Open(pager Pager, root page.ID) *Tree {
+	return &Tree{
+		pager: pager,
+		root:  root,
+	}
+}
+
+// Root returns the ID of the root page of this tree.
+func (t *Tree) Root() page.ID {
+	return t.root
+}
+
+// Insert stores the given record with the given key in this tree. If a record
+// with the given key already exists, ErrDuplicateKey is returned.
+func (t *Tree) Insert(key, record []byte) error {
+	if len(key) > MaxKeySize {
+		return ErrKeyTooLarge
+	}
+
+	split, err := t.insert(t.root, key, record)
+	if err != nil || split == nil {
+		return err
+	}
+
+	// the root was split, but since the root must stay in the same page, the
+	// cells that remained in the root are moved to a new page, which becomes
+	// the first child of the root
+	root, err := t.pager.Page(t.root)
+	if err != nil {
+		return fmt.Errorf("page %v: %w", t.root, err)
+	}
+	left, err := t.pager.Allocate()
+	if err != nil {
+		return fmt.Errorf("allocate: %w", err)
+	}
+	cells := copyCells(root.Cells())
+	for _, cell := range cells {
+		if _, err := root.DeleteCell(keyOf(cell)); err != nil {
+			return fmt.Errorf("delete cell: %w", err)
+		}
+		if err := storeCell(left, cell); err != nil {
+			return fmt.Errorf("store cell: %w", err)
+		}
+	}
+	if err := root.StorePointerCell(page.PointerCell{Key: []byte{}, Pointer: left.ID()}); err != nil {
+		return fmt.Errorf("store pointer cell: %w", err)
+	}
+	if err := root.StorePointerCell(*split); err != nil {
+		return fmt.Errorf("store pointer cell: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the record with the given key from this tree. If no such
+// record exists, false is returned.
+func (t *Tree) Delete(key []byte) (bool, error) {
+	leaf, err := t.leaf(key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := leaf.DeleteCell(key)
+	if err != nil {
+		return false, fmt.Errorf("delete cell: %w", err)
+	}
+	return ok, nil
+}
+
+// Get returns the record with the given key, or false, if no such record
+// exists.
+func (t *Tree) Get(key []byte) ([]byte, bool, error) {
+	leaf, err := t.leaf(key)
+	if err != nil {
+		return nil, false, err
+	}
+	cell, ok := leaf.Cell(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return copyCell(cell).(page.RecordCell).Record, true, nil
+}
+
+// Scan calls the given function with all records of this tree, whose key is
+// greater than or equal to from and less than to, in the order of their keys.
+// If to is nil, all records from the given key on are scanned. The scan stops
+// if the function returns false or an error. The tree must not be modified
+// by the function.
+func (t *Tree) Scan(from, to []byte, fn func(key, record []byte) (bool, error)) error {
+	_, err := t.scan(t.root, from, to, fn)
+	return err
+}
+
+// ScanPrefix calls the given function with all records of this tree, whose key
+// starts with the given prefix, in the order of their keys, see Scan.
+func (t *Tree) ScanPrefix(prefix []byte, fn func(key, record []byte) (bool, error)) error {
+	return t.Scan(prefix, PrefixEnd(prefix), fn)
+}
+
+func (t *Tree) insert(id page.ID, key, record []byte) (*page.PointerCell, error) {
+	p, err := t.pager.Page(id)
+	if err != nil {
+		return nil, fmt.Errorf("page %v: %w", id, err)
+	}
+
+	cells := p.Cells()
+	if isLeaf(cells) {
+		if _, ok := p.Cell(key); ok {
+			return nil, ErrDuplicateKey
+		}
+		return t.store(p, page.RecordCell{Key: key, Record: record})
+	}
+
+	split, err := t.insert(cells[childIndex(cells, key)].(page.PointerCell).Pointer, key, record)
+	if err != nil || split == nil {
+		return nil, err
+	}
+	return t.store(p, *split)
+}
+
+// store stores the given cell in the given page. If the page is full, the page
+// is split into two pages, and a pointer cell to the new right sibling of the
+// page is returned, which must be stored in the parent of the page.
+func (t *Tree) store(p *page.Page, cell page.CellTyper) (*page.PointerCell, error) {
+	if err := storeCell(p, cell); err != page.ErrPageFull {
+		return nil, err
+	}
+
+	cells := copyCells(p.Cells())
+	index := sort.Search(len(cells), func(i int) bool {
+		return bytes.Compare(keyOf(cells[i]), keyOf(cell)) >= 0
+	})
+	cells = append(cells[:index], append([]page.CellTyper{cell}, cells[index:]...)...)
+	mid := splitIndex(cells)
+
+	right, err := t.pager.Allocate()
+	if err != nil {
+		return nil, fmt.Errorf("allocate: %w", err)
+	}
+	for _, moved := range cells[mid:] {
+		// the new cell is not stored in the page, so it is not found
+		if _, err := p.DeleteCell(keyOf(moved)); err != nil {
+			return nil, fmt.Errorf("delete cell: %w", err)
+		}
+		if err := storeCell(right, moved); err != nil {
+			return nil, fmt.Errorf("store cell: %w", err)
+		}
+	}
+	if index < mid {
+		if err := storeCell(p, cell); err != nil {
+			return nil, fmt.Errorf("store cell: %w", err)
+		}
+	}
+	return &page.PointerCell{
+		Key:     keyOf(cells[mid]),
+		Pointer: right.ID(),
+	}, nil
+}
+
+// leaf returns the leaf page, in which a record with the given key is stored.
+func (t *Tree) leaf(key []byte) (*page.Page, error) {
+	id := t.root
+	for {
+		p, err := t.pager.Page(id)
+		if err != nil {
+			return nil, fmt.Errorf("page %v: %w", id, err)
+		}
+		cells := p.Cells()
+		if isLeaf(cells) {
+			return p, nil
+		}
+		id = cells[childIndex(cells, key)].(page.PointerCell).Pointer
+	}
+}
+
+func (t *Tree) scan(id page.ID, from, to []byte, fn func(key, record []byte) (bool, error)) (bool, error) {
+	p, err := t.pager.Page(id)
+	if err != nil {
+		return false, fmt.Errorf("page %v: %w", id, err)
+	}
+
+	cells := p.Cells()
+	if isLeaf(cells) {
+		for _, cell := range copyCells(cells) {
+			rec := cell.(page.RecordCell)
+			if bytes.Compare(rec.Key, from) < 0 {
+				continue
+			}
+			if to != nil && bytes.Compare(rec.Key, to) >= 0 {
+				return false, nil
+			}
+			if cont, err := fn(rec.Key, rec.Record); err != nil || !cont {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	for i := childIndex(cells, from); i < len(cells); i++ {
+		pointer := cells[i].(page.PointerCell)
+		if to != nil && bytes.Compare(pointer.Key, to) >= 0 {
+			return false, nil
+		}
+		if cont, err := t.scan(pointer.Pointer, from, to, fn); err != nil || !cont {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isLeaf returns whether a page with the given cells is a leaf page. Empty
+// pages are leaf pages, since inner pages always point to at least one child.
+func isLeaf(cells []page.CellTyper) bool {
+	if len(cells) == 0 {
+		return true
+	}
+	_, ok := cells[0].(page.RecordCell)
+	return ok
+}
+
+// childIndex returns the index of the pointer cell of the given cells of an
+// inner page, that points to the child, in which the given key is stored.
+func childIndex(cells []page.CellTyper, key []byte) int {
+	index := sort.Search(len(cells), func(i int) bool {
+		return bytes.Compare(keyOf(cells[i]), key) > 0
+	}) - 1
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+// splitIndex returns the index, at which the given cells are split into two
+// pages of about the same size. Both pages hold at least one cell.
+func splitIndex(cells []page.CellTyper) int {
+	total := 0
+	for _, cell := range cells {
+		total += cellSize(cell)
+	}
+	size := 0
+	for i, cell := range cells {
+		size += cellSize(cell)
+		if size >= total/2 {
+			if i+1 == len(cells) {
+				return i
+			}
+			return i + 1
+		}
+	}
+	return len(cells) / 2
+}
+
+// PrefixEnd returns the smallest key, that is greater than all keys with the
+// given prefix, or nil, if there is no such key.
+func PrefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+func cellSize(cell page.CellTyper) int {
+	switch cell := cell.(type) {
+	case page.RecordCell:
+		return len(cell.Key) + len(cell.Record)
+	case page.PointerCell:
+		return len(cell.Key) + int(page.IDSize)
+	}
+	return 0
+}
+
+func keyOf(cell page.CellTyper) []byte {
+	switch cell := cell.(type) {
+	case page.RecordCell:
+		return cell.Key
+	case page.PointerCell:
+		return cell.Key
+	}
+	return nil
+}
+
+func storeCell(p *page.Page, cell page.CellTyper) error {
+	switch cell := cell.(type) {
+	case page.RecordCell:
+		return p.StoreRecordCell(cell)
+	case page.PointerCell:
+		return p.StorePointerCell(cell)
+	}
+	return fmt.Errorf("unknown cell type %T", cell)
+}
+
+// copyCells copies the given cells, so that they don't point into the data of
+// the page, that they were decoded from, anymore.
+func copyCells(cells []page.CellTyper) []page.CellTyper {
+	copied := make([]page.CellTyper, len(cells))
+	for i, cell := range cells {
+		copied[i] = copyCell(cell)
+	}
+	return copied
+}
+
+func copyCell(cell page.CellTyper) page.CellTyper {
+	switch cell := cell.(type) {
+	case page.RecordCell:
+		return page.RecordCell{
+			Key:    append([]byte{}, cell.Key...),
+			Record: append([]byte{}, cell.Record...),
+		}
+	case page.PointerCell:
+		return page.PointerCell{
+			Key:     append([]byte{}, cell.Key...),
+			Pointer: cell.Pointer,
+		}
+	}
+	return cell
+}
@@ -0,0 +1,183 @@
+package btree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/page"
+)
+
+func TestTreeSuite(t *testing.T) {
+	suite.Run(t, new(TreeSuite))
+}
+
+// TreeSuite is a test suite that holds an empty tree for use of every test,
+// whose pages are kept in memory.
+type TreeSuite struct {
+	suite.Suite
+
+	pager *memoryPager
+	tree  *Tree
+}
+
+func (suite *TreeSuite) SetupTest() {
+	suite.pager = &memoryPager{
+		pages: make(map[page.ID]*page.Page),
+	}
+	tree, err := Create(suite.pager)
+	suite.Require().NoError(err)
+	suite.tree = tree
+}
+
+func (suite *TreeSuite) TestInsertGet() {
+	n := 5000
+	for _, i := range rand.New(rand.NewSource(0)).Perm(n) {
+		suite.Require().NoError(suite.tree.Insert(key(i), record(i)))
+	}
+	// the records don't fit into a single page, so the tree must have been
+	// split several times
+	suite.Greater(len(suite.pager.pages), 100)
+
+	for i := 0; i < n; i++ {
+		rec, ok, err := suite.tree.Get(key(i))
+		suite.NoError(err)
+		suite.True(ok)
+		suite.Equal(record(i), rec)
+	}
+	_, ok, err := suite.tree.Get(key(n))
+	suite.NoError(err)
+	suite.False(ok)
+
+	suite.Equal(ErrDuplicateKey, suite.tree.Insert(key(10), record(10)))
+
+	// the tree can be opened with its root
+	rec, ok, err := Open(suite.pager, suite.tree.Root()).Get(key(42))
+	suite.NoError(err)
+	suite.True(ok)
+	suite.Equal(record(42), rec)
+}
+
+func (suite *TreeSuite) TestScan() {
+	n := 3000
+	for _, i := range rand.New(rand.NewSource(1)).Perm(n) {
+		suite.Require().NoError(suite.tree.Insert(key(i), record(i)))
+	}
+
+	suite.Equal(keys(0, n), suite.scan(nil, nil))
+	suite.Equal(keys(1000, 2500), suite.scan(key(1000), key(2500)))
+	suite.Equal(keys(2999, n), suite.scan(key(2999), nil))
+	suite.Empty(suite.scan(key(n), nil))
+	suite.Empty(suite.scan(key(10), key(10)))
+
+	// the scan stops when the function returns false
+	var scanned int
+	suite.NoError(suite.tree.Scan(nil, nil, func(key, record []byte) (bool, error) {
+		scanned++
+		return scanned < 10, nil
+	}))
+	suite.Equal(10, scanned)
+}
+
+func (suite *TreeSuite) TestScanPrefix() {
+	for _, k := range []string{"a", "ab", "abc", "abd", "b", "\xff", "\xff\xff", "\xff\xffa"} {
+		suite.Require().NoError(suite.tree.Insert([]byte(k), nil))
+	}
+
+	var scanned []string
+	suite.NoError(suite.tree.ScanPrefix([]byte("ab"), func(key, record []byte) (bool, error) {
+		scanned = append(scanned, string(key))
+		return true, nil
+	}))
+	suite.Equal([]string{"ab", "abc", "abd"}, scanned)
+
+	scanned = nil
+	suite.NoError(suite.tree.ScanPrefix([]byte("\xff\xff"), func(key, record []byte) (bool, error) {
+		scanned = append(scanned, string(key))
+		return true, nil
+	}))
+	suite.Equal([]string{"\xff\xff", "\xff\xffa"}, scanned)
+}
+
+func (suite *TreeSuite) TestDelete() {
+	n := 3000
+	for i := 0; i < n; i++ {
+		suite.Require().NoError(suite.tree.Insert(key(i), record(i)))
+	}
+	for i := 0; i < n; i += 2 {
+		ok, err := suite.tree.Delete(key(i))
+		suite.NoError(err)
+		suite.True(ok)
+	}
+	ok, err := suite.tree.Delete(key(0))
+	suite.NoError(err)
+	suite.False(ok)
+
+	var expected [][]byte
+	for i := 1; i < n; i += 2 {
+		expected = append(expected, key(i))
+	}
+	suite.Equal(expected, suite.scan(nil, nil))
+
+	// deleted keys can be inserted again
+	suite.NoError(suite.tree.Insert(key(0), record(0)))
+	rec, ok, err := suite.tree.Get(key(0))
+	suite.NoError(err)
+	suite.True(ok)
+	suite.Equal(record(0), rec)
+}
+
+func (suite *TreeSuite) TestKeyTooLarge() {
+	suite.Equal(ErrKeyTooLarge, suite.tree.Insert(make([]byte, MaxKeySize+1), nil))
+	suite.NoError(suite.tree.Insert(make([]byte, MaxKeySize), nil))
+}
+
+func (suite *TreeSuite) scan(from, to []byte) [][]byte {
+	var scanned [][]byte
+	suite.NoError(suite.tree.Scan(from, to, func(key, record []byte) (bool, error) {
+		scanned = append(scanned, key)
+		return true, nil
+	}))
+	return scanned
+}
+
+// key returns a key, that is large enough, so that pages are split after a
+// few cells.
+func key(i int) []byte {
+	k := make([]byte, 200)
+	binary.BigEndian.PutUint32(k, uint32(i))
+	return k
+}
+
+func keys(from, to int) [][]byte {
+	var result [][]byte
+	for i := from; i < to; i++ {
+		result = append(result, key(i))
+	}
+	return result
+}
+
+func record(i int) []byte {
+	rec := make([]byte, 8)
+	binary.BigEndian.PutUint64(rec, uint64(i))
+	return rec
+}
+
+type memoryPager struct {
+	pages map[page.ID]*page.Page
+}
+
+func (p *memoryPager) Page(id page.ID) (*page.Page, error) {
+	return p.pages[id], nil
+}
+
+func (p *memoryPager) Allocate() (*page.Page, error) {
+	allocated, err := page.New(page.ID(len(p.pages)))
+	if err != nil {
+		return nil, err
+	}
+	p.pages[allocated.ID()] = allocated
+	return allocated, nil
+}
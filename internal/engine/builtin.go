@@ -1,8 +1,8 @@
 // This file contains implementations for builtin functions, such as RAND() or
 // NOW(). The arguments for the herein implemented functions differ from those
-// that are required in the SQL statement. For example, COUNT(x) takes only one
-// argument, but builtinCount requires many values. The engine is responsible to
-// interpret COUNT(x), and instead of the single value 'x', pass in all values
+// that are required in the SQL statement. For example, MAX(x) takes only one
+// argument, but builtinMax requires many values. The engine is responsible to
+// interpret MAX(x), and instead of the single value 'x', pass in all values
 // in the column 'x'. How SQL arguments are to be interpreted, depends on the
 // SQL function. The builtin functions in this file don't access the result
 // table, but instead rely on the engine to pass in the correct values.
@@ -30,14 +30,6 @@ func (e Engine) builtinRand(rp randomProvider) (types.IntegerValue, error) {
 	return types.NewInteger(rp()), nil
 }
 
-// builtinCount returns a new integral value, representing the count of the
-// passed in values.
-func (e Engine) builtinCount(args ...types.Value) (types.IntegerValue, error) {
-	defer e.profiler.Enter("count").Exit()
-
-	return types.NewInteger(int64(len(args))), nil
-}
-
 // builtinUCase maps all passed in string values to new string values with the
 // internal string value folded to upper case.
 func (e Engine) builtinUCase(args ...types.StringValue) ([]types.StringValue, error) {
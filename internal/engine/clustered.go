@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/page"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/transaction"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+var _ btree.Pager = (*dataPager)(nil)
+
+// dataPager provides access to the data pages of a table within a
+// transaction.
+type dataPager struct {
+	tx    *transaction.TX
+	table string
+}
+
+func (p dataPager) Page(id page.ID) (*page.Page, error) {
+	return p.tx.DataPage(p.table, id)
+}
+
+func (p dataPager) Allocate() (*page.Page, error) {
+	return p.tx.AllocateNewDataPage(p.table)
+}
+
+// clusteredTree returns the B+tree over the data pages of this table, that
+// holds the rows of this table, or false, if this table is not clustered by
+// its primary key. The key of a record in the tree is the encoded primary key
+// of its row, see (*Table).primaryKey.
+func (t *Table) clusteredTree() (*btree.Tree, bool, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, false, fmt.Errorf("schema file: %w", err)
+	}
+	if !sf.WithoutRowID {
+		return nil, false, nil
+	}
+	return btree.Open(dataPager{t.tx, t.name}, sf.Root), true, nil
+}
+
+// primaryKey returns the values of the primary key of the given row, encoded
+// with encodeIndexKey, so that the order of the keys is the order of the
+// primary key.
+func (t *Table) primaryKey(row table.Row) ([]byte, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	cols, err := columnIndices(sf.Columns, sf.PrimaryKey)
+	if err != nil {
+		return nil, fmt.Errorf("primary key: %w", err)
+	}
+	values := make([]types.Value, len(cols))
+	for i, col := range cols {
+		values[i] = row.Values[col]
+	}
+	return encodeIndexKey(values)
+}
+
+// storeClusteredRecord stores the given row in the given tree of this table,
+// and returns the record that holds the row. If the tree already holds a row
+// with the same primary key, an error is returned.
+func (t *Table) storeClusteredRecord(tree *btree.Tree, row table.Row) (record, error) {
+	key, err := t.primaryKey(row)
+	if err != nil {
+		return record{}, err
+	}
+	serializedRow, err := serializeRow(row)
+	if err != nil {
+		return record{}, fmt.Errorf("serialize row: %w", err)
+	}
+	if err := tree.Insert(key, serializedRow); err == btree.ErrDuplicateKey {
+		sf, err := t.tx.SchemaFile(t.name)
+		if err != nil {
+			return record{}, fmt.Errorf("schema file: %w", err)
+		}
+		cols, _ := columnIndices(sf.Columns, sf.PrimaryKey)
+		values, _ := keyValues(cols, row)
+		return record{}, fmt.Errorf("%v(%v)=(%v): %w", t.name, strings.Join(sf.PrimaryKey, ","), joinValues(values), ErrUniqueViolation)
+	} else if err != nil {
+		return record{}, fmt.Errorf("insert: %w", err)
+	}
+	return record{key: key, row: row}, nil
+}
+
+// recordData returns the serialized row of the record with the given key. If
+// this table is clustered by its primary key, the record is looked up in the
+// tree of this table. Otherwise, it is read from the data page with the given
+// ID.
+func (t *Table) recordData(id page.ID, key []byte) ([]byte, error) {
+	tree, clustered, err := t.clusteredTree()
+	if err != nil {
+		return nil, err
+	}
+	if clustered {
+		data, ok, err := tree.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("get: %w", err)
+		} else if !ok {
+			return nil, fmt.Errorf("no record with key %x", key)
+		}
+		return data, nil
+	}
+
+	p, err := t.tx.DataPage(t.name, id)
+	if err != nil {
+		return nil, fmt.Errorf("data page: %w", err)
+	}
+	cell, ok := p.Cell(key)
+	if !ok {
+		return nil, fmt.Errorf("no record with key %x in page %v", key, id)
+	}
+	return cell.(page.RecordCell).Record, nil
+}
+
+// scanClustered returns the records of all rows in the given tree of this
+// table, whose primary key is in the given range, in the order of the primary
+// key.
+func (t *Table) scanClustered(tree *btree.Tree, r indexRange) ([]record, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	from, to, ok, err := r.keys()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var records []record
+	if err := tree.Scan(from, to, func(key, data []byte) (bool, error) {
+		row, err := deserializeRow(sf.Columns, sf.Defaults, data)
+		if err != nil {
+			return false, fmt.Errorf("deserialize: %w", err)
+		}
+		records = append(records, record{key: key, row: row})
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	return records, nil
+}
+
+// nextClusteredRecords returns at most the given amount of records in the
+// given tree of this table, whose keys follow the given key, in the order of
+// their keys. If the given key is nil, the records from the first record on
+// are returned. Since the tree is searched again for every call, the table may
+// be modified between two calls.
+func (t *Table) nextClusteredRecords(tree *btree.Tree, key []byte, n int) ([]record, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+
+	var from []byte
+	if key != nil {
+		// the smallest key, that is greater than the given key
+		from = append(key[:len(key):len(key)], 0x00)
+	}
+
+	records := make([]record, 0, n)
+	if err := tree.Scan(from, nil, func(key, data []byte) (bool, error) {
+		row, err := deserializeRow(sf.Columns, sf.Defaults, data)
+		if err != nil {
+			return false, fmt.Errorf("deserialize: %w", err)
+		}
+		records = append(records, record{key: key, row: row})
+		return len(records) < n, nil
+	}); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	return records, nil
+}
+
+// countClusteredRecords returns the amount of records in the given tree.
+func countClusteredRecords(tree *btree.Tree) (int64, error) {
+	var count int64
+	if err := tree.Scan(nil, nil, func(_, _ []byte) (bool, error) {
+		count++
+		return true, nil
+	}); err != nil {
+		return 0, fmt.Errorf("scan: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestClusteredSuite(t *testing.T) {
+	suite.Run(t, new(ClusteredSuite))
+}
+
+type ClusteredSuite struct {
+	EngineSuite
+}
+
+func (suite *ClusteredSuite) TestOrder() {
+	suite.NoError(suite.exec(`CREATE TABLE pairs (a INTEGER, b STRING, PRIMARY KEY (b, a)) WITHOUT ROWID`))
+	suite.NoError(suite.exec(`INSERT INTO pairs VALUES (2, 'b'), (1, 'c'), (3, 'a'), (1, 'b')`))
+
+	tbl, err := suite.evaluateStatement(`SELECT a FROM pairs`)
+	suite.Require().NoError(err)
+	suite.Equal([]table.Row{
+		intRow(3),
+		intRow(1),
+		intRow(2),
+		intRow(1),
+	}, suite.rows(tbl))
+}
+
+func (suite *ClusteredSuite) TestDuplicateKey() {
+	suite.createItems(3, true)
+
+	err := suite.exec(`INSERT INTO items VALUES (1, 0, 'other')`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.NoError(suite.exec(`INSERT OR REPLACE INTO items VALUES (1, 0, 'other')`))
+	suite.Equal([]table.Row{
+		itemRow(0, 0, "item0"),
+		itemRow(1, 0, "other"),
+		itemRow(2, 2, "item2"),
+	}, suite.selectRows(`SELECT * FROM items`))
+
+	// the primary key is never NULL
+	suite.Error(suite.exec(`INSERT INTO items (grp, name) VALUES (0, 'none')`))
+}
+
+func (suite *ClusteredSuite) TestUpdatePrimaryKey() {
+	suite.createItems(5, true)
+	suite.NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+
+	// every new key is the old key of the row before
+	suite.NoError(suite.exec(`DELETE FROM items WHERE id = 0`))
+	suite.NoError(suite.exec(`UPDATE items SET id = id - 1`))
+	suite.NoError(suite.exec(`UPDATE items SET id = 10 WHERE id = 0`))
+	suite.Equal([]table.Row{
+		itemRow(1, 2, "item2"),
+		itemRow(2, 0, "item3"),
+		itemRow(3, 1, "item4"),
+		itemRow(10, 1, "item1"),
+	}, suite.selectRows(`SELECT * FROM items`))
+	suite.Equal([]table.Row{
+		itemRow(10, 1, "item1"),
+	}, suite.selectRows(`SELECT * FROM items INDEXED BY items_name WHERE name = 'item1'`))
+
+	suite.ErrorIs(suite.exec(`UPDATE items SET id = 1 WHERE id = 10`), ErrUniqueViolation)
+}
+
+func (suite *ClusteredSuite) TestAccessPath() {
+	suite.createItems(10, true)
+	suite.NoError(suite.exec(`CREATE INDEX items_grp ON items (grp)`))
+
+	path, err := suite.engine.chooseAccessPath(suite.ctx, suite.items(), command.SimpleTable{Table: "items"}, command.EqualityExpr{BinaryBase: command.BinaryBase{
+		Left:  command.ColumnReference{Name: "id"},
+		Right: command.ConstantLiteral{Value: "3", Numeric: true},
+	}})
+	suite.Require().NoError(err)
+	suite.True(path.primary)
+	suite.Nil(path.index)
+	suite.Equal(indexRange{prefix: []types.Value{types.NewInteger(3)}}, path.rng)
+
+	suite.Equal([]table.Row{
+		itemRow(3, 0, "item3"),
+		itemRow(4, 1, "item4"),
+		itemRow(5, 2, "item5"),
+	}, suite.selectRows(`SELECT * FROM items WHERE id BETWEEN 3 AND 5`))
+	suite.Equal([]table.Row{
+		itemRow(8, 2, "item8"),
+		itemRow(9, 0, "item9"),
+	}, suite.selectRows(`SELECT * FROM items WHERE id > 7`))
+	suite.Equal([]table.Row{
+		itemRow(2, 2, "item2"),
+		itemRow(5, 2, "item5"),
+		itemRow(8, 2, "item8"),
+	}, suite.selectRows(`SELECT * FROM items WHERE grp = 2`))
+}
+
+func (suite *ClusteredSuite) TestManyRows() {
+	// the rows don't fit into a single data page, and are inserted in
+	// descending order
+	n := 2000
+	suite.NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING) WITHOUT ROWID`))
+	for i := n - 1; i >= 0; i-- {
+		suite.Require().NoError(suite.exec(fmt.Sprintf(`INSERT INTO items VALUES (%d, %d, 'item%d')`, i, i%3, i)))
+	}
+	suite.NoError(suite.exec(`DELETE FROM items WHERE grp = 1`))
+
+	var expected []table.Row
+	for i := 0; i < n; i++ {
+		if i%3 != 1 {
+			expected = append(expected, itemRow(int64(i), int64(i%3), fmt.Sprintf("item%d", i)))
+		}
+	}
+	suite.Equal(expected, suite.selectRows(`SELECT * FROM items`))
+
+	count, err := suite.engine.tableRowCount(suite.ctx, command.SimpleTable{Table: "items"})
+	suite.NoError(err)
+	suite.EqualValues(len(expected), count)
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// coerceValue converts the given value to the given type, so that it can be
+// stored in a column of that type. NULL values become NULL values of the given
+// type, integers are converted to reals, and reals without a fractional part
+// are converted to integers. Strings are converted to integers or reals, if
+// they hold a numeric literal. Other values are only converted, if the given
+// type can cast them.
+func (e Engine) coerceValue(val types.Value, typ types.Type) (types.Value, error) {
+	if val.IsNull() {
+		return types.NewNull(typ), nil
+	}
+	if val.Is(typ) {
+		return val, nil
+	}
+	switch {
+	case typ == types.Real && val.Is(types.Integer):
+		return types.NewReal(float64(val.(types.IntegerValue).Value)), nil
+	case typ == types.Integer && val.Is(types.Real):
+		real := val.(types.RealValue).Value
+		if real != math.Trunc(real) || real < math.MinInt64 || real >= math.MaxInt64 {
+			return nil, types.ErrCannotCast(val.Type(), typ)
+		}
+		return types.NewInteger(int64(real)), nil
+	case (typ == types.Integer || typ == types.Real) && val.Is(types.String):
+		numeric, ok := ToNumericValue(val.(types.StringValue).Value)
+		if !ok {
+			return nil, types.ErrCannotCast(val.Type(), typ)
+		}
+		return e.coerceValue(numeric, typ)
+	}
+	if caster, ok := typ.(types.Caster); ok {
+		casted, err := caster.Cast(val)
+		if err != nil {
+			return nil, err
+		}
+		return casted, nil
+	}
+	return nil, types.ErrCannotCast(val.Type(), typ)
+}
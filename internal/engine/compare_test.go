@@ -38,6 +38,24 @@ func TestEngine_cmp(t *testing.T) {
 			types.NewBool(false),
 			cmpEqual,
 		},
+		{
+			"1 <-> 1",
+			types.NewInteger(1),
+			types.NewInteger(1),
+			cmpEqual,
+		},
+		{
+			"2 <-> 1",
+			types.NewInteger(2),
+			types.NewInteger(1),
+			cmpGreaterThan,
+		},
+		{
+			"1 <-> 2",
+			types.NewInteger(1),
+			types.NewInteger(2),
+			cmpLessThan,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
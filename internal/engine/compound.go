@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// evaluateCompound evaluates the given compound. UNION ALL has bag semantics,
+// meaning that all rows of the left list are followed by all rows of the right
+// list. All other operators have set semantics, meaning that duplicate rows
+// are removed from the result. The columns of the compound are the columns of
+// the left list. Both lists must have the same amount of columns, and the
+// types of the columns at the same position must be equal.
+func (e Engine) evaluateCompound(ctx ExecutionContext, compound command.Compound) (table.Table, error) {
+	defer e.profiler.Enter("compound").Exit()
+
+	left, err := e.evaluateList(ctx, compound.Left)
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	right, err := e.evaluateList(ctx, compound.Right)
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+
+	leftCols, err := left.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("left cols: %w", err)
+	}
+	rightCols, err := right.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("right cols: %w", err)
+	}
+	if len(leftCols) != len(rightCols) {
+		return nil, fmt.Errorf("compound lists have %d and %d columns", len(leftCols), len(rightCols))
+	}
+	for i := range leftCols {
+		if leftCols[i].Type != rightCols[i].Type {
+			return nil, fmt.Errorf("column %d: incompatible types %v and %v", i+1, leftCols[i].Type, rightCols[i].Type)
+		}
+	}
+
+	tbl := compoundTable{
+		operator: compound.Operator,
+		left:     left,
+		right:    right,
+		cols:     leftCols,
+		e:        e,
+	}
+
+	switch compound.Operator {
+	case command.CompoundUnionAll:
+		return tbl, nil
+	case command.CompoundUnion, command.CompoundIntersect, command.CompoundExcept:
+		return distinctTable{
+			origin: tbl,
+			cols:   leftCols,
+			e:      e,
+		}, nil
+	}
+	return nil, ErrUnimplemented(compound.Operator)
+}
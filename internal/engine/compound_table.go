@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// compoundTable is a table, which combines the rows of a left and a right
+// table. For unions, the rows of the left table are followed by the rows of
+// the right table. For intersections and exceptions, the rows of the left
+// table, that are (or are not) contained in the right table are returned.
+// Duplicate rows are not removed by this table.
+type compoundTable struct {
+	operator command.CompoundOperator
+	left     table.Table
+	right    table.Table
+	cols     []table.Col
+	e        Engine
+}
+
+// Cols returns the columns of the left table.
+func (t compoundTable) Cols() ([]table.Col, error) {
+	return t.cols, nil
+}
+
+// Rows returns a row iterator of the compound table. For intersections and
+// exceptions, all rows of the right table are read into memory when the first
+// row is requested from the iterator.
+func (t compoundTable) Rows() (table.RowIterator, error) {
+	return t.createIterator()
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+type compoundTableIterator struct {
+	table compoundTable
+	left  table.RowIterator
+	right table.RowIterator
+
+	// leftDone indicates, that all rows of the left iterator of a union were
+	// returned.
+	leftDone bool
+	// rightRows are the rows of the right table of an intersection or
+	// exception, bucketed by their hash, or nil, if the rows were not read
+	// yet.
+	rightRows map[uint64][][]types.Value
+}
+
+func (t compoundTable) createIterator() (*compoundTableIterator, error) {
+	left, err := t.left.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	right, err := t.right.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+	return &compoundTableIterator{
+		table: t,
+		left:  left,
+		right: right,
+	}, nil
+}
+
+// Next returns the next row of the compound table.
+func (i *compoundTableIterator) Next() (table.Row, error) {
+	switch i.table.operator {
+	case command.CompoundUnion, command.CompoundUnionAll:
+		return i.nextUnion()
+	case command.CompoundIntersect:
+		return i.nextMember(true)
+	case command.CompoundExcept:
+		return i.nextMember(false)
+	}
+	return table.Row{}, ErrUnimplemented(i.table.operator)
+}
+
+// Reset resets both underlying iterators.
+func (i *compoundTableIterator) Reset() error {
+	i.leftDone = false
+	i.rightRows = nil
+	if err := i.left.Reset(); err != nil {
+		return err
+	}
+	return i.right.Reset()
+}
+
+// Close closes both underlying iterators.
+func (i *compoundTableIterator) Close() error {
+	leftErr := i.left.Close()
+	rightErr := i.right.Close()
+	if leftErr != nil {
+		return leftErr
+	}
+	return rightErr
+}
+
+func (i *compoundTableIterator) nextUnion() (table.Row, error) {
+	if !i.leftDone {
+		next, err := i.left.Next()
+		if err != table.ErrEOT {
+			return next, err
+		}
+		i.leftDone = true
+	}
+	return i.right.Next()
+}
+
+// nextMember returns the next row of the left iterator, that is contained in
+// the right table if member is true, or that is not contained in the right
+// table if member is false.
+func (i *compoundTableIterator) nextMember(member bool) (table.Row, error) {
+	if i.rightRows == nil {
+		if err := i.readRight(); err != nil {
+			return table.Row{}, fmt.Errorf("right: %w", err)
+		}
+	}
+
+	for {
+		next, err := i.left.Next()
+		if err != nil {
+			return table.Row{}, err
+		}
+		if i.containsRight(next.Values) == member {
+			return next, nil
+		}
+	}
+}
+
+func (i *compoundTableIterator) readRight() error {
+	i.rightRows = make(map[uint64][][]types.Value)
+	for {
+		next, err := i.right.Next()
+		if err == table.ErrEOT {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		hash := hashValues(0, next.Values)
+		i.rightRows[hash] = append(i.rightRows[hash], next.Values)
+	}
+}
+
+func (i *compoundTableIterator) containsRight(vals []types.Value) bool {
+	for _, candidate := range i.rightRows[hashValues(0, vals)] {
+		if i.table.e.valuesEqual(candidate, vals) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestCompoundSuite(t *testing.T) {
+	suite.Run(t, new(CompoundSuite))
+}
+
+type CompoundSuite struct {
+	EngineSuite
+}
+
+func (suite *CompoundSuite) compound(operator command.CompoundOperator) []table.Row {
+	suite.RunScript(`
+CREATE TABLE tableA (name STRING);
+CREATE TABLE tableB (name STRING);
+INSERT INTO tableA VALUES ("a"), ("b"), ("a"), ("c");
+INSERT INTO tableB VALUES ("b"), ("d"), ("b")`)
+
+	result, err := suite.engine.evaluateCompound(suite.ctx, command.Compound{
+		Operator: operator,
+		Left:     command.Scan{Table: command.SimpleTable{Table: "tableA"}},
+		Right:    command.Scan{Table: command.SimpleTable{Table: "tableB"}},
+	})
+	suite.Require().NoError(err)
+	return suite.rows(result)
+}
+
+func (suite *CompoundSuite) TestUnionAll() {
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a")}},
+		{Values: []types.Value{types.NewString("b")}},
+		{Values: []types.Value{types.NewString("a")}},
+		{Values: []types.Value{types.NewString("c")}},
+		{Values: []types.Value{types.NewString("b")}},
+		{Values: []types.Value{types.NewString("d")}},
+		{Values: []types.Value{types.NewString("b")}},
+	}, suite.compound(command.CompoundUnionAll))
+}
+
+func (suite *CompoundSuite) TestUnion() {
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a")}},
+		{Values: []types.Value{types.NewString("b")}},
+		{Values: []types.Value{types.NewString("c")}},
+		{Values: []types.Value{types.NewString("d")}},
+	}, suite.compound(command.CompoundUnion))
+}
+
+func (suite *CompoundSuite) TestIntersect() {
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("b")}},
+	}, suite.compound(command.CompoundIntersect))
+}
+
+func (suite *CompoundSuite) TestExcept() {
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a")}},
+		{Values: []types.Value{types.NewString("c")}},
+	}, suite.compound(command.CompoundExcept))
+}
+
+func (suite *CompoundSuite) TestIncompatibleColumns() {
+	suite.RunScript(`
+CREATE TABLE tableA (name STRING);
+CREATE TABLE tableB (id INTEGER, name STRING);
+CREATE TABLE tableC (id INTEGER)`)
+
+	_, err := suite.engine.evaluateCompound(suite.ctx, command.Compound{
+		Operator: command.CompoundUnion,
+		Left:     command.Scan{Table: command.SimpleTable{Table: "tableA"}},
+		Right:    command.Scan{Table: command.SimpleTable{Table: "tableB"}},
+	})
+	suite.Error(err)
+
+	_, err = suite.engine.evaluateCompound(suite.ctx, command.Compound{
+		Operator: command.CompoundUnion,
+		Left:     command.Scan{Table: command.SimpleTable{Table: "tableA"}},
+		Right:    command.Scan{Table: command.SimpleTable{Table: "tableC"}},
+	})
+	suite.Error(err)
+}
@@ -0,0 +1,381 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+var _ Inserter = (*conflictResolver)(nil)
+
+// excludedTable is the name of the table, through which the update expressions
+// of an upsert reference the row, that was meant to be inserted.
+const excludedTable = "excluded"
+
+// tableKeys returns the keys of the table with the given schema, see
+// (*dbfs.SchemaFile).Keys. Every key is a list of indices of the columns of
+// the key. The primary key, if any, is the first key.
+func tableKeys(sf *dbfs.SchemaFile) ([][]int, error) {
+	names := sf.Keys()
+
+	keys := make([][]int, len(names))
+	for i, key := range names {
+		keys[i] = make([]int, len(key))
+		for j, name := range key {
+			index := columnIndex(sf.Columns, name)
+			if index == -1 {
+				return nil, ErrNoSuchColumn(name)
+			}
+			keys[i][j] = index
+		}
+	}
+	return keys, nil
+}
+
+// conflict is a conflict of a row with a stored record on a key.
+type conflict struct {
+	key int
+	rec record
+}
+
+// conflictResolver resolves conflicts of rows, that are inserted into a table or
+// updated, with the rows of the table. Conflicting rows are looked up through
+// the index of every key, or through the tree of the table for the primary key
+// of a table without row ID, see (*Table).keyLookup, so only the keys of the
+// written rows are read. Rows are written as soon as their conflicts are
+// resolved, so that they conflict with the rows, that are written after them.
+// If the statement fails, its changes are undone by the statement savepoint,
+// see (Engine).evaluateAtomically.
+type conflictResolver struct {
+	e        Engine
+	ctx      ExecutionContext
+	tbl      *Table
+	cols     []table.Col
+	keys     [][]int
+	insertOr command.InsertOr
+	upsert   *command.Upsert
+	// upsertKeys are the indices of the keys, whose conflicts are handled by
+	// the upsert.
+	upsertKeys map[int]bool
+
+	// lookups look up the records of the rows, that hold given values in
+	// the columns of a key, in the order of the keys.
+	lookups []keyLookup
+	// changes are the changes, that were written into the table, in the
+	// order in which they were written.
+	changes []rowChange
+	// deleted holds the keys of the records, that were deleted, because
+	// they conflicted with a written row.
+	deleted map[string]bool
+}
+
+// newConflictResolver creates a new conflict resolver for the given table, with
+// the given schema. No rows are read, until conflicts are looked up.
+func (e Engine) newConflictResolver(ctx ExecutionContext, tbl *Table, sf *dbfs.SchemaFile) (*conflictResolver, error) {
+	keys, err := tableKeys(sf)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+
+	r := &conflictResolver{
+		e:       e,
+		ctx:     ctx,
+		tbl:     tbl,
+		cols:    sf.Columns,
+		keys:    keys,
+		lookups: make([]keyLookup, len(keys)),
+		deleted: make(map[string]bool),
+	}
+	for i, key := range keys {
+		if r.lookups[i], err = tbl.keyLookup(key); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// setInsert sets the insert, by whose upsert and InsertOr the conflicts of
+// inserted rows are resolved.
+func (r *conflictResolver) setInsert(c command.Insert) error {
+	r.insertOr = c.InsertOr
+	r.upsert = c.Upsert
+	if c.Upsert != nil {
+		upsertKeys, err := r.matchUpsertTarget(c.Upsert.Target)
+		if err != nil {
+			return err
+		}
+		r.upsertKeys = upsertKeys
+	}
+	return nil
+}
+
+// matchUpsertTarget returns the indices of the keys, that are handled by an
+// upsert with the given target columns. If there are no target columns, all
+// keys are handled. Otherwise, the target columns must be the columns of a
+// key.
+func (r *conflictResolver) matchUpsertTarget(target []string) (map[int]bool, error) {
+	keys := make(map[int]bool)
+	if len(target) == 0 {
+		for i := range r.keys {
+			keys[i] = true
+		}
+		return keys, nil
+	}
+
+	targetCols := make(map[int]bool)
+	for _, name := range target {
+		index := columnIndex(r.cols, name)
+		if index == -1 {
+			return nil, ErrNoSuchColumn(name)
+		}
+		targetCols[index] = true
+	}
+	for i, key := range r.keys {
+		if len(key) != len(targetCols) {
+			continue
+		}
+		matches := true
+		for _, col := range key {
+			matches = matches && targetCols[col]
+		}
+		if matches {
+			keys[i] = true
+			return keys, nil
+		}
+	}
+	return nil, fmt.Errorf("conflict target (%v) is not a key of table %v", strings.Join(target, ","), r.tbl.name)
+}
+
+// Insert inserts the given row. If the row conflicts with a stored row, the
+// conflict is resolved by the upsert of the insert, if the upsert handles the
+// conflict, or by the InsertOr of the insert. Rows that violate another
+// constraint of the table are rejected with an error.
+func (r *conflictResolver) Insert(row table.Row) error {
+	if err := r.tbl.checkConstraints(row); err != nil {
+		return err
+	}
+
+	conflicts, err := r.conflicts(row, nil)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return r.insert(row)
+	}
+
+	for _, conflict := range conflicts {
+		if r.upsertKeys[conflict.key] {
+			if r.upsert.DoNothing {
+				return nil
+			}
+			return r.upsertRow(conflict.rec, row)
+		}
+	}
+
+	conflictErr := r.conflictError(conflicts[0].key, row)
+	switch r.insertOr {
+	case command.InsertOrIgnore:
+		return nil
+	case command.InsertOrReplace:
+		for _, conflict := range conflicts {
+			if err := r.delete(conflict.rec); err != nil {
+				return err
+			}
+		}
+		return r.insert(row)
+	case command.InsertOrRollback:
+		return r.e.rollback(r.ctx, conflictErr)
+	default:
+		// InsertOrAbort and InsertOrFail, whose insert decides, whether the
+		// rows that were inserted before are kept
+		return conflictErr
+	}
+}
+
+// upsertRow updates the given existing record with the updates of the upsert.
+// The given excluded row, that was meant to be inserted, can be referenced in
+// the update expressions through the excluded table.
+func (r *conflictResolver) upsertRow(existing record, excluded table.Row) error {
+	ctxCols := make([]table.Col, 0, 2*len(r.cols))
+	for _, col := range r.cols {
+		col.QualifiedName = r.tbl.name + "." + col.QualifiedName
+		ctxCols = append(ctxCols, col)
+	}
+	for _, col := range r.cols {
+		col.QualifiedName = excludedTable + "." + col.QualifiedName
+		ctxCols = append(ctxCols, col)
+	}
+	ctxValues := make([]types.Value, 0, len(ctxCols))
+	ctxValues = append(ctxValues, existing.row.Values...)
+	ctxValues = append(ctxValues, excluded.Values...)
+	rowCtx := r.ctx.IntermediateRow(table.RowWithColInfo{
+		Cols: ctxCols,
+		Row:  table.Row{Values: ctxValues},
+	})
+
+	if r.upsert.Filter != nil {
+		if ok, err := r.e.evaluateFilter(rowCtx, r.upsert.Filter); err != nil {
+			return fmt.Errorf("upsert filter: %w", err)
+		} else if !ok {
+			return nil
+		}
+	}
+
+	updated, err := r.e.applyUpdateSetters(rowCtx, r.cols, existing.row, r.upsert.Updates)
+	if err != nil {
+		return err
+	}
+	for i, col := range r.cols {
+		value, err := r.e.coerceValue(updated.Values[i], col.Type)
+		if err != nil {
+			return fmt.Errorf("column %v: %w", col.QualifiedName, err)
+		}
+		updated.Values[i] = value
+	}
+	if err := r.tbl.checkConstraints(updated); err != nil {
+		return err
+	}
+
+	if conflicts, err := r.conflicts(updated, existing.key); err != nil {
+		return err
+	} else if len(conflicts) != 0 {
+		return r.conflictError(conflicts[0].key, updated)
+	}
+	return r.replace(existing, updated)
+}
+
+// Update updates the row of the given existing record to the given updated
+// row. If the updated row conflicts with another stored row, the conflicting
+// rows are deleted if the given UpdateOr is UpdateOrReplace, otherwise an
+// error is returned and the row is not updated.
+func (r *conflictResolver) Update(existing record, updated table.Row, updateOr command.UpdateOr) error {
+	conflicts, err := r.conflicts(updated, existing.key)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) != 0 {
+		if updateOr != command.UpdateOrReplace {
+			return r.conflictError(conflicts[0].key, updated)
+		}
+		for _, conflict := range conflicts {
+			if err := r.delete(conflict.rec); err != nil {
+				return err
+			}
+		}
+	}
+	return r.replace(existing, updated)
+}
+
+// isDeleted returns whether the given record was deleted, because it conflicted
+// with a row, that was written after it was read.
+func (r *conflictResolver) isDeleted(rec record) bool {
+	return r.deleted[string(rec.key)]
+}
+
+// conflicts returns the conflicts of the given row with all stored rows, except
+// the row of the record with the given key. Every conflicting row is only
+// returned once.
+func (r *conflictResolver) conflicts(row table.Row, ignore []byte) ([]conflict, error) {
+	var conflicts []conflict
+	seen := make(map[string]bool)
+	for i, key := range r.keys {
+		values, ok := keyValues(key, row)
+		if !ok {
+			continue
+		}
+		candidates, err := r.lookups[i](values)
+		if err != nil {
+			return nil, fmt.Errorf("lookup: %w", err)
+		}
+		for _, candidate := range candidates {
+			if (ignore != nil && bytes.Equal(candidate.key, ignore)) || seen[string(candidate.key)] {
+				continue
+			}
+			seen[string(candidate.key)] = true
+			conflicts = append(conflicts, conflict{
+				key: i,
+				rec: candidate,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// insert inserts the given row into the table.
+func (r *conflictResolver) insert(row table.Row) error {
+	if err := r.tbl.Insert(row); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+	r.changes = append(r.changes, rowChange{new: row})
+	return nil
+}
+
+// replace replaces the row of the given record with the given row.
+func (r *conflictResolver) replace(rec record, row table.Row) error {
+	old := rec.row
+	rec.row = row
+	if err := r.tbl.replaceRecord(rec); err != nil {
+		return fmt.Errorf("replace record: %w", err)
+	}
+	r.changes = append(r.changes, rowChange{old: old, new: row})
+	return nil
+}
+
+// delete deletes the given record from the table.
+func (r *conflictResolver) delete(rec record) error {
+	if err := r.tbl.deleteRecord(rec); err != nil {
+		return fmt.Errorf("delete record: %w", err)
+	}
+	r.deleted[string(rec.key)] = true
+	r.changes = append(r.changes, rowChange{old: rec.row})
+	return nil
+}
+
+// changedRows returns the rows, that were inserted or updated.
+func (r *conflictResolver) changedRows() []table.Row {
+	var rows []table.Row
+	for _, change := range r.changes {
+		if !change.deleted() {
+			rows = append(rows, change.new)
+		}
+	}
+	return rows
+}
+
+// conflictError returns an error indicating, that the given row violates the
+// key with the given index.
+func (r *conflictResolver) conflictError(key int, row table.Row) error {
+	names := make([]string, len(r.keys[key]))
+	values := make([]string, len(r.keys[key]))
+	for i, col := range r.keys[key] {
+		names[i] = r.cols[col].QualifiedName
+		values[i] = row.Values[col].String()
+	}
+	return fmt.Errorf("%v(%v)=(%v): %w", r.tbl.name, strings.Join(names, ","), strings.Join(values, ","), ErrUniqueViolation)
+}
+
+// isConflict returns whether the given error indicates, that a row conflicts
+// with another row on a key, or violates a NOT NULL or CHECK constraint.
+func isConflict(err error) bool {
+	return errors.Is(err, ErrUniqueViolation) || isConstraintViolation(err)
+}
+
+// keyValues returns the values of the columns of the given key in the given
+// row. If any of the values is NULL, false is returned, since NULL values
+// never conflict.
+func keyValues(key []int, row table.Row) ([]types.Value, bool) {
+	values := make([]types.Value, len(key))
+	for i, col := range key {
+		if row.Values[col].IsNull() {
+			return nil, false
+		}
+		values[i] = row.Values[col]
+	}
+	return values, true
+}
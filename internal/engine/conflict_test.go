@@ -0,0 +1,233 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestConflictSuite(t *testing.T) {
+	suite.Run(t, new(ConflictSuite))
+}
+
+type ConflictSuite struct {
+	EngineSuite
+}
+
+func (suite *ConflictSuite) TestAbort() {
+	suite.setupMyTable()
+
+	err := suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30), (1, 'd', 40)`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.Contains(err.Error(), "myTable(id)=(1)")
+
+	// the statement is aborted, so no row is inserted
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "b", 20),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestUniqueColumn() {
+	suite.setupMyTable()
+
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (3, 'a', 30)`), ErrUniqueViolation)
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30), (4, 'c', 40)`), ErrUniqueViolation)
+}
+
+func (suite *ConflictSuite) TestNullNeverConflicts() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT INTO myTable (id, amount) VALUES (3, 30), (4, 40)`))
+	suite.Len(suite.scanMyTable(), 4)
+}
+
+func (suite *ConflictSuite) TestInsertOrIgnore() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT OR IGNORE INTO myTable VALUES (1, 'x', 30), (3, 'c', 30)`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "b", 20),
+		myTableRow(3, "c", 30),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestInsertOrReplace() {
+	suite.setupMyTable()
+
+	// the row conflicts with both existing rows, so both are replaced
+	suite.NoError(suite.exec(`INSERT OR REPLACE INTO myTable VALUES (1, 'b', 30)`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "b", 30),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestInsertOrFail() {
+	suite.setupMyTable()
+
+	suite.ErrorIs(suite.exec(`INSERT OR FAIL INTO myTable VALUES (3, 'c', 30), (1, 'd', 40), (4, 'e', 50)`), ErrUniqueViolation)
+
+	// rows prior to the conflict are kept
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "b", 20),
+		myTableRow(3, "c", 30),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestDoNothing() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (1, 'x', 30), (3, 'c', 30) ON CONFLICT DO NOTHING`))
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (4, 'a', 40) ON CONFLICT (name) DO NOTHING`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "b", 20),
+		myTableRow(3, "c", 30),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestDoUpdate() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (1, 'x', 5), (3, 'c', 30), (3, 'y', 7)
+ON CONFLICT (id) DO UPDATE SET amount = amount + excluded.amount`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 15),
+		myTableRow(2, "b", 20),
+		myTableRow(3, "c", 37),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestDoUpdateWithFilter() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (1, 'x', 5), (2, 'y', 5)
+ON CONFLICT (id) DO UPDATE SET name = excluded.name WHERE myTable.amount > 10`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "y", 20),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestDoUpdateConflict() {
+	suite.setupMyTable()
+
+	// the update itself conflicts with the row with id 2
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (1, 'x', 5) ON CONFLICT (id) DO UPDATE SET name = 'b'`), ErrUniqueViolation)
+}
+
+func (suite *ConflictSuite) TestUnhandledConflict() {
+	suite.setupMyTable()
+
+	// the conflict on name is not handled by the upsert
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (3, 'a', 5) ON CONFLICT (id) DO NOTHING`), ErrUniqueViolation)
+}
+
+func (suite *ConflictSuite) TestInvalidConflictTarget() {
+	suite.setupMyTable()
+
+	suite.Error(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 5) ON CONFLICT (amount) DO NOTHING`))
+	suite.Error(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 5) ON CONFLICT (unknown) DO NOTHING`))
+}
+
+func (suite *ConflictSuite) TestTableUniqueConstraint() {
+	suite.NoError(suite.exec(`CREATE TABLE pairs (a INTEGER, b INTEGER, c STRING, UNIQUE (a, b))`))
+	suite.NoError(suite.exec(`INSERT INTO pairs VALUES (1, 1, 'x'), (1, 2, 'x')`))
+
+	err := suite.exec(`INSERT INTO pairs VALUES (1, 2, 'y')`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.Contains(err.Error(), "pairs(a,b)=(1,2)")
+
+	// NULL values never conflict
+	suite.NoError(suite.exec(`INSERT INTO pairs (a, c) VALUES (1, 'y'), (1, 'z')`))
+}
+
+func (suite *ConflictSuite) TestUpdate() {
+	suite.setupMyTable()
+
+	err := suite.exec(`UPDATE myTable SET name = 'a' WHERE id = 2`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.Contains(err.Error(), "myTable(name)=(a)")
+
+	// rows that are updated by the same statement conflict with each other
+	suite.ErrorIs(suite.exec(`UPDATE myTable SET name = 'c'`), ErrUniqueViolation)
+
+	// the row keeps its own key
+	suite.NoError(suite.exec(`UPDATE myTable SET name = 'a', amount = 15 WHERE id = 1`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 15),
+		myTableRow(2, "b", 20),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestUpdateOrReplace() {
+	suite.setupMyTable()
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30)`))
+
+	// the updated row conflicts with the row with id 1, which is replaced
+	suite.NoError(suite.exec(`UPDATE OR REPLACE myTable SET id = 1 WHERE name = 'c'`))
+	suite.Equal([]table.Row{
+		myTableRow(2, "b", 20),
+		myTableRow(1, "c", 30),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestUpdateOrIgnore() {
+	suite.setupMyTable()
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30)`))
+
+	// the rows with id 1 and 2 conflict with the next row, and are skipped
+	result, err := suite.evaluateStatement(`UPDATE OR IGNORE myTable SET id = id + 1`)
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(1), result)
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(2, "b", 20),
+		myTableRow(4, "c", 30),
+	}, suite.scanMyTable())
+
+	// type conflicts are ignored as well
+	result, err = suite.evaluateStatement(`UPDATE OR IGNORE myTable SET id = name`)
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(0), result)
+}
+
+func (suite *ConflictSuite) TestUpdateOrFail() {
+	suite.setupMyTable()
+
+	suite.ErrorIs(suite.exec(`UPDATE OR FAIL myTable SET id = id + 10, name = 'z'`), ErrUniqueViolation)
+
+	// updates prior to the conflict are kept
+	suite.Equal([]table.Row{
+		myTableRow(11, "z", 10),
+		myTableRow(2, "b", 20),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) TestWithoutRowID() {
+	suite.Require().NoError(suite.exec(`CREATE TABLE myTable (id INTEGER PRIMARY KEY, name STRING UNIQUE, amount INTEGER) WITHOUT ROWID`))
+	suite.Require().NoError(suite.exec(`INSERT INTO myTable VALUES (1, 'a', 10), (2, 'b', 20)`))
+
+	// conflicts on the primary key are looked up in the tree of the table
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30), (2, 'd', 40)`), ErrUniqueViolation)
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (3, 'c', 30), (4, 'c', 40)`), ErrUniqueViolation)
+	suite.NoError(suite.exec(`UPDATE OR REPLACE myTable SET id = 2, name = 'x' WHERE id = 1`))
+	suite.Equal([]table.Row{
+		myTableRow(2, "x", 10),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConflictSuite) setupMyTable() {
+	suite.createMyTable(`id INTEGER PRIMARY KEY, name STRING UNIQUE, amount INTEGER`, `(1, 'a', 10), (2, 'b', 20)`)
+}
+
+func myTableRow(id int64, name string, amount int64) table.Row {
+	return table.Row{
+		Values: []types.Value{types.NewInteger(id), types.NewString(name), types.NewInteger(amount)},
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler"
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// checkConstraints returns an error, if the given row violates a NOT NULL or a
+// CHECK constraint of this table. The columns of the primary key must not hold
+// NULL values either. Violations are reported as ErrNotNullViolation and
+// ErrCheckViolation respectively. Unique constraints are not checked, since
+// they depend on the other rows of the table, see conflictResolver.
+func (t *Table) checkConstraints(row table.Row) error {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return fmt.Errorf("schema file: %w", err)
+	}
+
+	notNull := make([]string, 0, len(sf.PrimaryKey)+len(sf.NotNull))
+	notNull = append(notNull, sf.PrimaryKey...)
+	notNull = append(notNull, sf.NotNull...)
+	for _, name := range notNull {
+		index := columnIndex(sf.Columns, name)
+		if index == -1 {
+			return ErrNoSuchColumn(name)
+		}
+		if row.Values[index].IsNull() {
+			return fmt.Errorf("%v.%v: %w", t.name, name, ErrNotNullViolation)
+		}
+	}
+
+	if len(sf.Checks) == 0 {
+		return nil
+	}
+	ctx := newEmptyExecutionContext(t.tx).IntermediateRow(table.RowWithColInfo{
+		Cols: sf.Columns,
+		Row:  row,
+	})
+	for _, check := range sf.Checks {
+		expr, err := t.compileCheck(check)
+		if err != nil {
+			return err
+		}
+		ok, err := t.engine.evaluateCheck(ctx, expr)
+		if err != nil {
+			return fmt.Errorf("check %v: %w", check.Expr, err)
+		}
+		if !ok {
+			name := check.Name
+			if name == "" {
+				name = "CHECK(" + check.Expr + ")"
+			}
+			return fmt.Errorf("%v: %v: %w", t.name, name, ErrCheckViolation)
+		}
+	}
+	return nil
+}
+
+// compileCheck returns the compiled expression of the given CHECK constraint.
+// Compiled expressions are cached in this table.
+func (t *Table) compileCheck(check dbfs.Check) (command.Expr, error) {
+	if expr, ok := t.checks[check.Expr]; ok {
+		return expr, nil
+	}
+	expr, err := compiler.CompileExpr(check.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("compile check %v: %w", check.Expr, err)
+	}
+	if t.checks == nil {
+		t.checks = make(map[string]command.Expr)
+	}
+	t.checks[check.Expr] = expr
+	return expr, nil
+}
+
+// evaluateCheck evaluates the given expression of a CHECK constraint in the
+// given context, and returns whether the constraint is satisfied. This is the
+// case, if the expression evaluates to true or to NULL. Since comparisons
+// don't evaluate to NULL, an expression that references a NULL value of the
+// intermediate row satisfies the constraint as well.
+func (e Engine) evaluateCheck(ctx ExecutionContext, expr command.Expr) (bool, error) {
+	if referencesNull(ctx, expr) {
+		return true, nil
+	}
+
+	value, err := e.evaluateExpression(ctx, expr)
+	if err != nil {
+		return false, err
+	}
+	if value.IsNull() {
+		return true, nil
+	}
+	if !value.Is(types.Bool) {
+		return false, fmt.Errorf("expression does not evaluate to bool")
+	}
+	return value.(types.BoolValue).Value, nil
+}
+
+// referencesNull returns whether the given expression references a column, that
+// holds NULL in the intermediate row of the given context. Function arguments
+// are not considered, since functions may handle NULL values.
+func referencesNull(ctx ExecutionContext, expr command.Expr) bool {
+	var name string
+	switch ex := expr.(type) {
+	case command.BinaryExpression:
+		return referencesNull(ctx, ex.LeftExpr()) || referencesNull(ctx, ex.RightExpr())
+	case command.UnaryNegativeExpr:
+		return referencesNull(ctx, ex.Value)
+	case command.UnaryNegationExpr:
+		return referencesNull(ctx, ex.Value)
+	case command.UnaryBitwiseNegationExpr:
+		return referencesNull(ctx, ex.Value)
+	case command.ColumnReference:
+		name = ex.Name
+	case command.ConstantLiteralOrColumnReference:
+		name = ex.ValueOrName
+	default:
+		return false
+	}
+	value, ok := ctx.intermediateRow.ValueForColName(name)
+	return ok && value.IsNull()
+}
+
+// isConstraintViolation returns whether the given error indicates, that a row
+// violates a NOT NULL or CHECK constraint.
+func isConstraintViolation(err error) bool {
+	return errors.Is(err, ErrNotNullViolation) || errors.Is(err, ErrCheckViolation)
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestConstraintSuite(t *testing.T) {
+	suite.Run(t, new(ConstraintSuite))
+}
+
+type ConstraintSuite struct {
+	EngineSuite
+}
+
+func (suite *ConstraintSuite) TestNotNull() {
+	suite.setupMyTable()
+
+	err := suite.exec(`INSERT INTO myTable (id, amount) VALUES (2, 20)`)
+	suite.ErrorIs(err, ErrNotNullViolation)
+	suite.Contains(err.Error(), "myTable.name")
+
+	// the primary key must not be NULL either
+	err = suite.exec(`INSERT INTO myTable (name, amount) VALUES ('b', 20)`)
+	suite.ErrorIs(err, ErrNotNullViolation)
+	suite.Contains(err.Error(), "myTable.id")
+}
+
+func (suite *ConstraintSuite) TestCheck() {
+	suite.setupMyTable()
+
+	err := suite.exec(`INSERT INTO myTable VALUES (2, 'b', -1)`)
+	suite.ErrorIs(err, ErrCheckViolation)
+	suite.Contains(err.Error(), "CHECK(amount >= 0)")
+
+	err = suite.exec(`INSERT INTO myTable VALUES (2, 'b', 200)`)
+	suite.ErrorIs(err, ErrCheckViolation)
+	suite.Contains(err.Error(), "myTable: max_amount")
+
+	// a check that references NULL is satisfied
+	suite.NoError(suite.exec(`INSERT INTO myTable (id, name) VALUES (2, 'b')`))
+}
+
+func (suite *ConstraintSuite) TestTablePrimaryKey() {
+	suite.NoError(suite.exec(`CREATE TABLE pairs (a INTEGER, b INTEGER, PRIMARY KEY (a, b))`))
+	suite.NoError(suite.exec(`INSERT INTO pairs VALUES (1, 1), (1, 2)`))
+	suite.ErrorIs(suite.exec(`INSERT INTO pairs VALUES (1, 2)`), ErrUniqueViolation)
+	suite.ErrorIs(suite.exec(`INSERT INTO pairs (a) VALUES (1)`), ErrNotNullViolation)
+}
+
+func (suite *ConstraintSuite) TestUpdate() {
+	suite.setupMyTable()
+
+	suite.ErrorIs(suite.exec(`UPDATE myTable SET amount = amount - 20`), ErrCheckViolation)
+	// the violating row is skipped
+	result, err := suite.evaluateStatement(`UPDATE OR IGNORE myTable SET amount = amount - 20`)
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(0), result)
+	suite.NoError(suite.exec(`UPDATE myTable SET amount = amount - 10`))
+
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 0),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConstraintSuite) TestUpsert() {
+	suite.setupMyTable()
+
+	suite.ErrorIs(suite.exec(`INSERT INTO myTable VALUES (1, 'a', 10) ON CONFLICT (id) DO UPDATE SET amount = -1`), ErrCheckViolation)
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConstraintSuite) TestInsertOrIgnore() {
+	suite.setupMyTable()
+
+	suite.NoError(suite.exec(`INSERT OR IGNORE INTO myTable (id, amount) VALUES (2, 20)`))
+	suite.NoError(suite.exec(`INSERT OR IGNORE INTO myTable VALUES (3, 'c', -1), (4, 'd', 40)`))
+	suite.Equal([]table.Row{
+		myTableRow(1, "a", 10),
+		myTableRow(4, "d", 40),
+	}, suite.scanMyTable())
+}
+
+func (suite *ConstraintSuite) TestInvalidCheck() {
+	_, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Name: "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "id", Type: types.Integer},
+		},
+		Checks: []command.Check{
+			{Source: "id >"},
+		},
+	})
+	suite.Error(err)
+}
+
+func (suite *ConstraintSuite) setupMyTable() {
+	suite.createMyTable(`id INTEGER PRIMARY KEY,
+	name STRING NOT NULL,
+	amount INTEGER CHECK (amount >= 0),
+	CONSTRAINT max_amount CHECK (amount <= 100)`, `(1, 'a', 10)`)
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"github.com/xqueries/xdb/internal/engine/table"
 	"github.com/xqueries/xdb/internal/engine/transaction"
+	"github.com/xqueries/xdb/internal/engine/types"
 	"github.com/xqueries/xdb/internal/id"
 )
 
@@ -13,6 +14,16 @@ type ExecutionContext struct {
 
 	intermediateRow table.RowWithColInfo
 	tx              *transaction.TX
+	// trigger is the trigger, whose statements are evaluated in this context,
+	// or nil, if no trigger is firing.
+	trigger *firingTrigger
+	// outer is the context of the query, in which the subquery, that is
+	// evaluated in this context, is nested, or nil, if no subquery is
+	// evaluated.
+	outer *ExecutionContext
+	// commonTables are the common tables of the WITH clauses, that enclose
+	// the command, that is evaluated in this context, by their names.
+	commonTables map[string]*commonTable
 }
 
 func newEmptyExecutionContext(tx *transaction.TX) ExecutionContext {
@@ -29,6 +40,57 @@ func (c ExecutionContext) IntermediateRow(row table.RowWithColInfo) ExecutionCon
 	return c
 }
 
+// firing returns a context for the statements of the given trigger. Unlike
+// the intermediate row, the trigger is kept in all contexts, that are derived
+// from the returned context.
+func (c ExecutionContext) firing(trigger *firingTrigger) ExecutionContext {
+	return ExecutionContext{
+		id:      c.id,
+		tx:      c.tx,
+		trigger: trigger,
+	}
+}
+
+// subquery returns a context for a subquery, that is nested in the query,
+// that is evaluated in this context. Columns, that can't be found in the rows
+// of the subquery, are looked up in the intermediate row of this context and
+// its outer contexts, which allows for correlated subqueries.
+func (c ExecutionContext) subquery() ExecutionContext {
+	outer := c
+	return ExecutionContext{
+		id:           c.id,
+		tx:           c.tx,
+		trigger:      c.trigger,
+		outer:        &outer,
+		commonTables: c.commonTables,
+	}
+}
+
+// withCommonTable returns a context, in which the given common table can be
+// referenced by its name. The common table hides a common table with the
+// same name, that is available in this context.
+func (c ExecutionContext) withCommonTable(tbl *commonTable) ExecutionContext {
+	commonTables := make(map[string]*commonTable, len(c.commonTables)+1)
+	for name, existing := range c.commonTables {
+		commonTables[name] = existing
+	}
+	commonTables[tbl.Name] = tbl
+	c.commonTables = commonTables
+	return c
+}
+
+// outerValue returns the value of the column with the given name from the
+// intermediate row of the closest outer context, that has such a column, or
+// false, if no outer context has such a column.
+func (c ExecutionContext) outerValue(name string) (types.Value, bool) {
+	for outer := c.outer; outer != nil; outer = outer.outer {
+		if val, ok := outer.intermediateRow.ValueForColName(name); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
 func (c ExecutionContext) String() string {
 	return c.id.String()
 }
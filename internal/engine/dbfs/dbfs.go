@@ -35,6 +35,12 @@ func CreateNew(fs afero.Fs) (*DBFS, error) {
 	if err := dbfs.touch(filepath.Join(TablesDirectory, TablesInfoFile)); err != nil {
 		return nil, err
 	}
+	if err := dbfs.touch(ViewsInfoFile); err != nil {
+		return nil, err
+	}
+	if err := dbfs.touch(TriggersInfoFile); err != nil {
+		return nil, err
+	}
 
 	if err := dbfs.Close(); err != nil {
 		return nil, fmt.Errorf("close stub dbfs: %w", err)
@@ -128,6 +134,9 @@ func (dbfs *DBFS) CreateTable(name string) (Table, error) {
 	if err := dbfs.touch(filepath.Join(tableDir, TableDataFile)); err != nil {
 		return Table{}, err
 	}
+	if err := dbfs.touch(filepath.Join(tableDir, TableIndexFile)); err != nil {
+		return Table{}, err
+	}
 	if err := dbfs.touch(filepath.Join(tableDir, TableSchemaFile)); err != nil {
 		return Table{}, err
 	}
@@ -138,6 +147,71 @@ func (dbfs *DBFS) CreateTable(name string) (Table, error) {
 	}, nil
 }
 
+// DropTable removes the table with the given name, together with all its files,
+// from this DBFS. The entry of the table is removed from the tables info file
+// first, so that the table doesn't exist anymore, even if the table directory
+// can not be removed completely. This will return an error if no table with the
+// given name exists.
+func (dbfs *DBFS) DropTable(name string) error {
+	infos, err := dbfs.LoadTablesInfo()
+	if err != nil {
+		return err
+	}
+
+	tblID, ok := infos.Tables[name]
+	if !ok {
+		return fmt.Errorf("table '%s' does not exist", name)
+	}
+
+	delete(infos.Tables, name)
+	infos.Count--
+	if err := dbfs.StoreTablesInfo(infos); err != nil {
+		return fmt.Errorf("store table info: %w", err)
+	}
+
+	tableDir := filepath.Join(TablesDirectory, tblID)
+	if err := dbfs.fs.RemoveAll(tableDir); err != nil {
+		return fmt.Errorf("remove '%s': %w", tableDir, err)
+	}
+	return nil
+}
+
+// RenameTables renames the tables in this DBFS. The keys of the given map are
+// the new names of the tables, and the values are the current names. All tables
+// are renamed with a single update of the tables info file, which is why two
+// tables can swap their names. The files of the renamed tables are not touched.
+// This will return an error if one of the tables doesn't exist, or if a new name
+// is already taken by a table, that is not renamed.
+func (dbfs *DBFS) RenameTables(names map[string]string) error {
+	infos, err := dbfs.LoadTablesInfo()
+	if err != nil {
+		return err
+	}
+
+	tblIDs := make(map[string]string, len(names))
+	for newName, oldName := range names {
+		tblID, ok := infos.Tables[oldName]
+		if !ok {
+			return fmt.Errorf("table '%s' does not exist", oldName)
+		}
+		tblIDs[newName] = tblID
+	}
+	for _, oldName := range names {
+		delete(infos.Tables, oldName)
+	}
+	for newName, tblID := range tblIDs {
+		if _, ok := infos.Tables[newName]; ok {
+			return fmt.Errorf("table '%s' already exists", newName)
+		}
+		infos.Tables[newName] = tblID
+	}
+
+	if err := dbfs.StoreTablesInfo(infos); err != nil {
+		return fmt.Errorf("store table info: %w", err)
+	}
+	return nil
+}
+
 // LoadTablesInfo loads the content of the tables.info file as structured content.
 // The returned TablesInfo is a value, and must be stored using StoreTablesInfo to
 // persist any changes.
@@ -184,6 +258,91 @@ func (dbfs *DBFS) StoreTablesInfo(info TablesInfo) error {
 	return nil
 }
 
+// LoadViewsInfo loads the content of the views.info file as structured content.
+// If the file doesn't exist, which is the case for databases that were created
+// before views were supported, an empty ViewsInfo is returned. The returned
+// ViewsInfo is a value, and must be stored using StoreViewsInfo to persist any
+// changes.
+func (dbfs *DBFS) LoadViewsInfo() (ViewsInfo, error) {
+	var infos ViewsInfo
+	infos.Views = make(map[string]View)
+
+	infoFile, err := dbfs.fs.Open(ViewsInfoFile)
+	if os.IsNotExist(err) {
+		return infos, nil
+	} else if err != nil {
+		return ViewsInfo{}, fmt.Errorf("open '%s': %w", ViewsInfoFile, err)
+	}
+	defer func() {
+		_ = infoFile.Close()
+	}()
+
+	if err := yaml.NewDecoder(infoFile).Decode(&infos); err != nil && err != io.EOF {
+		return ViewsInfo{}, fmt.Errorf("decode infos: %w", err)
+	}
+	return infos, nil
+}
+
+// StoreViewsInfo stores the given ViewsInfo in the views.info file, which is
+// created if it doesn't exist. This completely overwrites the existing content
+// in the file.
+func (dbfs *DBFS) StoreViewsInfo(info ViewsInfo) error {
+	infoFile, err := dbfs.fs.OpenFile(ViewsInfoFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	if err != nil {
+		return fmt.Errorf("open '%s': %w", ViewsInfoFile, err)
+	}
+	defer func() {
+		_ = infoFile.Close()
+	}()
+
+	if err := yaml.NewEncoder(infoFile).Encode(&info); err != nil {
+		return fmt.Errorf("encode infos: %w", err)
+	}
+	return nil
+}
+
+// LoadTriggersInfo loads the content of the triggers.info file as structured
+// content. Like LoadViewsInfo, an empty TriggersInfo is returned if the file
+// doesn't exist. The returned TriggersInfo must be stored using
+// StoreTriggersInfo to persist any changes.
+func (dbfs *DBFS) LoadTriggersInfo() (TriggersInfo, error) {
+	var infos TriggersInfo
+	infos.Triggers = make(map[string]Trigger)
+
+	infoFile, err := dbfs.fs.Open(TriggersInfoFile)
+	if os.IsNotExist(err) {
+		return infos, nil
+	} else if err != nil {
+		return TriggersInfo{}, fmt.Errorf("open '%s': %w", TriggersInfoFile, err)
+	}
+	defer func() {
+		_ = infoFile.Close()
+	}()
+
+	if err := yaml.NewDecoder(infoFile).Decode(&infos); err != nil && err != io.EOF {
+		return TriggersInfo{}, fmt.Errorf("decode infos: %w", err)
+	}
+	return infos, nil
+}
+
+// StoreTriggersInfo stores the given TriggersInfo in the triggers.info file,
+// which is created if it doesn't exist. This completely overwrites the
+// existing content in the file.
+func (dbfs *DBFS) StoreTriggersInfo(info TriggersInfo) error {
+	infoFile, err := dbfs.fs.OpenFile(TriggersInfoFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, defaultFilePerm)
+	if err != nil {
+		return fmt.Errorf("open '%s': %w", TriggersInfoFile, err)
+	}
+	defer func() {
+		_ = infoFile.Close()
+	}()
+
+	if err := yaml.NewEncoder(infoFile).Encode(&info); err != nil {
+		return fmt.Errorf("encode infos: %w", err)
+	}
+	return nil
+}
+
 // StoreSchema will store the given schema information in the schema
 // file of the table with the given name. If the table doesn't exist, an
 // error is returned. The current content of the schema file will be
@@ -209,12 +368,37 @@ func (dbfs *DBFS) StoreSchema(table string, sf *SchemaFile) error {
 
 	var syaml schemaYaml
 	syaml.HighestRowID = sf.HighestRowID
+	syaml.PrimaryKey = sf.PrimaryKey
+	syaml.UniqueKeys = sf.UniqueKeys
+	syaml.NotNull = sf.NotNull
+	for _, check := range sf.Checks {
+		syaml.Checks = append(syaml.Checks, checkYaml{
+			Name: check.Name,
+			Expr: check.Expr,
+		})
+	}
+	for _, foreignKey := range sf.ForeignKeys {
+		syaml.ForeignKeys = append(syaml.ForeignKeys, foreignKeyYaml(foreignKey))
+	}
+	for _, index := range sf.Indexes {
+		syaml.Indexes = append(syaml.Indexes, indexYaml(index))
+	}
+	syaml.WithoutRowID = sf.WithoutRowID
+	syaml.Root = sf.Root
 	for _, column := range sf.Columns {
-		syaml.Columns = append(syaml.Columns, columnYaml{
+		cyaml := columnYaml{
 			QualifiedName: column.QualifiedName,
 			Alias:         column.Alias,
 			Type:          types.IndicatorFor(column.Type),
-		})
+		}
+		if defaultValue, ok := sf.Defaults[column.QualifiedName]; ok {
+			vyaml, err := encodeValue(defaultValue)
+			if err != nil {
+				return fmt.Errorf("default of column %v: %w", column.QualifiedName, err)
+			}
+			cyaml.Default = vyaml
+		}
+		syaml.Columns = append(syaml.Columns, cyaml)
 	}
 
 	enc := yaml.NewEncoder(f)
@@ -224,6 +408,30 @@ func (dbfs *DBFS) StoreSchema(table string, sf *SchemaFile) error {
 	return nil
 }
 
+// CreateTempFile creates a new, empty file in the temp directory of this DBFS.
+// The temp directory is created if it does not exist yet. The caller is
+// responsible for closing the file, and removing it with RemoveTempFile once it
+// is not needed anymore.
+func (dbfs *DBFS) CreateTempFile() (afero.File, error) {
+	if err := dbfs.fs.MkdirAll(TempDirectory, defaultDirPerm); err != nil {
+		return nil, fmt.Errorf("mkdir all: %w", err)
+	}
+	f, err := afero.TempFile(dbfs.fs, TempDirectory, "")
+	if err != nil {
+		return nil, fmt.Errorf("temp file: %w", err)
+	}
+	return f, nil
+}
+
+// RemoveTempFile removes the given file, which must have been created with
+// CreateTempFile. The file should be closed before it is removed.
+func (dbfs *DBFS) RemoveTempFile(f afero.File) error {
+	if err := dbfs.fs.Remove(f.Name()); err != nil {
+		return fmt.Errorf("remove: %w", err)
+	}
+	return nil
+}
+
 // touch creates an empty file with the given path in this DBFS.
 func (dbfs *DBFS) touch(path string) error {
 	f, err := dbfs.fs.OpenFile(path, os.O_CREATE, defaultFilePerm)
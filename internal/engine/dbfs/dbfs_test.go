@@ -7,6 +7,9 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
 )
 
 func TestDBFSSuite(t *testing.T) {
@@ -86,6 +89,223 @@ func (suite *DBFSSuite) TestCreateTable() {
 	suite.NoError(Validate(fs))
 }
 
+func (suite *DBFSSuite) TestDropTable() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+
+	tbl, err := dbfs.CreateTable("myTable")
+	suite.NoError(err)
+	_, err = dbfs.CreateTable("otherTable")
+	suite.NoError(err)
+
+	suite.NoError(dbfs.DropTable("myTable"))
+	suite.NoError(Validate(fs))
+
+	ok, err := dbfs.HasTable("myTable")
+	suite.NoError(err)
+	suite.False(ok)
+	ok, err = dbfs.HasTable("otherTable")
+	suite.NoError(err)
+	suite.True(ok)
+
+	tblCount, err := dbfs.TableCount()
+	suite.NoError(err)
+	suite.Equal(1, tblCount)
+
+	exists, err := afero.Exists(fs, filepath.Join(TablesDirectory, tbl.id.String()))
+	suite.NoError(err)
+	suite.False(exists)
+
+	suite.EqualError(dbfs.DropTable("myTable"), "table 'myTable' does not exist")
+}
+
+func (suite *DBFSSuite) TestRenameTables() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+
+	tbl1, err := dbfs.CreateTable("table1")
+	suite.NoError(err)
+	tbl2, err := dbfs.CreateTable("table2")
+	suite.NoError(err)
+
+	// swap the names of the two tables
+	suite.NoError(dbfs.RenameTables(map[string]string{
+		"table1": "table2",
+		"table2": "table1",
+	}))
+	suite.NoError(Validate(fs))
+
+	infos, err := dbfs.LoadTablesInfo()
+	suite.NoError(err)
+	suite.Equal(TablesInfo{
+		Tables: map[string]string{
+			"table1": tbl2.id.String(),
+			"table2": tbl1.id.String(),
+		},
+		Count: 2,
+	}, infos)
+
+	suite.NoError(dbfs.RenameTables(map[string]string{"table3": "table1"}))
+	ok, err := dbfs.HasTable("table1")
+	suite.NoError(err)
+	suite.False(ok)
+	ok, err = dbfs.HasTable("table3")
+	suite.NoError(err)
+	suite.True(ok)
+
+	suite.EqualError(dbfs.RenameTables(map[string]string{"table4": "table1"}), "table 'table1' does not exist")
+	suite.EqualError(dbfs.RenameTables(map[string]string{"table2": "table3"}), "table 'table2' already exists")
+}
+
+func (suite *DBFSSuite) TestViewsInfo() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+	suite.FileEmpty(fs, ViewsInfoFile)
+
+	infos, err := dbfs.LoadViewsInfo()
+	suite.NoError(err)
+	suite.Empty(infos.Views)
+
+	infos.Views["myView"] = View{
+		Query:   "SELECT a , b FROM myTable",
+		Columns: []string{"a", "b"},
+	}
+	suite.NoError(dbfs.StoreViewsInfo(infos))
+	suite.NoError(Validate(fs))
+
+	loaded, err := dbfs.LoadViewsInfo()
+	suite.NoError(err)
+	suite.Equal(infos, loaded)
+
+	// databases without a views.info file have no views
+	suite.NoError(fs.Remove(ViewsInfoFile))
+	suite.NoError(Validate(fs))
+	loaded, err = dbfs.LoadViewsInfo()
+	suite.NoError(err)
+	suite.Empty(loaded.Views)
+	suite.NoError(dbfs.StoreViewsInfo(infos))
+	loaded, err = dbfs.LoadViewsInfo()
+	suite.NoError(err)
+	suite.Equal(infos, loaded)
+}
+
+func (suite *DBFSSuite) TestTriggersInfo() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+	suite.FileEmpty(fs, TriggersInfoFile)
+
+	infos, err := dbfs.LoadTriggersInfo()
+	suite.NoError(err)
+	suite.Empty(infos.Triggers)
+
+	infos.Triggers["myTrigger"] = Trigger{
+		Table:      "myTable",
+		Timing:     After,
+		Event:      Update,
+		Columns:    []string{"a"},
+		When:       "NEW . a > 0",
+		Statements: []string{"INSERT INTO log VALUES ( NEW . a )", "DELETE FROM other"},
+	}
+	suite.NoError(dbfs.StoreTriggersInfo(infos))
+	suite.NoError(Validate(fs))
+
+	loaded, err := dbfs.LoadTriggersInfo()
+	suite.NoError(err)
+	suite.Equal(infos, loaded)
+
+	// databases without a triggers.info file have no triggers
+	suite.NoError(fs.Remove(TriggersInfoFile))
+	suite.NoError(Validate(fs))
+	loaded, err = dbfs.LoadTriggersInfo()
+	suite.NoError(err)
+	suite.Empty(loaded.Triggers)
+}
+
+func (suite *DBFSSuite) TestTempFile() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+
+	f, err := dbfs.CreateTempFile()
+	suite.NoError(err)
+	_, err = f.Write([]byte("hello"))
+	suite.NoError(err)
+	suite.NoError(f.Close())
+	suite.FileExists(fs, f.Name())
+	suite.NoError(Validate(fs))
+
+	suite.NoError(dbfs.RemoveTempFile(f))
+	suite.DirEmpty(fs, TempDirectory)
+}
+
+func (suite *DBFSSuite) TestStoreSchema() {
+	fs := afero.NewMemMapFs()
+
+	dbfs, err := CreateNew(fs)
+	suite.NoError(err)
+	tbl, err := dbfs.CreateTable("myTable")
+	suite.NoError(err)
+
+	sf := &SchemaFile{
+		HighestRowID: 5,
+		Columns: []table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+			{QualifiedName: "price", Type: types.Real},
+		},
+		Defaults: map[string]types.Value{
+			"name":  types.NewString("unknown"),
+			"price": types.NewNull(types.Real),
+		},
+		PrimaryKey: []string{"id"},
+		UniqueKeys: [][]string{{"name"}},
+		NotNull:    []string{"id", "name"},
+		Checks: []Check{
+			{Expr: "price > 0"},
+			{Name: "named", Expr: "name > 'a'"},
+		},
+		ForeignKeys: []ForeignKey{
+			{
+				Columns:        []string{"name"},
+				ForeignTable:   "names",
+				ForeignColumns: []string{"name"},
+				OnDelete:       Cascade,
+				OnUpdate:       NoAction,
+			},
+			{
+				Name:           "self",
+				Columns:        []string{"price", "name"},
+				ForeignTable:   "myTable",
+				ForeignColumns: []string{"price", "name"},
+				OnDelete:       SetNull,
+				OnUpdate:       Restrict,
+				Deferred:       true,
+			},
+		},
+		Indexes: []Index{
+			{Name: "myIndex", Columns: []string{"name", "price"}, Root: 3},
+			{Name: "myUniqueIndex", Columns: []string{"price"}, Unique: true},
+			{Name: "myAutoIndex", Columns: []string{"name"}, Unique: true, Auto: true},
+		},
+		WithoutRowID: true,
+		Root:         2,
+	}
+	suite.NoError(dbfs.StoreSchema("myTable", sf))
+
+	loaded, err := tbl.SchemaFile()
+	suite.NoError(err)
+	suite.Equal(sf, loaded)
+}
+
 func (suite *DBFSSuite) TestManyTables() {
 	fs := afero.NewMemMapFs()
 
@@ -109,6 +329,7 @@ func (suite *DBFSSuite) TestManyTables() {
 		tableDir := filepath.Join(TablesDirectory, tbl.id.String())
 		suite.DirExists(fs, tableDir)
 		suite.FileEmpty(fs, filepath.Join(tableDir, TableDataFile))
+		suite.FileEmpty(fs, filepath.Join(tableDir, TableIndexFile))
 		suite.FileEmpty(fs, filepath.Join(tableDir, TableSchemaFile))
 	}
 }
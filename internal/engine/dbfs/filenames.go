@@ -2,8 +2,12 @@ package dbfs
 
 // Used filenames.
 const (
-	TablesDirectory = "tables"
-	TablesInfoFile  = "tables.info"
-	TableDataFile   = "data"
-	TableSchemaFile = "schema"
+	TablesDirectory  = "tables"
+	TablesInfoFile   = "tables.info"
+	TableDataFile    = "data"
+	TableIndexFile   = "index"
+	TableSchemaFile  = "schema"
+	TempDirectory    = "tmp"
+	TriggersInfoFile = "triggers.info"
+	ViewsInfoFile    = "views.info"
 )
@@ -1,11 +1,13 @@
 package dbfs
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/xqueries/xdb/internal/engine/page"
 	"github.com/xqueries/xdb/internal/engine/table"
 	"github.com/xqueries/xdb/internal/engine/types"
 )
@@ -16,13 +18,193 @@ import (
 type SchemaFile struct {
 	HighestRowID int
 	Columns      []table.Col
+	// Defaults are the default values of the columns, by the name of the
+	// column. Columns without a default value are not contained.
+	Defaults map[string]types.Value
+	// PrimaryKey are the names of the columns of the primary key of the
+	// table, or nil, if the table has no primary key.
+	PrimaryKey []string
+	// UniqueKeys are the keys of the table, whose values must be unique
+	// across all rows of the table. Every key is a list of column names.
+	UniqueKeys [][]string
+	// NotNull are the names of the columns, that must not hold NULL values.
+	NotNull []string
+	// Checks are the CHECK constraints of the table.
+	Checks []Check
+	// ForeignKeys are the foreign keys of the table, that reference other
+	// tables or the table itself.
+	ForeignKeys []ForeignKey
+	// Indexes are the indexes of the table, whose pages are stored in the
+	// index file of the table.
+	Indexes []Index
+	// WithoutRowID indicates, that the rows of the table are clustered by
+	// the primary key of the table. The rows are stored in a B+tree over the
+	// data pages of the table, instead of being keyed by a row ID.
+	WithoutRowID bool
+	// Root is the ID of the root page of the B+tree, that holds the rows of a
+	// table without row ID.
+	Root page.ID
+}
+
+// Keys returns the keys of the table, whose values must be unique across all
+// rows of the table. These are the primary key, the unique keys and the
+// columns of all unique indexes of the table, that were not created for the
+// primary key or a unique key. The primary key, if any, is the first key.
+func (sf *SchemaFile) Keys() [][]string {
+	var keys [][]string
+	if sf.PrimaryKey != nil {
+		keys = append(keys, sf.PrimaryKey)
+	}
+	keys = append(keys, sf.UniqueKeys...)
+	for _, index := range sf.Indexes {
+		if index.Unique && !index.Auto {
+			keys = append(keys, index.Columns)
+		}
+	}
+	return keys
+}
+
+// Copy returns a deep copy of this schema file, that shares no slices or maps
+// with this schema file.
+func (sf *SchemaFile) Copy() *SchemaFile {
+	cp := *sf
+	cp.Columns = append([]table.Col(nil), sf.Columns...)
+	if sf.Defaults != nil {
+		cp.Defaults = make(map[string]types.Value, len(sf.Defaults))
+		for name, value := range sf.Defaults {
+			cp.Defaults[name] = value
+		}
+	}
+	cp.PrimaryKey = copyStrings(sf.PrimaryKey)
+	cp.UniqueKeys = nil
+	for _, key := range sf.UniqueKeys {
+		cp.UniqueKeys = append(cp.UniqueKeys, copyStrings(key))
+	}
+	cp.NotNull = copyStrings(sf.NotNull)
+	cp.Checks = append([]Check(nil), sf.Checks...)
+	cp.ForeignKeys = nil
+	for _, fk := range sf.ForeignKeys {
+		fk.Columns = copyStrings(fk.Columns)
+		fk.ForeignColumns = copyStrings(fk.ForeignColumns)
+		cp.ForeignKeys = append(cp.ForeignKeys, fk)
+	}
+	cp.Indexes = nil
+	for _, index := range sf.Indexes {
+		index.Columns = copyStrings(index.Columns)
+		cp.Indexes = append(cp.Indexes, index)
+	}
+	return &cp
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// Check is a CHECK constraint of a table. The expression is stored as SQL
+// source, and is compiled when the constraint is checked.
+type Check struct {
+	// Name is the name of the constraint, or empty, if the constraint is not
+	// named.
+	Name string
+	// Expr is the SQL source of the expression of the constraint.
+	Expr string
+}
+
+// ReferentialAction is the action of a foreign key, that is performed on the
+// referencing rows, when a referenced row is deleted or its key is updated.
+type ReferentialAction string
+
+// Known ReferentialActions.
+const (
+	NoAction ReferentialAction = "no action"
+	Restrict ReferentialAction = "restrict"
+	SetNull  ReferentialAction = "set null"
+	Cascade  ReferentialAction = "cascade"
+)
+
+// ForeignKey is a FOREIGN KEY constraint of a table.
+type ForeignKey struct {
+	// Name is the name of the constraint, or empty, if the constraint is not
+	// named.
+	Name string
+	// Columns are the names of the referencing columns.
+	Columns []string
+	// ForeignTable is the name of the referenced table.
+	ForeignTable string
+	// ForeignColumns are the names of the referenced columns, which are a key
+	// of the referenced table.
+	ForeignColumns []string
+	OnDelete       ReferentialAction
+	OnUpdate       ReferentialAction
+	// Deferred indicates, that the constraint is checked when a transaction
+	// is committed, instead of at the end of every statement.
+	Deferred bool
+}
+
+// Index is a secondary index of a table. The index is a B+tree, whose root is
+// stored in a page of the index file of the table.
+type Index struct {
+	// Name is the name of the index, which is unique across all tables.
+	Name string
+	// Columns are the names of the indexed columns.
+	Columns []string
+	// Unique indicates, that the values of the indexed columns must be
+	// unique across all rows of the table.
+	Unique bool
+	// Auto indicates, that the index was created for the primary key or a
+	// unique key of the table, whose rows are looked up by their key through
+	// the index. The name of such an index is only unique within the table,
+	// and the index is dropped with the table.
+	Auto bool
+	// Root is the ID of the root page of the index.
+	Root page.ID
 }
 
 // schemaYaml is an intermediate structure used for encoding
 // a SchemaFile into yaml.
 type schemaYaml struct {
-	HighestRowID int          `yaml:"highest_row_id"`
-	Columns      []columnYaml `yaml:"columns"`
+	HighestRowID int              `yaml:"highest_row_id"`
+	Columns      []columnYaml     `yaml:"columns"`
+	PrimaryKey   []string         `yaml:"primary_key,omitempty"`
+	UniqueKeys   [][]string       `yaml:"unique_keys,omitempty"`
+	NotNull      []string         `yaml:"not_null,omitempty"`
+	Checks       []checkYaml      `yaml:"checks,omitempty"`
+	ForeignKeys  []foreignKeyYaml `yaml:"foreign_keys,omitempty"`
+	Indexes      []indexYaml      `yaml:"indexes,omitempty"`
+	WithoutRowID bool             `yaml:"without_row_id,omitempty"`
+	Root         page.ID          `yaml:"root,omitempty"`
+}
+
+// indexYaml is an intermediate structure used for encoding
+// an Index into yaml.
+type indexYaml struct {
+	Name    string   `yaml:"name"`
+	Columns []string `yaml:"columns"`
+	Unique  bool     `yaml:"unique,omitempty"`
+	Auto    bool     `yaml:"auto,omitempty"`
+	Root    page.ID  `yaml:"root"`
+}
+
+// foreignKeyYaml is an intermediate structure used for encoding
+// a ForeignKey into yaml.
+type foreignKeyYaml struct {
+	Name           string            `yaml:"name,omitempty"`
+	Columns        []string          `yaml:"columns"`
+	ForeignTable   string            `yaml:"foreign_table"`
+	ForeignColumns []string          `yaml:"foreign_columns"`
+	OnDelete       ReferentialAction `yaml:"on_delete"`
+	OnUpdate       ReferentialAction `yaml:"on_update"`
+	Deferred       bool              `yaml:"deferred,omitempty"`
+}
+
+// checkYaml is an intermediate structure used for encoding
+// a Check into yaml.
+type checkYaml struct {
+	Name string `yaml:"name,omitempty"`
+	Expr string `yaml:"expr"`
 }
 
 // columnYaml is an intermediate structure used for encoding
@@ -31,6 +213,55 @@ type columnYaml struct {
 	QualifiedName string              `yaml:"qualified_name"`
 	Alias         string              `yaml:"alias"`
 	Type          types.TypeIndicator `yaml:"type"`
+	Default       *valueYaml          `yaml:"default,omitempty"`
+}
+
+// valueYaml is an intermediate structure used for encoding
+// a types.Value into yaml. The value is serialized with the
+// serializer of its type.
+type valueYaml struct {
+	Type  types.TypeIndicator `yaml:"type"`
+	Null  bool                `yaml:"null,omitempty"`
+	Value string              `yaml:"value,omitempty"`
+}
+
+func encodeValue(val types.Value) (*valueYaml, error) {
+	vyaml := &valueYaml{
+		Type: types.IndicatorFor(val.Type()),
+		Null: val.IsNull(),
+	}
+	if val.IsNull() {
+		return vyaml, nil
+	}
+	serializer, ok := val.Type().(types.Serializer)
+	if !ok {
+		return nil, fmt.Errorf("type %v is not serializable", val.Type())
+	}
+	serialized, err := serializer.Serialize(val)
+	if err != nil {
+		return nil, fmt.Errorf("serialize: %w", err)
+	}
+	vyaml.Value = base64.StdEncoding.EncodeToString(serialized)
+	return vyaml, nil
+}
+
+func decodeValue(vyaml *valueYaml) (types.Value, error) {
+	typ := types.ByIndicator(vyaml.Type)
+	if typ == nil {
+		return nil, fmt.Errorf("unknown type indicator %v", vyaml.Type)
+	}
+	if vyaml.Null {
+		return types.NewNull(typ), nil
+	}
+	serializer, ok := typ.(types.Serializer)
+	if !ok {
+		return nil, fmt.Errorf("type %v is not deserializable", typ)
+	}
+	serialized, err := base64.StdEncoding.DecodeString(vyaml.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return serializer.Deserialize(serialized)
 }
 
 func (sf *SchemaFile) load(rd io.Reader) error {
@@ -46,13 +277,44 @@ func (sf *SchemaFile) load(rd io.Reader) error {
 	}
 
 	sf.HighestRowID = syaml.HighestRowID
+	sf.PrimaryKey = syaml.PrimaryKey
+	sf.UniqueKeys = syaml.UniqueKeys
+	sf.NotNull = syaml.NotNull
+	sf.Checks = nil
+	for _, check := range syaml.Checks {
+		sf.Checks = append(sf.Checks, Check{
+			Name: check.Name,
+			Expr: check.Expr,
+		})
+	}
+	sf.ForeignKeys = nil
+	for _, foreignKey := range syaml.ForeignKeys {
+		sf.ForeignKeys = append(sf.ForeignKeys, ForeignKey(foreignKey))
+	}
+	sf.Indexes = nil
+	for _, index := range syaml.Indexes {
+		sf.Indexes = append(sf.Indexes, Index(index))
+	}
+	sf.WithoutRowID = syaml.WithoutRowID
+	sf.Root = syaml.Root
 	sf.Columns = nil
+	sf.Defaults = nil
 	for _, column := range syaml.Columns {
 		sf.Columns = append(sf.Columns, table.Col{
 			QualifiedName: column.QualifiedName,
 			Alias:         column.Alias,
 			Type:          types.ByIndicator(column.Type),
 		})
+		if column.Default != nil {
+			defaultValue, err := decodeValue(column.Default)
+			if err != nil {
+				return fmt.Errorf("default of column %v: %w", column.QualifiedName, err)
+			}
+			if sf.Defaults == nil {
+				sf.Defaults = make(map[string]types.Value)
+			}
+			sf.Defaults[column.QualifiedName] = defaultValue
+		}
 	}
 
 	return nil
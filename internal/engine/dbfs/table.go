@@ -32,6 +32,25 @@ func (t Table) DataFile() (*PagedFile, error) {
 	return pf, nil
 }
 
+// IndexFile returns the index file of the table that this object represents,
+// which holds the pages of all indexes of the table. If the file does not
+// exist, because the table was created before indexes were supported, an
+// empty index file is created. Calling this will load a new PagedFile, which
+// is expensive. Cache the returned page if possible.
+func (t Table) IndexFile() (*PagedFile, error) {
+	f, err := t.fs.OpenFile(TableIndexFile, os.O_RDWR|os.O_CREATE, defaultFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("open '%s/%s': %w", t.id.String(), TableIndexFile, err)
+	}
+
+	pf, err := newPagedFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("load paged file '%s/%s': %w", t.id.String(), TableIndexFile, err)
+	}
+
+	return pf, nil
+}
+
 // SchemaFile returns a new schema file which contains information about the table schema.
 func (t Table) SchemaFile() (*SchemaFile, error) {
 	f, err := t.fs.OpenFile(TableSchemaFile, os.O_RDWR, defaultFilePerm)
@@ -0,0 +1,45 @@
+package dbfs
+
+// TriggersInfo is a structured representation of the contents in the triggers.info file.
+type TriggersInfo struct {
+	Triggers map[string]Trigger `yaml:"triggers"`
+}
+
+// TriggerTiming determines, when the statements of a trigger are executed,
+// relative to the change of the row, that fires the trigger.
+type TriggerTiming string
+
+// Known TriggerTimings.
+const (
+	Before    TriggerTiming = "before"
+	After     TriggerTiming = "after"
+	InsteadOf TriggerTiming = "instead of"
+)
+
+// TriggerEvent is the kind of change of a row, that fires a trigger.
+type TriggerEvent string
+
+// Known TriggerEvents.
+const (
+	Delete TriggerEvent = "delete"
+	Insert TriggerEvent = "insert"
+	Update TriggerEvent = "update"
+)
+
+// Trigger is the definition of a single trigger. The condition and the
+// statements of a trigger are stored as SQL text, and are compiled whenever
+// the trigger fires.
+type Trigger struct {
+	// Table is the name of the table or view, whose changes fire the trigger.
+	Table  string        `yaml:"table"`
+	Timing TriggerTiming `yaml:"timing"`
+	Event  TriggerEvent  `yaml:"event"`
+	// Columns are the names of the columns, of which at least one must be
+	// updated to fire an update trigger. If empty, every update fires the
+	// trigger.
+	Columns []string `yaml:"columns,omitempty"`
+	// When is the SQL source of the condition of the trigger, or empty, if
+	// the trigger has no condition.
+	When       string   `yaml:"when,omitempty"`
+	Statements []string `yaml:"statements"`
+}
@@ -0,0 +1,15 @@
+package dbfs
+
+// ViewsInfo is a structured representation of the contents in the views.info file.
+type ViewsInfo struct {
+	Views map[string]View `yaml:"views"`
+}
+
+// View is the definition of a single view. The query of a view is the SQL
+// text of the select statement of the view, and the columns are the names,
+// that are given to the columns of the result of that query. If there are no
+// columns, the columns of the view are named like the columns of the query.
+type View struct {
+	Query   string   `yaml:"query"`
+	Columns []string `yaml:"columns"`
+}
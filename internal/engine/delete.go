@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// evaluateDelete deletes all rows from the table, that match the filter of the
+// given command. If the command has a RETURNING clause, the deleted rows are
+// projected onto its columns, otherwise the returned table holds the amount of
+// deleted rows. The rows are read through the access path, that is chosen for
+// the table and the filter, see (Engine).chooseAccessPath. After the rows were
+// deleted, the foreign keys, that reference the table, are enforced, see
+// (Engine).enforceForeignKeys. The BEFORE DELETE triggers of the table fire
+// for every matching row, before any row is deleted, and the AFTER DELETE
+// triggers fire for every deleted row, after all rows were deleted. If the
+// table is a view, its INSTEAD OF triggers fire instead, see
+// (Engine).deleteFromView.
+func (e Engine) evaluateDelete(ctx ExecutionContext, c command.Delete) (table.Table, error) {
+	defer e.profiler.Enter("delete").Exit()
+
+	if err := ensureFilter(c.Filter); err != nil {
+		return nil, err
+	}
+
+	if query, view, ok, err := viewQuery(ctx, c.Table.QualifiedName()); err != nil {
+		return nil, err
+	} else if ok {
+		return e.deleteFromView(ctx, c, query, view)
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, c.Table.QualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("load table: %w", err)
+	}
+	tbl, ok := loaded.(*Table)
+	if !ok {
+		return nil, fmt.Errorf("cannot delete from table %v", c.Table.QualifiedName())
+	}
+
+	// tables, that are not simple tables, don't hold any index hints
+	simple, _ := c.Table.(command.SimpleTable)
+	path, err := e.chooseAccessPath(ctx, tbl, simple, c.Filter)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := e.selectRecordsThrough(ctx, tbl, path, c.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := tbl.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	triggers, err := triggersOn(ctx, c.Table.QualifiedName(), dbfs.Delete)
+	if err != nil {
+		return nil, err
+	}
+	var deletes []record
+	for _, rec := range selected {
+		if ignored, err := e.fireTriggers(ctx, triggers, dbfs.Before, cols, rowChange{old: rec.row}, nil); err != nil {
+			return nil, err
+		} else if !ignored {
+			deletes = append(deletes, rec)
+		}
+	}
+
+	// records are deleted after the iteration, since deleting a cell
+	// changes the slots of the page that is being iterated over
+	changes := make([]rowChange, len(deletes))
+	for i, rec := range deletes {
+		if err := tbl.deleteRecord(rec); err != nil {
+			return nil, fmt.Errorf("delete record: %w", err)
+		}
+		changes[i] = rowChange{old: rec.row}
+	}
+	if err := e.enforceForeignKeys(ctx, c.Table.QualifiedName(), changes); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if _, err := e.fireTriggers(ctx, triggers, dbfs.After, cols, change, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.Returning) != 0 {
+		rows := make([]table.Row, len(deletes))
+		for i, rec := range deletes {
+			rows[i] = rec.row
+		}
+		return e.evaluateReturning(ctx, cols, rows, c.Returning)
+	}
+	return affectedRows(len(deletes)), nil
+}
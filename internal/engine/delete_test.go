@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestDeleteSuite(t *testing.T) {
+	suite.Run(t, new(DeleteSuite))
+}
+
+type DeleteSuite struct {
+	EngineSuite
+}
+
+func (suite *DeleteSuite) TestDeleteWithFilter() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "a"), (2, "b"), (3, "c")`)
+
+	result, err := suite.engine.evaluateDelete(suite.ctx, command.Delete{
+		Table: command.SimpleTable{Table: "myTable"},
+		Filter: command.GreaterThanOrEqualToExpr{
+			BinaryBase: command.BinaryBase{
+				Left:  command.ColumnReference{Name: "id"},
+				Right: command.ConstantLiteral{Value: "2", Numeric: true},
+			},
+		},
+	})
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(2), result)
+
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		},
+	), suite.scan("myTable"))
+}
+
+func (suite *DeleteSuite) TestDeleteAll() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "a"), (2, "b"), (3, "c")`)
+
+	result, err := suite.engine.evaluateDelete(suite.ctx, command.Delete{
+		Table:  command.SimpleTable{Table: "myTable"},
+		Filter: command.ConstantBooleanExpr{Value: true},
+	})
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(3), result)
+
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+		},
+		nil,
+	), suite.scan("myTable"))
+}
+
+func (suite *DeleteSuite) TestDeleteUnknownTable() {
+	_, err := suite.engine.evaluateDelete(suite.ctx, command.Delete{
+		Table:  command.SimpleTable{Table: "myTable"},
+		Filter: command.ConstantBooleanExpr{Value: true},
+	})
+	suite.EqualError(err, "load table: table 'myTable' does not exist")
+}
+
+func (suite *DeleteSuite) TestDeletedSpaceIsReused() {
+	suite.RunScript(`CREATE TABLE myTable (id INTEGER, name STRING)`)
+
+	long := make([]byte, 5000)
+	for i := range long {
+		long[i] = 'x'
+	}
+
+	tbl, err := suite.engine.LoadTable(suite.ctx.tx, "myTable")
+	suite.Require().NoError(err)
+	// three rows fit into a single page
+	for i := 1; i <= 3; i++ {
+		suite.NoError(tbl.(*Table).Insert(table.Row{Values: []types.Value{types.NewInteger(int64(i)), types.NewString(string(long))}}))
+	}
+	pages, err := suite.ctx.tx.ExistingDataPagesForTable("myTable")
+	suite.NoError(err)
+	suite.Len(pages, 1)
+
+	_, err = suite.engine.evaluateDelete(suite.ctx, command.Delete{
+		Table: command.SimpleTable{Table: "myTable"},
+		Filter: command.EqualityExpr{
+			BinaryBase: command.BinaryBase{
+				Left:  command.ColumnReference{Name: "id"},
+				Right: command.ConstantLiteral{Value: "2", Numeric: true},
+			},
+		},
+	})
+	suite.NoError(err)
+
+	// the new row must be stored in the space of the deleted row
+	suite.NoError(tbl.(*Table).Insert(table.Row{Values: []types.Value{types.NewInteger(4), types.NewString(string(long))}}))
+	pages, err = suite.ctx.tx.ExistingDataPagesForTable("myTable")
+	suite.NoError(err)
+	suite.Len(pages, 1)
+
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewString(string(long))}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString(string(long))}},
+			{Values: []types.Value{types.NewInteger(4), types.NewString(string(long))}},
+		},
+	), suite.scan("myTable"))
+}
+
+func (suite *DeleteSuite) scan(name string) table.Table {
+	tbl, err := suite.engine.evaluateScan(suite.ctx, command.Scan{
+		Table: command.SimpleTable{Table: name},
+	})
+	suite.Require().NoError(err)
+	return tbl
+}
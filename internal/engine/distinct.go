@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+const (
+	// distinctPartitions is the amount of temporary files, that rows are
+	// partitioned into, if the distinct memory budget is exceeded.
+	distinctPartitions = 16
+)
+
+// evaluateDistinct evaluates the given distinct command. Duplicate rows are
+// removed while the rows are streamed from the input list, so that the first
+// occurrence of every row is returned as soon as it is encountered. Rows are
+// considered duplicates, if all their values are equal, where two NULL values
+// of the same type are also considered equal.
+func (e Engine) evaluateDistinct(ctx ExecutionContext, distinct command.Distinct) (table.Table, error) {
+	defer e.profiler.Enter("distinct").Exit()
+
+	origin, err := e.evaluateList(ctx, distinct.Input)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	cols, err := origin.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	return distinctTable{
+		origin: origin,
+		cols:   cols,
+		e:      e,
+	}, nil
+}
+
+// hashValues computes a hash over the types and values of the given values,
+// which is consistent with (Engine).valuesEqual, meaning that equal values have
+// the same hash. The seed is part of the hash, so that different seeds
+// distribute the same values differently.
+func hashValues(seed int, vals []types.Value) uint64 {
+	h := fnv.New64a()
+	seedBytes := make([]byte, 8)
+	byteOrder.PutUint64(seedBytes, uint64(seed))
+	_, _ = h.Write(seedBytes)
+	for _, val := range vals {
+		_, _ = h.Write([]byte(val.Type().Name()))
+		if val.IsNull() {
+			_, _ = h.Write([]byte{1})
+			continue
+		}
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(val.String()))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// valuesEqual checks if all given left values are equal to the right values,
+// according to the comparators of their types. Two NULL values of the same
+// type are considered equal.
+func (e Engine) valuesEqual(left, right []types.Value) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		l, r := left[i], right[i]
+		if l.IsNull() || r.IsNull() {
+			if !(l.IsNull() && r.IsNull() && r.Is(l.Type())) {
+				return false
+			}
+			continue
+		}
+		if !e.eq(l, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// distinctTable is a table, which contains the rows of an underlying table,
+// without duplicate rows.
+type distinctTable struct {
+	origin table.Table
+	cols   []table.Col
+	e      Engine
+}
+
+// Cols returns the columns of the underlying table.
+func (t distinctTable) Cols() ([]table.Col, error) {
+	return t.cols, nil
+}
+
+// Rows returns a row iterator of the distinct table, which removes duplicate
+// rows while iterating over the underlying table.
+func (t distinctTable) Rows() (table.RowIterator, error) {
+	return t.createIterator()
+}
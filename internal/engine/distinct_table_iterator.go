@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// distinctTableIterator removes duplicate rows from an underlying iterator.
+// Rows that were already encountered are kept in a hash set. If the set
+// exceeds the distinct memory budget, no more rows are added to it. Instead,
+// rows that are not in the set are partitioned by their hash into temporary
+// files. Since duplicates always end up in the same partition, every
+// partition is deduplicated on its own after the underlying iterator is
+// drained, in the same way, but with a different hash seed.
+type distinctTableIterator struct {
+	table      distinctTable
+	underlying table.RowIterator
+
+	// seen holds the rows that were already returned in the current pass,
+	// bucketed by their hash.
+	seen map[uint64][][]types.Value
+	size int
+	// level is the hash seed of the current pass. The underlying iterator is
+	// read in level 0, spilled partitions are read in higher levels.
+	level int
+
+	// source is the partition that is read in the current pass, or nil, if
+	// the underlying iterator is read.
+	source *spillFile
+	// partitions are the partitions, that rows of the current pass are
+	// spilled to, or nil, if the memory budget was not exceeded yet.
+	partitions []*spillFile
+	// pending are the spilled partitions, that still have to be read.
+	pending []distinctPartition
+}
+
+// distinctPartition is a spilled partition of rows, together with the level of
+// the pass, in which the partition will be read.
+type distinctPartition struct {
+	file  *spillFile
+	level int
+}
+
+func (t distinctTable) createIterator() (*distinctTableIterator, error) {
+	underlying, err := t.origin.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &distinctTableIterator{
+		table:      t,
+		underlying: underlying,
+		seen:       make(map[uint64][][]types.Value),
+	}, nil
+}
+
+// Next returns the next row, that was not returned by this iterator yet.
+func (i *distinctTableIterator) Next() (table.Row, error) {
+	for {
+		next, err := i.nextFromSource()
+		if err == table.ErrEOT {
+			ok, err := i.nextPass()
+			if err != nil {
+				return table.Row{}, err
+			}
+			if !ok {
+				return table.Row{}, table.ErrEOT
+			}
+			continue
+		} else if err != nil {
+			return table.Row{}, err
+		}
+
+		hash := hashValues(i.level, next.Values)
+		if i.contains(hash, next.Values) {
+			continue
+		}
+
+		if i.partitions != nil {
+			partition := i.partitions[hash%distinctPartitions]
+			if err := partition.write(next.Values); err != nil {
+				return table.Row{}, fmt.Errorf("spill: %w", err)
+			}
+			continue
+		}
+
+		i.seen[hash] = append(i.seen[hash], next.Values)
+		i.size += approximateSize(next.Values)
+		if i.size > i.table.e.distinctMemoryBudget {
+			if err := i.createPartitions(); err != nil {
+				return table.Row{}, err
+			}
+		}
+		return next, nil
+	}
+}
+
+// Reset resets this table iterator. All spilled partitions are removed and
+// the underlying iterator is read again from the beginning.
+func (i *distinctTableIterator) Reset() error {
+	if err := i.removePartitions(); err != nil {
+		return err
+	}
+	i.seen = make(map[uint64][][]types.Value)
+	i.size = 0
+	i.level = 0
+	return i.underlying.Reset()
+}
+
+// Close removes all spilled partitions and closes the underlying iterator.
+func (i *distinctTableIterator) Close() error {
+	if err := i.removePartitions(); err != nil {
+		return err
+	}
+	return i.underlying.Close()
+}
+
+func (i *distinctTableIterator) contains(hash uint64, vals []types.Value) bool {
+	return i.table.e.containsValues(i.seen[hash], vals)
+}
+
+// nextFromSource reads the next row from the partition of the current pass, or
+// from the underlying iterator, if no partition is read.
+func (i *distinctTableIterator) nextFromSource() (table.Row, error) {
+	if i.source == nil {
+		return i.underlying.Next()
+	}
+
+	vals, err := i.source.read()
+	if err == io.EOF {
+		return table.Row{}, table.ErrEOT
+	} else if err != nil {
+		return table.Row{}, fmt.Errorf("read partition: %w", err)
+	}
+	return table.Row{Values: vals}, nil
+}
+
+// createPartitions creates the temporary files, that rows are spilled to for
+// the rest of the current pass.
+func (i *distinctTableIterator) createPartitions() error {
+	defer i.table.e.profiler.Enter("distinct (spill)").Exit()
+
+	i.partitions = make([]*spillFile, distinctPartitions)
+	for p := range i.partitions {
+		partition, err := i.table.e.newSpillFile()
+		if err != nil {
+			return fmt.Errorf("create partition: %w", err)
+		}
+		i.partitions[p] = partition
+	}
+	return nil
+}
+
+// nextPass finishes the current pass and starts reading the next pending
+// partition. If there are no more pending partitions, false is returned.
+func (i *distinctTableIterator) nextPass() (bool, error) {
+	if i.source != nil {
+		if err := i.source.remove(); err != nil {
+			return false, fmt.Errorf("remove partition: %w", err)
+		}
+		i.source = nil
+	}
+
+	for _, partition := range i.partitions {
+		if err := partition.rewind(); err != nil {
+			return false, fmt.Errorf("rewind: %w", err)
+		}
+		i.pending = append(i.pending, distinctPartition{
+			file:  partition,
+			level: i.level + 1,
+		})
+	}
+	i.partitions = nil
+
+	if len(i.pending) == 0 {
+		return false, nil
+	}
+
+	next := i.pending[0]
+	i.pending = i.pending[1:]
+	i.source = next.file
+	i.level = next.level
+	i.seen = make(map[uint64][][]types.Value)
+	i.size = 0
+	return true, nil
+}
+
+// removePartitions removes the temporary files of all partitions.
+func (i *distinctTableIterator) removePartitions() error {
+	files := make([]*spillFile, 0, len(i.partitions)+len(i.pending)+1)
+	if i.source != nil {
+		files = append(files, i.source)
+	}
+	files = append(files, i.partitions...)
+	for _, partition := range i.pending {
+		files = append(files, partition.file)
+	}
+
+	var firstErr error
+	for _, file := range files {
+		if err := file.remove(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	i.source = nil
+	i.partitions = nil
+	i.pending = nil
+	return firstErr
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestDistinctSuite(t *testing.T) {
+	suite.Run(t, new(DistinctSuite))
+}
+
+type DistinctSuite struct {
+	EngineSuite
+}
+
+func (suite *DistinctSuite) TestDistinct() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "a"), (2, "a"), (1, "a"), (1, "b"), (2, "a")`)
+
+	result, err := suite.engine.evaluateDistinct(suite.ctx, command.Distinct{
+		Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.NoError(err)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(1), types.NewString("b")}},
+	}, suite.rows(result))
+}
+
+func (suite *DistinctSuite) TestDistinctNulls() {
+	tbl := distinctTable{
+		origin: table.NewInMemory(
+			[]table.Col{{QualifiedName: "col1", Type: types.Integer}},
+			[]table.Row{
+				{Values: []types.Value{types.NewNull(types.Integer)}},
+				{Values: []types.Value{types.NewInteger(1)}},
+				{Values: []types.Value{types.NewNull(types.Integer)}},
+			},
+		),
+		e: suite.engine,
+	}
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewNull(types.Integer)}},
+		{Values: []types.Value{types.NewInteger(1)}},
+	}, suite.rows(tbl))
+}
+
+func (suite *DistinctSuite) TestDistinctWithSpill() {
+	fs := afero.NewMemMapFs()
+	dbfs, err := dbfs.CreateNew(fs)
+	suite.Require().NoError(err)
+	// a budget of 100 bytes holds only a few rows, so that
+	// most rows are partitioned, and partitions are partitioned again
+	e, err := New(dbfs, WithDistinctMemoryBudget(100))
+	suite.Require().NoError(err)
+
+	var rows []table.Row
+	for i := 0; i < 300; i++ {
+		rows = append(rows, table.Row{Values: []types.Value{types.NewInteger(int64((i * 37) % 100))}})
+	}
+	tbl := distinctTable{
+		origin: table.NewInMemory([]table.Col{{QualifiedName: "id", Type: types.Integer}}, rows),
+		e:      e,
+	}
+
+	it, err := tbl.createIterator()
+	suite.Require().NoError(err)
+	seen := make(map[int64]bool)
+	for {
+		next, err := it.Next()
+		if err == table.ErrEOT {
+			break
+		}
+		suite.Require().NoError(err)
+		id := next.Values[0].(types.IntegerValue).Value
+		suite.False(seen[id], "duplicate row %v", id)
+		seen[id] = true
+	}
+	suite.Len(seen, 100)
+	suite.Greater(it.level, 1)
+
+	// all partitions are removed once the iterator is drained
+	files, err := afero.ReadDir(fs, "tmp")
+	suite.NoError(err)
+	suite.Empty(files)
+	suite.NoError(it.Close())
+}
@@ -20,6 +20,15 @@ var (
 	byteOrder = binary.BigEndian
 )
 
+const (
+	// defaultSortMemoryBudget is the default amount of bytes, that rows may
+	// occupy in memory while sorting, before they are spilled to disk.
+	defaultSortMemoryBudget = 16 << 20
+	// defaultDistinctMemoryBudget is the default amount of bytes, that
+	// distinct rows may occupy in memory, before rows are spilled to disk.
+	defaultDistinctMemoryBudget = 16 << 20
+)
+
 type timeProvider func() time.Time
 type randomProvider func() int64
 
@@ -28,9 +37,15 @@ type Engine struct {
 	log      zerolog.Logger
 	profiler *profile.Profiler
 	txmgr    transaction.Manager
-
-	timeProvider   timeProvider
-	randomProvider randomProvider
+	dbfs     *dbfs.DBFS
+
+	timeProvider         timeProvider
+	randomProvider       randomProvider
+	sortMemoryBudget     int
+	distinctMemoryBudget int
+	// deferForeignKeys indicates, that all foreign keys are checked when a
+	// transaction is committed, see WithDeferredForeignKeys.
+	deferForeignKeys bool
 }
 
 // New creates a new engine object and applies the given options to it.
@@ -40,7 +55,8 @@ func New(dbfs *dbfs.DBFS, opts ...Option) (Engine, error) {
 	}
 
 	e := Engine{
-		log: zerolog.Nop(),
+		log:  zerolog.Nop(),
+		dbfs: dbfs,
 
 		timeProvider: time.Now,
 		randomProvider: func() int64 {
@@ -48,6 +64,8 @@ func New(dbfs *dbfs.DBFS, opts ...Option) (Engine, error) {
 			_, _ = rand.Read(buf)
 			return int64(byteOrder.Uint64(buf))
 		},
+		sortMemoryBudget:     defaultSortMemoryBudget,
+		distinctMemoryBudget: defaultDistinctMemoryBudget,
 	}
 	for _, opt := range opts {
 		opt(&e)
@@ -69,11 +87,8 @@ func (e Engine) Evaluate(cmd command.Command) (table.Table, error) {
 	_ = e.gt
 	_ = e.lteq
 	_ = e.gteq
-	_ = e.builtinCount
 	_ = e.builtinUCase
 	_ = e.builtinLCase
-	_ = e.builtinMin
-	_ = e.builtinMax
 
 	tx, err := e.txmgr.Start()
 	if err != nil {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -83,6 +84,23 @@ func (suite *EngineSuite) EqualTables(expected, got table.Table) {
 	}
 }
 
+// rows returns all rows of the given table, in the order in which they are
+// returned by the iterator of the table.
+func (suite *EngineSuite) rows(tbl table.Table) []table.Row {
+	it, err := tbl.Rows()
+	suite.Require().NoError(err)
+
+	var rows []table.Row
+	for {
+		next, err := it.Next()
+		if err == table.ErrEOT {
+			return rows
+		}
+		suite.Require().NoError(err)
+		rows = append(rows, next)
+	}
+}
+
 // RunScript will run the given SQL script, which is useful for setting up a minimalistic
 // database for a test.
 // The test will fail if the script is incorrect or results in errors.
@@ -106,3 +124,77 @@ func (suite *EngineSuite) RunScript(sqlScript string) {
 		suite.NoError(err)
 	}
 }
+
+// evaluateStatement compiles the given statement and evaluates it in the
+// transaction of this suite.
+func (suite *EngineSuite) evaluateStatement(stmt string) (table.Table, error) {
+	p, err := parser.New(stmt)
+	suite.Require().NoError(err)
+	next, errs, ok := p.Next()
+	suite.Require().True(ok)
+	suite.Require().Len(errs, 0)
+
+	cmd, err := compiler.New().Compile(next)
+	suite.Require().NoError(err)
+
+	return suite.engine.evaluate(suite.ctx, cmd)
+}
+
+// exec evaluates the given statement in the transaction of this suite, and
+// discards the result.
+func (suite *EngineSuite) exec(stmt string) error {
+	_, err := suite.evaluateStatement(stmt)
+	return err
+}
+
+// selectRows evaluates the given query in the transaction of this suite, and
+// returns all rows of the result.
+func (suite *EngineSuite) selectRows(query string) []table.Row {
+	tbl, err := suite.evaluateStatement(query)
+	suite.Require().NoError(err)
+	return suite.rows(tbl)
+}
+
+// commit commits the transaction of this suite, and starts a new one, so that
+// the following statements read the committed tables from disk.
+func (suite *EngineSuite) commit() {
+	suite.Require().NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+	tx, err := suite.engine.txmgr.Start()
+	suite.Require().NoError(err)
+	suite.ctx = newEmptyExecutionContext(tx)
+}
+
+// createMyTable creates the table myTable with the given column definitions,
+// and inserts the given rows, which are given as the VALUES of an INSERT
+// statement.
+func (suite *EngineSuite) createMyTable(columnDefs, values string) {
+	suite.Require().NoError(suite.exec(`CREATE TABLE myTable (` + columnDefs + `)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO myTable VALUES ` + values))
+}
+
+// scanMyTable returns all rows of the table myTable, in the order in which
+// they are scanned.
+func (suite *EngineSuite) scanMyTable() []table.Row {
+	return suite.selectRows(`SELECT * FROM myTable`)
+}
+
+// createItems creates the table items with the given amount of rows. The i-th
+// row has the id i, the group i%3 and the name "item<i>". If clustered is
+// true, the table is clustered by its id.
+func (suite *EngineSuite) createItems(n int, clustered bool) {
+	stmt := `CREATE TABLE items (id INTEGER, grp INTEGER, name STRING)`
+	if clustered {
+		stmt = `CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING) WITHOUT ROWID`
+	}
+	suite.Require().NoError(suite.exec(stmt))
+	for i := 0; i < n; i++ {
+		suite.Require().NoError(suite.exec(fmt.Sprintf(`INSERT INTO items VALUES (%d, %d, 'item%d')`, i, i%3, i)))
+	}
+}
+
+// items returns the table items.
+func (suite *EngineSuite) items() *Table {
+	loaded, err := suite.engine.LoadTable(suite.ctx.tx, "items")
+	suite.Require().NoError(err)
+	return loaded.(*Table)
+}
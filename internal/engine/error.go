@@ -21,6 +21,35 @@ const (
 	// ErrAlreadyExists indicates, that whatever was meant to be created, already
 	// exists, and therefore, the new thing cannot be created.
 	ErrAlreadyExists Error = "already exists"
+	// ErrNoSuchTable indicates, that a table that was referenced by a command
+	// does not exist.
+	ErrNoSuchTable Error = "no such table"
+	// ErrNoSuchView indicates, that a view that was referenced by a command
+	// does not exist.
+	ErrNoSuchView Error = "no such view"
+	// ErrNoSuchTrigger indicates, that a trigger that was referenced by a
+	// command does not exist.
+	ErrNoSuchTrigger Error = "no such trigger"
+	// ErrNoSuchIndex indicates, that an index that was referenced by a command
+	// does not exist.
+	ErrNoSuchIndex Error = "no such index"
+	// ErrUniqueViolation indicates, that a row holds the same values in the
+	// columns of a key of a table, as another row of that table.
+	ErrUniqueViolation Error = "unique constraint violation"
+	// ErrNotNullViolation indicates, that a row holds a NULL value in a column,
+	// that must not hold NULL values, such as a NOT NULL column or a column of
+	// the primary key.
+	ErrNotNullViolation Error = "not null constraint violation"
+	// ErrCheckViolation indicates, that the expression of a CHECK constraint
+	// evaluates to false for a row.
+	ErrCheckViolation Error = "check constraint violation"
+	// ErrForeignKeyViolation indicates, that a row references a row of another
+	// table through a foreign key, that does not exist, or that a row that is
+	// referenced by another row was deleted or updated.
+	ErrForeignKeyViolation Error = "foreign key constraint violation"
+	// ErrRaise indicates, that the statements of a trigger evaluated a RAISE
+	// function, which aborted the statement, that fired the trigger.
+	ErrRaise Error = "raise"
 )
 
 // ErrNoSuchFunction returns an error indicating that a function with the given
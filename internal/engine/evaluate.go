@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/xqueries/xdb/internal/compiler/command"
 	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/transaction"
 )
 
 func (e Engine) evaluate(ctx ExecutionContext, c command.Command) (table.Table, error) {
@@ -12,21 +14,156 @@ func (e Engine) evaluate(ctx ExecutionContext, c command.Command) (table.Table,
 	case command.List:
 		return e.evaluateList(ctx, cmd)
 	case command.CreateTable:
-		tbl, err := e.evaluateCreateTable(ctx, cmd)
+		tbl, err := e.evaluateAtomically(ctx, func() (table.Table, error) {
+			return e.evaluateCreateTable(ctx, cmd)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("create table: %w", err)
 		}
 		return tbl, nil
+	case command.DropTable:
+		tbl, err := e.evaluateAtomically(ctx, func() (table.Table, error) {
+			return e.evaluateDropTable(ctx, cmd)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("drop table: %w", err)
+		}
+		return tbl, nil
+	case command.CreateIndex:
+		tbl, err := e.evaluateCreateIndex(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("create index: %w", err)
+		}
+		return tbl, nil
+	case command.DropIndex:
+		tbl, err := e.evaluateDropIndex(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("drop index: %w", err)
+		}
+		return tbl, nil
+	case command.CreateView:
+		tbl, err := e.evaluateCreateView(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("create view: %w", err)
+		}
+		return tbl, nil
+	case command.DropView:
+		tbl, err := e.evaluateDropView(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("drop view: %w", err)
+		}
+		return tbl, nil
+	case command.CreateTrigger:
+		tbl, err := e.evaluateCreateTrigger(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("create trigger: %w", err)
+		}
+		return tbl, nil
+	case command.DropTrigger:
+		tbl, err := e.evaluateDropTrigger(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("drop trigger: %w", err)
+		}
+		return tbl, nil
+	case command.AddColumn:
+		tbl, err := e.evaluateAddColumn(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("add column: %w", err)
+		}
+		return tbl, nil
+	case command.RenameColumn:
+		tbl, err := e.evaluateRenameColumn(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("rename column: %w", err)
+		}
+		return tbl, nil
+	case command.RenameTable:
+		tbl, err := e.evaluateRenameTable(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("rename table: %w", err)
+		}
+		return tbl, nil
+	case command.DropColumn:
+		tbl, err := e.evaluateDropColumn(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("drop column: %w", err)
+		}
+		return tbl, nil
 	case command.Insert:
-		tbl, err := e.evaluateInsert(ctx, cmd)
+		tbl, err := e.evaluateAtomically(ctx, func() (table.Table, error) {
+			return e.evaluateInsert(ctx, cmd)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("insert into %v: %w", cmd.Table.QualifiedName(), err)
 		}
 		return tbl, nil
+	case command.Update:
+		tbl, err := e.evaluateAtomically(ctx, func() (table.Table, error) {
+			return e.evaluateUpdate(ctx, cmd)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("update %v: %w", cmd.Table.QualifiedName(), err)
+		}
+		return tbl, nil
+	case command.Delete:
+		tbl, err := e.evaluateAtomically(ctx, func() (table.Table, error) {
+			return e.evaluateDelete(ctx, cmd)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("delete from %v: %w", cmd.Table.QualifiedName(), err)
+		}
+		return tbl, nil
+	case command.Explain:
+		tbl, err := e.evaluateExplain(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("explain: %w", err)
+		}
+		return tbl, nil
 	}
 	return nil, ErrUnimplemented(c)
 }
 
+// keptChanges wraps the error of a statement, whose changes, that were made
+// prior to the error, are kept, as for a conflict of an UPDATE OR FAIL.
+type keptChanges struct {
+	error
+}
+
+func (k keptChanges) Unwrap() error { return k.error }
+
+// evaluateAtomically evaluates a statement, that modifies the rows of tables,
+// with the given function. If the statement fails, all changes that it made,
+// including the changes of the triggers that it fired, are undone, unless the
+// transaction was rolled back, or the error is wrapped in keptChanges.
+func (e Engine) evaluateAtomically(ctx ExecutionContext, evaluate func() (table.Table, error)) (table.Table, error) {
+	ctx.tx.Savepoint()
+	tbl, err := evaluate()
+	if ctx.tx.State() != transaction.StatePending {
+		return tbl, err
+	}
+
+	var kept keptChanges
+	if err == nil || errors.As(err, &kept) {
+		if releaseErr := ctx.tx.ReleaseSavepoint(); releaseErr != nil {
+			return nil, fmt.Errorf("release savepoint: %w", releaseErr)
+		}
+		return tbl, err
+	}
+	if rollbackErr := ctx.tx.RollbackToSavepoint(); rollbackErr != nil {
+		return nil, fmt.Errorf("rollback to savepoint: %w (%v)", rollbackErr, err)
+	}
+	return nil, err
+}
+
+// rollback rolls back the transaction of the given context, because of the
+// given error, which is returned.
+func (e Engine) rollback(ctx ExecutionContext, cause error) error {
+	if err := e.txmgr.Rollback(ctx.tx); err != nil {
+		return fmt.Errorf("rollback: %w (%v)", err, cause)
+	}
+	return cause
+}
+
 func (e Engine) evaluateList(ctx ExecutionContext, l command.List) (table.Table, error) {
 	switch list := l.(type) {
 	case command.Values:
@@ -45,6 +182,30 @@ func (e Engine) evaluateList(ctx ExecutionContext, l command.List) (table.Table,
 		return e.evaluateProjection(ctx, list)
 	case command.Select:
 		return e.evaluateSelection(ctx, list)
+	case command.Join:
+		joined, err := e.evaluateJoin(ctx, list)
+		if err != nil {
+			return nil, fmt.Errorf("join: %w", err)
+		}
+		return joined, nil
+	case command.Compound:
+		compound, err := e.evaluateCompound(ctx, list)
+		if err != nil {
+			return nil, fmt.Errorf("compound: %w", err)
+		}
+		return compound, nil
+	case command.Limit:
+		return e.evaluateLimit(ctx, list)
+	case command.Offset:
+		return e.evaluateOffset(ctx, list)
+	case command.Sort:
+		return e.evaluateSort(ctx, list)
+	case command.Distinct:
+		return e.evaluateDistinct(ctx, list)
+	case command.Aggregate:
+		return e.evaluateAggregate(ctx, list)
+	case command.With:
+		return e.evaluateWith(ctx, list)
 	}
 	return nil, ErrUnimplemented(l)
 }
@@ -0,0 +1,492 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// explainNode is a node of an explained command tree.
+type explainNode struct {
+	// id is the 1-based pre-order index of this node in the command tree.
+	id int
+	// parent is the id of the parent node, or 0 for the root node.
+	parent        int
+	depth         int
+	operator      string
+	arguments     string
+	estimatedRows int64
+}
+
+// evaluateExplain evaluates the given explain command. The explained command
+// is not executed. Instead, the result contains one row per node of the
+// command tree, in pre-order, with the depth of the node, the operator, its
+// arguments and an estimation of the amount of rows that the node produces.
+// If the query plan is explained, the result contains one row per node with
+// the id of the node, the id of its parent and a description of the node,
+// followed by one row per optimization, that was applied to the command.
+func (e Engine) evaluateExplain(ctx ExecutionContext, explain command.Explain) (table.Table, error) {
+	defer e.profiler.Enter("explain").Exit()
+
+	var nodes []explainNode
+	if _, err := e.explainCommand(ctx, explain.Command, nil, 0, 0, &nodes); err != nil {
+		return nil, err
+	}
+
+	if explain.QueryPlan {
+		return explainQueryPlan(nodes, explain.Optimizations), nil
+	}
+
+	rows := make([]table.Row, len(nodes))
+	for i, node := range nodes {
+		rows[i] = table.Row{
+			Values: []types.Value{
+				types.NewInteger(int64(node.depth)),
+				types.NewString(node.operator),
+				types.NewString(node.arguments),
+				types.NewInteger(node.estimatedRows),
+			},
+		}
+	}
+	return table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "depth", Type: types.Integer},
+			{QualifiedName: "operator", Type: types.String},
+			{QualifiedName: "arguments", Type: types.String},
+			{QualifiedName: "estimated_rows", Type: types.Integer},
+		},
+		rows,
+	), nil
+}
+
+func explainQueryPlan(nodes []explainNode, optimizations []string) table.Table {
+	var rows []table.Row
+	for _, node := range nodes {
+		detail := node.operator
+		if node.arguments != "" {
+			detail += " " + node.arguments
+		}
+		rows = append(rows, table.Row{
+			Values: []types.Value{
+				types.NewInteger(int64(node.id)),
+				types.NewInteger(int64(node.parent)),
+				types.NewString(detail),
+			},
+		})
+	}
+	for i, optimization := range optimizations {
+		rows = append(rows, table.Row{
+			Values: []types.Value{
+				types.NewInteger(int64(len(nodes) + i + 1)),
+				types.NewInteger(0),
+				types.NewString("OPTIMIZATION " + optimization),
+			},
+		})
+	}
+	return table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "parent", Type: types.Integer},
+			{QualifiedName: "detail", Type: types.String},
+		},
+		rows,
+	)
+}
+
+// explainCommand appends the node of the given command and the nodes of all
+// its inputs to the given nodes, and returns the estimated amount of rows,
+// that the command produces. If the command is a scan, whose rows are filtered
+// by its parent, the filter of the parent is given, so that the access path of
+// the scan can be explained, see (Engine).describeAccessPath.
+func (e Engine) explainCommand(ctx ExecutionContext, cmd command.Command, filter command.Expr, depth, parent int, nodes *[]explainNode) (int64, error) {
+	operator, args, inputs := describeCommand(cmd)
+	switch c := cmd.(type) {
+	case command.Scan:
+		if simple, ok := c.Table.(command.SimpleTable); ok {
+			pathArgs, search, err := e.describeAccessPath(ctx, simple, filter)
+			if err != nil {
+				return 0, err
+			}
+			if search {
+				operator = "Search"
+			}
+			args = append(args, pathArgs...)
+		}
+	case command.Update:
+		if simple, ok := c.Table.(command.SimpleTable); ok {
+			pathArgs, _, err := e.describeAccessPath(ctx, simple, c.Filter)
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, pathArgs...)
+		}
+	case command.Delete:
+		if simple, ok := c.Table.(command.SimpleTable); ok {
+			pathArgs, _, err := e.describeAccessPath(ctx, simple, c.Filter)
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, pathArgs...)
+		}
+	}
+	index := len(*nodes)
+	*nodes = append(*nodes, explainNode{
+		id:        index + 1,
+		parent:    parent,
+		depth:     depth,
+		operator:  operator,
+		arguments: strings.Join(args, ","),
+	})
+
+	with, isWith := cmd.(command.With)
+	inputRows := make([]int64, len(inputs))
+	for i, input := range inputs {
+		inputCtx := ctx
+		if isWith && i < len(with.Tables) {
+			// a common table is available to the following inputs, and a
+			// recursive common table also to its own input
+			next := ctx.withCommonTable(&commonTable{
+				CommonTable: with.Tables[i],
+				ctx:         ctx,
+			})
+			if _, ok := input.(command.Recursion); ok {
+				inputCtx = next
+			}
+			ctx = next
+		}
+		var inputFilter command.Expr
+		if sel, ok := cmd.(command.Select); ok {
+			// the filter may be evaluated with an index of a scanned table
+			inputFilter = sel.Filter
+		}
+		estimated, err := e.explainCommand(inputCtx, input, inputFilter, depth+1, index+1, nodes)
+		if err != nil {
+			return 0, err
+		}
+		inputRows[i] = estimated
+	}
+
+	estimated, err := e.estimateRows(ctx, cmd, inputRows)
+	if err != nil {
+		return 0, fmt.Errorf("estimate %v: %w", operator, err)
+	}
+	(*nodes)[index].estimatedRows = estimated
+	return estimated, nil
+}
+
+// describeCommand returns the name of the operator of the given command, its
+// arguments, and the input commands of the command.
+func describeCommand(cmd command.Command) (string, []string, []command.Command) {
+	switch c := cmd.(type) {
+	case command.Scan:
+		if derived, ok := c.Table.(command.DerivedTable); ok {
+			// the query of a derived table is explained as input of the scan
+			var args []string
+			if derived.Alias != "" {
+				args = append(args, "alias="+derived.Alias)
+			}
+			return "Scan", args, []command.Command{derived.Input}
+		}
+		return "Scan", []string{fmt.Sprintf("table=%v", c.Table)}, nil
+	case command.Select:
+		return "Select", []string{fmt.Sprintf("filter=%v", c.Filter)}, []command.Command{c.Input}
+	case command.Project:
+		cols := make([]string, len(c.Cols))
+		for i, col := range c.Cols {
+			cols[i] = col.String()
+		}
+		return "Project", []string{"cols=" + strings.Join(cols, ",")}, []command.Command{c.Input}
+	case command.Join:
+		var args []string
+		if c.Type != command.JoinUnknown {
+			args = append(args, fmt.Sprintf("type=%v", c.Type))
+		}
+		if c.Natural {
+			args = append(args, "natural=true")
+		}
+		if c.Filter != nil {
+			args = append(args, fmt.Sprintf("filter=%v", c.Filter))
+		}
+		return "Join", args, []command.Command{c.Left, c.Right}
+	case command.Compound:
+		return "Compound", []string{fmt.Sprintf("operator=%v", c.Operator)}, []command.Command{c.Left, c.Right}
+	case command.Limit:
+		return "Limit", []string{fmt.Sprintf("limit=%v", c.Limit)}, []command.Command{c.Input}
+	case command.Offset:
+		return "Offset", []string{fmt.Sprintf("offset=%v", c.Offset)}, []command.Command{c.Input}
+	case command.Sort:
+		terms := make([]string, len(c.Terms))
+		for i, term := range c.Terms {
+			terms[i] = term.String()
+		}
+		return "Sort", []string{"by=" + strings.Join(terms, ",")}, []command.Command{c.Input}
+	case command.Aggregate:
+		groupBy := make([]string, len(c.GroupBy))
+		for i, expr := range c.GroupBy {
+			groupBy[i] = expr.String()
+		}
+		aggregates := make([]string, len(c.Aggregates))
+		for i, fn := range c.Aggregates {
+			aggregates[i] = fn.String()
+		}
+		return "Aggregate", []string{
+			"groupby=(" + strings.Join(groupBy, ",") + ")",
+			"aggregates=(" + strings.Join(aggregates, ",") + ")",
+		}, []command.Command{c.Input}
+	case command.Distinct:
+		return "Distinct", nil, []command.Command{c.Input}
+	case command.Values:
+		return "Values", []string{fmt.Sprintf("datasets=%d", len(c.Values))}, nil
+	case command.With:
+		tables := make([]string, len(c.Tables))
+		inputs := make([]command.Command, 0, len(c.Tables)+1)
+		for i, tbl := range c.Tables {
+			tables[i] = tbl.Name
+			if tbl.Materialized {
+				tables[i] += " MATERIALIZED"
+			}
+			inputs = append(inputs, tbl.Input)
+		}
+		return "With", []string{"tables=(" + strings.Join(tables, ",") + ")"}, append(inputs, c.Input)
+	case command.Recursion:
+		return "Recursion", []string{fmt.Sprintf("distinct=%v", c.Distinct)}, []command.Command{c.Initial, c.Step}
+	case command.Insert:
+		args := []string{fmt.Sprintf("table=%v", c.Table)}
+		if c.InsertOr != command.InsertOrUnknown {
+			args = append(args, fmt.Sprintf("or=%v", c.InsertOr))
+		}
+		if c.Upsert != nil {
+			args = append(args, fmt.Sprintf("upsert=%v", c.Upsert))
+		}
+		if len(c.Returning) != 0 {
+			args = append(args, returningArg(c.Returning))
+		}
+		if c.Input == nil {
+			return "Insert", args, nil
+		}
+		return "Insert", args, []command.Command{c.Input}
+	case command.Update:
+		sets := make([]string, len(c.Updates))
+		for i, set := range c.Updates {
+			sets[i] = set.String()
+		}
+		args := []string{fmt.Sprintf("table=%v", c.Table), "sets=(" + strings.Join(sets, ",") + ")"}
+		if c.UpdateOr != command.UpdateOrUnknown {
+			args = append(args, fmt.Sprintf("or=%v", c.UpdateOr))
+		}
+		if c.Filter != nil {
+			args = append(args, fmt.Sprintf("filter=%v", c.Filter))
+		}
+		if len(c.Returning) != 0 {
+			args = append(args, returningArg(c.Returning))
+		}
+		return "Update", args, nil
+	case command.Delete:
+		args := []string{fmt.Sprintf("table=%v", c.Table)}
+		if c.Filter != nil {
+			args = append(args, fmt.Sprintf("filter=%v", c.Filter))
+		}
+		if len(c.Returning) != 0 {
+			args = append(args, returningArg(c.Returning))
+		}
+		return "Delete", args, nil
+	case command.CreateTable:
+		return "CreateTable", []string{"table=" + c.Name}, nil
+	case command.DropTable:
+		return "DropTable", []string{"table=" + c.Name}, nil
+	}
+	return reflect.TypeOf(cmd).Name(), nil, nil
+}
+
+// describeAccessPath returns the arguments, that describe the access path,
+// which is chosen for reading the records of the given table, whose rows are
+// filtered by the given filter, and whether the access path searches a range
+// of an index or of the primary key. A full scan of the table, or of the
+// records of a view or a common table, is described without arguments.
+func (e Engine) describeAccessPath(ctx ExecutionContext, simple command.SimpleTable, filter command.Expr) ([]string, bool, error) {
+	name := simple.QualifiedName()
+	if _, ok := ctx.commonTables[name]; ok {
+		return nil, false, nil
+	}
+	if _, _, ok, err := viewQuery(ctx, name); err != nil || ok {
+		return nil, false, err
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	tbl, ok := loaded.(*Table)
+	if !ok {
+		return nil, false, nil
+	}
+	if filter == nil {
+		filter = command.ConstantBooleanExpr{Value: true}
+	}
+	path, err := e.chooseAccessPath(ctx, tbl, simple, filter)
+	if err != nil || path.isFullScan() {
+		return nil, false, err
+	}
+
+	var args []string
+	if path.index != nil {
+		args = append(args, "index="+path.index.def.Name)
+	} else {
+		args = append(args, "key=primary")
+	}
+	if len(path.rng.prefix) != 0 {
+		values := make([]string, len(path.rng.prefix))
+		for i, value := range path.rng.prefix {
+			values[i] = value.String()
+		}
+		args = append(args, "prefix=("+strings.Join(values, ",")+")")
+	}
+	if path.rng.lower != nil || path.rng.upper != nil {
+		args = append(args, "range="+path.rng.boundsString())
+	}
+	return args, len(args) > 1, nil
+}
+
+// returningArg describes the given columns of a RETURNING clause.
+func returningArg(cols []command.Column) string {
+	strs := make([]string, len(cols))
+	for i, col := range cols {
+		strs[i] = col.String()
+	}
+	return "returning=(" + strings.Join(strs, ",") + ")"
+}
+
+// estimateRows estimates the amount of rows, that the given command produces,
+// based on the estimated amount of rows of its inputs. Tables are not scanned
+// for the estimation, instead the amount of records in their data pages is
+// used. Filters are assumed to select half of the rows.
+func (e Engine) estimateRows(ctx ExecutionContext, cmd command.Command, inputRows []int64) (int64, error) {
+	switch c := cmd.(type) {
+	case command.Scan:
+		if _, ok := c.Table.(command.DerivedTable); ok {
+			return inputRows[0], nil
+		}
+		return e.tableRowCount(ctx, c.Table)
+	case command.Update:
+		return e.tableRowCount(ctx, c.Table)
+	case command.Delete:
+		return e.tableRowCount(ctx, c.Table)
+	case command.Select:
+		return (inputRows[0] + 1) / 2, nil
+	case command.Join:
+		if c.Natural || c.Filter != nil {
+			return maxInt64(inputRows[0], inputRows[1]), nil
+		}
+		return inputRows[0] * inputRows[1], nil
+	case command.Compound:
+		switch c.Operator {
+		case command.CompoundIntersect:
+			return minInt64(inputRows[0], inputRows[1]), nil
+		case command.CompoundExcept:
+			return inputRows[0], nil
+		}
+		return inputRows[0] + inputRows[1], nil
+	case command.Limit:
+		limit, err := e.evaluateInteger(ctx, c.Limit)
+		if err != nil {
+			return 0, err
+		}
+		if limit < 0 {
+			return inputRows[0], nil
+		}
+		return minInt64(limit, inputRows[0]), nil
+	case command.Offset:
+		offset, err := e.evaluateInteger(ctx, c.Offset)
+		if err != nil {
+			return 0, err
+		}
+		return maxInt64(inputRows[0]-maxInt64(offset, 0), 0), nil
+	case command.Aggregate:
+		if len(c.GroupBy) == 0 {
+			return 1, nil
+		}
+		return inputRows[0], nil
+	case command.Values:
+		return int64(len(c.Values)), nil
+	case command.With:
+		return inputRows[len(inputRows)-1], nil
+	case command.Recursion:
+		return inputRows[0] + inputRows[1], nil
+	}
+	if len(inputRows) == 1 {
+		return inputRows[0], nil
+	}
+	return 0, nil
+}
+
+// tableRowCount returns the amount of records in the data pages of the given
+// table. The records of a table, that is clustered by its primary key, are
+// counted in the tree of the table, since its data pages also hold the inner
+// pages of the tree. The amount of rows of a view or a common table is
+// estimated like the amount of rows of its query.
+func (e Engine) tableRowCount(ctx ExecutionContext, tbl command.Table) (int64, error) {
+	name := tbl.QualifiedName()
+	if common, ok := ctx.commonTables[name]; ok {
+		// the amount of rows of a recursive common table is estimated like
+		// the amount of rows of its initial list
+		query := common.Input
+		if recursion, ok := query.(command.Recursion); ok {
+			query = recursion.Initial
+		}
+		var nodes []explainNode
+		return e.explainCommand(common.ctx, query, nil, 0, 0, &nodes)
+	}
+	if query, _, ok, err := viewQuery(ctx, name); err != nil {
+		return 0, err
+	} else if ok {
+		var nodes []explainNode
+		return e.explainCommand(ctx, query, nil, 0, 0, &nodes)
+	}
+
+	if ok, err := ctx.tx.HasTable(name); err != nil {
+		return 0, fmt.Errorf("has table: %w", err)
+	} else if !ok {
+		return 0, fmt.Errorf("%v: %w", name, ErrNoSuchTable)
+	}
+
+	sf, err := ctx.tx.SchemaFile(name)
+	if err != nil {
+		return 0, fmt.Errorf("schema file: %w", err)
+	}
+	if sf.WithoutRowID {
+		return countClusteredRecords(btree.Open(dataPager{ctx.tx, name}, sf.Root))
+	}
+
+	pages, err := ctx.tx.ExistingDataPagesForTable(name)
+	if err != nil {
+		return 0, fmt.Errorf("data pages: %w", err)
+	}
+	var count int64
+	for _, id := range pages {
+		p, err := ctx.tx.DataPageReadOnly(name, id)
+		if err != nil {
+			return 0, fmt.Errorf("load page: %w", err)
+		}
+		count += int64(p.CellCount())
+	}
+	return count, nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestExplainSuite(t *testing.T) {
+	suite.Run(t, new(ExplainSuite))
+}
+
+type ExplainSuite struct {
+	EngineSuite
+}
+
+func (suite *ExplainSuite) TestExplainDoesNotExecute() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER);
+INSERT INTO myTable VALUES (1), (2)`)
+
+	result, err := suite.engine.evaluateExplain(suite.ctx, command.Explain{
+		Command: command.Delete{
+			Table:  command.SimpleTable{Table: "myTable"},
+			Filter: command.ConstantBooleanExpr{Value: true},
+		},
+	})
+	suite.NoError(err)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(0), types.NewString("Delete"), types.NewString("table=myTable,filter=true"), types.NewInteger(2)}},
+	}, suite.rows(result))
+
+	scanned, err := suite.engine.evaluateScan(suite.ctx, command.Scan{Table: command.SimpleTable{Table: "myTable"}})
+	suite.NoError(err)
+	suite.Len(suite.rows(scanned), 2)
+}
+
+func (suite *ExplainSuite) TestExplainQueryPlan() {
+	result, err := suite.engine.evaluateExplain(suite.ctx, command.Explain{
+		Command: command.Compound{
+			Operator: command.CompoundUnionAll,
+			Left:     command.Values{Values: [][]command.Expr{{command.ConstantLiteral{Value: "1", Numeric: true}}}},
+			Right:    command.Values{Values: [][]command.Expr{{command.ConstantLiteral{Value: "2", Numeric: true}}}},
+		},
+		QueryPlan:     true,
+		Optimizations: []string{"halfjoin"},
+	})
+	suite.NoError(err)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewInteger(0), types.NewString("Compound operator=CompoundUnionAll")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewInteger(1), types.NewString("Values datasets=1")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewInteger(1), types.NewString("Values datasets=1")}},
+		{Values: []types.Value{types.NewInteger(4), types.NewInteger(0), types.NewString("OPTIMIZATION halfjoin")}},
+	}, suite.rows(result))
+}
+
+func (suite *ExplainSuite) TestExplainUnknownTable() {
+	_, err := suite.engine.evaluateExplain(suite.ctx, command.Explain{
+		Command: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.Error(err)
+}
+
+func (suite *ExplainSuite) TestExplainAccessPath() {
+	suite.RunScript(`
+CREATE TABLE t (id INTEGER, name STRING);
+CREATE TABLE c (id INTEGER PRIMARY KEY, name STRING) WITHOUT ROWID;
+CREATE INDEX t_id ON t (id);
+INSERT INTO t VALUES (1, 'a'), (2, 'b');
+INSERT INTO c VALUES (1, 'a'), (2, 'b')`)
+
+	// the scan is the last node of the explained selections
+	for _, tt := range []struct {
+		query  string
+		detail string
+	}{
+		{`SELECT name FROM t WHERE name = 'a'`, "Scan table=t"},
+		{`SELECT name FROM t WHERE id = 2`, "Search table=t,index=t_id,prefix=(2)"},
+		{`SELECT name FROM t WHERE id >= 2`, "Search table=t,index=t_id,range=[2,+inf)"},
+		{`SELECT name FROM t INDEXED BY t_id`, "Scan table=t INDEXED BY t_id,index=t_id"},
+		{`SELECT name FROM c WHERE id < 2`, "Search table=c,key=primary,range=(-inf,2)"},
+		{`DELETE FROM t WHERE id = 1`, "Delete table=t,filter=id==1,index=t_id,prefix=(1)"},
+	} {
+		tbl, err := suite.evaluateStatement(`EXPLAIN QUERY PLAN ` + tt.query)
+		suite.Require().NoError(err, tt.query)
+		rows := suite.rows(tbl)
+		suite.Require().NotEmpty(rows, tt.query)
+		suite.Equal(tt.detail, rows[len(rows)-1].Values[2].String(), tt.query)
+	}
+}
@@ -28,6 +28,36 @@ func (e Engine) evaluateExpression(ctx ExecutionContext, expr command.Expr) (typ
 		return e.evaluateConstantLiteralOrColumnReference(ctx, ex)
 	case command.FunctionExpr:
 		return e.evaluateFunctionExpr(ctx, ex)
+	case command.RangeExpr:
+		ok, err := e.evaluateRangeExpr(ctx, ex)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewBool(ok), nil
+	case command.LikeExpr:
+		ok, err := e.evaluateLikeExpr(ctx, ex)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewBool(ok), nil
+	case command.UnaryNegativeExpr:
+		return e.evaluateUnaryExpr(ctx, ex, ex.Value)
+	case command.UnaryNegationExpr:
+		return e.evaluateUnaryExpr(ctx, ex, ex.Value)
+	case command.UnaryBitwiseNegationExpr:
+		return e.evaluateUnaryExpr(ctx, ex, ex.Value)
+	case command.RaiseExpr:
+		return nil, e.evaluateRaise(ctx, ex)
+	case command.SubqueryExpr:
+		return e.evaluateSubqueryExpr(ctx, ex)
+	case command.ExistsExpr:
+		ok, err := e.evaluateExistsExpr(ctx, ex)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewBool(ok), nil
+	case command.InExpr:
+		return e.evaluateInExpr(ctx, ex)
 	}
 	return nil, ErrUnimplemented(fmt.Sprintf("evaluate %T", expr))
 }
@@ -45,9 +75,18 @@ func (e Engine) evaluateMultipleExpressions(ctx ExecutionContext, exprs []comman
 }
 
 func (e Engine) evaluateColumnReference(ctx ExecutionContext, expr command.ColumnReference) (types.Value, error) {
+	// the NEW and OLD rows of a trigger take precedence, since a name like
+	// NEW.id would also match an unqualified column id
+	if val, ok, err := triggerValue(ctx, expr.Name); ok {
+		return val, err
+	}
 	if val, ok := ctx.intermediateRow.ValueForColName(expr.Name); ok {
 		return val, nil
 	}
+	// a column of an outer query of a correlated subquery
+	if val, ok := ctx.outerValue(expr.Name); ok {
+		return val, nil
+	}
 	return nil, ErrNoSuchColumn(expr.Name)
 }
 
@@ -55,9 +94,15 @@ func (e Engine) evaluateConstantLiteralOrColumnReference(ctx ExecutionContext, e
 	// ConstantLiteralOrColumnReference can't be numeric. For it to be a ConstantLiteralOrColumnReference,
 	// the value has to be enclosed in double quotes in the query.
 	value := expr.ValueOrName
+	if val, ok, err := triggerValue(ctx, value); ok {
+		return val, err
+	}
 	if val, ok := ctx.intermediateRow.ValueForColName(value); ok {
 		return val, nil
 	}
+	if val, ok := ctx.outerValue(value); ok {
+		return val, nil
+	}
 	return types.NewString(value), nil
 }
 
@@ -86,6 +131,8 @@ func (e Engine) evaluateFunctionExpr(ctx ExecutionContext, expr command.Function
 	return e.evaluateFunction(ctx, function)
 }
 
+// evaluateBinaryExpr evaluates the given binary expression. Arithmetic with a
+// NULL operand results in NULL.
 func (e Engine) evaluateBinaryExpr(ctx ExecutionContext, expr command.BinaryExpression) (types.Value, error) {
 	left, err := e.evaluateExpression(ctx, expr.LeftExpr())
 	if err != nil {
@@ -97,7 +144,23 @@ func (e Engine) evaluateBinaryExpr(ctx ExecutionContext, expr command.BinaryExpr
 	}
 
 	switch expr.(type) {
+	case command.AddExpression, command.SubExpression, command.MulExpression,
+		command.DivExpression, command.ModExpression, command.PowExpression:
+		if left != nil && right != nil {
+			if left.IsNull() {
+				return left, nil
+			}
+			if right.IsNull() {
+				return types.NewNull(left.Type()), nil
+			}
+		}
+	}
+
+	switch ex := expr.(type) {
 	case command.EqualityExpr:
+		if ex.Invert {
+			return types.NewBool(!e.eq(left, right)), nil
+		}
 		return types.NewBool(e.eq(left, right)), nil
 	case command.LessThanExpr:
 		return types.NewBool(e.lt(left, right)), nil
@@ -122,3 +185,55 @@ func (e Engine) evaluateBinaryExpr(ctx ExecutionContext, expr command.BinaryExpr
 	}
 	return nil, ErrUnimplemented(fmt.Sprintf("%T", expr))
 }
+
+// evaluateRangeExpr evaluates whether the needle of the given range expression
+// is between its lower and upper bound, both inclusive. If the range is
+// inverted, the result is negated.
+func (e Engine) evaluateRangeExpr(ctx ExecutionContext, expr command.RangeExpr) (bool, error) {
+	needle, err := e.evaluateExpression(ctx, expr.Needle)
+	if err != nil {
+		return false, fmt.Errorf("needle: %w", err)
+	}
+	lo, err := e.evaluateExpression(ctx, expr.Lo)
+	if err != nil {
+		return false, fmt.Errorf("lo: %w", err)
+	}
+	hi, err := e.evaluateExpression(ctx, expr.Hi)
+	if err != nil {
+		return false, fmt.Errorf("hi: %w", err)
+	}
+
+	between := e.gteq(needle, lo) && e.lteq(needle, hi)
+	return between != expr.Invert, nil
+}
+
+// evaluateUnaryExpr evaluates the given unary expression with the given
+// operand. Negating NULL results in NULL.
+func (e Engine) evaluateUnaryExpr(ctx ExecutionContext, expr command.Expr, operand command.Expr) (types.Value, error) {
+	val, err := e.evaluateExpression(ctx, operand)
+	if err != nil {
+		return nil, fmt.Errorf("value: %w", err)
+	}
+	if val.IsNull() {
+		return val, nil
+	}
+
+	switch expr.(type) {
+	case command.UnaryNegativeExpr:
+		switch v := val.(type) {
+		case types.IntegerValue:
+			return types.NewInteger(-v.Value), nil
+		case types.RealValue:
+			return types.NewReal(-v.Value), nil
+		}
+	case command.UnaryNegationExpr:
+		if v, ok := val.(types.BoolValue); ok {
+			return types.NewBool(!v.Value), nil
+		}
+	case command.UnaryBitwiseNegationExpr:
+		if v, ok := val.(types.IntegerValue); ok {
+			return types.NewInteger(^v.Value), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot evaluate %v on %v", expr, val.Type())
+}
@@ -45,6 +45,76 @@ func (suite *EngineSuite) TestEvaluateExpression() {
 			"",
 		},
 	})
+	suite.Run("unary", func() {
+		suite.testEvaluateExpressionTest([]evaluateExpressionTest{
+			{
+				"negative integer",
+				builder().build(),
+				command.UnaryNegativeExpr{UnaryBase: command.UnaryBase{Value: command.ConstantLiteral{Value: "5", Numeric: true}}},
+				types.NewInteger(-5),
+				"",
+			},
+			{
+				"negative real",
+				builder().build(),
+				command.UnaryNegativeExpr{UnaryBase: command.UnaryBase{Value: command.ConstantLiteral{Value: "1.5", Numeric: true}}},
+				types.NewReal(-1.5),
+				"",
+			},
+			{
+				"negation",
+				builder().build(),
+				command.UnaryNegationExpr{UnaryBase: command.UnaryBase{Value: command.ConstantBooleanExpr{Value: true}}},
+				types.NewBool(false),
+				"",
+			},
+			{
+				"negative string",
+				builder().build(),
+				command.UnaryNegativeExpr{UnaryBase: command.UnaryBase{Value: command.ConstantLiteral{Value: "abc"}}},
+				nil,
+				"cannot evaluate - abc on String",
+			},
+		})
+	})
+	suite.Run("range", func() {
+		suite.testEvaluateExpressionTest([]evaluateExpressionTest{
+			{
+				"in range",
+				builder().build(),
+				command.RangeExpr{
+					Needle: command.ConstantLiteral{Value: "5", Numeric: true},
+					Lo:     command.ConstantLiteral{Value: "1", Numeric: true},
+					Hi:     command.ConstantLiteral{Value: "5", Numeric: true},
+				},
+				types.NewBool(true),
+				"",
+			},
+			{
+				"not in range",
+				builder().build(),
+				command.RangeExpr{
+					Needle: command.ConstantLiteral{Value: "6", Numeric: true},
+					Lo:     command.ConstantLiteral{Value: "1", Numeric: true},
+					Hi:     command.ConstantLiteral{Value: "5", Numeric: true},
+				},
+				types.NewBool(false),
+				"",
+			},
+			{
+				"inverted range",
+				builder().build(),
+				command.RangeExpr{
+					Needle: command.ConstantLiteral{Value: "b"},
+					Lo:     command.ConstantLiteral{Value: "c"},
+					Hi:     command.ConstantLiteral{Value: "d"},
+					Invert: true,
+				},
+				types.NewBool(true),
+				"",
+			},
+		})
+	})
 	suite.Run("functions", func() {
 		suite.testEvaluateExpressionTest([]evaluateExpressionTest{
 			{
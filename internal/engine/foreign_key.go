@@ -0,0 +1,433 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// rowChange is the change of a single row of a table by a statement. The old
+// row of an inserted row, and the new row of a deleted row hold no values.
+type rowChange struct {
+	old table.Row
+	new table.Row
+}
+
+func (c rowChange) inserted() bool { return c.old.Values == nil }
+
+func (c rowChange) deleted() bool { return c.new.Values == nil }
+
+// referencingKey is a foreign key of a table, that references another table.
+type referencingKey struct {
+	table string
+	key   dbfs.ForeignKey
+}
+
+// enforceForeignKeys enforces all foreign keys, that are defined by or that
+// reference the table with the given name, after the given changes were
+// applied to the table. If rows of the table are referenced by rows of other
+// tables, the actions of the referencing foreign keys are performed, which may
+// change other tables, whose foreign keys are enforced in turn. Afterwards,
+// all changed rows of the table must reference existing rows. Foreign keys,
+// that are deferred, are checked when the transaction is committed.
+func (e Engine) enforceForeignKeys(ctx ExecutionContext, tableName string, changes []rowChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	sf, err := ctx.tx.SchemaFile(tableName)
+	if err != nil {
+		return fmt.Errorf("schema file: %w", err)
+	}
+
+	referencing, err := e.referencingKeys(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	for _, ref := range referencing {
+		if err := e.performReferentialActions(ctx, tableName, sf, ref, changes); err != nil {
+			return err
+		}
+	}
+
+	for _, foreignKey := range sf.ForeignKeys {
+		if e.isDeferred(foreignKey) {
+			e.deferForeignKeyCheck(ctx, tableName, foreignKey)
+			continue
+		}
+
+		cols, err := columnIndices(sf.Columns, foreignKey.Columns)
+		if err != nil {
+			return err
+		}
+		var rows []table.Row
+		for _, change := range changes {
+			if change.deleted() {
+				continue
+			}
+			if !change.inserted() {
+				// only rows whose reference changed must be checked
+				oldValues, oldOK := keyValues(cols, change.old)
+				newValues, newOK := keyValues(cols, change.new)
+				if oldOK && newOK && e.valuesEqual(oldValues, newValues) {
+					continue
+				}
+			}
+			rows = append(rows, change.new)
+		}
+		if err := e.checkReferences(ctx, tableName, foreignKey, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencingKeys returns all foreign keys of all tables, that reference the
+// table with the given name, including the foreign keys of the table itself.
+func (e Engine) referencingKeys(ctx ExecutionContext, tableName string) ([]referencingKey, error) {
+	tables, err := ctx.tx.Tables()
+	if err != nil {
+		return nil, fmt.Errorf("tables: %w", err)
+	}
+
+	var referencing []referencingKey
+	for _, name := range tables {
+		sf, err := ctx.tx.SchemaFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("schema file of %v: %w", name, err)
+		}
+		for _, foreignKey := range sf.ForeignKeys {
+			if foreignKey.ForeignTable == tableName {
+				referencing = append(referencing, referencingKey{
+					table: name,
+					key:   foreignKey,
+				})
+			}
+		}
+	}
+	return referencing, nil
+}
+
+// performReferentialActions performs the actions of the given referencing
+// foreign key for all rows, whose referenced key was deleted or updated by the
+// given changes to the referenced table with the given name and schema.
+// Changed referencing rows are written to the referencing table, and the
+// foreign keys of the referencing table are enforced for these changes. The
+// referencing rows are looked up through an index over the referencing
+// columns, if there is one, otherwise all rows of the referencing table are
+// read.
+func (e Engine) performReferentialActions(ctx ExecutionContext, tableName string, sf *dbfs.SchemaFile, ref referencingKey, changes []rowChange) error {
+	key, err := columnIndices(sf.Columns, ref.key.ForeignColumns)
+	if err != nil {
+		return err
+	}
+	referenced, err := e.LoadTable(ctx.tx, tableName)
+	if err != nil {
+		return fmt.Errorf("load table: %w", err)
+	}
+	lookupReferenced, err := referenced.(*Table).keyLookup(key)
+	if err != nil {
+		return err
+	}
+
+	// removed holds the new values of all keys, that were removed by the
+	// changes, by their old values, or nil, if the row was deleted
+	removed := newKeyMap(e)
+	var removedKeys [][]types.Value
+	for _, change := range changes {
+		if change.inserted() {
+			continue
+		}
+		oldValues, ok := keyValues(key, change.old)
+		if !ok {
+			continue
+		}
+		var newValues []types.Value
+		if !change.deleted() {
+			if newValues, ok = keyValues(key, change.new); ok && e.valuesEqual(oldValues, newValues) {
+				continue
+			}
+		}
+		// keys that were removed from one row and assigned to another row
+		// are still referenced
+		if still, err := lookupReferenced(oldValues); err != nil {
+			return fmt.Errorf("lookup: %w", err)
+		} else if len(still) != 0 {
+			continue
+		}
+		if !removed.contains(oldValues) {
+			removedKeys = append(removedKeys, oldValues)
+		}
+		removed.put(oldValues, newValues)
+	}
+	if removed.len() == 0 {
+		return nil
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, ref.table)
+	if err != nil {
+		return fmt.Errorf("load table: %w", err)
+	}
+	tbl := loaded.(*Table)
+	cols, err := tbl.Cols()
+	if err != nil {
+		return fmt.Errorf("cols: %w", err)
+	}
+	referencingCols, err := columnIndices(cols, ref.key.Columns)
+	if err != nil {
+		return err
+	}
+
+	var records []record
+	if lookup, ok, err := tbl.indexedLookup(referencingCols); err != nil {
+		return err
+	} else if ok {
+		for _, values := range removedKeys {
+			found, err := lookup(values)
+			if err != nil {
+				return fmt.Errorf("lookup: %w", err)
+			}
+			records = append(records, found...)
+		}
+	} else if records, err = e.selectRecords(ctx, tbl, command.ConstantBooleanExpr{Value: true}); err != nil {
+		return err
+	}
+	var deletes, updates []record
+	var referencingChanges []rowChange
+	for _, rec := range records {
+		values, ok := keyValues(referencingCols, rec.row)
+		if !ok {
+			continue
+		}
+		newValues, ok := removed.get(values)
+		if !ok {
+			continue
+		}
+
+		action := ref.key.OnUpdate
+		if newValues == nil {
+			action = ref.key.OnDelete
+		}
+		switch action {
+		case dbfs.Restrict:
+			return e.referencedError(tableName, ref, values)
+		case dbfs.Cascade:
+			if newValues == nil {
+				deletes = append(deletes, rec)
+				referencingChanges = append(referencingChanges, rowChange{old: rec.row})
+				continue
+			}
+		case dbfs.SetNull:
+			newValues = make([]types.Value, len(referencingCols))
+			for i, col := range referencingCols {
+				newValues[i] = types.NewNull(cols[col].Type)
+			}
+		default:
+			// NoAction
+			if e.isDeferred(ref.key) {
+				e.deferForeignKeyCheck(ctx, ref.table, ref.key)
+				continue
+			}
+			return e.referencedError(tableName, ref, values)
+		}
+
+		updated := table.Row{Values: make([]types.Value, len(rec.row.Values))}
+		copy(updated.Values, rec.row.Values)
+		for i, col := range referencingCols {
+			updated.Values[col] = newValues[i]
+		}
+		referencingChanges = append(referencingChanges, rowChange{old: rec.row, new: updated})
+		rec.row = updated
+		updates = append(updates, rec)
+	}
+
+	// records are changed after the iteration, since changing a record
+	// changes the slots of the page that holds it
+	for _, rec := range deletes {
+		if err := tbl.deleteRecord(rec); err != nil {
+			return fmt.Errorf("delete record: %w", err)
+		}
+	}
+	if err := tbl.replaceRecords(updates); err != nil {
+		return fmt.Errorf("replace records: %w", err)
+	}
+	return e.enforceForeignKeys(ctx, ref.table, referencingChanges)
+}
+
+// checkReferences checks, that all given rows of the table with the given name
+// reference an existing row through the given foreign key of the table. The
+// referenced rows are looked up through the referenced key.
+func (e Engine) checkReferences(ctx ExecutionContext, tableName string, foreignKey dbfs.ForeignKey, rows []table.Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sf, err := ctx.tx.SchemaFile(tableName)
+	if err != nil {
+		return fmt.Errorf("schema file: %w", err)
+	}
+	cols, err := columnIndices(sf.Columns, foreignKey.Columns)
+	if err != nil {
+		return err
+	}
+
+	// if the referenced table was dropped, no row is referenced
+	lookup := func([]types.Value) ([]record, error) { return nil, nil }
+	if ok, err := ctx.tx.HasTable(foreignKey.ForeignTable); err != nil {
+		return fmt.Errorf("has table: %w", err)
+	} else if ok {
+		foreignSchema, err := ctx.tx.SchemaFile(foreignKey.ForeignTable)
+		if err != nil {
+			return fmt.Errorf("schema file: %w", err)
+		}
+		key, err := columnIndices(foreignSchema.Columns, foreignKey.ForeignColumns)
+		if err != nil {
+			return err
+		}
+		referenced, err := e.LoadTable(ctx.tx, foreignKey.ForeignTable)
+		if err != nil {
+			return fmt.Errorf("load table: %w", err)
+		}
+		if lookup, err = referenced.(*Table).keyLookup(key); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		values, ok := keyValues(cols, row)
+		if !ok {
+			// a reference with NULL values references no row
+			continue
+		}
+		if found, err := lookup(values); err != nil {
+			return fmt.Errorf("lookup: %w", err)
+		} else if len(found) == 0 {
+			return fmt.Errorf("%v(%v)=(%v) references %v(%v): %w", tableName, strings.Join(foreignKey.Columns, ","), joinValues(values), foreignKey.ForeignTable, strings.Join(foreignKey.ForeignColumns, ","), ErrForeignKeyViolation)
+		}
+	}
+	return nil
+}
+
+// isDeferred returns whether the given foreign key is checked when the
+// transaction is committed, instead of at the end of a statement.
+func (e Engine) isDeferred(foreignKey dbfs.ForeignKey) bool {
+	return foreignKey.Deferred || e.deferForeignKeys
+}
+
+// deferForeignKeyCheck defers the check of the given foreign key of the table
+// with the given name until the transaction is committed. When the check is
+// performed, all rows of the table must reference an existing row.
+func (e Engine) deferForeignKeyCheck(ctx ExecutionContext, tableName string, foreignKey dbfs.ForeignKey) {
+	name := fmt.Sprintf("foreign key %v(%v)", tableName, strings.Join(foreignKey.Columns, ","))
+	tx := ctx.tx
+	tx.DeferCheck(name, func() error {
+		ctx := newEmptyExecutionContext(tx)
+		if ok, err := tx.HasTable(tableName); err != nil {
+			return fmt.Errorf("has table: %w", err)
+		} else if !ok {
+			// the table was dropped
+			return nil
+		}
+
+		loaded, err := e.LoadTable(tx, tableName)
+		if err != nil {
+			return fmt.Errorf("load table: %w", err)
+		}
+		records, err := e.selectRecords(ctx, loaded.(*Table), command.ConstantBooleanExpr{Value: true})
+		if err != nil {
+			return err
+		}
+		rows := make([]table.Row, len(records))
+		for i, rec := range records {
+			rows[i] = rec.row
+		}
+		return e.checkReferences(ctx, tableName, foreignKey, rows)
+	})
+}
+
+// referencedError returns an error indicating, that the given key values of the
+// table with the given name are still referenced through the given foreign
+// key.
+func (e Engine) referencedError(tableName string, ref referencingKey, values []types.Value) error {
+	return fmt.Errorf("%v(%v)=(%v) is referenced by %v(%v): %w", tableName, strings.Join(ref.key.ForeignColumns, ","), joinValues(values), ref.table, strings.Join(ref.key.Columns, ","), ErrForeignKeyViolation)
+}
+
+// keyMap maps the values of keys to values.
+type keyMap struct {
+	e       Engine
+	size    int
+	buckets map[uint64][]keyMapEntry
+}
+
+type keyMapEntry struct {
+	key   []types.Value
+	value []types.Value
+}
+
+func newKeyMap(e Engine) *keyMap {
+	return &keyMap{
+		e:       e,
+		buckets: make(map[uint64][]keyMapEntry),
+	}
+}
+
+// put associates the given value with the given key.
+func (m *keyMap) put(key, value []types.Value) {
+	hash := hashValues(0, key)
+	for i, entry := range m.buckets[hash] {
+		if m.e.valuesEqual(key, entry.key) {
+			m.buckets[hash][i].value = value
+			return
+		}
+	}
+	m.buckets[hash] = append(m.buckets[hash], keyMapEntry{
+		key:   key,
+		value: value,
+	})
+	m.size++
+}
+
+// get returns the value, that is associated with the given key, and whether
+// the key is contained in this map.
+func (m *keyMap) get(key []types.Value) ([]types.Value, bool) {
+	for _, entry := range m.buckets[hashValues(0, key)] {
+		if m.e.valuesEqual(key, entry.key) {
+			return entry.value, true
+		}
+	}
+	return nil, false
+}
+
+func (m *keyMap) contains(key []types.Value) bool {
+	_, ok := m.get(key)
+	return ok
+}
+
+func (m *keyMap) len() int {
+	return m.size
+}
+
+// columnIndices returns the indices of the columns with the given names.
+func columnIndices(cols []table.Col, names []string) ([]int, error) {
+	indices := make([]int, len(names))
+	for i, name := range names {
+		index := columnIndex(cols, name)
+		if index == -1 {
+			return nil, ErrNoSuchColumn(name)
+		}
+		indices[i] = index
+	}
+	return indices, nil
+}
+
+func joinValues(values []types.Value) string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = value.String()
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestForeignKeySuite(t *testing.T) {
+	suite.Run(t, new(ForeignKeySuite))
+}
+
+type ForeignKeySuite struct {
+	EngineSuite
+}
+
+func (suite *ForeignKeySuite) TestReference() {
+	suite.setupTables("")
+
+	err := suite.exec(`INSERT INTO child VALUES (10, 3)`)
+	suite.ErrorIs(err, ErrForeignKeyViolation)
+	suite.Contains(err.Error(), "child(parent)=(3) references parent(id)")
+
+	// a NULL reference references no row
+	suite.NoError(suite.exec(`INSERT INTO child (id) VALUES (11)`))
+	suite.NoError(suite.exec(`UPDATE child SET parent = 2 WHERE id = 11`))
+	suite.ErrorIs(suite.exec(`UPDATE child SET parent = 3 WHERE id = 11`), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestViolationIsUndone() {
+	suite.setupTables("")
+
+	// the valid first row is undone with the violating second row
+	suite.ErrorIs(suite.exec(`INSERT INTO child VALUES (3, 2), (10, 3)`), ErrForeignKeyViolation)
+	suite.commit()
+
+	suite.Equal([]table.Row{
+		intRow(1, 1),
+		intRow(2, 1),
+	}, suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestNoActionDelete() {
+	suite.setupTables("")
+
+	// rows, that are not referenced, can be deleted
+	suite.NoError(suite.exec(`DELETE FROM parent WHERE id = 2`))
+
+	err := suite.exec(`DELETE FROM parent WHERE id = 1`)
+	suite.ErrorIs(err, ErrForeignKeyViolation)
+	suite.Contains(err.Error(), "parent(id)=(1) is referenced by child(parent)")
+}
+
+func (suite *ForeignKeySuite) TestNoActionUpdate() {
+	suite.setupTables("")
+
+	// keys, that are not referenced, can be changed
+	suite.NoError(suite.exec(`UPDATE parent SET id = 3 WHERE id = 2`))
+	suite.ErrorIs(suite.exec(`UPDATE parent SET id = 5 WHERE id = 1`), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestRestrictDelete() {
+	suite.setupTables("ON DELETE RESTRICT")
+
+	suite.ErrorIs(suite.exec(`DELETE FROM parent WHERE id = 1`), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestRestrictUpdate() {
+	suite.setupTables("ON UPDATE RESTRICT")
+
+	suite.ErrorIs(suite.exec(`UPDATE parent SET id = 5 WHERE id = 1`), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestCascade() {
+	suite.setupTables("ON DELETE CASCADE ON UPDATE CASCADE")
+
+	suite.NoError(suite.exec(`UPDATE parent SET id = 5 WHERE id = 1`))
+	suite.Equal([]table.Row{
+		intRow(1, 5),
+		intRow(2, 5),
+	}, suite.scan("child"))
+
+	suite.NoError(suite.exec(`DELETE FROM parent WHERE id = 5`))
+	suite.Empty(suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestCascadeThroughIndex() {
+	suite.setupTables("ON DELETE CASCADE ON UPDATE CASCADE")
+	suite.NoError(suite.exec(`CREATE INDEX child_parent ON child (parent)`))
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (3, 2)`))
+
+	suite.NoError(suite.exec(`DELETE FROM parent WHERE id = 1`))
+	suite.Equal([]table.Row{
+		intRow(3, 2),
+	}, suite.scan("child"))
+
+	// the key 2 is still referenced, since it was assigned to another row
+	suite.NoError(suite.exec(`INSERT INTO parent VALUES (3, 'c')`))
+	suite.NoError(suite.exec(`UPDATE parent SET id = id - 1`))
+	suite.Equal([]table.Row{
+		intRow(3, 2),
+	}, suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestSetNull() {
+	suite.setupTables("ON DELETE SET NULL")
+
+	suite.NoError(suite.exec(`DELETE FROM parent WHERE id = 1`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewNull(types.Integer)}},
+		{Values: []types.Value{types.NewInteger(2), types.NewNull(types.Integer)}},
+	}, suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestCascadeChain() {
+	suite.setupTables("ON DELETE CASCADE")
+	suite.NoError(suite.exec(`CREATE TABLE grandchild (id INTEGER PRIMARY KEY, child INTEGER REFERENCES child ON DELETE CASCADE)`))
+	suite.NoError(suite.exec(`INSERT INTO grandchild VALUES (1, 1), (2, 2)`))
+
+	suite.NoError(suite.exec(`DELETE FROM parent`))
+	suite.Empty(suite.scan("child"))
+	suite.Empty(suite.scan("grandchild"))
+}
+
+func (suite *ForeignKeySuite) TestDropCascade() {
+	suite.setupTables("ON DELETE CASCADE")
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (3, 2)`))
+
+	suite.NoError(suite.exec(`DROP TABLE parent`))
+	suite.Empty(suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestDropSetNull() {
+	suite.setupTables("ON DELETE SET NULL")
+
+	suite.NoError(suite.exec(`DROP TABLE parent`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewNull(types.Integer)}},
+		{Values: []types.Value{types.NewInteger(2), types.NewNull(types.Integer)}},
+	}, suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestDropRestrict() {
+	suite.setupTables("ON DELETE RESTRICT")
+
+	suite.ErrorIs(suite.exec(`DROP TABLE parent`), ErrForeignKeyViolation)
+	// the failed drop is undone
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+	}, suite.scan("parent"))
+	suite.Equal([]table.Row{
+		intRow(1, 1),
+		intRow(2, 1),
+	}, suite.scan("child"))
+}
+
+func (suite *ForeignKeySuite) TestDropNoAction() {
+	suite.setupTables("")
+
+	suite.ErrorIs(suite.exec(`DROP TABLE parent`), ErrForeignKeyViolation)
+	// the table can be dropped, once it is not referenced anymore
+	suite.NoError(suite.exec(`DELETE FROM child`))
+	suite.NoError(suite.exec(`DROP TABLE parent`))
+}
+
+func (suite *ForeignKeySuite) TestSelfReference() {
+	suite.NoError(suite.exec(`CREATE TABLE employees (id INTEGER PRIMARY KEY, manager INTEGER REFERENCES employees (id) ON DELETE CASCADE)`))
+	suite.NoError(suite.exec(`INSERT INTO employees (id) VALUES (1)`))
+	suite.NoError(suite.exec(`INSERT INTO employees VALUES (2, 1), (3, 2), (4, 4)`))
+
+	suite.NoError(suite.exec(`DELETE FROM employees WHERE id = 1`))
+	suite.Equal([]table.Row{
+		intRow(4, 4),
+	}, suite.scan("employees"))
+
+	suite.ErrorIs(suite.exec(`INSERT INTO employees VALUES (5, 6)`), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestDeferred() {
+	suite.setupTables("DEFERRABLE INITIALLY DEFERRED")
+
+	// the references are checked when the transaction is committed
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (3, 3)`))
+	suite.NoError(suite.exec(`DELETE FROM parent WHERE id = 1`))
+	suite.NoError(suite.exec(`INSERT INTO parent VALUES (1, 'a'), (3, 'c')`))
+	suite.NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+}
+
+func (suite *ForeignKeySuite) TestDeferredViolation() {
+	suite.setupTables("DEFERRABLE INITIALLY DEFERRED")
+
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (3, 3)`))
+	suite.ErrorIs(suite.engine.txmgr.Commit(suite.ctx.tx), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestDeferredForeignKeysOption() {
+	WithDeferredForeignKeys()(&suite.engine)
+	suite.setupTables("ON DELETE RESTRICT")
+
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (3, 3)`))
+	// restrict is never deferred
+	suite.ErrorIs(suite.exec(`DELETE FROM parent WHERE id = 1`), ErrForeignKeyViolation)
+	suite.ErrorIs(suite.engine.txmgr.Commit(suite.ctx.tx), ErrForeignKeyViolation)
+}
+
+func (suite *ForeignKeySuite) TestInvalidForeignKey() {
+	suite.setupTables("")
+
+	suite.ErrorIs(suite.exec(`CREATE TABLE t1 (a INTEGER REFERENCES unknown)`), ErrNoSuchTable)
+	// name is a key of parent, but parent.id is not referenced by two columns
+	suite.Error(suite.exec(`CREATE TABLE t2 (a INTEGER, b STRING, FOREIGN KEY (a, b) REFERENCES parent)`))
+	suite.Error(suite.exec(`CREATE TABLE t3 (a INTEGER, b STRING, FOREIGN KEY (a, b) REFERENCES parent (id, name))`))
+	suite.NoError(suite.exec(`CREATE TABLE t4 (a STRING REFERENCES parent (name))`))
+}
+
+// setupTables creates a parent and a child table, where child.parent
+// references parent.id with the given foreign key clauses. Both children
+// reference the parent with the id 1.
+func (suite *ForeignKeySuite) setupTables(clauses string) {
+	suite.Require().NoError(suite.exec(`CREATE TABLE parent (id INTEGER PRIMARY KEY, name STRING UNIQUE)`))
+	suite.Require().NoError(suite.exec(`CREATE TABLE child (id INTEGER PRIMARY KEY, parent INTEGER REFERENCES parent (id) ` + clauses + `)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO parent VALUES (1, 'a'), (2, 'b')`))
+	suite.Require().NoError(suite.exec(`INSERT INTO child VALUES (1, 1), (2, 1)`))
+}
+
+func (suite *ForeignKeySuite) scan(tableName string) []table.Row {
+	tbl, err := suite.engine.evaluateScan(suite.ctx, command.Scan{Table: command.SimpleTable{Table: tableName}})
+	suite.Require().NoError(err)
+	return suite.rows(tbl)
+}
+
+func intRow(values ...int64) table.Row {
+	row := table.Row{}
+	for _, value := range values {
+		row.Values = append(row.Values, types.NewInteger(value))
+	}
+	return row
+}
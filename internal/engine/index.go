@@ -0,0 +1,642 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/page"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/transaction"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+var _ btree.Pager = (*indexPager)(nil)
+
+// Markers, that precede every encoded value in an index key. NULL values are
+// ordered before all other values.
+const (
+	indexKeyNull    byte = 0x00
+	indexKeyNotNull byte = 0x01
+)
+
+// indexPager provides access to the pages of the index file of a table within
+// a transaction.
+type indexPager struct {
+	tx    *transaction.TX
+	table string
+}
+
+func (p indexPager) Page(id page.ID) (*page.Page, error) {
+	return p.tx.IndexPage(p.table, id)
+}
+
+func (p indexPager) Allocate() (*page.Page, error) {
+	return p.tx.AllocateNewIndexPage(p.table)
+}
+
+// tableIndex is a secondary index of a table, which is stored as a B+tree in
+// the index file of the table. The index holds an entry for every row of the
+// table. The key of an entry are the encoded values of the indexed columns of
+// the row, followed by the key of the record that holds the row, so that the
+// keys of all entries are unique, even if the index is not. The entry holds
+// the ID of the data page that holds the record, followed by the key of the
+// record.
+type tableIndex struct {
+	tbl  *Table
+	def  dbfs.Index
+	cols []int
+	tree *btree.Tree
+}
+
+// indexBound is the lower or upper bound of a range of an index.
+type indexBound struct {
+	value     types.Value
+	inclusive bool
+}
+
+// indexRange is a range of the entries of an index. All entries in the range
+// hold the values of the prefix in the first indexed columns. If the range is
+// bounded, the value of the indexed column after the prefix must be within the
+// bounds. Since NULL values are less than all other values, they are only in
+// ranges without a lower bound.
+type indexRange struct {
+	prefix []types.Value
+	// lower and upper are the bounds of the range, or nil, if the range is not
+	// bounded in that direction.
+	lower, upper *indexBound
+}
+
+// boundsString returns the bounds of this range as an interval, whose open
+// ends are -inf and +inf, e.g. [2,+inf) for a range, that holds all values
+// greater than or equal to 2.
+func (r indexRange) boundsString() string {
+	lower, upper := "(-inf", "+inf)"
+	if r.lower != nil {
+		bracket := "("
+		if r.lower.inclusive {
+			bracket = "["
+		}
+		lower = bracket + r.lower.value.String()
+	}
+	if r.upper != nil {
+		bracket := ")"
+		if r.upper.inclusive {
+			bracket = "]"
+		}
+		upper = r.upper.value.String() + bracket
+	}
+	return lower + "," + upper
+}
+
+// indexes returns all indexes of this table.
+func (t *Table) indexes() ([]*tableIndex, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+
+	indexes := make([]*tableIndex, len(sf.Indexes))
+	for i, def := range sf.Indexes {
+		cols, err := columnIndices(sf.Columns, def.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", def.Name, err)
+		}
+		indexes[i] = &tableIndex{
+			tbl:  t,
+			def:  def,
+			cols: cols,
+			tree: btree.Open(indexPager{t.tx, t.name}, def.Root),
+		}
+	}
+	return indexes, nil
+}
+
+// index returns the index of this table with the given name, or false, if the
+// table has no such index.
+func (t *Table) index(name string) (*tableIndex, bool, error) {
+	indexes, err := t.indexes()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, idx := range indexes {
+		if idx.def.Name == name {
+			return idx, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// addToIndexes adds the entries of the given record to all indexes of this
+// table.
+func (t *Table) addToIndexes(rec record) error {
+	indexes, err := t.indexes()
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := idx.add(rec); err != nil {
+			return fmt.Errorf("index %v: %w", idx.def.Name, err)
+		}
+	}
+	return nil
+}
+
+// removeFromIndexes removes the entries of the given record from all indexes of
+// this table. Since the row of the given record may already have been changed,
+// the indexed values are read from the row that is stored in the table.
+func (t *Table) removeFromIndexes(rec record) error {
+	indexes, err := t.indexes()
+	if err != nil || len(indexes) == 0 {
+		return err
+	}
+	stored, err := t.loadRecord(rec.page, rec.key)
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := idx.remove(stored); err != nil {
+			return fmt.Errorf("index %v: %w", idx.def.Name, err)
+		}
+	}
+	return nil
+}
+
+// keyLookup looks up the records of all rows of a table, that hold the given
+// values in a list of columns of the table.
+type keyLookup func(values []types.Value) ([]record, error)
+
+// keyLookup returns the lookup of records by the given key of this table, which
+// holds the indices of the columns of the key, see (*Table).indexedLookup. If
+// the key is not indexed, an error is returned. The keys of a table are
+// indexed, see createKeyIndexes.
+func (t *Table) keyLookup(key []int) (keyLookup, error) {
+	lookup, ok, err := t.indexedLookup(key)
+	if err != nil || ok {
+		return lookup, err
+	}
+	cols, err := t.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	names := make([]string, len(key))
+	for i, col := range key {
+		names[i] = cols[col].QualifiedName
+	}
+	return nil, fmt.Errorf("key (%v) of table %v is not indexed", strings.Join(names, ","), t.name)
+}
+
+// indexedLookup returns the lookup of records by the values of the given
+// columns of this table, or false, if the columns are not indexed. If the
+// columns are the primary key of a table without row ID, records are looked
+// up in the tree of the table. Otherwise, they are looked up in an index,
+// whose first columns are the given columns. In both cases, the columns may
+// be given in any order.
+func (t *Table) indexedLookup(cols []int) (keyLookup, bool, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return nil, false, fmt.Errorf("schema file: %w", err)
+	}
+
+	if tree, clustered, err := t.clusteredTree(); err != nil {
+		return nil, false, err
+	} else if clustered {
+		primaryKey, err := columnIndices(sf.Columns, sf.PrimaryKey)
+		if err != nil {
+			return nil, false, fmt.Errorf("primary key: %w", err)
+		}
+		if order, ok := columnOrder(primaryKey, cols); ok && len(primaryKey) == len(cols) {
+			return func(values []types.Value) ([]record, error) {
+				return t.scanClustered(tree, indexRange{prefix: reorder(values, order)})
+			}, true, nil
+		}
+	}
+
+	indexes, err := t.indexes()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, idx := range indexes {
+		if order, ok := columnOrder(idx.cols, cols); ok {
+			idx := idx
+			return func(values []types.Value) ([]record, error) {
+				return idx.lookup(reorder(values, order))
+			}, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// columnOrder returns, for each of the first len(cols) of the given indexed
+// columns, the position of that column in the given columns, or false, if the
+// first indexed columns are not the given columns in any order.
+func columnOrder(indexed, cols []int) ([]int, bool) {
+	if len(indexed) < len(cols) {
+		return nil, false
+	}
+	order := make([]int, len(cols))
+	for i, col := range indexed[:len(cols)] {
+		pos := -1
+		for j, c := range cols {
+			if c == col {
+				pos = j
+			}
+		}
+		if pos == -1 {
+			return nil, false
+		}
+		order[i] = pos
+	}
+	return order, true
+}
+
+// reorder returns the given values in the given order, see columnOrder.
+func reorder(values []types.Value, order []int) []types.Value {
+	reordered := make([]types.Value, len(order))
+	for i, pos := range order {
+		reordered[i] = values[pos]
+	}
+	return reordered
+}
+
+// loadRecord loads the record with the given key from the data page with the
+// given ID. If this table is clustered by its primary key, the page is
+// ignored, and the record is looked up in the tree of this table.
+func (t *Table) loadRecord(id page.ID, key []byte) (record, error) {
+	sf, err := t.tx.SchemaFile(t.name)
+	if err != nil {
+		return record{}, fmt.Errorf("schema file: %w", err)
+	}
+	data, err := t.recordData(id, key)
+	if err != nil {
+		return record{}, err
+	}
+	row, err := deserializeRow(sf.Columns, sf.Defaults, data)
+	if err != nil {
+		return record{}, fmt.Errorf("deserialize: %w", err)
+	}
+	return record{
+		page: id,
+		key:  append([]byte{}, key...),
+		row:  row,
+	}, nil
+}
+
+// add adds the entry of the given record to this index.
+func (idx *tableIndex) add(rec record) error {
+	key, err := idx.entryKey(rec)
+	if err != nil {
+		return err
+	}
+	location := make([]byte, page.IDSize, int(page.IDSize)+len(rec.key))
+	byteOrder.PutUint32(location, rec.page)
+	location = append(location, rec.key...)
+	if err := idx.tree.Insert(key, location); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+	return nil
+}
+
+// remove removes the entry of the given record from this index.
+func (idx *tableIndex) remove(rec record) error {
+	key, err := idx.entryKey(rec)
+	if err != nil {
+		return err
+	}
+	if ok, err := idx.tree.Delete(key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	} else if !ok {
+		return fmt.Errorf("no entry for record %x", rec.key)
+	}
+	return nil
+}
+
+func (idx *tableIndex) entryKey(rec record) ([]byte, error) {
+	values := make([]types.Value, len(idx.cols))
+	for i, col := range idx.cols {
+		values[i] = rec.row.Values[col]
+	}
+	key, err := encodeIndexKey(values)
+	if err != nil {
+		return nil, err
+	}
+	return append(key, rec.key...), nil
+}
+
+// lookup returns the records of all rows of the table, that hold the given
+// values in the indexed columns, in the order of the index. Fewer values than
+// indexed columns may be given, in which case only the first columns are
+// matched.
+func (idx *tableIndex) lookup(values []types.Value) ([]record, error) {
+	return idx.scan(indexRange{prefix: values})
+}
+
+// contains returns whether this index holds an entry of a row, that holds the
+// given values in the first indexed columns.
+func (idx *tableIndex) contains(values []types.Value) (bool, error) {
+	from, to, ok, err := indexRange{prefix: values}.keys()
+	if err != nil || !ok {
+		return false, err
+	}
+	var found bool
+	if err := idx.tree.Scan(from, to, func(_, _ []byte) (bool, error) {
+		found = true
+		return false, nil
+	}); err != nil {
+		return false, fmt.Errorf("scan: %w", err)
+	}
+	return found, nil
+}
+
+// scan returns the records of all rows of the table, whose entries are in the
+// given range, in the order of the index.
+func (idx *tableIndex) scan(r indexRange) ([]record, error) {
+	if len(r.prefix) > len(idx.cols) || (len(r.prefix) == len(idx.cols) && (r.lower != nil || r.upper != nil)) {
+		return nil, fmt.Errorf("range exceeds the %v columns of the index", len(idx.cols))
+	}
+
+	from, to, ok, err := r.keys()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// records are loaded after the scan, since loading pages must not
+	// interfere with the scan of the tree
+	var locations [][]byte
+	if err := idx.tree.Scan(from, to, func(_, location []byte) (bool, error) {
+		locations = append(locations, location)
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	records := make([]record, len(locations))
+	for i, location := range locations {
+		rec, err := idx.tbl.loadRecord(page.DecodeID(location), location[page.IDSize:])
+		if err != nil {
+			return nil, err
+		}
+		records[i] = rec
+	}
+	return records, nil
+}
+
+// keys returns the smallest key of the entries in this range, and the smallest
+// key, that is greater than the keys of all entries in this range, or nil, if
+// there is no such key. The keys of the entries must start with the encoded
+// values of the indexed columns. If the range is empty, false is returned.
+func (r indexRange) keys() (from, to []byte, ok bool, err error) {
+	prefix, err := encodeIndexKey(r.prefix)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	from, to = prefix, btree.PrefixEnd(prefix)
+	if r.lower != nil {
+		bound, err := encodeIndexKey([]types.Value{r.lower.value})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		from = append(prefix[:len(prefix):len(prefix)], bound...)
+		if !r.lower.inclusive {
+			if from = btree.PrefixEnd(from); from == nil {
+				// there is no value greater than the bound
+				return nil, nil, false, nil
+			}
+		}
+	}
+	if r.upper != nil {
+		bound, err := encodeIndexKey([]types.Value{r.upper.value})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		to = append(prefix[:len(prefix):len(prefix)], bound...)
+		if r.upper.inclusive {
+			to = btree.PrefixEnd(to)
+		}
+	}
+	if to != nil && bytes.Compare(from, to) >= 0 {
+		return nil, nil, false, nil
+	}
+	return from, to, true, nil
+}
+
+// evaluateCreateIndex creates a new index from the given command, and adds
+// the entries of all rows of the table to it. Index names are unique across
+// all tables. If the index is unique, but the table holds rows with equal
+// values in the indexed columns, an error is returned.
+func (e Engine) evaluateCreateIndex(ctx ExecutionContext, cmd command.CreateIndex) (table.Table, error) {
+	defer e.profiler.Enter("create index").Exit()
+	tx := ctx.tx
+
+	if _, ok, err := e.findIndex(ctx, cmd.Name); err != nil {
+		return nil, err
+	} else if ok {
+		if cmd.IfNotExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("index %v: %w", cmd.Name, ErrAlreadyExists)
+	}
+
+	if ok, err := tx.HasTable(cmd.Table); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if !ok {
+		return nil, fmt.Errorf("%v: %w", cmd.Table, ErrNoSuchTable)
+	}
+	sf, err := tx.SchemaFile(cmd.Table)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	cols, err := columnIndices(sf.Columns, cmd.Cols)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := e.LoadTable(tx, cmd.Table)
+	if err != nil {
+		return nil, fmt.Errorf("load table: %w", err)
+	}
+	tbl := loaded.(*Table)
+	records, err := e.selectRecords(ctx, tbl, command.ConstantBooleanExpr{Value: true})
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := btree.Create(indexPager{tx, cmd.Table})
+	if err != nil {
+		return nil, fmt.Errorf("create tree: %w", err)
+	}
+	idx := &tableIndex{
+		tbl: tbl,
+		def: dbfs.Index{
+			Name:    cmd.Name,
+			Columns: cmd.Cols,
+			Unique:  cmd.Unique,
+			Root:    tree.Root(),
+		},
+		cols: cols,
+		tree: tree,
+	}
+	for _, rec := range records {
+		// rows with NULL values never conflict
+		if values, ok := keyValues(cols, rec.row); ok && cmd.Unique {
+			if found, err := idx.contains(values); err != nil {
+				return nil, err
+			} else if found {
+				return nil, fmt.Errorf("%v(%v)=(%v): %w", cmd.Table, strings.Join(cmd.Cols, ","), joinValues(values), ErrUniqueViolation)
+			}
+		}
+		if err := idx.add(rec); err != nil {
+			return nil, err
+		}
+	}
+	sf.Indexes = append(sf.Indexes, idx.def)
+
+	return table.Empty, nil
+}
+
+// createKeyIndexes creates a unique index over the primary key and over every
+// unique key of the new table with the given name and schema, through which
+// rows are looked up by their keys. The primary key of a table without row ID
+// needs no index, since the rows of the table are clustered by it.
+func createKeyIndexes(tx *transaction.TX, tableName string, sf *dbfs.SchemaFile) error {
+	var keys [][]string
+	if sf.PrimaryKey != nil && !sf.WithoutRowID {
+		keys = append(keys, sf.PrimaryKey)
+	}
+	keys = append(keys, sf.UniqueKeys...)
+	for i, key := range keys {
+		tree, err := btree.Create(indexPager{tx, tableName})
+		if err != nil {
+			return fmt.Errorf("create tree: %w", err)
+		}
+		sf.Indexes = append(sf.Indexes, dbfs.Index{
+			Name:    autoIndexName(tableName, i+1),
+			Columns: key,
+			Unique:  true,
+			Auto:    true,
+			Root:    tree.Root(),
+		})
+	}
+	return nil
+}
+
+// autoIndexName returns the name of the index with the given number, that was
+// created for a key of the table with the given name, see createKeyIndexes.
+func autoIndexName(tableName string, n int) string {
+	return fmt.Sprintf("autoindex_%v_%d", tableName, n)
+}
+
+// evaluateDropIndex drops the index from the given command. The pages of the
+// index remain in the index file of the table, but are not used anymore. If
+// the index does not exist, an error is returned, unless the command specifies
+// IfExists.
+func (e Engine) evaluateDropIndex(ctx ExecutionContext, cmd command.DropIndex) (table.Table, error) {
+	defer e.profiler.Enter("drop index").Exit()
+
+	tableName, ok, err := e.findIndex(ctx, cmd.Name)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		if cmd.IfExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", cmd.Name, ErrNoSuchIndex)
+	}
+
+	sf, err := ctx.tx.SchemaFile(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	for i, def := range sf.Indexes {
+		if def.Name == cmd.Name {
+			sf.Indexes = append(sf.Indexes[:i:i], sf.Indexes[i+1:]...)
+			break
+		}
+	}
+	return table.Empty, nil
+}
+
+// findIndex returns the name of the table, that has an index with the given
+// name, or false, if there is no such index. Indexes, that were created for
+// the keys of a table, are not found.
+func (e Engine) findIndex(ctx ExecutionContext, name string) (string, bool, error) {
+	tables, err := ctx.tx.Tables()
+	if err != nil {
+		return "", false, fmt.Errorf("tables: %w", err)
+	}
+	for _, tableName := range tables {
+		sf, err := ctx.tx.SchemaFile(tableName)
+		if err != nil {
+			return "", false, fmt.Errorf("schema file of %v: %w", tableName, err)
+		}
+		for _, def := range sf.Indexes {
+			if def.Name == name && !def.Auto {
+				return tableName, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// encodeIndexKey encodes the given values, so that the byte-wise order of the
+// encoded values is the order of the values. Every value is preceded by a
+// marker, that orders NULL values before all other values. Encoded values are
+// self-delimiting, so that no encoded value is a prefix of another encoded
+// value of the same type.
+func encodeIndexKey(values []types.Value) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, value := range values {
+		if value.IsNull() {
+			buf.WriteByte(indexKeyNull)
+			continue
+		}
+		buf.WriteByte(indexKeyNotNull)
+
+		num := make([]byte, 8)
+		switch value := value.(type) {
+		case types.IntegerValue:
+			byteOrder.PutUint64(num, uint64(value.Value)^(1<<63))
+			buf.Write(num)
+		case types.RealValue:
+			v := value.Value
+			if v == 0 {
+				// -0 and 0 are equal
+				v = 0
+			}
+			bits := math.Float64bits(v)
+			if bits&(1<<63) != 0 {
+				bits = ^bits
+			} else {
+				bits ^= 1 << 63
+			}
+			byteOrder.PutUint64(num, bits)
+			buf.Write(num)
+		case types.DateValue:
+			byteOrder.PutUint64(num, uint64(value.Value.UnixNano())^(1<<63))
+			buf.Write(num)
+		case types.BoolValue:
+			if value.Value {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		case types.StringValue:
+			// zero bytes are escaped, so that the terminator is ordered before
+			// all other bytes
+			for _, b := range []byte(value.Value) {
+				buf.WriteByte(b)
+				if b == 0x00 {
+					buf.WriteByte(0xff)
+				}
+			}
+			buf.Write([]byte{0x00, 0x01})
+		default:
+			return nil, fmt.Errorf("values of type %v can not be indexed", value.Type())
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestIndexSuite(t *testing.T) {
+	suite.Run(t, new(IndexSuite))
+}
+
+type IndexSuite struct {
+	EngineSuite
+}
+
+func (suite *IndexSuite) TestLookup() {
+	suite.createItems(10, false)
+	suite.NoError(suite.exec(`CREATE INDEX items_group ON items (grp, name)`))
+
+	suite.Equal([]table.Row{
+		itemRow(2, 2, "item2"),
+		itemRow(5, 2, "item5"),
+		itemRow(8, 2, "item8"),
+	}, suite.lookup("items_group", types.NewInteger(2)))
+	suite.Equal([]table.Row{
+		itemRow(5, 2, "item5"),
+	}, suite.lookup("items_group", types.NewInteger(2), types.NewString("item5")))
+	suite.Empty(suite.lookup("items_group", types.NewInteger(3)))
+	suite.Len(suite.lookup("items_group"), 10)
+}
+
+func (suite *IndexSuite) TestScanRange() {
+	suite.createItems(10, false)
+	suite.NoError(suite.exec(`CREATE INDEX items_id ON items (id)`))
+
+	suite.Equal([]int64{3, 4, 5}, suite.scanIDs("items_id", indexRange{
+		lower: &indexBound{value: types.NewInteger(3), inclusive: true},
+		upper: &indexBound{value: types.NewInteger(5), inclusive: true},
+	}))
+	suite.Equal([]int64{4}, suite.scanIDs("items_id", indexRange{
+		lower: &indexBound{value: types.NewInteger(3)},
+		upper: &indexBound{value: types.NewInteger(5)},
+	}))
+	suite.Equal([]int64{8, 9}, suite.scanIDs("items_id", indexRange{
+		lower: &indexBound{value: types.NewInteger(7)},
+	}))
+	suite.Equal([]int64{0, 1}, suite.scanIDs("items_id", indexRange{
+		upper: &indexBound{value: types.NewInteger(2)},
+	}))
+	suite.Empty(suite.scanIDs("items_id", indexRange{
+		lower: &indexBound{value: types.NewInteger(5)},
+		upper: &indexBound{value: types.NewInteger(3)},
+	}))
+}
+
+func (suite *IndexSuite) TestMaintenance() {
+	suite.createItems(3, false)
+	suite.NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (3, 0, 'new')`))
+	suite.NoError(suite.exec(`UPDATE items SET name = 'changed' WHERE id = 1`))
+	suite.NoError(suite.exec(`DELETE FROM items WHERE id = 2`))
+
+	suite.Equal([]table.Row{
+		itemRow(3, 0, "new"),
+	}, suite.lookup("items_name", types.NewString("new")))
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "changed"),
+	}, suite.lookup("items_name", types.NewString("changed")))
+	suite.Empty(suite.lookup("items_name", types.NewString("item1")))
+	suite.Empty(suite.lookup("items_name", types.NewString("item2")))
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "changed"),
+		itemRow(0, 0, "item0"),
+		itemRow(3, 0, "new"),
+	}, suite.lookup("items_name"))
+}
+
+func (suite *IndexSuite) TestManyRows() {
+	// the entries don't fit into a single page of the index
+	n := 2000
+	suite.createItems(n, false)
+	suite.NoError(suite.exec(`CREATE INDEX items_id ON items (id)`))
+	suite.NoError(suite.exec(`DELETE FROM items WHERE grp = 1`))
+	suite.NoError(suite.exec(`UPDATE items SET id = id + 10000 WHERE grp = 2`))
+
+	var expected []int64
+	for i := 0; i < n; i++ {
+		if i%3 == 0 {
+			expected = append(expected, int64(i))
+		}
+	}
+	for i := 0; i < n; i++ {
+		if i%3 == 2 {
+			expected = append(expected, int64(i+10000))
+		}
+	}
+	suite.Equal(expected, suite.scanIDs("items_id", indexRange{}))
+}
+
+func (suite *IndexSuite) TestUniqueIndex() {
+	suite.createItems(3, false)
+	suite.NoError(suite.exec(`CREATE UNIQUE INDEX items_name ON items (name)`))
+
+	err := suite.exec(`INSERT INTO items VALUES (3, 0, 'item1')`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.Contains(err.Error(), "items(name)=(item1)")
+	suite.ErrorIs(suite.exec(`UPDATE items SET name = 'item0' WHERE id = 2`), ErrUniqueViolation)
+	suite.NoError(suite.exec(`INSERT OR REPLACE INTO items VALUES (4, 1, 'item1')`))
+	suite.Equal([]table.Row{
+		itemRow(4, 1, "item1"),
+	}, suite.lookup("items_name", types.NewString("item1")))
+}
+
+func (suite *IndexSuite) TestCreateUniqueIndexOnDuplicates() {
+	suite.createItems(6, false)
+
+	err := suite.exec(`CREATE UNIQUE INDEX items_group ON items (grp)`)
+	suite.ErrorIs(err, ErrUniqueViolation)
+	suite.Contains(err.Error(), "items(grp)=(0)")
+
+	// the index has not been created
+	suite.ErrorIs(suite.exec(`DROP INDEX items_group`), ErrNoSuchIndex)
+}
+
+func (suite *IndexSuite) TestCreateAndDrop() {
+	suite.createItems(3, false)
+
+	suite.NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+	suite.ErrorIs(suite.exec(`CREATE INDEX items_name ON items (grp)`), ErrAlreadyExists)
+	suite.NoError(suite.exec(`CREATE INDEX IF NOT EXISTS items_name ON items (grp)`))
+	suite.ErrorIs(suite.exec(`CREATE INDEX unknown_name ON unknown (name)`), ErrNoSuchTable)
+	suite.Error(suite.exec(`CREATE INDEX items_unknown ON items (unknown)`))
+
+	suite.NoError(suite.exec(`DROP INDEX items_name`))
+	suite.ErrorIs(suite.exec(`DROP INDEX items_name`), ErrNoSuchIndex)
+	suite.NoError(suite.exec(`DROP INDEX IF EXISTS items_name`))
+
+	// dropped indexes are not maintained anymore
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (3, 0, 'item3')`))
+	suite.NoError(suite.exec(`CREATE INDEX items_name ON items (name)`))
+	suite.Len(suite.lookup("items_name"), 4)
+}
+
+func (suite *IndexSuite) TestKeyIndexes() {
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING UNIQUE)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 0, 'a'), (2, 0, 'b')`))
+	suite.NoError(suite.exec(`UPDATE items SET id = 3, name = 'c' WHERE id = 2`))
+
+	// the keys are indexed, and the indexes are maintained
+	suite.Equal([]table.Row{
+		itemRow(3, 0, "c"),
+	}, suite.lookup("autoindex_items_1", types.NewInteger(3)))
+	suite.Empty(suite.lookup("autoindex_items_1", types.NewInteger(2)))
+	suite.Equal([]table.Row{
+		itemRow(3, 0, "c"),
+	}, suite.lookup("autoindex_items_2", types.NewString("c")))
+
+	// the indexes of keys can not be dropped, and don't occupy index names
+	suite.ErrorIs(suite.exec(`DROP INDEX autoindex_items_1`), ErrNoSuchIndex)
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME TO things`))
+	suite.NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY)`))
+	suite.NoError(suite.exec(`CREATE INDEX autoindex_items_2 ON items (id)`))
+	sf, err := suite.ctx.tx.SchemaFile("things")
+	suite.Require().NoError(err)
+	suite.Equal("autoindex_things_1", sf.Indexes[0].Name)
+	suite.Equal("autoindex_things_2", sf.Indexes[1].Name)
+}
+
+func (suite *IndexSuite) TestEncodeIndexKeyOrder() {
+	ordered := [][]types.Value{
+		{types.NewNull(types.Integer)},
+		{types.NewInteger(-1 << 40)},
+		{types.NewInteger(-1)},
+		{types.NewInteger(0)},
+		{types.NewInteger(1)},
+		{types.NewInteger(1), types.NewString("")},
+		{types.NewInteger(1), types.NewString("\x00")},
+		{types.NewInteger(1), types.NewString("a")},
+		{types.NewInteger(1), types.NewString("a\x00")},
+		{types.NewInteger(1), types.NewString("ab")},
+		{types.NewInteger(1 << 40)},
+	}
+	for i := 1; i < len(ordered); i++ {
+		prev, err := encodeIndexKey(ordered[i-1])
+		suite.Require().NoError(err)
+		next, err := encodeIndexKey(ordered[i])
+		suite.Require().NoError(err)
+		suite.Less(string(prev), string(next), "%v < %v", ordered[i-1], ordered[i])
+	}
+
+	reals := []float64{-1e10, -1.5, -0.5, 0, 0.5, 1.5, 1e10}
+	for i := 1; i < len(reals); i++ {
+		prev, err := encodeIndexKey([]types.Value{types.NewReal(reals[i-1])})
+		suite.Require().NoError(err)
+		next, err := encodeIndexKey([]types.Value{types.NewReal(reals[i])})
+		suite.Require().NoError(err)
+		suite.Less(string(prev), string(next), "%v < %v", reals[i-1], reals[i])
+	}
+}
+
+func (suite *IndexSuite) index(name string) *tableIndex {
+	idx, ok, err := suite.items().index(name)
+	suite.Require().NoError(err)
+	suite.Require().True(ok)
+	return idx
+}
+
+func (suite *IndexSuite) lookup(name string, values ...types.Value) []table.Row {
+	records, err := suite.index(name).lookup(values)
+	suite.Require().NoError(err)
+	rows := make([]table.Row, len(records))
+	for i, rec := range records {
+		rows[i] = rec.row
+	}
+	return rows
+}
+
+func (suite *IndexSuite) scanIDs(name string, r indexRange) []int64 {
+	records, err := suite.index(name).scan(r)
+	suite.Require().NoError(err)
+	var ids []int64
+	for _, rec := range records {
+		ids = append(ids, rec.row.Values[0].(types.IntegerValue).Value)
+	}
+	return ids
+}
+
+func itemRow(id, grp int64, name string) table.Row {
+	return table.Row{Values: []types.Value{types.NewInteger(id), types.NewInteger(grp), types.NewString(name)}}
+}
@@ -2,12 +2,42 @@ package engine
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
 	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
 )
 
+// evaluateInsert evaluates the given insert command. The values of every row of
+// the input list are assigned to the columns of the insert, or to all columns
+// of the table in order, if the insert has no columns. Columns of the table,
+// that are not assigned a value, are filled with their default value, or NULL
+// if they have no default value. If the insert inserts default values, a
+// single row with the default values of all columns is inserted. Input rows,
+// that are not literal values, are read completely before the first row is
+// inserted, so that the input sees the table as of the start of the insert.
+// If the table has keys, conflicts of the inserted rows are resolved by the
+// upsert or the InsertOr of the insert, see (*conflictResolver).Insert. An
+// INSERT OR FAIL keeps the rows, that were inserted before a conflict. If the
+// insert has a RETURNING clause, the inserted rows and the rows that were
+// updated by the upsert are projected onto its columns. The foreign keys of
+// the table are enforced for all inserted and updated rows. The BEFORE INSERT
+// triggers of the table fire for every row, before it is inserted, and the
+// AFTER triggers fire for every inserted or updated row, after all rows were
+// written, see (Engine).fireTriggers. Rows, that are updated by the upsert,
+// only fire AFTER UPDATE triggers. If the table is a view, its INSTEAD OF
+// triggers fire instead, see (Engine).insertIntoView.
 func (e Engine) evaluateInsert(ctx ExecutionContext, c command.Insert) (table.Table, error) {
+	defer e.profiler.Enter("insert").Exit()
+
+	if query, view, ok, err := viewQuery(ctx, c.Table.QualifiedName()); err != nil {
+		return nil, err
+	} else if ok {
+		return e.insertIntoView(ctx, c, query, view)
+	}
+
 	tbl, err := e.LoadTable(ctx.tx, c.Table.QualifiedName())
 	if err != nil {
 		return nil, fmt.Errorf("load table: %w", err)
@@ -17,29 +47,283 @@ func (e Engine) evaluateInsert(ctx ExecutionContext, c command.Insert) (table.Ta
 		return nil, fmt.Errorf("table %v is not insertable", c.Table.QualifiedName())
 	}
 
-	if len(c.Cols) != 0 {
-		return nil, fmt.Errorf("explicit insert projectedColumns: %w", ErrUnsupported)
+	schemaFile, err := ctx.tx.SchemaFile(c.Table.QualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+
+	cols := schemaFile.Columns
+	defaults := make([]types.Value, len(cols))
+	for i, col := range cols {
+		if defaultValue, ok := schemaFile.Defaults[col.QualifiedName]; ok {
+			defaults[i] = defaultValue
+		} else {
+			defaults[i] = types.NewNull(col.Type)
+		}
+	}
+
+	// rows can only conflict if the table has keys, but an upsert must be
+	// checked for a valid conflict target in any case
+	var resolver *conflictResolver
+	if len(schemaFile.Keys()) != 0 || c.Upsert != nil {
+		diskTable, ok := tbl.(*Table)
+		if !ok {
+			return nil, fmt.Errorf("table %v does not support conflict resolution", c.Table.QualifiedName())
+		}
+		resolver, err = e.newConflictResolver(ctx, diskTable, schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("conflict resolver: %w", err)
+		}
+		if err := resolver.setInsert(c); err != nil {
+			return nil, err
+		}
+		inserter = resolver
+	}
+
+	insertTriggers, err := triggersOn(ctx, c.Table.QualifiedName(), dbfs.Insert)
+	if err != nil {
+		return nil, err
+	}
+	var updateTriggers []namedTrigger
+	if c.Upsert != nil {
+		updateTriggers, err = triggersOn(ctx, c.Table.QualifiedName(), dbfs.Update)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// inserted rows are recorded, so that they can be returned, and their
+	// references can be checked
+	var recorder *recordingInserter
+	if resolver == nil && (len(c.Returning) != 0 || len(schemaFile.ForeignKeys) != 0 || hasTiming(insertTriggers, dbfs.After)) {
+		recorder = &recordingInserter{Inserter: inserter}
+		inserter = recorder
+	}
+	if hasTiming(insertTriggers, dbfs.Before) {
+		inserter = triggeringInserter{
+			Inserter: inserter,
+			e:        e,
+			ctx:      ctx,
+			triggers: insertTriggers,
+			timing:   dbfs.Before,
+			cols:     cols,
+		}
+	}
+
+	var insertErr error
+	if c.DefaultValues {
+		if c.Input != nil {
+			return nil, fmt.Errorf("default values cannot have an input")
+		}
+		if err := inserter.Insert(table.Row{Values: defaults}); err != nil {
+			insertErr = fmt.Errorf("insert: %w", err)
+		}
+	} else {
+		insertErr = e.insertInput(ctx, c, cols, defaults, inserter)
+	}
+	// an INSERT OR FAIL keeps the rows, that were inserted before a conflict
+	if insertErr != nil && (c.InsertOr != command.InsertOrFail || !isConflict(insertErr)) {
+		return nil, insertErr
+	}
+
+	var affected []table.Row
+	var changes []rowChange
+	if recorder != nil {
+		affected = recorder.rows
+		changes = make([]rowChange, len(affected))
+		for i, row := range affected {
+			changes[i] = rowChange{new: row}
+		}
+	}
+	if resolver != nil {
+		affected = resolver.changedRows()
+		changes = resolver.changes
+	}
+	if err := e.enforceForeignKeys(ctx, c.Table.QualifiedName(), changes); err != nil {
+		return nil, err
+	}
+
+	var updatedCols []string
+	if c.Upsert != nil {
+		updatedCols = setterCols(c.Upsert.Updates)
+	}
+	for _, change := range changes {
+		var err error
+		switch {
+		case change.inserted():
+			_, err = e.fireTriggers(ctx, insertTriggers, dbfs.After, cols, change, nil)
+		case !change.deleted():
+			_, err = e.fireTriggers(ctx, updateTriggers, dbfs.After, cols, change, updatedCols)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if insertErr != nil {
+		return nil, keptChanges{insertErr}
+	}
+
+	if len(c.Returning) != 0 {
+		return e.evaluateReturning(ctx, cols, affected, c.Returning)
+	}
+	return table.Empty, nil
+}
+
+// insertInput inserts the rows of the input list of the given insert into the
+// given inserter. The values of the rows are assigned to the columns of the
+// insert, and the remaining columns are filled with the given defaults. If the
+// insert is an INSERT OR IGNORE, rows that violate a NOT NULL or CHECK
+// constraint are skipped.
+func (e Engine) insertInput(ctx ExecutionContext, c command.Insert, cols []table.Col, defaults []types.Value, inserter Inserter) error {
+	targets, err := insertTargets(c.Cols, cols)
+	if err != nil {
+		return err
 	}
 
 	insertInput, err := e.evaluateList(ctx, c.Input)
 	if err != nil {
-		return nil, fmt.Errorf("insert input: %w", err)
+		return fmt.Errorf("insert input: %w", err)
 	}
 
 	inputRows, err := insertInput.Rows()
 	if err != nil {
-		return nil, fmt.Errorf("rows: %w", err)
+		return fmt.Errorf("rows: %w", err)
 	}
+	defer func() { _ = inputRows.Close() }()
+
+	nextRow := inputRows.Next
+	if _, ok := c.Input.(command.Values); !ok {
+		// the input may read from the table that is inserted into, so all
+		// input rows are read before the first row is inserted
+		snapshot, err := e.snapshotRows(inputRows)
+		if err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+		defer func() { _ = snapshot.remove() }()
+		nextRow = func() (table.Row, error) {
+			values, err := snapshot.read()
+			if err == io.EOF {
+				return table.Row{}, table.ErrEOT
+			}
+			return table.Row{Values: values}, err
+		}
+	}
+
 	for {
-		next, err := inputRows.Next()
+		next, err := nextRow()
+		if err == table.ErrEOT {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(next.Values) != len(targets) {
+			return fmt.Errorf("%d values for %d columns", len(next.Values), len(targets))
+		}
+
+		values := make([]types.Value, len(cols))
+		copy(values, defaults)
+		for i, target := range targets {
+			value, err := e.coerceValue(next.Values[i], cols[target].Type)
+			if err != nil {
+				return fmt.Errorf("column %v: %w", cols[target].QualifiedName, err)
+			}
+			values[target] = value
+		}
+		if err := inserter.Insert(table.Row{Values: values}); err != nil {
+			if c.InsertOr == command.InsertOrIgnore && isConstraintViolation(err) {
+				continue
+			}
+			return fmt.Errorf("insert: %w", err)
+		}
+	}
+}
+
+// triggeringInserter is an inserter, that fires the insert triggers of a table
+// with a timing for every row, before the row is inserted into the underlying
+// inserter. Rows, for which a trigger evaluates RAISE(IGNORE), are skipped. If
+// there is no underlying inserter, as for the INSTEAD OF triggers of a view,
+// the rows are not inserted anywhere.
+type triggeringInserter struct {
+	Inserter
+	e        Engine
+	ctx      ExecutionContext
+	triggers []namedTrigger
+	timing   dbfs.TriggerTiming
+	cols     []table.Col
+}
+
+// Insert fires the triggers for the given row, and inserts the row into the
+// underlying inserter, unless it is skipped.
+func (i triggeringInserter) Insert(row table.Row) error {
+	ignored, err := i.e.fireTriggers(i.ctx, i.triggers, i.timing, i.cols, rowChange{new: row}, nil)
+	if err != nil || ignored || i.Inserter == nil {
+		return err
+	}
+	return i.Inserter.Insert(row)
+}
+
+// insertTargets returns the indices of the given table columns, that the given
+// insert columns reference. If there are no insert columns, the indices of all
+// table columns are returned.
+func insertTargets(insertCols []command.Column, tableCols []table.Col) ([]int, error) {
+	if len(insertCols) == 0 {
+		targets := make([]int, len(tableCols))
+		for i := range targets {
+			targets[i] = i
+		}
+		return targets, nil
+	}
+
+	targets := make([]int, len(insertCols))
+	assigned := make(map[int]struct{})
+	for i, col := range insertCols {
+		var name string
+		switch expr := col.Expr.(type) {
+		case command.ConstantLiteral:
+			name = expr.Value
+		case command.ColumnReference:
+			name = expr.Name
+		default:
+			return nil, fmt.Errorf("cannot insert into %v", col.Expr)
+		}
+
+		target := columnIndex(tableCols, name)
+		if target == -1 {
+			return nil, ErrNoSuchColumn(name)
+		}
+		if _, ok := assigned[target]; ok {
+			return nil, fmt.Errorf("column %v is assigned more than once", name)
+		}
+		assigned[target] = struct{}{}
+		targets[i] = target
+	}
+	return targets, nil
+}
+
+// snapshotRows reads all rows from the given iterator into a spill file, which
+// is rewound and can be read from the beginning. The caller must remove the
+// spill file.
+func (e Engine) snapshotRows(rows table.RowIterator) (*spillFile, error) {
+	snapshot, err := e.newSpillFile()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		next, err := rows.Next()
 		if err == table.ErrEOT {
 			break
 		} else if err != nil {
+			_ = snapshot.remove()
 			return nil, err
 		}
-		if err := inserter.Insert(next); err != nil {
-			return nil, fmt.Errorf("insert: %w", err)
+		if err := snapshot.write(next.Values); err != nil {
+			_ = snapshot.remove()
+			return nil, fmt.Errorf("write: %w", err)
 		}
 	}
-	return table.Empty, nil
+	if err := snapshot.rewind(); err != nil {
+		_ = snapshot.remove()
+		return nil, err
+	}
+	return snapshot, nil
 }
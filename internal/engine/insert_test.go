@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -67,3 +69,129 @@ func (suite *InsertSuite) TestSimpleInsert() {
 		},
 	), tbl)
 }
+
+func (suite *InsertSuite) TestInsertColumnsWithDefaults() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING DEFAULT 'unknown', price REAL DEFAULT -1, amount INTEGER);
+INSERT INTO myTable (amount, id) VALUES (5, 1), (7, 2);
+INSERT INTO myTable DEFAULT VALUES`)
+
+	tbl, err := suite.engine.evaluateScan(suite.ctx, command.Scan{Table: command.SimpleTable{Table: "myTable"}})
+	suite.NoError(err)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("unknown"), types.NewReal(-1), types.NewInteger(5)}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("unknown"), types.NewReal(-1), types.NewInteger(7)}},
+		{Values: []types.Value{types.NewNull(types.Integer), types.NewString("unknown"), types.NewReal(-1), types.NewNull(types.Integer)}},
+	}, suite.rows(tbl))
+}
+
+func (suite *InsertSuite) TestInsertInvalidColumns() {
+	suite.RunScript(`CREATE TABLE myTable (id INTEGER, name STRING)`)
+
+	insert := func(cols []string, values ...command.Expr) error {
+		var insertCols []command.Column
+		for _, col := range cols {
+			insertCols = append(insertCols, command.Column{Expr: command.ConstantLiteral{Value: col}})
+		}
+		_, err := suite.engine.evaluateInsert(suite.ctx, command.Insert{
+			Table: command.SimpleTable{Table: "myTable"},
+			Cols:  insertCols,
+			Input: command.Values{Values: [][]command.Expr{values}},
+		})
+		return err
+	}
+	one := command.ConstantLiteral{Value: "1", Numeric: true}
+
+	suite.Error(insert([]string{"unknown"}, one))
+	suite.Error(insert([]string{"id", "id"}, one, one))
+	suite.Error(insert([]string{"id"}, one, one))
+	suite.Error(insert(nil, one))
+	suite.NoError(insert([]string{"id"}, one))
+}
+
+func (suite *InsertSuite) TestInsertSelectFromSelf() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, 'a'), (2, 'b');
+INSERT INTO myTable SELECT * FROM myTable;
+INSERT INTO myTable (name, id) SELECT name, id FROM myTable WHERE id = 2`)
+
+	tbl, err := suite.engine.Evaluate(command.Scan{Table: command.SimpleTable{Table: "myTable"}})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+			{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+		},
+	), tbl)
+}
+
+func (suite *InsertSuite) TestInsertSelectFromSelfMultiplePages() {
+	suite.RunScript(`CREATE TABLE myTable (id INTEGER, name STRING)`)
+
+	// the rows span multiple pages, so that rows inserted into a page, that
+	// was not yet read by the input, are not read again
+	var values [][]command.Expr
+	for i := 0; i < 100; i++ {
+		values = append(values, []command.Expr{
+			command.ConstantLiteral{Value: strconv.Itoa(i), Numeric: true},
+			command.ConstantLiteral{Value: strings.Repeat("x", 500)},
+		})
+	}
+	_, err := suite.engine.Evaluate(command.Insert{
+		Table: command.SimpleTable{Table: "myTable"},
+		Input: command.Values{Values: values},
+	})
+	suite.NoError(err)
+	suite.RunScript(`INSERT INTO myTable SELECT * FROM myTable`)
+
+	tbl, err := suite.engine.Evaluate(command.Scan{Table: command.SimpleTable{Table: "myTable"}})
+	suite.NoError(err)
+	suite.Equal(200, len(suite.rows(tbl)))
+}
+
+func (suite *InsertSuite) TestInsertSelectCoercion() {
+	suite.RunScript(`
+CREATE TABLE source (i INTEGER, r REAL, s STRING);
+INSERT INTO source VALUES (1, 2.0, '3'), (4, 5.0, '6.5');
+CREATE TABLE target (r REAL, i INTEGER, s STRING, x REAL);
+INSERT INTO target (r, i, s, x) SELECT i, r, i, s FROM source`)
+
+	tbl, err := suite.engine.Evaluate(command.Scan{Table: command.SimpleTable{Table: "target"}})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "r", Type: types.Real},
+			{QualifiedName: "i", Type: types.Integer},
+			{QualifiedName: "s", Type: types.String},
+			{QualifiedName: "x", Type: types.Real},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewReal(1), types.NewInteger(2), types.NewString("1"), types.NewReal(3)}},
+			{Values: []types.Value{types.NewReal(4), types.NewInteger(5), types.NewString("4"), types.NewReal(6.5)}},
+		},
+	), tbl)
+
+	suite.RunScript(`INSERT INTO source VALUES (7, 7.5, 'seven')`)
+	_, err = suite.engine.Evaluate(command.Insert{
+		Table: command.SimpleTable{Table: "target"},
+		Input: command.Project{
+			Cols: []command.Column{
+				{Expr: command.ColumnReference{Name: "r"}},
+				{Expr: command.ColumnReference{Name: "r"}},
+				{Expr: command.ColumnReference{Name: "s"}},
+				{Expr: command.ColumnReference{Name: "r"}},
+			},
+			Input: command.Scan{Table: command.SimpleTable{Table: "source"}},
+		},
+	})
+	suite.Error(err)
+}
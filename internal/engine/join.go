@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// evaluateJoin evaluates the given join. If the rows of the join are merged
+// based on the equality of columns, which is the case for natural joins and
+// joins with a filter of the form left.col = right.col, a hash join is
+// performed. Otherwise, left and right rows are joined in a nested loop.
+// The columns of tables in the join are qualified with the table name or the
+// alias of the table, so that columns with the same name can be told apart.
+func (e Engine) evaluateJoin(ctx ExecutionContext, join command.Join) (table.Table, error) {
+	defer e.profiler.Enter("join").Exit()
+
+	left, err := e.evaluateJoinInput(ctx, join.Left)
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	right, err := e.evaluateJoinInput(ctx, join.Right)
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+
+	leftCols, err := left.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("left cols: %w", err)
+	}
+	rightCols, err := right.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("right cols: %w", err)
+	}
+
+	tbl := joinedTable{
+		e:         e,
+		ctx:       ctx,
+		left:      left,
+		right:     right,
+		leftCols:  leftCols,
+		rightCols: rightCols,
+	}
+
+	switch join.Type {
+	case command.JoinUnknown, command.JoinInner, command.JoinCross:
+	case command.JoinLeft, command.JoinLeftOuter:
+		tbl.outer = true
+	default:
+		return nil, ErrUnimplemented(join.Type)
+	}
+
+	if join.Natural {
+		if join.Filter != nil {
+			return nil, fmt.Errorf("natural join cannot have a filter")
+		}
+		tbl.keys = commonColumns(leftCols, rightCols)
+
+		// the common columns of the right table are not part of the result
+		dropped := make(map[int]struct{})
+		for _, key := range tbl.keys {
+			dropped[len(leftCols)+key.right] = struct{}{}
+		}
+		return table.NewFilteredCol(tbl, func(i int, _ table.Col) bool {
+			_, drop := dropped[i]
+			return !drop
+		}), nil
+	}
+
+	if join.Filter != nil {
+		if keys, ok := equiJoinKeys(join.Filter, leftCols, rightCols); ok {
+			tbl.keys = keys
+		} else {
+			if err := ensureFilter(join.Filter); err != nil {
+				return nil, err
+			}
+			tbl.filter = join.Filter
+		}
+	}
+
+	return tbl, nil
+}
+
+// evaluateJoinInput evaluates the given list, which is an input of a join. If
+// the list is a scan of a simple table, the columns of the result are qualified
+// with the alias or the name of the scanned table.
+func (e Engine) evaluateJoinInput(ctx ExecutionContext, list command.List) (table.Table, error) {
+	tbl, err := e.evaluateList(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	scan, ok := list.(command.Scan)
+	if !ok {
+		return tbl, nil
+	}
+	simpleTable, ok := scan.Table.(command.SimpleTable)
+	if !ok {
+		return tbl, nil
+	}
+	return table.NewQualifiedCol(tbl, tableQualifier(simpleTable)), nil
+}
+
+// equiJoinKeys returns the join keys for the given filter, if the filter is an
+// equality of a column of the left and a column of the right columns, such as
+//
+//	a.id = b.id
+//
+// If the filter is not of that form, false is returned.
+func equiJoinKeys(filter command.Expr, leftCols, rightCols []table.Col) ([]joinKey, bool) {
+	eq, ok := filter.(command.EqualityExpr)
+	if !ok || eq.Invert {
+		return nil, false
+	}
+	leftRef, ok := eq.Left.(command.ColumnReference)
+	if !ok {
+		return nil, false
+	}
+	rightRef, ok := eq.Right.(command.ColumnReference)
+	if !ok {
+		return nil, false
+	}
+
+	// the columns may be referenced in any order
+	if l, r, ok := uniqueColumnIndices(leftRef.Name, rightRef.Name, leftCols, rightCols); ok {
+		return []joinKey{{left: l, right: r}}, true
+	}
+	if l, r, ok := uniqueColumnIndices(rightRef.Name, leftRef.Name, leftCols, rightCols); ok {
+		return []joinKey{{left: l, right: r}}, true
+	}
+	return nil, false
+}
+
+// uniqueColumnIndices returns the index of the column with the given left name
+// in the left columns, and the index of the column with the given right name in
+// the right columns. If one of the names also references a column of the other
+// side, the columns are ambiguous and false is returned.
+func uniqueColumnIndices(leftName, rightName string, leftCols, rightCols []table.Col) (int, int, bool) {
+	l := columnIndex(leftCols, leftName)
+	r := columnIndex(rightCols, rightName)
+	if l == -1 || r == -1 ||
+		columnIndex(rightCols, leftName) != -1 ||
+		columnIndex(leftCols, rightName) != -1 {
+		return 0, 0, false
+	}
+	return l, r, true
+}
+
+// commonColumns returns the join keys of all columns in the left and right
+// columns, that have the same unqualified name.
+func commonColumns(leftCols, rightCols []table.Col) []joinKey {
+	var keys []joinKey
+	for l, leftCol := range leftCols {
+		_, leftName := table.SplitQualifiedName(leftCol.QualifiedName)
+		for r, rightCol := range rightCols {
+			if _, rightName := table.SplitQualifiedName(rightCol.QualifiedName); leftName == rightName {
+				keys = append(keys, joinKey{left: l, right: r})
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// columnIndex returns the index of the first column that matches the given name
+// or alias, or -1 if there is no such column.
+func columnIndex(cols []table.Col, nameOrAlias string) int {
+	for i, col := range cols {
+		if col.MatchesName(nameOrAlias) {
+			return i
+		}
+	}
+	return -1
+}
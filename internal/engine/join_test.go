@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestJoinSuite(t *testing.T) {
+	suite.Run(t, new(JoinSuite))
+}
+
+type JoinSuite struct {
+	EngineSuite
+}
+
+func (suite *JoinSuite) TestInnerJoin() {
+	suite.setupTables()
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Filter: suite.idsEqual("a.id", "b.id"),
+		Left:   command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right:  command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("a", "b"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(2), types.NewString("zwei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("drei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("trois")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestInnerJoinWithAliases() {
+	suite.setupTables()
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Type:   command.JoinInner,
+		Filter: suite.idsEqual("y.id", "x.id"),
+		Left:   command.Scan{Table: command.SimpleTable{Table: "a", Alias: "x"}},
+		Right:  command.Scan{Table: command.SimpleTable{Table: "b", Alias: "y"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("x", "y"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(2), types.NewString("zwei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("drei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("trois")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestLeftJoin() {
+	suite.setupTables()
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Type:   command.JoinLeft,
+		Filter: suite.idsEqual("a.id", "b.id"),
+		Left:   command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right:  command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("a", "b"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewString("one"), types.NewNull(types.Integer), types.NewNull(types.String)}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(2), types.NewString("zwei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("drei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("trois")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestCrossJoin() {
+	suite.RunScript(`
+CREATE TABLE a (id INTEGER, name STRING);
+CREATE TABLE b (id INTEGER, name STRING);
+INSERT INTO a VALUES (1, "one"), (2, "two");
+INSERT INTO b VALUES (3, "drei")`)
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Type:  command.JoinCross,
+		Left:  command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right: command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("a", "b"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewString("one"), types.NewInteger(3), types.NewString("drei")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(3), types.NewString("drei")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestNaturalJoin() {
+	suite.RunScript(`
+CREATE TABLE a (id INTEGER, name STRING);
+CREATE TABLE b (id INTEGER, price INTEGER);
+INSERT INTO a VALUES (1, "one"), (2, "two");
+INSERT INTO b VALUES (2, 20), (3, 30)`)
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Natural: true,
+		Left:    command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right:   command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "a.id", Type: types.Integer},
+			{QualifiedName: "a.name", Type: types.String},
+			{QualifiedName: "b.price", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(20)}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestNestedLoopJoin() {
+	suite.setupTables()
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Filter: command.GreaterThanExpr{
+			BinaryBase: command.BinaryBase{
+				Left:  command.ColumnReference{Name: "a.id"},
+				Right: command.ColumnReference{Name: "b.id"},
+			},
+		},
+		Left:  command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right: command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("a", "b"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(2), types.NewString("zwei")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) TestJoinWithAmbiguousColumn() {
+	suite.setupTables()
+
+	result, err := suite.engine.evaluateJoin(suite.ctx, command.Join{
+		Filter: suite.idsEqual("id", "b.id"),
+		Left:   command.Scan{Table: command.SimpleTable{Table: "a"}},
+		Right:  command.Scan{Table: command.SimpleTable{Table: "b"}},
+	})
+	suite.NoError(err)
+
+	// the unqualified column id is resolved to the first matching column,
+	// which is a.id, so the rows are joined in a nested loop
+	suite.EqualTables(table.NewInMemory(
+		suite.joinedCols("a", "b"),
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2), types.NewString("two"), types.NewInteger(2), types.NewString("zwei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("drei")}},
+			{Values: []types.Value{types.NewInteger(3), types.NewString("three"), types.NewInteger(3), types.NewString("trois")}},
+		},
+	), result)
+}
+
+func (suite *JoinSuite) setupTables() {
+	suite.RunScript(`
+CREATE TABLE a (id INTEGER, name STRING);
+CREATE TABLE b (id INTEGER, name STRING);
+INSERT INTO a VALUES (1, "one"), (2, "two"), (3, "three");
+INSERT INTO b VALUES (2, "zwei"), (3, "drei"), (3, "trois"), (4, "vier")`)
+}
+
+func (suite *JoinSuite) joinedCols(left, right string) []table.Col {
+	return []table.Col{
+		{QualifiedName: left + ".id", Type: types.Integer},
+		{QualifiedName: left + ".name", Type: types.String},
+		{QualifiedName: right + ".id", Type: types.Integer},
+		{QualifiedName: right + ".name", Type: types.String},
+	}
+}
+
+func (suite *JoinSuite) idsEqual(left, right string) command.Expr {
+	return command.EqualityExpr{
+		BinaryBase: command.BinaryBase{
+			Left:  command.ColumnReference{Name: left},
+			Right: command.ColumnReference{Name: right},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// joinedTable is a table, which joins the rows of a left and a right table.
+// Every row of the joined table consists of the values of a left row, followed
+// by the values of a right row.
+type joinedTable struct {
+	left      table.Table
+	right     table.Table
+	leftCols  []table.Col
+	rightCols []table.Col
+	// keys are the columns that must be equal in a left and a right row for
+	// the rows to be joined. If there are keys, a hash join is performed.
+	keys []joinKey
+	// filter is the filter that a joined row must match. The filter is only
+	// used if there are no keys.
+	filter command.Expr
+	// outer indicates, that a left row that has no matching right row is
+	// joined with a right row consisting of null values.
+	outer bool
+	ctx   ExecutionContext
+	e     Engine
+}
+
+// joinKey is a pair of column indices, one of the left and one of the right
+// table, whose values must be equal for a left and a right row to be joined.
+type joinKey struct {
+	left  int
+	right int
+}
+
+// Cols returns the columns of the left table, followed by the columns of the
+// right table.
+func (t joinedTable) Cols() ([]table.Col, error) {
+	cols := make([]table.Col, 0, len(t.leftCols)+len(t.rightCols))
+	cols = append(cols, t.leftCols...)
+	cols = append(cols, t.rightCols...)
+	return cols, nil
+}
+
+// Rows returns a row iterator of the joined table. Use it to read rows one by one.
+func (t joinedTable) Rows() (table.RowIterator, error) {
+	return t.createIterator()
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+type joinedTableIterator struct {
+	table joinedTable
+	cols  []table.Col
+	left  table.RowIterator
+	right table.RowIterator
+
+	// current is the current left row, for which matching right rows are
+	// being searched.
+	current    table.Row
+	hasCurrent bool
+	matched    bool
+
+	// hashed holds all right rows by their join key. It is built when the
+	// first row is requested, and only used if the joined table has keys.
+	hashed     map[string][]table.Row
+	candidates []table.Row
+}
+
+func (t joinedTable) createIterator() (*joinedTableIterator, error) {
+	cols, err := t.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	left, err := t.left.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("left rows: %w", err)
+	}
+	right, err := t.right.Rows()
+	if err != nil {
+		_ = left.Close()
+		return nil, fmt.Errorf("right rows: %w", err)
+	}
+	return &joinedTableIterator{
+		table: t,
+		cols:  cols,
+		left:  left,
+		right: right,
+	}, nil
+}
+
+// Next returns the next joined row of this table. If there are no more rows,
+// table.ErrEOT is returned.
+func (i *joinedTableIterator) Next() (table.Row, error) {
+	if len(i.table.keys) > 0 && i.hashed == nil {
+		if err := i.buildHashTable(); err != nil {
+			return table.Row{}, fmt.Errorf("build hash table: %w", err)
+		}
+	}
+
+	for {
+		if !i.hasCurrent {
+			next, err := i.left.Next()
+			if err != nil {
+				return table.Row{}, err
+			}
+			if err := i.startLeftRow(next); err != nil {
+				return table.Row{}, err
+			}
+		}
+
+		right, ok, err := i.nextMatch()
+		if err != nil {
+			return table.Row{}, err
+		}
+		if ok {
+			i.matched = true
+			return i.join(right), nil
+		}
+
+		// no more matches for the current left row
+		i.hasCurrent = false
+		if i.table.outer && !i.matched {
+			return i.join(i.nullRow()), nil
+		}
+	}
+}
+
+// Reset resets this table iterator, causing it to start from row 0 again.
+func (i *joinedTableIterator) Reset() error {
+	i.hasCurrent = false
+	i.candidates = nil
+	if err := i.left.Reset(); err != nil {
+		return err
+	}
+	return i.right.Reset()
+}
+
+// Close closes the underlying left and right row iterators.
+func (i *joinedTableIterator) Close() error {
+	leftErr := i.left.Close()
+	rightErr := i.right.Close()
+	if leftErr != nil {
+		return leftErr
+	}
+	return rightErr
+}
+
+// startLeftRow makes the given row the current left row, and prepares the
+// search for matching right rows.
+func (i *joinedTableIterator) startLeftRow(row table.Row) error {
+	i.current = row
+	i.hasCurrent = true
+	i.matched = false
+
+	if i.hashed != nil {
+		key, ok := hashKey(row, i.table.keys, func(k joinKey) int { return k.left })
+		if ok {
+			i.candidates = i.hashed[key]
+		} else {
+			i.candidates = nil
+		}
+		return nil
+	}
+
+	if err := i.right.Reset(); err != nil {
+		return fmt.Errorf("reset right: %w", err)
+	}
+	return nil
+}
+
+// nextMatch returns the next right row that matches the current left row. If
+// there are no more matching right rows, false is returned.
+func (i *joinedTableIterator) nextMatch() (table.Row, bool, error) {
+	if i.hashed != nil {
+		if len(i.candidates) == 0 {
+			return table.Row{}, false, nil
+		}
+		next := i.candidates[0]
+		i.candidates = i.candidates[1:]
+		return next, true, nil
+	}
+
+	for {
+		next, err := i.right.Next()
+		if err == table.ErrEOT {
+			return table.Row{}, false, nil
+		} else if err != nil {
+			return table.Row{}, false, err
+		}
+		if i.table.filter == nil {
+			return next, true, nil
+		}
+
+		ok, err := i.table.e.evaluateFilter(i.table.ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: i.cols,
+			Row:  i.join(next),
+		}), i.table.filter)
+		if err != nil {
+			return table.Row{}, false, fmt.Errorf("filter: %w", err)
+		}
+		if ok {
+			return next, true, nil
+		}
+	}
+}
+
+// buildHashTable reads all rows from the right table and stores them by their
+// join key. Rows with a null value in a key column are not stored, since null
+// is not equal to any value.
+func (i *joinedTableIterator) buildHashTable() error {
+	i.hashed = make(map[string][]table.Row)
+	for {
+		next, err := i.right.Next()
+		if err == table.ErrEOT {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if key, ok := hashKey(next, i.table.keys, func(k joinKey) int { return k.right }); ok {
+			i.hashed[key] = append(i.hashed[key], next)
+		}
+	}
+}
+
+// join returns a row consisting of the values of the current left row and the
+// given right row.
+func (i *joinedTableIterator) join(right table.Row) table.Row {
+	vals := make([]types.Value, 0, len(i.current.Values)+len(right.Values))
+	vals = append(vals, i.current.Values...)
+	vals = append(vals, right.Values...)
+	return table.Row{Values: vals}
+}
+
+// nullRow returns a right row, where every value is null.
+func (i *joinedTableIterator) nullRow() table.Row {
+	vals := make([]types.Value, len(i.table.rightCols))
+	for idx, col := range i.table.rightCols {
+		vals[idx] = types.NewNull(col.Type)
+	}
+	return table.Row{Values: vals}
+}
+
+// hashKey computes the hash table key of the given row. The index function
+// selects the column index of the row from a join key. If one of the key values
+// is null, false is returned.
+func hashKey(row table.Row, keys []joinKey, index func(joinKey) int) (string, bool) {
+	var buf strings.Builder
+	for _, key := range keys {
+		val := row.Values[index(key)]
+		if val.IsNull() {
+			return "", false
+		}
+		buf.WriteString(val.Type().Name())
+		buf.WriteByte(':')
+		buf.WriteString(val.String())
+		buf.WriteByte(0)
+	}
+	return buf.String(), true
+}
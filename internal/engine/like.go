@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// likeElem is an element of a LIKE pattern, which is either one of the
+// wildcards '%' and '_', or a character, that matches itself.
+type likeElem struct {
+	char     rune
+	wildcard bool
+}
+
+// evaluateLikeExpr evaluates whether the value of the given expression matches
+// its pattern. As in SQLite, upper and lower case ASCII letters match each
+// other, while all other characters only match themselves. If the expression
+// is inverted, the result is negated. Values
+// and patterns, that are not strings, are matched as their string
+// representation. If the value, the pattern or the escape character is NULL,
+// the expression evaluates to false, even if it is inverted.
+func (e Engine) evaluateLikeExpr(ctx ExecutionContext, expr command.LikeExpr) (bool, error) {
+	value, err := e.evaluateExpression(ctx, expr.Value)
+	if err != nil {
+		return false, fmt.Errorf("value: %w", err)
+	}
+	pattern, ok, err := e.evaluateLikePattern(ctx, expr)
+	if err != nil || !ok || value.IsNull() {
+		return false, err
+	}
+	return matchLike([]rune(likeString(value)), pattern) != expr.Invert, nil
+}
+
+// evaluateLikePattern evaluates the pattern of the given expression with its
+// escape character. If the pattern or the escape character is NULL, false is
+// returned.
+func (e Engine) evaluateLikePattern(ctx ExecutionContext, expr command.LikeExpr) ([]likeElem, bool, error) {
+	pattern, err := e.evaluateExpression(ctx, expr.Pattern)
+	if err != nil {
+		return nil, false, fmt.Errorf("pattern: %w", err)
+	}
+	if pattern.IsNull() {
+		return nil, false, nil
+	}
+
+	var escape *rune
+	if expr.Escape != nil {
+		value, err := e.evaluateExpression(ctx, expr.Escape)
+		if err != nil {
+			return nil, false, fmt.Errorf("escape: %w", err)
+		}
+		if value.IsNull() {
+			return nil, false, nil
+		}
+		runes := []rune(likeString(value))
+		if len(runes) != 1 {
+			return nil, false, fmt.Errorf("escape %v is not a single character", value)
+		}
+		escape = &runes[0]
+	}
+
+	elems, err := parseLikePattern(likeString(pattern), escape)
+	if err != nil {
+		return nil, false, err
+	}
+	return elems, true, nil
+}
+
+// likeString returns the string, that is matched for the given value, which
+// must not be NULL.
+func likeString(value types.Value) string {
+	if str, ok := value.(types.StringValue); ok {
+		return str.Value
+	}
+	return value.String()
+}
+
+// parseLikePattern parses the given pattern into its elements. If the escape
+// character is not nil, the character following it is a character, even if it
+// is a wildcard or the escape character.
+func parseLikePattern(pattern string, escape *rune) ([]likeElem, error) {
+	runes := []rune(pattern)
+	elems := make([]likeElem, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		if escape != nil && runes[i] == *escape {
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("pattern %q ends with the escape character", pattern)
+			}
+			elems = append(elems, likeElem{char: runes[i]})
+			continue
+		}
+		elems = append(elems, likeElem{
+			char:     runes[i],
+			wildcard: runes[i] == '%' || runes[i] == '_',
+		})
+	}
+	return elems, nil
+}
+
+// matchLike returns whether the given value matches the given pattern. When a
+// character of the value doesn't match, the last '%' of the pattern is matched
+// against one more character, so that the value is scanned only once for every
+// '%' of the pattern.
+func matchLike(value []rune, pattern []likeElem) bool {
+	// star is the position after the last '%' in the pattern, or -1, if no
+	// '%' was matched yet, and mark is the position in the value, after the
+	// characters matched by that '%'
+	v, p, star, mark := 0, 0, -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p].isMany():
+			p++
+			star, mark = p, v
+		case p < len(pattern) && pattern[p].matches(value[v]):
+			v++
+			p++
+		case star >= 0:
+			mark++
+			v, p = mark, star
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p].isMany() {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// likePrefix returns the characters of the given pattern, that precede the
+// first wildcard or the first ASCII letter. All values, that match the
+// pattern, start with the prefix. Letters are not part of the prefix, since
+// they also match letters of the other case.
+func likePrefix(pattern []likeElem) string {
+	var prefix strings.Builder
+	for _, elem := range pattern {
+		if elem.wildcard || isASCIILetter(elem.char) {
+			break
+		}
+		prefix.WriteRune(elem.char)
+	}
+	return prefix.String()
+}
+
+// isMany returns whether this element is the wildcard '%', which matches any
+// sequence of characters.
+func (e likeElem) isMany() bool {
+	return e.wildcard && e.char == '%'
+}
+
+// matches returns whether this element, which must not be the wildcard '%',
+// matches the given character. ASCII letters match regardless of their case.
+func (e likeElem) matches(r rune) bool {
+	if e.wildcard {
+		return true
+	}
+	return foldASCII(e.char) == foldASCII(r)
+}
+
+// foldASCII returns the lower case of the given character, if it is an upper
+// case ASCII letter, and the character itself otherwise.
+func foldASCII(r rune) rune {
+	if 'A' <= r && r <= 'Z' {
+		return r + 'a' - 'A'
+	}
+	return r
+}
+
+// isASCIILetter returns whether the given character is an ASCII letter.
+func isASCIILetter(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestMatchLike(t *testing.T) {
+	escape := '!'
+	for _, tt := range []struct {
+		value   string
+		pattern string
+		escape  *rune
+		want    bool
+	}{
+		{"abc", "abc", nil, true},
+		{"abc", "ABC", nil, true},
+		{"aBc", "Ab_", nil, true},
+		{"äöü", "ÄÖÜ", nil, false},
+		{"abc", "ab", nil, false},
+		{"abc", "a%", nil, true},
+		{"abc", "%c", nil, true},
+		{"abc", "%b%", nil, true},
+		{"abc", "%", nil, true},
+		{"", "%", nil, true},
+		{"", "_", nil, false},
+		{"abc", "a_c", nil, true},
+		{"abc", "a_", nil, false},
+		{"abcbc", "a%bc", nil, true},
+		{"abcbd", "a%bc", nil, false},
+		{"aXbXc", "a%b%c", nil, true},
+		{"äöü", "_ö_", nil, true},
+		{"a%c", "a!%c", &escape, true},
+		{"abc", "a!%c", &escape, false},
+		{"a!c", "a!!c", &escape, true},
+		{"a_c", "a!_%", &escape, true},
+	} {
+		pattern, err := parseLikePattern(tt.pattern, tt.escape)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.want, matchLike([]rune(tt.value), pattern), "%v LIKE %v", tt.value, tt.pattern)
+	}
+
+	_, err := parseLikePattern("a!", &escape)
+	assert.Error(t, err)
+}
+
+func TestLikeSuite(t *testing.T) {
+	suite.Run(t, new(LikeSuite))
+}
+
+type LikeSuite struct {
+	EngineSuite
+}
+
+func (suite *LikeSuite) TestLike() {
+	suite.createMyTable("id INTEGER, name STRING", "(1, 'apple'), (2, 'banana'), (3, 'apricot'), (12, 'a_b')")
+	suite.Require().NoError(suite.exec(`INSERT INTO myTable (id) VALUES (4)`))
+
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(3),
+		intRow(12),
+	}, suite.selectRows(`SELECT id FROM myTable WHERE name LIKE 'a%'`))
+	// NULL neither matches a pattern, nor doesn't match it
+	suite.Equal([]table.Row{
+		intRow(2),
+	}, suite.selectRows(`SELECT id FROM myTable WHERE name NOT LIKE 'a%'`))
+	suite.Equal([]table.Row{
+		intRow(12),
+	}, suite.selectRows(`SELECT id FROM myTable WHERE name LIKE 'a!_%' ESCAPE '!'`))
+	// values, that are not strings, are matched as strings
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(12),
+	}, suite.selectRows(`SELECT id FROM myTable WHERE id LIKE '1%'`))
+
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewBool(true)}},
+	}, suite.selectRows(`SELECT 'abc' LIKE '_b_'`))
+	// ASCII letters match regardless of their case
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(3),
+	}, suite.selectRows(`SELECT id FROM myTable WHERE name LIKE 'AP%'`))
+}
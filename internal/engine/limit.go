@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// evaluateLimit evaluates the given limit. The rows of the input list are
+// obtained lazily, and no more rows are obtained once the limit is reached. A
+// negative limit means, that there is no limit.
+func (e Engine) evaluateLimit(ctx ExecutionContext, l command.Limit) (table.Table, error) {
+	defer e.profiler.Enter("limit").Exit()
+
+	limit, err := e.evaluateInteger(ctx, l.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("limit: %w", err)
+	}
+
+	origin, err := e.evaluateList(ctx, l.Input)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	if limit < 0 {
+		return origin, nil
+	}
+	return table.NewLimitedRow(origin, limit), nil
+}
+
+// evaluateOffset evaluates the given offset. The rows before the offset are
+// skipped when the first row is obtained. A negative offset is treated like an
+// offset of zero.
+func (e Engine) evaluateOffset(ctx ExecutionContext, o command.Offset) (table.Table, error) {
+	defer e.profiler.Enter("offset").Exit()
+
+	offset, err := e.evaluateInteger(ctx, o.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("offset: %w", err)
+	}
+
+	origin, err := e.evaluateList(ctx, o.Input)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	if offset <= 0 {
+		return origin, nil
+	}
+	return table.NewOffsetRow(origin, offset), nil
+}
+
+// evaluateInteger evaluates the given expression, and returns an error if the
+// result is not an integer.
+func (e Engine) evaluateInteger(ctx ExecutionContext, expr command.Expr) (int64, error) {
+	val, err := e.evaluateExpression(ctx, expr)
+	if err != nil {
+		return 0, err
+	}
+	if !val.Is(types.Integer) {
+		return 0, fmt.Errorf("%v is not an integer", val)
+	}
+	return val.(types.IntegerValue).Value, nil
+}
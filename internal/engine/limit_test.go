@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestLimitSuite(t *testing.T) {
+	suite.Run(t, new(LimitSuite))
+}
+
+type LimitSuite struct {
+	EngineSuite
+}
+
+func (suite *LimitSuite) TestLimitAndOffset() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER);
+INSERT INTO myTable VALUES (1), (2), (3), (4), (5)`)
+
+	result, err := suite.engine.evaluateLimit(suite.ctx, command.Limit{
+		Limit: command.ConstantLiteral{Value: "2", Numeric: true},
+		Input: command.Offset{
+			Offset: command.ConstantLiteral{Value: "1", Numeric: true},
+			Input:  command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+		},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2)}},
+			{Values: []types.Value{types.NewInteger(3)}},
+		},
+	), result)
+}
+
+func (suite *LimitSuite) TestNegativeLimit() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER);
+INSERT INTO myTable VALUES (1), (2)`)
+
+	result, err := suite.engine.evaluateLimit(suite.ctx, command.Limit{
+		Limit: command.SubExpression{
+			BinaryBase: command.BinaryBase{
+				Left:  command.ConstantLiteral{Value: "0", Numeric: true},
+				Right: command.ConstantLiteral{Value: "1", Numeric: true},
+			},
+		},
+		Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1)}},
+			{Values: []types.Value{types.NewInteger(2)}},
+		},
+	), result)
+}
+
+func (suite *LimitSuite) TestLimitNotAnInteger() {
+	_, err := suite.engine.evaluateLimit(suite.ctx, command.Limit{
+		Limit: command.ConstantLiteral{Value: "abc"},
+		Input: command.Values{},
+	})
+	suite.EqualError(err, "limit: abc is not an integer")
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// materializedTable is a table, whose rows are read from an underlying table
+// only once. The rows are kept, when they are read from the underlying table
+// for the first time, and all iterators of the table share the kept rows.
+// Rows are only read from the underlying table, when an iterator reads past
+// the kept rows, so that the underlying table is only read as far as any
+// iterator of the table reads.
+type materializedTable struct {
+	*materializedRows
+}
+
+// materializedRows are the rows of a materialized table, that were read from
+// its underlying table.
+type materializedRows struct {
+	origin table.Table
+	cols   []table.Col
+	// source is the iterator over the underlying table, or nil, if no rows
+	// were read yet, or all rows were read.
+	source table.RowIterator
+	rows   []table.Row
+	done   bool
+}
+
+func newMaterializedTable(origin table.Table) (materializedTable, error) {
+	cols, err := origin.Cols()
+	if err != nil {
+		return materializedTable{}, err
+	}
+	return materializedTable{
+		&materializedRows{
+			origin: origin,
+			cols:   cols,
+		},
+	}, nil
+}
+
+// Cols returns the columns of the underlying table.
+func (t materializedTable) Cols() ([]table.Col, error) {
+	return t.cols, nil
+}
+
+// Rows returns a row iterator over the rows of the underlying table, which
+// reads the kept rows, before rows are read from the underlying table.
+func (t materializedTable) Rows() (table.RowIterator, error) {
+	return &materializedTableIterator{rows: t.materializedRows}, nil
+}
+
+// row returns the row with the given index, which is read from the underlying
+// table, if it wasn't read yet.
+func (r *materializedRows) row(index int) (table.Row, error) {
+	for index >= len(r.rows) {
+		if r.done {
+			return table.Row{}, table.ErrEOT
+		}
+		if r.source == nil {
+			source, err := r.origin.Rows()
+			if err != nil {
+				return table.Row{}, err
+			}
+			r.source = source
+		}
+		next, err := r.source.Next()
+		if err == table.ErrEOT {
+			r.done = true
+			err = r.source.Close()
+			r.source = nil
+			if err != nil {
+				return table.Row{}, err
+			}
+			continue
+		} else if err != nil {
+			return table.Row{}, err
+		}
+		r.rows = append(r.rows, next)
+	}
+	return r.rows[index], nil
+}
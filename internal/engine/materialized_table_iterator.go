@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// materializedTableIterator iterates over the rows of a materialized table.
+type materializedTableIterator struct {
+	rows  *materializedRows
+	index int
+}
+
+// Next returns the next row of the materialized table.
+func (i *materializedTableIterator) Next() (table.Row, error) {
+	next, err := i.rows.row(i.index)
+	if err != nil {
+		return table.Row{}, err
+	}
+	i.index++
+	return next, nil
+}
+
+// Reset makes this iterator start over from the first row.
+func (i *materializedTableIterator) Reset() error {
+	i.index = 0
+	return nil
+}
+
+// Close closes this iterator. The rows of the materialized table are kept.
+func (i *materializedTableIterator) Close() error {
+	return nil
+}
@@ -49,3 +49,32 @@ func WithTransactionManager(txmgr transaction.Manager) Option {
 		e.txmgr = txmgr
 	}
 }
+
+// WithSortMemoryBudget sets the amount of bytes, that rows may occupy in memory
+// while they are being sorted. If a sort exceeds this budget, sorted runs of
+// rows are spilled to temporary files, which are merged afterwards.
+func WithSortMemoryBudget(budget int) Option {
+	return func(e *Engine) {
+		e.sortMemoryBudget = budget
+	}
+}
+
+// WithDistinctMemoryBudget sets the amount of bytes, that distinct rows may
+// occupy in memory while duplicates are being removed. If this budget is
+// exceeded, rows that were not yet encountered are partitioned into temporary
+// files, which are deduplicated afterwards.
+func WithDistinctMemoryBudget(budget int) Option {
+	return func(e *Engine) {
+		e.distinctMemoryBudget = budget
+	}
+}
+
+// WithDeferredForeignKeys defers the checks of all foreign keys until the
+// transaction is committed, as if all foreign keys were declared DEFERRABLE
+// INITIALLY DEFERRED. Foreign keys with the RESTRICT action are still checked
+// immediately.
+func WithDeferredForeignKeys() Option {
+	return func(e *Engine) {
+		e.deferForeignKeys = true
+	}
+}
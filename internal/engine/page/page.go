@@ -101,17 +101,29 @@ func (p *Page) DeleteCell(key []byte) (ok bool, err error) {
 	}
 
 	freeBlock := p.freeBlock()
-	// delete the cell by overwriting it will cell data from the right
+	// delete the cell by overwriting it with the cell data from the right, all
+	// offsets are relative to the body, which starts after the header
 	endOfCellData := slot.Offset + slot.Size // end of cell
 	// delete (zero) the page data in case the move and zero doesn't fully overwrite it
-	p.zero(slot.Offset, slot.Size)
-	p.moveAndZero(endOfCellData, freeBlock.Offset-endOfCellData, slot.Offset)
+	p.zero(HeaderSize+slot.Offset, slot.Size)
+	if endOfCellData < freeBlock.Offset {
+		p.moveAndZero(HeaderSize+endOfCellData, freeBlock.Offset-endOfCellData, HeaderSize+slot.Offset)
+	}
 
-	// delete the slot
-	startOfAllOffsetData := freeBlock.Offset + freeBlock.Size
-	startOfOffsetData := startOfAllOffsetData + slotIndex*SlotByteSize
-	p.zero(startOfOffsetData, SlotByteSize)
-	p.moveAndZero(startOfAllOffsetData, startOfOffsetData-startOfAllOffsetData, startOfAllOffsetData+SlotByteSize)
+	// delete the slot and shift the offsets of all cells that were moved
+	slots := p.OccupiedSlots()
+	slots = append(slots[:slotIndex], slots[slotIndex+1:]...)
+	for i := range slots {
+		if slots[i].Offset > slot.Offset {
+			slots[i].Offset -= slot.Size
+		}
+	}
+	oldSlotsSize := (uint16(len(slots)) + 1) * SlotByteSize
+	p.zero(HeaderSize+BodySize-oldSlotsSize, oldSlotsSize)
+	slotsSize := len(slots) * int(SlotByteSize)
+	for i, s := range slots {
+		s.encodeInto(p.body[BodySize-slotsSize+i*int(SlotByteSize):])
+	}
 
 	// update header information
 	p.setFreeBlockStart(freeBlock.Offset - slot.Size)
@@ -171,6 +183,14 @@ func (p *Page) CopyOfData() []byte {
 	return cp
 }
 
+// Restore overwrites the data of this page with the given data, which must
+// have been obtained with CopyOfData from this page. This is not safe for
+// concurrent use.
+func (p *Page) Restore(data []byte) {
+	copy(p.data, data)
+	p.dirty = true
+}
+
 // FreeSpace returns the amount of free bytes that are available on this page.
 func (p *Page) FreeSpace() uint16 {
 	return p.freeBlock().Size
@@ -180,7 +200,8 @@ func (p *Page) FreeSpace() uint16 {
 // available to fit the given record cell.
 func (p *Page) CanAccommodateRecord(rec RecordCell) bool {
 	// FIXME: respect PCTFREE
-	return int(p.FreeSpace()) >= len(encodeRecordCell(rec))
+	// the cell needs a slot in addition to the cell data
+	return int(p.FreeSpace()) >= len(encodeRecordCell(rec))+int(SlotByteSize)
 }
 
 func load(data []byte) (*Page, error) {
@@ -221,7 +242,18 @@ func (p *Page) findCell(key []byte) (slotIndex uint16, cellSlot Slot, cell CellT
 	if result == len(offsets) {
 		return 0, Slot{}, nil, false
 	}
-	return uint16(result), offsets[result], p.CellAt(offsets[result]), true
+	cell = p.CellAt(offsets[result])
+	var cellKey []byte
+	switch c := cell.(type) {
+	case RecordCell:
+		cellKey = c.Key
+	case PointerCell:
+		cellKey = c.Key
+	}
+	if !bytes.Equal(cellKey, key) {
+		return 0, Slot{}, nil, false
+	}
+	return uint16(result), offsets[result], cell, true
 }
 
 func (p *Page) storePointerCell(cell PointerCell) error {
@@ -254,7 +286,7 @@ func (p *Page) setFreeBlockStart(newStart uint16) {
 func (p *Page) storeRawCell(rawCell []byte) error {
 	size := uint16(len(rawCell))
 	slot := p.freeBlock()
-	if size > slot.Size {
+	if size+SlotByteSize > slot.Size {
 		return ErrPageFull
 	}
 	copy(p.body[slot.Offset:], rawCell)
@@ -134,6 +134,29 @@ func (suite *PageSuite) TestPage_DeleteCell() {
 	suite.EqualValues(suite.page.freeBlock().Offset, cellDataLength)
 	suite.EqualValues(BodySize-2*SlotByteSize-cellDataLength, suite.page.freeBlock().Size)
 	suite.EqualValues(2, suite.page.CellCount())
+
+	// the remaining cells must still be readable
+	ct, ok := suite.page.Cell(cells[0].Key)
+	suite.True(ok)
+	suite.Equal(cells[0], ct)
+	ct, ok = suite.page.Cell(cells[1].Key)
+	suite.True(ok)
+	suite.Equal(cells[1], ct)
+
+	ok, err = suite.page.DeleteCell(cells[2].Key)
+	suite.NoError(err)
+	suite.False(ok)
+
+	// delete the first cell, so that the cell data of the second cell has to
+	// be moved
+	ok, err = suite.page.DeleteCell(cells[0].Key)
+	suite.NoError(err)
+	suite.True(ok)
+	suite.EqualValues(1, suite.page.CellCount())
+	suite.EqualValues(12, suite.page.freeBlock().Offset)
+	ct, ok = suite.page.Cell(cells[1].Key)
+	suite.True(ok)
+	suite.Equal(cells[1], ct)
 }
 
 func (suite *PageSuite) TestPage_findCell() {
@@ -28,7 +28,7 @@ func (e Engine) newProjectedTable(ctx ExecutionContext, originalTable table.Tabl
 
 	// compute the column names
 	var cols []table.Col
-	for i, colNameExpr := range columnExpressions {
+	for _, colNameExpr := range columnExpressions {
 		switch expr := colNameExpr.Expr.(type) {
 		case command.ColumnReference:
 			if expr.Name == "*" {
@@ -36,11 +36,22 @@ func (e Engine) newProjectedTable(ctx ExecutionContext, originalTable table.Tabl
 				if err != nil {
 					return projectedTable{}, fmt.Errorf("cols: %w", err)
 				}
-				cols = append(cols, originalTableCols...)
+				for _, idx := range asteriskColumnIndices(originalTableCols, colNameExpr.Table) {
+					cols = append(cols, originalTableCols[idx])
+				}
 			} else {
 				foundCol, ok := table.FindColumnForNameOrAlias(originalTable, expr.Name)
 				if !ok {
-					return projectedTable{}, ErrNoSuchColumn(expr.Name)
+					// the NEW or OLD row of a trigger, or a column of an
+					// outer query
+					val, err := e.evaluateColumnReference(ctx, expr)
+					if err != nil {
+						return projectedTable{}, err
+					}
+					foundCol = table.Col{
+						QualifiedName: expr.Name,
+						Type:          val.Type(),
+					}
 				}
 				cols = append(cols, foundCol)
 			}
@@ -58,10 +69,42 @@ func (e Engine) newProjectedTable(ctx ExecutionContext, originalTable table.Tabl
 					Type:          evaluatedName.Type(),
 				})
 			}
+		case command.SubqueryExpr, command.ExistsExpr, command.InExpr:
+			// the column is named after the subquery rather than its value,
+			// which may be different for every row
+			typ, err := e.projectedExpressionType(ctx, originalTable, expr)
+			if err != nil {
+				return projectedTable{}, fmt.Errorf("col type: %w", err)
+			}
+			name := expr.String()
+			if subquery, ok := expr.(command.SubqueryExpr); ok && subquery.Source != "" {
+				name = subquery.Source
+			}
+			cols = append(cols, table.Col{
+				QualifiedName: name,
+				Type:          typ,
+			})
+		case command.RaiseExpr:
+			// RAISE is only evaluated for the rows of the table
+			cols = append(cols, table.Col{
+				QualifiedName: expr.String(),
+				Type:          types.String,
+			})
 		default:
 			colName, err := e.evaluateExpression(ctx, colNameExpr.Expr)
 			if err != nil {
-				return projectedTable{}, fmt.Errorf("col name: %w", err)
+				// the expression references columns of the original table, so
+				// the column is named after the expression instead
+				typ, typErr := e.projectedExpressionType(ctx, originalTable, colNameExpr.Expr)
+				if typErr != nil {
+					return projectedTable{}, fmt.Errorf("col name: %w", err)
+				}
+				cols = append(cols, table.Col{
+					QualifiedName: colNameExpr.Expr.String(),
+					Alias:         colNameExpr.Alias,
+					Type:          typ,
+				})
+				break
 			}
 			if !colName.Is(types.String) {
 				colNameStr, err := types.String.Cast(colName)
@@ -81,13 +124,66 @@ func (e Engine) newProjectedTable(ctx ExecutionContext, originalTable table.Tabl
 				})
 			}
 		}
-		cols[i].Alias = colNameExpr.Alias
+		// an asterisk can't have an alias, and may not add any columns
+		if ref, ok := colNameExpr.Expr.(command.ColumnReference); !ok || ref.Name != "*" {
+			cols[len(cols)-1].Alias = colNameExpr.Alias
+		}
 	}
 
 	tbl.columns = cols
 	return tbl, nil
 }
 
+// projectedExpressionType returns the type of the given expression, when it is
+// evaluated against the first row of the given table, or against a row of NULL
+// values, if the table is empty.
+func (e Engine) projectedExpressionType(ctx ExecutionContext, originalTable table.Table, expr command.Expr) (types.Type, error) {
+	cols, err := originalTable.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	rows, err := originalTable.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	row, err := rows.Next()
+	if err == table.ErrEOT {
+		row = table.Row{Values: make([]types.Value, len(cols))}
+		for i, col := range cols {
+			row.Values[i] = types.NewNull(col.Type)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	value, err := e.evaluateExpression(ctx.IntermediateRow(table.RowWithColInfo{
+		Cols: cols,
+		Row:  row,
+	}), expr)
+	if err != nil {
+		return nil, err
+	}
+	return value.Type(), nil
+}
+
+// asteriskColumnIndices returns the indices of the given columns, that are
+// selected by an asterisk with the given table name, as in
+//
+//	SELECT myTable.* FROM ...
+//
+// If the table name is empty, all columns are selected.
+func asteriskColumnIndices(cols []table.Col, tableName string) []int {
+	indices := make([]int, 0, len(cols))
+	for i, col := range cols {
+		if qualifier, _ := table.SplitQualifiedName(col.QualifiedName); tableName == "" || qualifier == tableName {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
 // Cols returns the columns of the projected table.
 func (t projectedTable) Cols() ([]table.Col, error) {
 	return t.columns, nil
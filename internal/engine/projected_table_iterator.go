@@ -53,8 +53,9 @@ func (i *projectedTableIterator) Next() (table.Row, error) {
 		nextUnderlying = table.Row{} // this is what we expect right here, but we do this to avoid a warning
 		if err != table.ErrEOT {
 			return table.Row{}, err
-		} else if err == table.ErrEOT && i.rowCounter > 0 {
-			// Only allow ErrEOT if there's already been a row returned.
+		} else if err == table.ErrEOT && (i.rowCounter > 0 || len(i.underlyingColumns) > 0) {
+			// Only allow ErrEOT if there's already been a row returned,
+			// or if the underlying table has columns and is just empty.
 			// If we don't do this, something like `SELECT "a"` wouldn't
 			// return any rows, since the underlyingTable is empty.
 			return table.Row{}, err
@@ -73,8 +74,12 @@ func (i *projectedTableIterator) Next() (table.Row, error) {
 		})
 
 		if name, ok := col.Expr.(command.ColumnReference); ok && name.Name == "*" {
-			// add all underlying columns for an asterisk
-			vals = append(vals, nextUnderlying.Values...)
+			// add all selected underlying columns for an asterisk
+			if len(nextUnderlying.Values) > 0 {
+				for _, idx := range asteriskColumnIndices(i.underlyingColumns, col.Table) {
+					vals = append(vals, nextUnderlying.Values[idx])
+				}
+			}
 		} else {
 			val, err := i.e.evaluateExpression(newCtx, col.Expr)
 			if err != nil {
@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// rangeTable is a table, which contains the rows of a table, that are read
+// through an access path, which is not a full scan. The rows are read from the
+// tree of the access path while iterating, in the order of the tree.
+type rangeTable struct {
+	tbl  *Table
+	path accessPath
+}
+
+// Cols returns the columns of the underlying table.
+func (t rangeTable) Cols() ([]table.Col, error) {
+	return t.tbl.Cols()
+}
+
+// Rows returns a row iterator over the rows in the range of the access path.
+func (t rangeTable) Rows() (table.RowIterator, error) {
+	return newRangeTableIterator(t.tbl, t.path)
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/page"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// rangeBatchSize is the amount of entries, that a range table iterator reads
+// from the tree of its access path at once.
+const rangeBatchSize = 64
+
+// rangeTableIterator reads the records in the range of an access path from the
+// tree of the access path, which is either an index or the tree of a clustered
+// table. Entries are read in batches, and since the tree is searched again for
+// every batch, the table may be modified between two calls of Next.
+type rangeTableIterator struct {
+	tbl    *Table
+	schema *dbfs.SchemaFile
+	path   accessPath
+	tree   *btree.Tree
+
+	// start and to are the keys of the range in the tree, and from is the key,
+	// from which the next batch is read. If the range is empty, or all
+	// entries of the range were read, done is set.
+	start, from, to []byte
+	empty, done     bool
+	buffered        []record
+}
+
+func newRangeTableIterator(tbl *Table, path accessPath) (*rangeTableIterator, error) {
+	sf, err := tbl.tx.SchemaFile(tbl.name)
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	tree, err := path.tree(tbl)
+	if err != nil {
+		return nil, err
+	}
+	from, to, ok, err := path.rng.keys()
+	if err != nil {
+		return nil, err
+	}
+	return &rangeTableIterator{
+		tbl:    tbl,
+		schema: sf,
+		path:   path,
+		tree:   tree,
+		start:  from,
+		from:   from,
+		to:     to,
+		empty:  !ok,
+		done:   !ok,
+	}, nil
+}
+
+// Next returns the next row in the range of the access path.
+func (i *rangeTableIterator) Next() (table.Row, error) {
+	rec, err := i.nextRecord()
+	if err != nil {
+		return table.Row{}, err
+	}
+	return rec.row, nil
+}
+
+// Reset makes this iterator start over from the first entry of the range.
+func (i *rangeTableIterator) Reset() error {
+	i.from = i.start
+	i.done = i.empty
+	i.buffered = nil
+	return nil
+}
+
+func (i *rangeTableIterator) Close() error {
+	return nil
+}
+
+// nextRecord returns the record of the next entry in the range of the access
+// path.
+func (i *rangeTableIterator) nextRecord() (record, error) {
+	if len(i.buffered) == 0 {
+		if i.done {
+			return record{}, table.ErrEOT
+		}
+		if err := i.readBatch(); err != nil {
+			return record{}, err
+		}
+		if len(i.buffered) == 0 {
+			return record{}, table.ErrEOT
+		}
+	}
+	rec := i.buffered[0]
+	i.buffered = i.buffered[1:]
+	return rec, nil
+}
+
+// readBatch reads the records of the next entries of the range from the tree.
+func (i *rangeTableIterator) readBatch() error {
+	var keys, values [][]byte
+	if err := i.tree.Scan(i.from, i.to, func(key, value []byte) (bool, error) {
+		keys = append(keys, key)
+		values = append(values, value)
+		return len(keys) < rangeBatchSize, nil
+	}); err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+	if len(keys) < rangeBatchSize {
+		i.done = true
+	} else {
+		// the smallest key, that is greater than the last key
+		last := keys[len(keys)-1]
+		i.from = append(last[:len(last):len(last)], 0x00)
+	}
+
+	// records are loaded after the scan, since loading pages must not
+	// interfere with the scan of the tree
+	i.buffered = make([]record, len(keys))
+	for n, value := range values {
+		if i.path.index == nil {
+			row, err := deserializeRow(i.schema.Columns, i.schema.Defaults, value)
+			if err != nil {
+				return fmt.Errorf("deserialize: %w", err)
+			}
+			i.buffered[n] = record{key: keys[n], row: row}
+			continue
+		}
+		rec, err := i.tbl.loadRecord(page.DecodeID(value), value[page.IDSize:])
+		if err != nil {
+			return fmt.Errorf("index %v: %w", i.path.index.def.Name, err)
+		}
+		i.buffered[n] = rec
+	}
+	return nil
+}
@@ -3,17 +3,28 @@ package engine
 import (
 	"bytes"
 	"fmt"
+	"math"
 
 	"github.com/xqueries/xdb/internal/engine/table"
 	"github.com/xqueries/xdb/internal/engine/types"
 )
 
-var _ = serializeRow // FIXME: remove when InsertRow is implemented, this us just to avoid the 'unused' linter warning
+// nullFrameLength is the length of the frame of a NULL value in a serialized
+// row. A NULL value has no frame content, and since no value can be this long,
+// it can be told apart from an empty value.
+const nullFrameLength = math.MaxUint32
 
 func serializeRow(row table.Row) ([]byte, error) {
 	var buf bytes.Buffer
 
 	for _, value := range row.Values {
+		if value.IsNull() {
+			header := make([]byte, 4)
+			byteOrder.PutUint32(header, nullFrameLength)
+			_, _ = buf.Write(header)
+			continue
+		}
+
 		t := value.Type()
 		if serializer, ok := t.(types.Serializer); ok {
 			serialized, err := serializer.Serialize(value)
@@ -29,7 +40,12 @@ func serializeRow(row table.Row) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func deserializeRow(cols []table.Col, data []byte) (table.Row, error) {
+// deserializeRow deserializes the given data, that was serialized with
+// serializeRow, into a row with the given columns. A row, that was serialized
+// before columns were added to its table, has fewer frames than columns. The
+// values of the missing columns are the given defaults, by the name of the
+// column, or NULL, if a column has no default.
+func deserializeRow(cols []table.Col, defaults map[string]types.Value, data []byte) (table.Row, error) {
 	var serializers []types.Serializer
 	for _, col := range cols {
 		if serializer, ok := col.Type.(types.Serializer); ok {
@@ -42,6 +58,15 @@ func deserializeRow(cols []table.Col, data []byte) (table.Row, error) {
 	buf := bytes.NewBuffer(data)
 	var vals []types.Value
 	for i := range serializers {
+		if buf.Len() == 0 {
+			if defaultValue, ok := defaults[cols[i].QualifiedName]; ok {
+				vals = append(vals, defaultValue)
+			} else {
+				vals = append(vals, types.NewNull(cols[i].Type))
+			}
+			continue
+		}
+
 		// read frame
 		frame := make([]byte, 4)
 		n, err := buf.Read(frame)
@@ -51,6 +76,10 @@ func deserializeRow(cols []table.Col, data []byte) (table.Row, error) {
 		if n != 4 {
 			return table.Row{}, fmt.Errorf("read frame: expected 4 bytes, could only read %v", n)
 		}
+		if byteOrder.Uint32(frame) == nullFrameLength {
+			vals = append(vals, types.NewNull(cols[i].Type))
+			continue
+		}
 		// read record
 		recBuf := make([]byte, byteOrder.Uint32(frame))
 		n, err = buf.Read(recBuf)
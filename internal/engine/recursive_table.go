@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// recursiveTable is a table, which contains the rows of a recursive common
+// table. The iterations of the recursion are evaluated while iterating over
+// the table, so that only the iterations are evaluated, whose rows are read.
+// This allows reading the first rows of a recursion, that never ends.
+type recursiveTable struct {
+	e         Engine
+	tbl       *commonTable
+	recursion command.Recursion
+	// initial are the rows of the initial list of the recursion.
+	initial table.Table
+	cols    []table.Col
+}
+
+// Cols returns the columns of the common table.
+func (t recursiveTable) Cols() ([]table.Col, error) {
+	return t.cols, nil
+}
+
+// Rows returns a row iterator, which evaluates the iterations of the
+// recursion.
+func (t recursiveTable) Rows() (table.RowIterator, error) {
+	return t.createIterator()
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// recursiveTableIterator returns the rows of the iterations of a recursion.
+// The first iteration are the rows of the initial list of the recursion. When
+// all rows of an iteration were returned, the step of the recursion is
+// evaluated, in which the common table holds the rows, that were returned from
+// the previous iteration. If the recursion is distinct, rows, that were
+// already returned, are discarded. The recursion ends with the first
+// iteration, that produces no rows.
+type recursiveTableIterator struct {
+	table recursiveTable
+
+	// current is the iterator over the rows of the current iteration, or nil,
+	// if the recursion ended.
+	current table.RowIterator
+	// added are the rows, that were returned from the current iteration.
+	added []table.Row
+	// seen are the rows, that were already returned, by their hash, if the
+	// recursion is distinct.
+	seen map[uint64][][]types.Value
+}
+
+func (t recursiveTable) createIterator() (*recursiveTableIterator, error) {
+	initial, err := t.initial.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("initial: %w", err)
+	}
+	return &recursiveTableIterator{
+		table:   t,
+		current: initial,
+		seen:    make(map[uint64][][]types.Value),
+	}, nil
+}
+
+// Next returns the next row of the recursion.
+func (i *recursiveTableIterator) Next() (table.Row, error) {
+	for i.current != nil {
+		next, err := i.current.Next()
+		if err == table.ErrEOT {
+			if err := i.nextIteration(); err != nil {
+				return table.Row{}, err
+			}
+			continue
+		} else if err != nil {
+			return table.Row{}, err
+		}
+
+		if len(next.Values) != len(i.table.cols) {
+			return table.Row{}, fmt.Errorf("step returns %d columns, but %v has %d columns", len(next.Values), i.table.tbl.Name, len(i.table.cols))
+		}
+		if i.table.recursion.Distinct {
+			hash := hashValues(0, next.Values)
+			if i.table.e.containsValues(i.seen[hash], next.Values) {
+				continue
+			}
+			i.seen[hash] = append(i.seen[hash], next.Values)
+		}
+		i.added = append(i.added, next)
+		return next, nil
+	}
+	return table.Row{}, table.ErrEOT
+}
+
+// nextIteration closes the iterator of the current iteration, and evaluates
+// the step of the recursion with the rows, that were returned from the current
+// iteration. If no rows were returned, the recursion ends.
+func (i *recursiveTableIterator) nextIteration() error {
+	if err := i.current.Close(); err != nil {
+		return err
+	}
+	i.current = nil
+	if len(i.added) == 0 {
+		return nil
+	}
+
+	stepCtx := i.table.tbl.ctx.withCommonTable(&commonTable{
+		CommonTable: i.table.tbl.CommonTable,
+		rows:        table.NewInMemory(i.table.cols, i.added),
+	})
+	i.added = nil
+	step, err := i.table.e.evaluateList(stepCtx, i.table.recursion.Step)
+	if err != nil {
+		return fmt.Errorf("step: %w", err)
+	}
+	if i.current, err = step.Rows(); err != nil {
+		return fmt.Errorf("step: %w", err)
+	}
+	return nil
+}
+
+// Reset makes this iterator start over with the first iteration of the
+// recursion.
+func (i *recursiveTableIterator) Reset() error {
+	if err := i.Close(); err != nil {
+		return err
+	}
+	initial, err := i.table.initial.Rows()
+	if err != nil {
+		return fmt.Errorf("initial: %w", err)
+	}
+	i.current = initial
+	i.added = nil
+	i.seen = make(map[uint64][][]types.Value)
+	return nil
+}
+
+// Close closes the iterator of the current iteration.
+func (i *recursiveTableIterator) Close() error {
+	if i.current == nil {
+		return nil
+	}
+	err := i.current.Close()
+	i.current = nil
+	return err
+}
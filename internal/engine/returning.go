@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+var _ Inserter = (*recordingInserter)(nil)
+
+// recordingInserter is an inserter, that records all rows that were
+// successfully inserted into the underlying inserter.
+type recordingInserter struct {
+	Inserter
+	rows []table.Row
+}
+
+// Insert inserts the given row into the underlying inserter, and records it,
+// if that succeeded.
+func (i *recordingInserter) Insert(row table.Row) error {
+	if err := i.Inserter.Insert(row); err != nil {
+		return err
+	}
+	i.rows = append(i.rows, row)
+	return nil
+}
+
+// evaluateReturning projects the given rows, that were affected by a data
+// manipulation command on a table with the given columns, onto the columns of
+// the RETURNING clause of that command.
+func (e Engine) evaluateReturning(ctx ExecutionContext, cols []table.Col, rows []table.Row, returning []command.Column) (table.Table, error) {
+	return e.newProjectedTable(ctx, table.NewInMemory(cols, rows), returning)
+}
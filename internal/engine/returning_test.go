@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestReturningSuite(t *testing.T) {
+	suite.Run(t, new(ReturningSuite))
+}
+
+type ReturningSuite struct {
+	EngineSuite
+}
+
+func (suite *ReturningSuite) TestInsertReturning() {
+	suite.setupMyTable()
+
+	result, err := suite.evaluateStatement(`INSERT INTO myTable (id) VALUES (3), (4) RETURNING *, amount + 1 AS next`)
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "name", Type: types.String},
+			{QualifiedName: "amount", Type: types.Integer},
+			{QualifiedName: "amount + 1", Alias: "next", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(3), types.NewString("new"), types.NewInteger(0), types.NewInteger(1)}},
+			{Values: []types.Value{types.NewInteger(4), types.NewString("new"), types.NewInteger(0), types.NewInteger(1)}},
+		},
+	), result)
+}
+
+func (suite *ReturningSuite) TestUpsertReturning() {
+	suite.setupMyTable()
+
+	// the ignored row is not returned, the updated row is returned with its
+	// updated values
+	result, err := suite.evaluateStatement(`INSERT INTO myTable VALUES (1, 'x', 5), (3, 'c', 30)
+ON CONFLICT (id) DO UPDATE SET amount = amount + excluded.amount RETURNING id, amount`)
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+			{QualifiedName: "amount", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1), types.NewInteger(15)}},
+			{Values: []types.Value{types.NewInteger(3), types.NewInteger(30)}},
+		},
+	), result)
+
+	result, err = suite.evaluateStatement(`INSERT OR IGNORE INTO myTable VALUES (1, 'x', 5) RETURNING id`)
+	suite.NoError(err)
+	suite.Empty(suite.rows(result))
+}
+
+func (suite *ReturningSuite) TestUpdateReturning() {
+	suite.setupMyTable()
+
+	result, err := suite.evaluateStatement(`UPDATE myTable SET amount = amount * 2 WHERE id = 2 RETURNING name, amount AS doubled`)
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "name", Type: types.String},
+			{QualifiedName: "amount", Alias: "doubled", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewString("b"), types.NewInteger(40)}},
+		},
+	), result)
+}
+
+func (suite *ReturningSuite) TestDeleteReturning() {
+	suite.setupMyTable()
+
+	result, err := suite.evaluateStatement(`DELETE FROM myTable WHERE amount > 10 RETURNING id`)
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "id", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2)}},
+		},
+	), result)
+
+	// nothing is deleted, so nothing is returned
+	result, err = suite.evaluateStatement(`DELETE FROM myTable WHERE amount > 10 RETURNING id`)
+	suite.NoError(err)
+	suite.Empty(suite.rows(result))
+}
+
+func (suite *ReturningSuite) setupMyTable() {
+	suite.createMyTable(`id INTEGER PRIMARY KEY, name STRING DEFAULT 'new', amount INTEGER DEFAULT 0`, `(1, 'a', 10), (2, 'b', 20)`)
+}
@@ -8,16 +8,72 @@ import (
 )
 
 func (e Engine) evaluateScan(ctx ExecutionContext, s command.Scan) (table.Table, error) {
+	return e.evaluateFilteredScan(ctx, s, command.ConstantBooleanExpr{Value: true})
+}
+
+// evaluateFilteredScan evaluates the given scan, whose rows are filtered by
+// the given filter afterwards. The filter is not applied, but is used to
+// choose how the rows of the table are read, see (Engine).chooseAccessPath.
+func (e Engine) evaluateFilteredScan(ctx ExecutionContext, s command.Scan, filter command.Expr) (table.Table, error) {
 	defer e.profiler.Enter("scan").Exit()
 
 	switch tbl := s.Table.(type) {
 	case command.SimpleTable:
-		return e.scanSimpleTable(ctx, tbl)
+		result, err := e.scanSimpleTable(ctx, tbl, filter)
+		if err != nil || ctx.outer == nil {
+			return result, err
+		}
+		// in a subquery, the columns are qualified, so that a qualified
+		// reference to a column of an outer query doesn't match a column of
+		// the same name of this table
+		return table.NewQualifiedCol(result, tableQualifier(tbl)), nil
+	case command.DerivedTable:
+		return e.scanDerivedTable(ctx, tbl)
 	default:
 		return nil, ErrUnimplemented(fmt.Sprintf("scan %T", tbl))
 	}
 }
 
-func (e Engine) scanSimpleTable(ctx ExecutionContext, tbl command.SimpleTable) (table.Table, error) {
-	return e.LoadTable(ctx.tx, tbl.QualifiedName())
+// tableQualifier returns the name, with which the columns of the given table
+// are qualified, which is the alias of the table, if it has one, or its name.
+func tableQualifier(simple command.SimpleTable) string {
+	if simple.Alias != "" {
+		return simple.Alias
+	}
+	return simple.Table
+}
+
+// scanSimpleTable returns the rows of the given table. If an index or the
+// primary key of a clustered table is chosen to read the rows for the given
+// filter, only the rows in its range are returned, in its order, and they are
+// read while the returned table is iterated. Otherwise, the table itself is
+// returned. If the given table is a view, the query of the view is evaluated
+// instead. Common tables hide tables and views with the same name.
+func (e Engine) scanSimpleTable(ctx ExecutionContext, simple command.SimpleTable, filter command.Expr) (table.Table, error) {
+	if common, ok := ctx.commonTables[simple.QualifiedName()]; ok {
+		return e.scanCommonTable(common)
+	}
+	if query, view, ok, err := viewQuery(ctx, simple.QualifiedName()); err != nil {
+		return nil, err
+	} else if ok {
+		return e.scanView(ctx, simple.QualifiedName(), query, view)
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, simple.QualifiedName())
+	if err != nil {
+		return nil, err
+	}
+	tbl, ok := loaded.(*Table)
+	if !ok {
+		return loaded, nil
+	}
+
+	path, err := e.chooseAccessPath(ctx, tbl, simple, filter)
+	if err != nil {
+		return nil, err
+	}
+	if path.isFullScan() {
+		return tbl, nil
+	}
+	return rangeTable{tbl, path}, nil
 }
@@ -11,7 +11,14 @@ import (
 func (e Engine) evaluateSelection(ctx ExecutionContext, sel command.Select) (table.Table, error) {
 	defer e.profiler.Enter("selection").Exit()
 
-	origin, err := e.evaluateList(ctx, sel.Input)
+	var origin table.Table
+	var err error
+	if scan, ok := sel.Input.(command.Scan); ok {
+		// the filter may be evaluated with an index of the scanned table
+		origin, err = e.evaluateFilteredScan(ctx, scan, sel.Filter)
+	} else {
+		origin, err = e.evaluateList(ctx, sel.Input)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("list: %w", err)
 	}
@@ -21,25 +28,83 @@ func (e Engine) evaluateSelection(ctx ExecutionContext, sel command.Select) (tab
 		return origin, nil
 	}
 
-	switch t := sel.Filter.(type) {
-	default:
-		return nil, fmt.Errorf("cannot use %T as filter", t)
-	case command.EqualityExpr, command.GreaterThanExpr, command.GreaterThanOrEqualToExpr, command.LessThanExpr, command.LessThanOrEqualToExpr:
+	if err := ensureFilter(sel.Filter); err != nil {
+		return nil, err
 	}
 
 	return table.NewFilteredRow(origin, func(r table.RowWithColInfo) (bool, error) {
 		defer e.profiler.Enter("selection (lazy)").Exit()
-		switch filter := sel.Filter.(type) {
-		case command.BinaryExpression:
-			val, err := e.evaluateBinaryExpr(ctx.IntermediateRow(r), filter)
-			if err != nil {
-				return false, err
-			}
-			if !val.Is(types.Bool) {
-				return false, fmt.Errorf("expression does not evaluate to bool")
-			}
-			return val.(types.BoolValue).Value, nil
-		}
-		return false, nil
+		return e.evaluateFilter(ctx.IntermediateRow(r), sel.Filter)
 	}), nil
 }
+
+// selectRecords returns all records of the given table, whose rows match the
+// given filter. All records of the table are read, see
+// (Engine).selectRecordsThrough.
+func (e Engine) selectRecords(ctx ExecutionContext, tbl *Table, filter command.Expr) ([]record, error) {
+	return e.selectRecordsThrough(ctx, tbl, accessPath{}, filter)
+}
+
+// selectRecordsThrough returns all records of the given table, that are read
+// through the given access path, and whose rows match the given filter.
+func (e Engine) selectRecordsThrough(ctx ExecutionContext, tbl *Table, path accessPath, filter command.Expr) ([]record, error) {
+	cols, err := tbl.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	var records []record
+	if err := path.scan(tbl, func(next record) error {
+		if ok, err := e.evaluateFilter(ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: cols,
+			Row:  next.row,
+		}), filter); err != nil {
+			return fmt.Errorf("filter: %w", err)
+		} else if ok {
+			records = append(records, next)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// evaluateFilter evaluates the given filter expression in the given context.
+// The result indicates, whether the intermediate row of the context passes the
+// filter.
+func (e Engine) evaluateFilter(ctx ExecutionContext, filter command.Expr) (bool, error) {
+	switch filter := filter.(type) {
+	case command.ConstantBooleanExpr:
+		return filter.Value, nil
+	case command.RangeExpr:
+		return e.evaluateRangeExpr(ctx, filter)
+	case command.ExistsExpr:
+		return e.evaluateExistsExpr(ctx, filter)
+	case command.InExpr:
+		return e.evaluateInFilter(ctx, filter)
+	case command.LikeExpr:
+		return e.evaluateLikeExpr(ctx, filter)
+	case command.BinaryExpression:
+		val, err := e.evaluateBinaryExpr(ctx, filter)
+		if err != nil {
+			return false, err
+		}
+		if !val.Is(types.Bool) {
+			return false, fmt.Errorf("expression does not evaluate to bool")
+		}
+		return val.(types.BoolValue).Value, nil
+	}
+	return false, nil
+}
+
+// ensureFilter returns an error if the given expression can not be used as a
+// filter.
+func ensureFilter(filter command.Expr) error {
+	switch t := filter.(type) {
+	case command.ConstantBooleanExpr, command.EqualityExpr, command.GreaterThanExpr, command.GreaterThanOrEqualToExpr, command.LessThanExpr, command.LessThanOrEqualToExpr, command.RangeExpr, command.ExistsExpr, command.InExpr, command.LikeExpr:
+		return nil
+	default:
+		return fmt.Errorf("cannot use %T as filter", t)
+	}
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// evaluateSort evaluates the given sort. Rows are sorted when the first row is
+// requested from the resulting table. If the rows exceed the sort memory
+// budget of the engine, sorted runs are spilled to temporary files and merged
+// afterwards.
+func (e Engine) evaluateSort(ctx ExecutionContext, s command.Sort) (table.Table, error) {
+	defer e.profiler.Enter("sort").Exit()
+
+	origin, err := e.evaluateList(ctx, s.Input)
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	cols, err := origin.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	return sortedTable{
+		origin: origin,
+		cols:   cols,
+		terms:  s.Terms,
+		ctx:    ctx,
+		e:      e,
+	}, nil
+}
+
+// sortRow is a row, together with the evaluated ordering terms of a sort.
+type sortRow struct {
+	keys []types.Value
+	row  table.Row
+}
+
+// sortKeys evaluates the ordering terms of the given sort for the given row.
+func (t sortedTable) sortKeys(row table.Row) ([]types.Value, error) {
+	rowCtx := t.ctx.IntermediateRow(table.RowWithColInfo{
+		Cols: t.cols,
+		Row:  row,
+	})
+	keys := make([]types.Value, len(t.terms))
+	for i, term := range t.terms {
+		val, err := t.e.evaluateExpression(rowCtx, term.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("ordering term %v: %w", term, err)
+		}
+		keys[i] = val
+	}
+	return keys, nil
+}
+
+// compareSortKeys compares the given evaluated ordering terms, and returns -1
+// if the left keys come before the right keys, 1 if the right keys come before
+// the left keys, and 0 if they are equal.
+func (e Engine) compareSortKeys(terms []command.OrderingTerm, left, right []types.Value) int {
+	for i, term := range terms {
+		if res := e.compareSortKey(term, left[i], right[i]); res != 0 {
+			return res
+		}
+	}
+	return 0
+}
+
+func (e Engine) compareSortKey(term command.OrderingTerm, left, right types.Value) int {
+	switch {
+	case left.IsNull() && right.IsNull():
+		return 0
+	case left.IsNull():
+		if term.NullsFirst {
+			return -1
+		}
+		return 1
+	case right.IsNull():
+		if term.NullsFirst {
+			return 1
+		}
+		return -1
+	}
+
+	var res int
+	switch e.cmp(left, right) {
+	case cmpLessThan:
+		res = -1
+	case cmpGreaterThan:
+		res = 1
+	case cmpEqual:
+		res = 0
+	default:
+		// values of different types are ordered by the name of their type,
+		// so that the order is deterministic
+		res = strings.Compare(left.Type().Name(), right.Type().Name())
+	}
+	if term.Desc {
+		return -res
+	}
+	return res
+}
+
+// approximateSize approximates the amount of bytes, that the given values
+// occupy in memory.
+func approximateSize(vals []types.Value) int {
+	size := 0
+	for _, val := range vals {
+		size += 16 // interface value
+		if str, ok := val.(types.StringValue); ok {
+			size += len(str.Value)
+		} else {
+			size += 8
+		}
+	}
+	return size
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+)
+
+// sortRunEntry is a row of a spilled sorted run, together with the index of
+// the run that the row was read from.
+type sortRunEntry struct {
+	sortRow
+	run int
+}
+
+// sortRunHeap is a min-heap of rows from spilled sorted runs, which is used to
+// merge the runs. Rows with equal keys are ordered by the index of their run,
+// which keeps the merge stable, since earlier runs contain earlier rows.
+type sortRunHeap struct {
+	e       Engine
+	terms   []command.OrderingTerm
+	entries []sortRunEntry
+}
+
+func (h sortRunHeap) Len() int { return len(h.entries) }
+
+func (h sortRunHeap) Less(i, j int) bool {
+	res := h.e.compareSortKeys(h.terms, h.entries[i].keys, h.entries[j].keys)
+	if res == 0 {
+		return h.entries[i].run < h.entries[j].run
+	}
+	return res < 0
+}
+
+func (h sortRunHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *sortRunHeap) Push(x interface{}) {
+	h.entries = append(h.entries, x.(sortRunEntry))
+}
+
+func (h *sortRunHeap) Pop() interface{} {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
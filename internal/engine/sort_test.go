@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestSortSuite(t *testing.T) {
+	suite.Run(t, new(SortSuite))
+}
+
+type SortSuite struct {
+	EngineSuite
+}
+
+func (suite *SortSuite) TestSortByMultipleTerms() {
+	suite.RunScript(`
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "b"), (2, "a"), (3, "b"), (4, "a")`)
+
+	result, err := suite.engine.evaluateSort(suite.ctx, command.Sort{
+		Terms: []command.OrderingTerm{
+			{Expr: command.ColumnReference{Name: "name"}, NullsFirst: true},
+			{Expr: command.ColumnReference{Name: "id"}, Desc: true},
+		},
+		Input: command.Scan{Table: command.SimpleTable{Table: "myTable"}},
+	})
+	suite.NoError(err)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(4), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("b")}},
+		{Values: []types.Value{types.NewInteger(1), types.NewString("b")}},
+	}, suite.rows(result))
+}
+
+func (suite *SortSuite) TestSortNulls() {
+	origin := table.NewInMemory(
+		[]table.Col{{QualifiedName: "col1", Type: types.Integer}},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(2)}},
+			{Values: []types.Value{types.NewNull(types.Integer)}},
+			{Values: []types.Value{types.NewInteger(1)}},
+		},
+	)
+	sorted := func(term command.OrderingTerm) table.Table {
+		cols, err := origin.Cols()
+		suite.Require().NoError(err)
+		return sortedTable{
+			origin: origin,
+			cols:   cols,
+			terms:  []command.OrderingTerm{term},
+			ctx:    suite.ctx,
+			e:      suite.engine,
+		}
+	}
+	col1 := command.ColumnReference{Name: "col1"}
+
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewNull(types.Integer)}},
+		{Values: []types.Value{types.NewInteger(1)}},
+		{Values: []types.Value{types.NewInteger(2)}},
+	}, suite.rows(sorted(command.OrderingTerm{Expr: col1, NullsFirst: true})))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(2)}},
+		{Values: []types.Value{types.NewInteger(1)}},
+		{Values: []types.Value{types.NewNull(types.Integer)}},
+	}, suite.rows(sorted(command.OrderingTerm{Expr: col1, Desc: true})))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewNull(types.Integer)}},
+		{Values: []types.Value{types.NewInteger(2)}},
+		{Values: []types.Value{types.NewInteger(1)}},
+	}, suite.rows(sorted(command.OrderingTerm{Expr: col1, Desc: true, NullsFirst: true})))
+}
+
+func (suite *SortSuite) TestSortWithSpill() {
+	fs := afero.NewMemMapFs()
+	dbfs, err := dbfs.CreateNew(fs)
+	suite.Require().NoError(err)
+	// a budget of 100 bytes holds only a few rows, so that
+	// many sorted runs are spilled and merged
+	e, err := New(dbfs, WithSortMemoryBudget(100))
+	suite.Require().NoError(err)
+
+	var rows []table.Row
+	var expected []table.Row
+	for i := 0; i < 100; i++ {
+		// insert the ids in a shuffled order
+		id := int64((i * 37) % 100)
+		rows = append(rows, table.Row{Values: []types.Value{types.NewInteger(id), types.NewString(fmt.Sprint(id % 3))}})
+	}
+	for name := 0; name < 3; name++ {
+		for id := int64(0); id < 100; id++ {
+			if id%3 == int64(name) {
+				expected = append(expected, table.Row{Values: []types.Value{types.NewInteger(id), types.NewString(fmt.Sprint(name))}})
+			}
+		}
+	}
+
+	origin := table.NewInMemory([]table.Col{
+		{QualifiedName: "id", Type: types.Integer},
+		{QualifiedName: "name", Type: types.String},
+	}, rows)
+	cols, err := origin.Cols()
+	suite.Require().NoError(err)
+	tbl := sortedTable{
+		origin: origin,
+		cols:   cols,
+		terms: []command.OrderingTerm{
+			{Expr: command.ColumnReference{Name: "name"}},
+			{Expr: command.ColumnReference{Name: "id"}},
+		},
+		ctx: suite.ctx,
+		e:   e,
+	}
+
+	it, err := tbl.createIterator()
+	suite.Require().NoError(err)
+	first, err := it.Next()
+	suite.NoError(err)
+	suite.Equal(expected[0], first)
+	suite.Greater(len(it.runs), 1)
+
+	// the runs of a fully drained iterator are removed, so only
+	// the runs of the first iterator remain
+	suite.Equal(expected, suite.rows(tbl))
+	files, err := afero.ReadDir(fs, "tmp")
+	suite.NoError(err)
+	suite.Len(files, len(it.runs))
+
+	// remaining runs are removed when the iterator is closed
+	suite.NoError(it.Close())
+	files, err = afero.ReadDir(fs, "tmp")
+	suite.NoError(err)
+	suite.Empty(files)
+}
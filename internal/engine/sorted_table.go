@@ -0,0 +1,27 @@
+package engine
+
+import (
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+)
+
+// sortedTable is a table, which contains the rows of an underlying table,
+// sorted by a list of ordering terms.
+type sortedTable struct {
+	origin table.Table
+	cols   []table.Col
+	terms  []command.OrderingTerm
+	ctx    ExecutionContext
+	e      Engine
+}
+
+// Cols returns the columns of the underlying table.
+func (t sortedTable) Cols() ([]table.Col, error) {
+	return t.cols, nil
+}
+
+// Rows returns a row iterator of the sorted table. The rows are sorted when
+// the first row is requested from the iterator.
+func (t sortedTable) Rows() (table.RowIterator, error) {
+	return t.createIterator()
+}
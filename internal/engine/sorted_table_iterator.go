@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+type sortedTableIterator struct {
+	table      sortedTable
+	underlying table.RowIterator
+	sorted     bool
+
+	// buffer holds the sorted rows, if all rows fit into memory.
+	buffer []sortRow
+	index  int
+
+	// runs are the spilled sorted runs, if not all rows fit into memory.
+	runs  []*spillFile
+	merge *sortRunHeap
+}
+
+func (t sortedTable) createIterator() (*sortedTableIterator, error) {
+	underlying, err := t.origin.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &sortedTableIterator{
+		table:      t,
+		underlying: underlying,
+	}, nil
+}
+
+// Next returns the next row of the sorted table. On the first call, all rows
+// of the underlying table are read and sorted.
+func (i *sortedTableIterator) Next() (table.Row, error) {
+	if !i.sorted {
+		if err := i.sort(); err != nil {
+			return table.Row{}, fmt.Errorf("sort: %w", err)
+		}
+		i.sorted = true
+	}
+
+	if i.merge != nil {
+		return i.nextMerged()
+	}
+
+	if i.index >= len(i.buffer) {
+		return table.Row{}, table.ErrEOT
+	}
+	next := i.buffer[i.index]
+	i.index++
+	return next.row, nil
+}
+
+// Reset resets this table iterator. The rows of the underlying table are
+// sorted again on the next call to Next.
+func (i *sortedTableIterator) Reset() error {
+	if err := i.removeRuns(); err != nil {
+		return err
+	}
+	i.sorted = false
+	i.buffer = nil
+	i.index = 0
+	return i.underlying.Reset()
+}
+
+// Close removes all remaining spilled runs and closes the underlying iterator.
+func (i *sortedTableIterator) Close() error {
+	if err := i.removeRuns(); err != nil {
+		return err
+	}
+	return i.underlying.Close()
+}
+
+// sort reads all rows from the underlying iterator and sorts them. Whenever
+// the buffered rows exceed the sort memory budget, they are sorted and spilled
+// to a temporary file as a sorted run. If any runs were spilled, the runs are
+// merged while rows are obtained from this iterator.
+func (i *sortedTableIterator) sort() error {
+	size := 0
+	for {
+		next, err := i.underlying.Next()
+		if err == table.ErrEOT {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		keys, err := i.table.sortKeys(next)
+		if err != nil {
+			return err
+		}
+		i.buffer = append(i.buffer, sortRow{keys: keys, row: next})
+		size += approximateSize(keys) + approximateSize(next.Values)
+
+		if size > i.table.e.sortMemoryBudget {
+			if err := i.spill(); err != nil {
+				return fmt.Errorf("spill: %w", err)
+			}
+			size = 0
+		}
+	}
+
+	i.sortBuffer()
+	if len(i.runs) == 0 {
+		return nil
+	}
+
+	// the remaining rows also become a run, so that all rows can be merged
+	if len(i.buffer) > 0 {
+		if err := i.spill(); err != nil {
+			return fmt.Errorf("spill: %w", err)
+		}
+	}
+	return i.startMerge()
+}
+
+func (i *sortedTableIterator) sortBuffer() {
+	sort.SliceStable(i.buffer, func(a, b int) bool {
+		return i.table.e.compareSortKeys(i.table.terms, i.buffer[a].keys, i.buffer[b].keys) < 0
+	})
+}
+
+// spill sorts the buffered rows and writes them to a new temporary file.
+func (i *sortedTableIterator) spill() error {
+	defer i.table.e.profiler.Enter("sort (spill)").Exit()
+
+	i.sortBuffer()
+
+	run, err := i.table.e.newSpillFile()
+	if err != nil {
+		return err
+	}
+	i.runs = append(i.runs, run)
+
+	for _, next := range i.buffer {
+		vals := make([]types.Value, 0, len(next.keys)+len(next.row.Values))
+		vals = append(vals, next.keys...)
+		vals = append(vals, next.row.Values...)
+		if err := run.write(vals); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	i.buffer = nil
+	return nil
+}
+
+// startMerge prepares merging all spilled runs, by reading the first row of
+// every run.
+func (i *sortedTableIterator) startMerge() error {
+	i.merge = &sortRunHeap{
+		e:     i.table.e,
+		terms: i.table.terms,
+	}
+	for runIndex, run := range i.runs {
+		if err := run.rewind(); err != nil {
+			return fmt.Errorf("rewind: %w", err)
+		}
+		if err := i.pushNextFromRun(runIndex); err != nil {
+			return err
+		}
+	}
+	heap.Init(i.merge)
+	return nil
+}
+
+// nextMerged returns the smallest row of all spilled runs, and replaces it
+// with the next row of the same run.
+func (i *sortedTableIterator) nextMerged() (table.Row, error) {
+	if i.merge.Len() == 0 {
+		return table.Row{}, table.ErrEOT
+	}
+	smallest := heap.Pop(i.merge).(sortRunEntry)
+	if err := i.pushNextFromRun(smallest.run); err != nil {
+		return table.Row{}, err
+	}
+	return smallest.row, nil
+}
+
+// pushNextFromRun reads the next row of the run with the given index and
+// pushes it onto the merge heap. If the run is exhausted, the temporary file of
+// the run is removed.
+func (i *sortedTableIterator) pushNextFromRun(runIndex int) error {
+	run := i.runs[runIndex]
+	if run == nil {
+		return nil
+	}
+
+	vals, err := run.read()
+	if err == io.EOF {
+		i.runs[runIndex] = nil
+		if err := run.remove(); err != nil {
+			return fmt.Errorf("remove run: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read run: %w", err)
+	}
+
+	termCount := len(i.table.terms)
+	heap.Push(i.merge, sortRunEntry{
+		sortRow: sortRow{
+			keys: vals[:termCount],
+			row:  table.Row{Values: vals[termCount:]},
+		},
+		run: runIndex,
+	})
+	return nil
+}
+
+// removeRuns removes the temporary files of all remaining spilled runs.
+func (i *sortedTableIterator) removeRuns() error {
+	var firstErr error
+	for runIndex, run := range i.runs {
+		if run == nil {
+			continue
+		}
+		if err := run.remove(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		i.runs[runIndex] = nil
+	}
+	i.runs = nil
+	i.merge = nil
+	return firstErr
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// spillFile is a temporary file, that rows are spilled to if they don't fit
+// into memory. Values are written sequentially, and can be read back in the
+// order in which they were written, after the file has been rewound.
+type spillFile struct {
+	e    Engine
+	file afero.File
+	w    *bufio.Writer
+	r    *bufio.Reader
+}
+
+func (e Engine) newSpillFile() (*spillFile, error) {
+	f, err := e.dbfs.CreateTempFile()
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	return &spillFile{
+		e:    e,
+		file: f,
+		w:    bufio.NewWriter(f),
+	}, nil
+}
+
+// write appends the given values to this spill file. Every value is written as
+// its type indicator, a null flag, and, if the value is not null, the framed
+// serialized value.
+func (f *spillFile) write(vals []types.Value) error {
+	header := make([]byte, 4)
+	byteOrder.PutUint32(header, uint32(len(vals)))
+	if _, err := f.w.Write(header); err != nil {
+		return err
+	}
+
+	for _, val := range vals {
+		indicator := types.IndicatorFor(val.Type())
+		if indicator == types.TypeIndicatorUnknown {
+			return fmt.Errorf("type %v is not serializable", val.Type())
+		}
+		if val.IsNull() {
+			if _, err := f.w.Write([]byte{byte(indicator), 1}); err != nil {
+				return err
+			}
+			continue
+		}
+		serialized, err := val.Type().(types.Serializer).Serialize(val)
+		if err != nil {
+			return fmt.Errorf("serialize: %w", err)
+		}
+		if _, err := f.w.Write([]byte{byte(indicator), 0}); err != nil {
+			return err
+		}
+		if _, err := f.w.Write(frame(serialized)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewind flushes all written values and prepares this spill file for reading
+// from the beginning.
+func (f *spillFile) rewind() error {
+	if err := f.w.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek: %w", err)
+	}
+	f.r = bufio.NewReader(f.file)
+	return nil
+}
+
+// read reads the next values from this spill file. If there are no more
+// values, io.EOF is returned.
+func (f *spillFile) read() ([]types.Value, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f.r, header); err != nil {
+		return nil, err
+	}
+
+	vals := make([]types.Value, byteOrder.Uint32(header))
+	for i := range vals {
+		valHeader := make([]byte, 2)
+		if _, err := io.ReadFull(f.r, valHeader); err != nil {
+			return nil, fmt.Errorf("read value header: %w", noEOF(err))
+		}
+		typ := types.ByIndicator(types.TypeIndicator(valHeader[0]))
+		if typ == nil {
+			return nil, fmt.Errorf("unknown type indicator %v", valHeader[0])
+		}
+		if valHeader[1] != 0 {
+			vals[i] = types.NewNull(typ)
+			continue
+		}
+
+		frameHeader := make([]byte, 4)
+		if _, err := io.ReadFull(f.r, frameHeader); err != nil {
+			return nil, fmt.Errorf("read frame: %w", noEOF(err))
+		}
+		data := make([]byte, byteOrder.Uint32(frameHeader))
+		if _, err := io.ReadFull(f.r, data); err != nil {
+			return nil, fmt.Errorf("read value: %w", noEOF(err))
+		}
+		val, err := typ.(types.Serializer).Deserialize(data)
+		if err != nil {
+			return nil, fmt.Errorf("deserialize: %w", err)
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// remove closes and removes this spill file.
+func (f *spillFile) remove() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	return f.e.dbfs.RemoveTempFile(f.file)
+}
+
+// noEOF converts io.EOF into io.ErrUnexpectedEOF, since an EOF in the middle
+// of a value indicates a corrupted spill file.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
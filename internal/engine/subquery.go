@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// scanDerivedTable evaluates the query of the given derived table. If the
+// table has an alias, the columns of the result are qualified with the alias.
+// Their names are the aliases of the columns of the query, or their names
+// without qualifier.
+func (e Engine) scanDerivedTable(ctx ExecutionContext, derived command.DerivedTable) (table.Table, error) {
+	defer e.profiler.Enter("scan derived table").Exit()
+
+	result, err := e.evaluateList(ctx, derived.Input)
+	if err != nil {
+		return nil, fmt.Errorf("derived table: %w", err)
+	}
+	if derived.Alias == "" {
+		return result, nil
+	}
+
+	cols, err := result.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	names := resultColNames(cols)
+	for i, name := range names {
+		names[i] = derived.Alias + "." + name
+	}
+	return table.NewRenamedCol(result, names), nil
+}
+
+// evaluateSubquery evaluates the given query, which is nested in the query,
+// that is evaluated in the given context. The query is evaluated for the
+// intermediate row of the context, so that it may reference its columns.
+func (e Engine) evaluateSubquery(ctx ExecutionContext, query command.List) (table.Table, table.RowIterator, error) {
+	defer e.profiler.Enter("subquery").Exit()
+
+	result, err := e.evaluateList(ctx.subquery(), query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("subquery: %w", err)
+	}
+	rows, err := result.Rows()
+	if err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+	return result, rows, nil
+}
+
+// evaluateSubqueryExpr evaluates the given scalar subquery to the value of the
+// first row of its query, or to NULL, if the query returns no rows. If the
+// query doesn't return exactly one column, an error is returned.
+func (e Engine) evaluateSubqueryExpr(ctx ExecutionContext, expr command.SubqueryExpr) (types.Value, error) {
+	result, rows, err := e.evaluateSubquery(ctx, expr.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	col, err := singleColumn(result)
+	if err != nil {
+		return nil, err
+	}
+	row, err := rows.Next()
+	if err == table.ErrEOT {
+		return types.NewNull(col.Type), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return row.Values[0], nil
+}
+
+// evaluateExistsExpr evaluates whether the query of the given expression
+// returns any rows. Only the first row of the query is read.
+func (e Engine) evaluateExistsExpr(ctx ExecutionContext, expr command.ExistsExpr) (bool, error) {
+	_, rows, err := e.evaluateSubquery(ctx, expr.Query)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if _, err := rows.Next(); err == table.ErrEOT {
+		return expr.Invert, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !expr.Invert, nil
+}
+
+// evaluateInExpr evaluates whether the needle of the given expression is
+// equal to any value of the query of the expression. If no equal value is
+// found, but the needle is NULL or the query returns a NULL value, it is
+// unknown whether the needle is in the values, and the result is NULL. This is
+// also the result of a NOT IN expression in that case, which is true only, if
+// the needle is known to be different from all values. If the query returns no
+// rows, the needle is not in the values, even if it is NULL. The rows of the
+// query are only read until an equal value is found. If the query doesn't
+// return exactly one column, an error is returned.
+func (e Engine) evaluateInExpr(ctx ExecutionContext, expr command.InExpr) (types.Value, error) {
+	needle, err := e.evaluateExpression(ctx, expr.Needle)
+	if err != nil {
+		return nil, fmt.Errorf("needle: %w", err)
+	}
+	result, rows, err := e.evaluateSubquery(ctx, expr.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if _, err := singleColumn(result); err != nil {
+		return nil, err
+	}
+	found, unknown := false, false
+	for !found {
+		row, err := rows.Next()
+		if err == table.ErrEOT {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if needle.IsNull() {
+			// a NULL needle is neither known to be equal to a value, nor
+			// known to be different from it
+			return types.NewNull(types.Bool), nil
+		}
+		if row.Values[0].IsNull() {
+			unknown = true
+			continue
+		}
+		found = e.eq(needle, row.Values[0])
+	}
+
+	if !found && unknown {
+		return types.NewNull(types.Bool), nil
+	}
+	if expr.Invert {
+		return types.NewBool(!found), nil
+	}
+	return types.NewBool(found), nil
+}
+
+// evaluateInFilter evaluates the given IN expression as a filter, which is
+// only passed, if the expression evaluates to true, and not if it evaluates to
+// NULL.
+func (e Engine) evaluateInFilter(ctx ExecutionContext, expr command.InExpr) (bool, error) {
+	value, err := e.evaluateInExpr(ctx, expr)
+	if err != nil {
+		return false, err
+	}
+	return !value.IsNull() && value.(types.BoolValue).Value, nil
+}
+
+// singleColumn returns the only column of the given result of a subquery, or
+// an error, if the result doesn't have exactly one column.
+func singleColumn(result table.Table) (table.Col, error) {
+	cols, err := result.Cols()
+	if err != nil {
+		return table.Col{}, fmt.Errorf("cols: %w", err)
+	}
+	if len(cols) != 1 {
+		return table.Col{}, fmt.Errorf("subquery returns %d columns, but must return 1 column", len(cols))
+	}
+	return cols[0], nil
+}
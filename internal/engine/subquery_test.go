@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestSubquerySuite(t *testing.T) {
+	suite.Run(t, new(SubquerySuite))
+}
+
+type SubquerySuite struct {
+	EngineSuite
+}
+
+func (suite *SubquerySuite) SetupTest() {
+	suite.EngineSuite.SetupTest()
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 1, 'a'), (2, 2, 'b'), (3, 1, 'c')`))
+	suite.Require().NoError(suite.exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY, item INTEGER, amount INTEGER)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO orders VALUES (1, 1, 5), (2, 3, 7), (3, 1, 2)`))
+}
+
+func (suite *SubquerySuite) TestDerivedTable() {
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM (SELECT * FROM items WHERE grp = 1)`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.selectRows(`SELECT t.id, t.label FROM (SELECT id, name AS label FROM items) AS t WHERE t.id > 2`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a"), types.NewInteger(5)}},
+		{Values: []types.Value{types.NewString("c"), types.NewInteger(7)}},
+		{Values: []types.Value{types.NewString("a"), types.NewInteger(2)}},
+	}, suite.selectRows(`SELECT i.name, o.amount FROM (SELECT item, amount FROM orders) o JOIN items i ON o.item = i.id`))
+}
+
+func (suite *SubquerySuite) TestScalarSubquery() {
+	suite.Equal([]table.Row{
+		intRow(3),
+	}, suite.selectRows(`SELECT (SELECT max(id) FROM items)`))
+	suite.Equal([]table.Row{
+		itemRow(2, 2, "b"),
+	}, suite.selectRows(`SELECT * FROM items WHERE grp = (SELECT max(grp) FROM items)`))
+
+	// the column is named after the SQL source of the subquery
+	tbl, err := suite.evaluateStatement(`SELECT (SELECT max(id) FROM items)`)
+	suite.Require().NoError(err)
+	cols, err := tbl.Cols()
+	suite.Require().NoError(err)
+	suite.Equal("( SELECT max ( id ) FROM items )", cols[0].QualifiedName)
+
+	// correlated with the projected row
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewInteger(2)}},
+		{Values: []types.Value{types.NewInteger(2), types.NewInteger(0)}},
+		{Values: []types.Value{types.NewInteger(3), types.NewInteger(1)}},
+	}, suite.selectRows(`SELECT id, (SELECT count(*) FROM orders WHERE orders.item = items.id) FROM items`))
+
+	// no rows result in NULL
+	rows := suite.selectRows(`SELECT (SELECT id FROM items WHERE id > 5)`)
+	suite.Require().Len(rows, 1)
+	suite.True(rows[0].Values[0].IsNull())
+
+	suite.NoError(suite.exec(`UPDATE items SET grp = (SELECT max(amount) FROM orders WHERE orders.item = items.id) WHERE id IN (SELECT item FROM orders)`))
+	suite.Equal([]table.Row{
+		itemRow(1, 5, "a"),
+		itemRow(2, 2, "b"),
+		itemRow(3, 7, "c"),
+	}, suite.selectRows(`SELECT * FROM items`))
+
+	tbl, err = suite.evaluateStatement(`SELECT (SELECT id, name FROM items)`)
+	if err == nil {
+		err = drainRows(tbl)
+	}
+	suite.Error(err)
+}
+
+func (suite *SubquerySuite) TestExists() {
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM items WHERE EXISTS (SELECT * FROM orders WHERE orders.item = items.id)`))
+	suite.Equal([]table.Row{
+		itemRow(2, 2, "b"),
+	}, suite.selectRows(`SELECT * FROM items i WHERE NOT EXISTS (SELECT * FROM orders WHERE item = i.id)`))
+	// a column of the subquery hides the column of the same name of the outer
+	// query
+	suite.Len(suite.selectRows(`SELECT * FROM items WHERE EXISTS (SELECT * FROM orders WHERE id = 3)`), 3)
+	suite.Empty(suite.selectRows(`SELECT * FROM items WHERE EXISTS (SELECT * FROM orders WHERE id = 4)`))
+}
+
+func (suite *SubquerySuite) TestIn() {
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM items WHERE id IN (SELECT item FROM orders)`))
+	suite.Equal([]table.Row{
+		itemRow(2, 2, "b"),
+	}, suite.selectRows(`SELECT * FROM items WHERE id NOT IN (SELECT item FROM orders)`))
+	// correlated
+	suite.Equal([]table.Row{
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM items WHERE 7 IN (SELECT amount FROM orders WHERE item = items.id)`))
+
+	suite.NoError(suite.exec(`DELETE FROM orders WHERE item IN (SELECT id FROM items WHERE name = 'a')`))
+	suite.Equal([]table.Row{
+		intRow(2),
+	}, suite.selectRows(`SELECT id FROM orders`))
+}
+
+func (suite *SubquerySuite) TestInWithNull() {
+	suite.NoError(suite.exec(`INSERT INTO orders (id, amount) VALUES (4, 1)`))
+	suite.NoError(suite.exec(`INSERT INTO items (id, name) VALUES (4, 'd')`))
+
+	// it is unknown, whether 2 is the item of the order without item
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(3),
+	}, suite.selectRows(`SELECT id FROM items WHERE id IN (SELECT item FROM orders)`))
+	suite.Empty(suite.selectRows(`SELECT id FROM items WHERE id NOT IN (SELECT item FROM orders)`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewBool(true), types.NewNull(types.Bool), types.NewNull(types.Bool)}},
+	}, suite.selectRows(`SELECT 1 IN (SELECT item FROM orders), 2 IN (SELECT item FROM orders), 2 NOT IN (SELECT item FROM orders)`))
+
+	// a NULL needle is neither in the values nor not in them, unless there
+	// are no values
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+	}, suite.selectRows(`SELECT id FROM items WHERE grp NOT IN (SELECT amount FROM orders WHERE amount > 4)`))
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+		intRow(4),
+	}, suite.selectRows(`SELECT id FROM items WHERE grp NOT IN (SELECT amount FROM orders WHERE id > 4)`))
+	suite.Empty(suite.selectRows(`SELECT id FROM items WHERE grp IN (SELECT amount FROM orders WHERE id > 4)`))
+}
@@ -2,12 +2,17 @@ package engine
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/xqueries/xdb/internal/compiler"
 	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
 	"github.com/xqueries/xdb/internal/engine/page"
 	"github.com/xqueries/xdb/internal/engine/profile"
 	"github.com/xqueries/xdb/internal/engine/table"
 	"github.com/xqueries/xdb/internal/engine/transaction"
+	"github.com/xqueries/xdb/internal/engine/types"
 )
 
 var _ Namer = (*Table)(nil)
@@ -26,6 +31,16 @@ type Inserter interface {
 	Insert(table.Row) error
 }
 
+// record is a row of a table, together with the location of the record cell
+// that the row is stored in.
+type record struct {
+	// page is the ID of the data page that holds the record cell.
+	page page.ID
+	// key is the key of the record cell.
+	key []byte
+	row table.Row
+}
+
 // Table is a representation of an on-disk table used by the engine.
 // It is an intermediate layer to access and manipulate data inside
 // the table's pages.
@@ -37,6 +52,13 @@ type Table struct {
 	tx *transaction.TX
 
 	name string
+	// engine is the engine that loaded this table. It is used to evaluate the
+	// CHECK constraints of this table.
+	engine Engine
+	// checks are the compiled expressions of the CHECK constraints of this
+	// table, by their source. Expressions are compiled when they are first
+	// checked.
+	checks map[string]command.Expr
 }
 
 // LoadTable loads a table with the given name from secondary storage. Only table meta
@@ -56,6 +78,7 @@ func (e Engine) LoadTable(tx *transaction.TX, name string) (table.Table, error)
 		profiler: e.profiler,
 		tx:       tx,
 		name:     name,
+		engine:   e,
 	}, nil
 }
 
@@ -83,7 +106,11 @@ func (t *Table) Rows() (table.RowIterator, error) {
 	return it, nil
 }
 
-// Insert inserts the given row into this table in secondary storage.
+// Insert inserts the given row into this table in secondary storage, and adds
+// it to all indexes of this table. An error is returned, if the row violates a
+// constraint of this table, see (*Table).checkConstraints. If this table is
+// clustered by its primary key, the row is stored under its primary key,
+// otherwise it is stored under the next row ID.
 func (t *Table) Insert(row table.Row) error {
 	tx := t.tx
 
@@ -92,6 +119,21 @@ func (t *Table) Insert(row table.Row) error {
 		return fmt.Errorf("schema file: %w", err)
 	}
 
+	if err := t.checkConstraints(row); err != nil {
+		return err
+	}
+
+	tree, clustered, err := t.clusteredTree()
+	if err != nil {
+		return err
+	} else if clustered {
+		rec, err := t.storeClusteredRecord(tree, row)
+		if err != nil {
+			return err
+		}
+		return t.addToIndexes(rec)
+	}
+
 	serializedRow, err := serializeRow(row)
 	if err != nil {
 		return fmt.Errorf("serialize row: %w", err)
@@ -99,15 +141,159 @@ func (t *Table) Insert(row table.Row) error {
 
 	key := make([]byte, 4)
 	byteOrder.PutUint32(key, uint32(schemaFile.HighestRowID))
-	record := page.RecordCell{
+	cell := page.RecordCell{
 		Key:    key,
 		Record: serializedRow,
 	}
 
+	id, err := t.storeRecordCell(cell)
+	if err != nil {
+		return err
+	}
+
+	// only increment highest row ID if cell was actually inserted
+	schemaFile.HighestRowID++
+
+	return t.addToIndexes(record{page: id, key: key, row: row})
+}
+
+// replaceRecords replaces the rows of the given records, see
+// (*Table).replaceRecord. If this table is clustered by its primary key, all
+// records are deleted before any new row is stored, since the new primary key
+// of a row may be the old primary key of another of the rows.
+func (t *Table) replaceRecords(recs []record) error {
+	tree, clustered, err := t.clusteredTree()
+	if err != nil {
+		return err
+	} else if !clustered {
+		for _, rec := range recs {
+			if err := t.replaceRecord(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, rec := range recs {
+		if err := t.checkConstraints(rec.row); err != nil {
+			return err
+		}
+	}
+	for _, rec := range recs {
+		if err := t.deleteRecord(rec); err != nil {
+			return err
+		}
+	}
+	for _, rec := range recs {
+		stored, err := t.storeClusteredRecord(tree, rec.row)
+		if err != nil {
+			return err
+		}
+		if err := t.addToIndexes(stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceRecord replaces the row of the given record, which must already exist
+// in the page that the record references. If the page can not accommodate the
+// new row, the record is moved to another page. The key of the record stays
+// the same, and the indexes of this table are updated. If this table is
+// clustered by its primary key, the record is stored under the primary key of
+// the new row instead. An error is returned, if the new row violates a
+// constraint of this table.
+func (t *Table) replaceRecord(rec record) error {
+	if err := t.checkConstraints(rec.row); err != nil {
+		return err
+	}
+
+	tree, clustered, err := t.clusteredTree()
+	if err != nil {
+		return err
+	} else if clustered {
+		if err := t.deleteRecord(rec); err != nil {
+			return err
+		}
+		stored, err := t.storeClusteredRecord(tree, rec.row)
+		if err != nil {
+			return err
+		}
+		return t.addToIndexes(stored)
+	}
+
+	serializedRow, err := serializeRow(rec.row)
+	if err != nil {
+		return fmt.Errorf("serialize row: %w", err)
+	}
+
+	if err := t.deleteRecord(rec); err != nil {
+		return err
+	}
+
+	p, err := t.tx.DataPage(t.name, rec.page)
+	if err != nil {
+		return fmt.Errorf("data page: %w", err)
+	}
+	cell := page.RecordCell{
+		Key:    rec.key,
+		Record: serializedRow,
+	}
+	id := rec.page
+	if p.CanAccommodateRecord(cell) {
+		if err := p.StoreRecordCell(cell); err != nil {
+			return fmt.Errorf("store record cell: %w", err)
+		}
+	} else if id, err = t.storeRecordCell(cell); err != nil {
+		return err
+	}
+	return t.addToIndexes(record{page: id, key: rec.key, row: rec.row})
+}
+
+// deleteRecord deletes the record cell of the given record from the page that
+// the record references, and removes the record from all indexes of this
+// table. The space that the record occupied in the page can be used by
+// subsequent inserts. If this table is clustered by its primary key, the
+// record is deleted from the tree of this table instead.
+func (t *Table) deleteRecord(rec record) error {
+	if err := t.removeFromIndexes(rec); err != nil {
+		return err
+	}
+
+	tree, clustered, err := t.clusteredTree()
+	if err != nil {
+		return err
+	} else if clustered {
+		if ok, err := tree.Delete(rec.key); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		} else if !ok {
+			return fmt.Errorf("no record with key %x", rec.key)
+		}
+		return nil
+	}
+
+	p, err := t.tx.DataPage(t.name, rec.page)
+	if err != nil {
+		return fmt.Errorf("data page: %w", err)
+	}
+	if ok, err := p.DeleteCell(rec.key); err != nil {
+		return fmt.Errorf("delete cell: %w", err)
+	} else if !ok {
+		return fmt.Errorf("no record with key %x in page %v", rec.key, rec.page)
+	}
+	return nil
+}
+
+// storeRecordCell stores the given record cell in the first page of this table,
+// that can accommodate the record, and returns the ID of that page. If there is
+// no such page, a new page will be allocated.
+func (t *Table) storeRecordCell(record page.RecordCell) (page.ID, error) {
+	tx := t.tx
+
 	// find a page to insert the row to
 	availablePageIDs, err := tx.ExistingDataPagesForTable(t.name)
 	if err != nil {
-		return fmt.Errorf("existing data pages: %w", err)
+		return 0, fmt.Errorf("existing data pages: %w", err)
 	}
 
 	var found bool
@@ -115,7 +301,7 @@ func (t *Table) Insert(row table.Row) error {
 	for _, pageID := range availablePageIDs {
 		roPage, err := tx.DataPageReadOnly(t.name, pageID)
 		if err != nil {
-			return fmt.Errorf("data page read-only: %w", err)
+			return 0, fmt.Errorf("data page read-only: %w", err)
 		}
 		if roPage.CanAccommodateRecord(record) {
 			found = true
@@ -128,50 +314,54 @@ func (t *Table) Insert(row table.Row) error {
 	if !found {
 		alloc, err := tx.AllocateNewDataPage(t.name)
 		if err != nil {
-			return fmt.Errorf("allocate new page: %w", err)
+			return 0, fmt.Errorf("allocate new page: %w", err)
 		}
 		p = alloc
 	} else {
 		loaded, err := tx.DataPage(t.name, foundID)
 		if err != nil {
-			return fmt.Errorf("data page: %w", err)
+			return 0, fmt.Errorf("data page: %w", err)
 		}
 		p = loaded
 	}
 
-	// only increment highest row ID if cell was actually inserted
-	schemaFile.HighestRowID++
-
 	if err := p.StoreRecordCell(record); err != nil {
 		// cannot be ErrPageFull because we checked whether or not the page
 		// can accommodate the record that we want to store
-		return fmt.Errorf("store record cell: %w", err)
+		return 0, fmt.Errorf("store record cell: %w", err)
 	}
 
-	return nil
+	return p.ID(), nil
 }
 
-// evaluateCreateTable creates a new table from the given command.
+// evaluateCreateTable creates a new table from the given command. If the
+// command overwrites an existing table, that table is dropped first, see
+// (Engine).dropTable.
 func (e Engine) evaluateCreateTable(ctx ExecutionContext, cmd command.CreateTable) (table.Table, error) {
 	defer e.profiler.Enter("create table").Exit()
 	tx := ctx.tx
 
-	/*
-		Since we don't support table deletion yet, we can't support overwriting tables.
-		However, the behavior below, that checks if the table already exists, is correct.
-	*/
-	if cmd.Overwrite {
-		return nil, fmt.Errorf("overwrite: %w", ErrUnsupported)
-	}
-
-	if !cmd.Overwrite {
-		if ok, err := tx.HasTable(cmd.Name); err != nil {
-			return nil, fmt.Errorf("has table: %w", err)
-		} else if ok {
+	if ok, err := tx.HasTable(cmd.Name); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if ok {
+		switch {
+		case cmd.IfNotExists:
+			return table.Empty, nil
+		case cmd.Overwrite:
+			if err := e.dropTable(ctx, cmd.Name); err != nil {
+				return nil, err
+			}
+		default:
 			return nil, fmt.Errorf("%v: %w", cmd.Name, ErrAlreadyExists)
 		}
 	}
 
+	if _, ok, err := tx.View(cmd.Name); err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	} else if ok {
+		return nil, fmt.Errorf("view %v: %w", cmd.Name, ErrAlreadyExists)
+	}
+
 	if err := tx.CreateTable(cmd.Name); err != nil {
 		return nil, fmt.Errorf("create table: %w", err)
 	}
@@ -188,12 +378,228 @@ func (e Engine) evaluateCreateTable(ctx ExecutionContext, cmd command.CreateTabl
 			QualifiedName: def.Name,
 			Type:          def.Type,
 		})
+		if def.PrimaryKey {
+			sf.PrimaryKey = []string{def.Name}
+		}
+		if def.Unique {
+			sf.UniqueKeys = append(sf.UniqueKeys, []string{def.Name})
+		}
+		if def.NotNull {
+			sf.NotNull = append(sf.NotNull, def.Name)
+		}
+		if def.Default == nil {
+			continue
+		}
+		defaultValue, err := e.evaluateColumnDefault(ctx, def)
+		if err != nil {
+			return nil, fmt.Errorf("default of column %v: %w", def.Name, err)
+		}
+		if sf.Defaults == nil {
+			sf.Defaults = make(map[string]types.Value)
+		}
+		sf.Defaults[def.Name] = defaultValue
 	}
 	sf.Columns = cols
 
+	if cmd.PrimaryKey != nil {
+		sf.PrimaryKey = cmd.PrimaryKey
+	}
+	if cmd.WithoutRowID {
+		if sf.PrimaryKey == nil {
+			return nil, fmt.Errorf("table %v without row ID has no primary key", cmd.Name)
+		}
+		tree, err := btree.Create(dataPager{tx, cmd.Name})
+		if err != nil {
+			return nil, fmt.Errorf("create tree: %w", err)
+		}
+		sf.WithoutRowID = true
+		sf.Root = tree.Root()
+	}
+	sf.UniqueKeys = append(sf.UniqueKeys, cmd.UniqueKeys...)
+	if err := createKeyIndexes(tx, cmd.Name, sf); err != nil {
+		return nil, fmt.Errorf("key indexes: %w", err)
+	}
+	for _, check := range cmd.Checks {
+		// the source is compiled again when the constraint is checked, so it
+		// must be valid
+		if _, err := compiler.CompileExpr(check.Source); err != nil {
+			return nil, fmt.Errorf("check %v: %w", check.Source, err)
+		}
+		sf.Checks = append(sf.Checks, dbfs.Check{
+			Name: check.Name,
+			Expr: check.Source,
+		})
+	}
+	for _, foreignKey := range cmd.ForeignKeys {
+		stored, err := e.createForeignKey(ctx, cmd.Name, sf, foreignKey)
+		if err != nil {
+			return nil, fmt.Errorf("foreign key (%v): %w", strings.Join(foreignKey.Cols, ","), err)
+		}
+		sf.ForeignKeys = append(sf.ForeignKeys, stored)
+	}
+
 	return table.Empty, nil
 }
 
+// createForeignKey validates the given foreign key of the table with the given
+// name and schema, and returns it as it is stored in the schema. The referenced
+// table must exist, and the referenced columns must be a key of the referenced
+// table. If no referenced columns are given, the primary key of the referenced
+// table is referenced. A table may reference itself.
+func (e Engine) createForeignKey(ctx ExecutionContext, tableName string, sf *dbfs.SchemaFile, foreignKey command.ForeignKey) (dbfs.ForeignKey, error) {
+	foreignSchema := sf
+	if foreignKey.ForeignTable != tableName {
+		if ok, err := ctx.tx.HasTable(foreignKey.ForeignTable); err != nil {
+			return dbfs.ForeignKey{}, fmt.Errorf("has table: %w", err)
+		} else if !ok {
+			return dbfs.ForeignKey{}, fmt.Errorf("%v: %w", foreignKey.ForeignTable, ErrNoSuchTable)
+		}
+		var err error
+		if foreignSchema, err = ctx.tx.SchemaFile(foreignKey.ForeignTable); err != nil {
+			return dbfs.ForeignKey{}, fmt.Errorf("schema file: %w", err)
+		}
+	}
+
+	foreignCols := foreignKey.ForeignCols
+	if foreignCols == nil {
+		if foreignSchema.PrimaryKey == nil {
+			return dbfs.ForeignKey{}, fmt.Errorf("table %v has no primary key", foreignKey.ForeignTable)
+		}
+		foreignCols = foreignSchema.PrimaryKey
+	}
+	if len(foreignCols) != len(foreignKey.Cols) {
+		return dbfs.ForeignKey{}, fmt.Errorf("%v columns reference %v columns", len(foreignKey.Cols), len(foreignCols))
+	}
+	if _, err := columnIndices(sf.Columns, foreignKey.Cols); err != nil {
+		return dbfs.ForeignKey{}, err
+	}
+	if !isKey(foreignSchema, foreignCols) {
+		return dbfs.ForeignKey{}, fmt.Errorf("%v(%v) is not a key", foreignKey.ForeignTable, strings.Join(foreignCols, ","))
+	}
+
+	return dbfs.ForeignKey{
+		Name:           foreignKey.Name,
+		Columns:        foreignKey.Cols,
+		ForeignTable:   foreignKey.ForeignTable,
+		ForeignColumns: foreignCols,
+		OnDelete:       referentialAction(foreignKey.OnDelete),
+		OnUpdate:       referentialAction(foreignKey.OnUpdate),
+		Deferred:       foreignKey.Deferred,
+	}, nil
+}
+
+// isKey returns whether the given columns are the columns of a key of the table
+// with the given schema, in any order, see (*dbfs.SchemaFile).Keys.
+func isKey(sf *dbfs.SchemaFile, cols []string) bool {
+	for _, key := range sf.Keys() {
+		if len(key) != len(cols) {
+			continue
+		}
+		matches := true
+		for _, col := range cols {
+			found := false
+			for _, keyCol := range key {
+				found = found || keyCol == col
+			}
+			matches = matches && found
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// referentialAction returns the action of a foreign key, as it is stored in
+// the schema of a table.
+func referentialAction(action command.ForeignKeyAction) dbfs.ReferentialAction {
+	switch action {
+	case command.ForeignKeyRestrict:
+		return dbfs.Restrict
+	case command.ForeignKeySetNull:
+		return dbfs.SetNull
+	case command.ForeignKeyCascade:
+		return dbfs.Cascade
+	default:
+		return dbfs.NoAction
+	}
+}
+
+// evaluateColumnDefault evaluates the default expression of the given column
+// definition, and converts it to the type of the column. Default values are
+// evaluated once, when the table is created.
+func (e Engine) evaluateColumnDefault(ctx ExecutionContext, def command.ColumnDef) (types.Value, error) {
+	val, err := e.evaluateExpression(ctx, def.Default)
+	if err != nil {
+		return nil, err
+	}
+	return e.coerceValue(val, def.Type)
+}
+
+// evaluateDropTable drops the table from the given command. If the table does
+// not exist, an error is returned, unless the command specifies IfExists. See
+// (Engine).dropTable.
+func (e Engine) evaluateDropTable(ctx ExecutionContext, cmd command.DropTable) (table.Table, error) {
+	defer e.profiler.Enter("drop table").Exit()
+
+	if ok, err := ctx.tx.HasTable(cmd.Name); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if !ok {
+		if cmd.IfExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", cmd.Name, ErrNoSuchTable)
+	}
+
+	if err := e.dropTable(ctx, cmd.Name); err != nil {
+		return nil, err
+	}
+	return table.Empty, nil
+}
+
+// dropTable drops the existing table with the given name, together with its
+// triggers. If the table is referenced by the foreign keys of other tables, all
+// of its rows are deleted first, without firing any triggers, so that the
+// actions of the foreign keys are performed, as for a DELETE. If a foreign key
+// restricts the deletion, an error is returned.
+func (e Engine) dropTable(ctx ExecutionContext, name string) error {
+	referencing, err := e.referencingKeys(ctx, name)
+	if err != nil {
+		return err
+	}
+	referenced := false
+	for _, ref := range referencing {
+		referenced = referenced || ref.table != name
+	}
+	if referenced {
+		loaded, err := e.LoadTable(ctx.tx, name)
+		if err != nil {
+			return fmt.Errorf("load table: %w", err)
+		}
+		tbl := loaded.(*Table)
+		records, err := e.selectRecords(ctx, tbl, command.ConstantBooleanExpr{Value: true})
+		if err != nil {
+			return err
+		}
+		changes := make([]rowChange, len(records))
+		for i, rec := range records {
+			if err := tbl.deleteRecord(rec); err != nil {
+				return fmt.Errorf("delete record: %w", err)
+			}
+			changes[i] = rowChange{old: rec.row}
+		}
+		if err := e.enforceForeignKeys(ctx, name, changes); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.tx.DropTable(name); err != nil {
+		return fmt.Errorf("drop table: %w", err)
+	}
+	// the triggers of a table are dropped with the table
+	return e.updateTriggers(ctx, name, func(*dbfs.Trigger) bool { return false })
+}
+
 func frame(data []byte) []byte {
 	buf := make([]byte, 4+len(data))
 	byteOrder.PutUint32(buf, uint32(len(data)))
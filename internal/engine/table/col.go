@@ -1,6 +1,10 @@
 package table
 
-import "github.com/xqueries/xdb/internal/engine/types"
+import (
+	"strings"
+
+	"github.com/xqueries/xdb/internal/engine/types"
+)
 
 // Col is a header for a single column in a table, containing the qualified name
 // of the col, a possible alias and the col data type.
@@ -18,3 +22,38 @@ func (c Col) String() string {
 	result += "type " + c.Type.String()
 	return result
 }
+
+// MatchesName determines whether the given name or alias refers to this column.
+// This is the case, if the given name is equal to the qualified name or the alias
+// of this column. Additionally, an unqualified name such as "id" matches a
+// qualified column such as "myTable.id", and a qualified name such as
+// "myTable.id" matches a column "id", whose table is unknown.
+func (c Col) MatchesName(nameOrAlias string) bool {
+	if c.QualifiedName == nameOrAlias || c.Alias == nameOrAlias {
+		return true
+	}
+
+	qualifier, name := SplitQualifiedName(nameOrAlias)
+	colQualifier, colName := SplitQualifiedName(c.QualifiedName)
+	switch {
+	case qualifier == "" && colQualifier != "":
+		return colName == name
+	case qualifier != "" && colQualifier == "":
+		return c.QualifiedName == name || c.Alias == name
+	}
+	return false
+}
+
+// SplitQualifiedName splits the given name into qualifier and the name
+// without qualifier. If the given name is not qualified, the returned
+// qualifier is empty.
+//
+//	SplitQualifiedName("myTable.id") // "myTable", "id"
+//	SplitQualifiedName("id")         // "", "id"
+func SplitQualifiedName(qualifiedName string) (qualifier, name string) {
+	index := strings.LastIndexByte(qualifiedName, '.')
+	if index == -1 {
+		return "", qualifiedName
+	}
+	return qualifiedName[:index], qualifiedName[index+1:]
+}
@@ -0,0 +1,31 @@
+package table
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCol_MatchesName(t *testing.T) {
+	tests := []struct {
+		name        string
+		col         Col
+		nameOrAlias string
+		want        bool
+	}{
+		{"name", Col{QualifiedName: "id"}, "id", true},
+		{"alias", Col{QualifiedName: "id", Alias: "foo"}, "foo", true},
+		{"other name", Col{QualifiedName: "id"}, "name", false},
+		{"qualified", Col{QualifiedName: "a.id"}, "a.id", true},
+		{"other qualifier", Col{QualifiedName: "a.id"}, "b.id", false},
+		{"unqualified name for qualified col", Col{QualifiedName: "a.id"}, "id", true},
+		{"qualified name for unqualified col", Col{QualifiedName: "id"}, "a.id", true},
+		{"qualified alias for unqualified col", Col{QualifiedName: "id", Alias: "foo"}, "a.foo", true},
+		{"asterisk", Col{QualifiedName: "a.id"}, "*", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.col.MatchesName(tt.nameOrAlias))
+		})
+	}
+}
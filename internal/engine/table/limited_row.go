@@ -0,0 +1,31 @@
+package table
+
+type limitedRowTable struct {
+	underlying Table
+	limit      int64
+}
+
+// NewLimitedRow returns a new table that only contains the first limit rows of
+// the given underlying table. Once the limit is reached, no more rows are
+// obtained from the underlying table.
+//
+//	tbl := getTableWithTenRows()
+//	newTbl = table.NewLimitedRow(tbl, 3)
+//	// newTbl only contains the first three rows of tbl
+func NewLimitedRow(underlying Table, limit int64) Table {
+	return limitedRowTable{
+		underlying: underlying,
+		limit:      limit,
+	}
+}
+
+// Cols returns the columns of the underlying table.
+func (t limitedRowTable) Cols() ([]Col, error) {
+	return t.underlying.Cols()
+}
+
+// Rows returns a row iterator that will return at most as many rows
+// as the limit of this table.
+func (t limitedRowTable) Rows() (RowIterator, error) {
+	return newLimitedRowIterator(t.underlying, t.limit)
+}
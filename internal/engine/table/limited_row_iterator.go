@@ -0,0 +1,51 @@
+package table
+
+type limitedRowIterator struct {
+	origin     Table
+	limit      int64
+	count      int64
+	underlying RowIterator
+}
+
+func newLimitedRowIterator(origin Table, limit int64) (*limitedRowIterator, error) {
+	rows, err := origin.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &limitedRowIterator{
+		origin:     origin,
+		limit:      limit,
+		underlying: rows,
+	}, nil
+}
+
+// Next returns the next row of the underlying iterator. If the limit is
+// reached, ErrEOT is returned without obtaining another row from the
+// underlying iterator.
+func (i *limitedRowIterator) Next() (Row, error) {
+	if i.count >= i.limit {
+		return Row{}, ErrEOT
+	}
+	next, err := i.underlying.Next()
+	if err != nil {
+		return Row{}, err
+	}
+	i.count++
+	return next, nil
+}
+
+// Reset resets this row iterator by obtaining a new row iterator
+// from the underlying table.
+func (i *limitedRowIterator) Reset() error {
+	rows, err := i.origin.Rows()
+	if err != nil {
+		return err
+	}
+	i.underlying = rows
+	i.count = 0
+	return nil
+}
+
+func (i *limitedRowIterator) Close() error {
+	return i.underlying.Close()
+}
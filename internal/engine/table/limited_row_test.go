@@ -0,0 +1,86 @@
+package table
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// countingTable is a table that counts the rows that were obtained
+// from its row iterators.
+type countingTable struct {
+	Table
+	count *int
+}
+
+func (t countingTable) Rows() (RowIterator, error) {
+	rows, err := t.Table.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return countingRowIterator{RowIterator: rows, count: t.count}, nil
+}
+
+type countingRowIterator struct {
+	RowIterator
+	count *int
+}
+
+func (i countingRowIterator) Next() (Row, error) {
+	*i.count++
+	return i.RowIterator.Next()
+}
+
+func newCountingTable(rows int) (Table, *int) {
+	var tableRows []Row
+	for i := 0; i < rows; i++ {
+		tableRows = append(tableRows, Row{Values: []types.Value{types.NewInteger(int64(i))}})
+	}
+	count := new(int)
+	return countingTable{
+		Table: NewInMemory([]Col{{QualifiedName: "col1", Type: types.Integer}}, tableRows),
+		count: count,
+	}, count
+}
+
+func drain(t *testing.T, tbl Table) []int64 {
+	it, err := tbl.Rows()
+	assert.NoError(t, err)
+
+	var vals []int64
+	for {
+		next, err := it.Next()
+		if err == ErrEOT {
+			return vals
+		}
+		assert.NoError(t, err)
+		vals = append(vals, next.Values[0].(types.IntegerValue).Value)
+	}
+}
+
+func TestLimitedRow(t *testing.T) {
+	tbl, count := newCountingTable(10)
+	assert.Equal(t, []int64{0, 1, 2}, drain(t, NewLimitedRow(tbl, 3)))
+	assert.Equal(t, 3, *count, "rows after the limit must not be obtained")
+
+	tbl, count = newCountingTable(10)
+	assert.Nil(t, drain(t, NewLimitedRow(tbl, 0)))
+	assert.Equal(t, 0, *count)
+
+	tbl, _ = newCountingTable(2)
+	assert.Equal(t, []int64{0, 1}, drain(t, NewLimitedRow(tbl, 5)))
+}
+
+func TestOffsetRow(t *testing.T) {
+	tbl, _ := newCountingTable(5)
+	assert.Equal(t, []int64{3, 4}, drain(t, NewOffsetRow(tbl, 3)))
+
+	tbl, _ = newCountingTable(2)
+	assert.Nil(t, drain(t, NewOffsetRow(tbl, 5)))
+
+	tbl, count := newCountingTable(10)
+	assert.Equal(t, []int64{4, 5}, drain(t, NewLimitedRow(NewOffsetRow(tbl, 4), 2)))
+	assert.Equal(t, 6, *count, "rows after the limit must not be obtained")
+}
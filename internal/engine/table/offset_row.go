@@ -0,0 +1,30 @@
+package table
+
+type offsetRowTable struct {
+	underlying Table
+	offset     int64
+}
+
+// NewOffsetRow returns a new table that skips the first offset rows of the
+// given underlying table.
+//
+//	tbl := getTableWithTenRows()
+//	newTbl = table.NewOffsetRow(tbl, 3)
+//	// newTbl contains all rows of tbl, except for the first three
+func NewOffsetRow(underlying Table, offset int64) Table {
+	return offsetRowTable{
+		underlying: underlying,
+		offset:     offset,
+	}
+}
+
+// Cols returns the columns of the underlying table.
+func (t offsetRowTable) Cols() ([]Col, error) {
+	return t.underlying.Cols()
+}
+
+// Rows returns a row iterator that will skip the first rows of the
+// underlying table.
+func (t offsetRowTable) Rows() (RowIterator, error) {
+	return newOffsetRowIterator(t.underlying, t.offset)
+}
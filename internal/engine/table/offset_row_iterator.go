@@ -0,0 +1,50 @@
+package table
+
+type offsetRowIterator struct {
+	origin     Table
+	offset     int64
+	skipped    bool
+	underlying RowIterator
+}
+
+func newOffsetRowIterator(origin Table, offset int64) (*offsetRowIterator, error) {
+	rows, err := origin.Rows()
+	if err != nil {
+		return nil, err
+	}
+	return &offsetRowIterator{
+		origin:     origin,
+		offset:     offset,
+		underlying: rows,
+	}, nil
+}
+
+// Next returns the next row of the underlying iterator. On the first call,
+// the rows before the offset are obtained and dropped.
+func (i *offsetRowIterator) Next() (Row, error) {
+	if !i.skipped {
+		for n := int64(0); n < i.offset; n++ {
+			if _, err := i.underlying.Next(); err != nil {
+				return Row{}, err
+			}
+		}
+		i.skipped = true
+	}
+	return i.underlying.Next()
+}
+
+// Reset resets this row iterator by obtaining a new row iterator
+// from the underlying table.
+func (i *offsetRowIterator) Reset() error {
+	rows, err := i.origin.Rows()
+	if err != nil {
+		return err
+	}
+	i.underlying = rows
+	i.skipped = false
+	return nil
+}
+
+func (i *offsetRowIterator) Close() error {
+	return i.underlying.Close()
+}
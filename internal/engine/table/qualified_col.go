@@ -0,0 +1,45 @@
+package table
+
+type qualifiedColTable struct {
+	underlying Table
+	qualifier  string
+}
+
+// NewQualifiedCol returns a new table that qualifies the columns of the given
+// underlying table with the given qualifier, which usually is the name or alias
+// of the underlying table. Columns that already are qualified, are not
+// modified.
+//
+//	tbl := getTableWithColID()
+//	tbl.Cols()[0].QualifiedName == "id"
+//	newTbl = table.NewQualifiedCol(tbl, "myTable")
+//	newTbl.Cols()[0].QualifiedName == "myTable.id"
+func NewQualifiedCol(underlying Table, qualifier string) Table {
+	return qualifiedColTable{
+		underlying: underlying,
+		qualifier:  qualifier,
+	}
+}
+
+// Cols returns the qualified columns of the underlying table.
+func (t qualifiedColTable) Cols() ([]Col, error) {
+	underlyingCols, err := t.underlying.Cols()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]Col, len(underlyingCols))
+	for i, col := range underlyingCols {
+		if qualifier, _ := SplitQualifiedName(col.QualifiedName); qualifier == "" {
+			col.QualifiedName = t.qualifier + "." + col.QualifiedName
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// Rows returns the row iterator of the underlying table, since the rows are
+// not modified by qualifying the columns.
+func (t qualifiedColTable) Rows() (RowIterator, error) {
+	return t.underlying.Rows()
+}
@@ -0,0 +1,50 @@
+package table
+
+import "fmt"
+
+type renamedColTable struct {
+	underlying Table
+	names      []string
+}
+
+// NewRenamedCol returns a new table that renames the columns of the given
+// underlying table to the given names, in the order of the columns. Aliases of
+// the columns are removed. If the amount of names doesn't match the amount of
+// columns of the underlying table, Cols returns an error.
+//
+//	tbl := getTableWithColID()
+//	tbl.Cols()[0].QualifiedName == "myTable.id"
+//	newTbl = table.NewRenamedCol(tbl, []string{"userID"})
+//	newTbl.Cols()[0].QualifiedName == "userID"
+func NewRenamedCol(underlying Table, names []string) Table {
+	return renamedColTable{
+		underlying: underlying,
+		names:      names,
+	}
+}
+
+// Cols returns the renamed columns of the underlying table.
+func (t renamedColTable) Cols() ([]Col, error) {
+	underlyingCols, err := t.underlying.Cols()
+	if err != nil {
+		return nil, err
+	}
+	if len(underlyingCols) != len(t.names) {
+		return nil, fmt.Errorf("cannot rename %d columns to %d names", len(underlyingCols), len(t.names))
+	}
+
+	cols := make([]Col, len(underlyingCols))
+	for i, col := range underlyingCols {
+		cols[i] = Col{
+			QualifiedName: t.names[i],
+			Type:          col.Type,
+		}
+	}
+	return cols, nil
+}
+
+// Rows returns the row iterator of the underlying table, since the rows are
+// not modified by renaming the columns.
+func (t renamedColTable) Rows() (RowIterator, error) {
+	return t.underlying.Rows()
+}
@@ -33,12 +33,11 @@ func NewRowWithImplicitColData(values []types.Value) RowWithColInfo {
 }
 
 // ValueForColName checks if this row has a value for the given col name.
-// It has such a value, if any cols QualifiedName or Alias matches the given
-// argument. If no such value is present, false is returned.
+// It has such a value, if any col matches the given argument, see
+// (Col).MatchesName. If no such value is present, false is returned.
 func (r RowWithColInfo) ValueForColName(colName string) (types.Value, bool) {
 	for i, col := range r.Cols {
-		if col.QualifiedName == colName ||
-			col.Alias == colName {
+		if col.MatchesName(colName) {
 			return r.Values[i], true
 		}
 	}
@@ -33,13 +33,14 @@ type RowIterator interface {
 
 // FindColumnForNameOrAlias checks the given table for a column that has the given nameOrAlias
 // as name or as an alias. Every column is first checked for its name, then for its alias.
+// A qualified nameOrAlias such as "myTable.id" only yields a column of the table myTable,
+// while an unqualified nameOrAlias yields the first column with that name, regardless of
+// the table, see (Col).MatchesName.
 // A nameOrAlias "*" will NOT yield a column.
 func FindColumnForNameOrAlias(tbl Table, nameOrAlias string) (foundColumn Col, found bool) {
 	cols, _ := tbl.Cols()
 	for _, col := range cols {
-		if col.QualifiedName == nameOrAlias {
-			return col, true
-		} else if col.Alias == nameOrAlias {
+		if col.MatchesName(nameOrAlias) {
 			return col, true
 		}
 	}
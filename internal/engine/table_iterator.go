@@ -3,6 +3,8 @@ package engine
 import (
 	"fmt"
 
+	"github.com/xqueries/xdb/internal/engine/btree"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
 	"github.com/xqueries/xdb/internal/engine/page"
 	"github.com/xqueries/xdb/internal/engine/profile"
 	"github.com/xqueries/xdb/internal/engine/table"
@@ -11,8 +13,8 @@ import (
 type tableRowIterator struct {
 	profiler *profile.Profiler
 
-	table *Table
-	cols  []table.Col
+	table  *Table
+	schema *dbfs.SchemaFile
 
 	pages            []page.ID
 	currentPageIndex int
@@ -20,21 +22,44 @@ type tableRowIterator struct {
 
 	slots       []page.Slot
 	currentSlot int
+
+	// tree is the tree of the table, if the table is clustered by its primary
+	// key, in which case the rows are read in the order of the primary key.
+	// key is the key of the last row, that was read from the tree, and
+	// buffered are the records, that were read from the tree, but were not
+	// returned yet.
+	tree     *btree.Tree
+	key      []byte
+	buffered []record
 }
 
+// clusteredBatchSize is the amount of records, that a table row iterator
+// reads from the tree of a clustered table at once.
+const clusteredBatchSize = 64
+
 func newTableRowIterator(tbl *Table) (*tableRowIterator, error) {
-	cols, err := tbl.Cols()
+	sf, err := tbl.tx.SchemaFile(tbl.name)
 	if err != nil {
-		return nil, fmt.Errorf("cols: %w", err)
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	tree, clustered, err := tbl.clusteredTree()
+	if err != nil {
+		return nil, err
+	} else if clustered {
+		return &tableRowIterator{
+			table:  tbl,
+			schema: sf,
+			tree:   tree,
+		}, nil
 	}
 	pages, err := tbl.tx.ExistingDataPagesForTable(tbl.name)
 	if err != nil {
 		return nil, fmt.Errorf("data pages: %w", err)
 	}
 	return &tableRowIterator{
-		table: tbl,
-		cols:  cols,
-		pages: pages,
+		table:  tbl,
+		schema: sf,
+		pages:  pages,
 	}, nil
 }
 
@@ -42,23 +67,64 @@ func newTableRowIterator(tbl *Table) (*tableRowIterator, error) {
 func (i *tableRowIterator) Next() (table.Row, error) {
 	i.profiler.Enter("next row").Exit()
 
+	rec, err := i.nextRecord()
+	if err != nil {
+		return table.Row{}, err
+	}
+	return rec.row, nil
+}
+
+// Reset makes this iterator start over from the first row.
+func (i *tableRowIterator) Reset() error {
+	i.currentPageIndex = 0
+	i.currentPage = nil
+	i.slots = nil
+	i.currentSlot = 0
+	i.key = nil
+	i.buffered = nil
+	return nil
+}
+
+func (i *tableRowIterator) Close() error {
+	return nil
+}
+
+// nextRecord returns the next row of this table iterator, together with the
+// location of the record cell that the row was read from.
+func (i *tableRowIterator) nextRecord() (record, error) {
 	tx := i.table.tx
 
+	if i.tree != nil {
+		if len(i.buffered) == 0 {
+			next, err := i.table.nextClusteredRecords(i.tree, i.key, clusteredBatchSize)
+			if err != nil {
+				return record{}, err
+			} else if len(next) == 0 {
+				return record{}, table.ErrEOT
+			}
+			i.buffered = next
+		}
+		rec := i.buffered[0]
+		i.buffered = i.buffered[1:]
+		i.key = rec.key
+		return rec, nil
+	}
+
 	if len(i.pages) == 0 {
-		return table.Row{}, table.ErrEOT
+		return record{}, table.ErrEOT
 	}
 
 start:
 	// if the current page index is higher than or equal to the amount of pages that exist, we are done
 	if i.currentPageIndex >= len(i.pages) {
-		return table.Row{}, table.ErrEOT
+		return record{}, table.ErrEOT
 	}
 
 	// no current page determined yet, choose the one under the currentPageIndex
 	if i.currentPage == nil {
 		p, err := tx.DataPage(i.table.name, i.pages[i.currentPageIndex])
 		if err != nil {
-			return table.Row{}, fmt.Errorf("load page: %w", err)
+			return record{}, fmt.Errorf("load page: %w", err)
 		}
 		i.currentPage = p
 	}
@@ -82,20 +148,17 @@ start:
 	// get the record and deserialize it
 	cell := i.currentPage.CellAt(i.slots[i.currentSlot]).(page.RecordCell)
 	i.currentSlot++
-	row, err := deserializeRow(i.cols, cell.Record)
+	row, err := deserializeRow(i.schema.Columns, i.schema.Defaults, cell.Record)
 	if err != nil {
-		return table.Row{}, fmt.Errorf("deserialize: %w", err)
+		return record{}, fmt.Errorf("deserialize: %w", err)
 	}
-	return row, nil
-}
-
-// Reset makes this iterator start over from the first row.
-func (i *tableRowIterator) Reset() error {
-	i.currentPageIndex = 0
-	i.currentSlot = 0
-	return nil
-}
-
-func (i *tableRowIterator) Close() error {
-	return nil
+	// the cell data points into the page, so copy the key, since the page
+	// may be modified before the record is used
+	key := make([]byte, len(cell.Key))
+	copy(key, cell.Key)
+	return record{
+		page: i.currentPage.ID(),
+		key:  key,
+		row:  row,
+	}, nil
 }
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -74,3 +75,180 @@ func (suite *TableSuite) TestEngine_LoadTable() {
 	suite.Equal(tableName, tbl.(Namer).Name())
 
 }
+
+func (suite *TableSuite) TestCreateTableIfNotExists() {
+	suite.RunScript(`
+CREATE TABLE myTable (col1 INTEGER);
+INSERT INTO myTable VALUES (1)`)
+
+	result, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		IfNotExists: true,
+		Name:        "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "other", Type: types.String},
+		},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.Empty, result)
+
+	tbl, err := suite.engine.LoadTable(suite.ctx.tx, "myTable")
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "col1", Type: types.Integer},
+		},
+		[]table.Row{
+			{Values: []types.Value{types.NewInteger(1)}},
+		},
+	), tbl)
+}
+
+func (suite *TableSuite) TestCreateTableOverwrite() {
+	suite.RunScript(`
+CREATE TABLE myTable (col1 INTEGER);
+INSERT INTO myTable VALUES (1)`)
+
+	_, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Name: "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "other", Type: types.String},
+		},
+	})
+	suite.True(errors.Is(err, ErrAlreadyExists))
+
+	_, err = suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Overwrite: true,
+		Name:      "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "other", Type: types.String},
+		},
+	})
+	suite.NoError(err)
+	suite.NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+
+	tbl, err := suite.engine.Evaluate(command.Scan{
+		Table: command.SimpleTable{Table: "myTable"},
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.NewInMemory(
+		[]table.Col{
+			{QualifiedName: "other", Type: types.String},
+		},
+		[]table.Row{},
+	), tbl)
+
+	count, err := suite.dbfs.TableCount()
+	suite.NoError(err)
+	suite.Equal(1, count)
+}
+
+func (suite *TableSuite) TestCreateTableOverwriteDropsTriggers() {
+	suite.createMyTable(`col1 INTEGER`, `(1)`)
+	suite.NoError(suite.exec(`CREATE TABLE log (col1 INTEGER)`))
+	suite.NoError(suite.exec(`CREATE TRIGGER logInsert AFTER INSERT ON myTable BEGIN INSERT INTO log VALUES (NEW.col1); END`))
+
+	_, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Overwrite: true,
+		Name:      "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "col1", Type: types.Integer},
+		},
+	})
+	suite.NoError(err)
+	_, ok, err := suite.ctx.tx.Trigger("logInsert")
+	suite.NoError(err)
+	suite.False(ok)
+
+	suite.NoError(suite.exec(`INSERT INTO myTable VALUES (2)`))
+	suite.Empty(suite.selectRows(`SELECT * FROM log`))
+}
+
+func (suite *TableSuite) TestCreateTableOverwriteReferencedTable() {
+	suite.createMyTable(`id INTEGER PRIMARY KEY`, `(1), (2)`)
+	suite.NoError(suite.exec(`CREATE TABLE child (id INTEGER, parent INTEGER REFERENCES myTable (id) ON DELETE CASCADE)`))
+	suite.NoError(suite.exec(`INSERT INTO child VALUES (10, 1)`))
+
+	_, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Overwrite: true,
+		Name:      "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "id", Type: types.Integer, PrimaryKey: true},
+		},
+	})
+	suite.NoError(err)
+	suite.Empty(suite.selectRows(`SELECT * FROM child`))
+}
+
+func (suite *TableSuite) TestDropTable() {
+	suite.RunScript(`
+CREATE TABLE myTable (col1 INTEGER);
+INSERT INTO myTable VALUES (1)`)
+
+	result, err := suite.engine.evaluateDropTable(suite.ctx, command.DropTable{
+		Name: "myTable",
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.Empty, result)
+	suite.False(suite.ctx.tx.HasTable("myTable"))
+
+	// the table must not be removed from disk before commit
+	ok, err := suite.dbfs.HasTable("myTable")
+	suite.NoError(err)
+	suite.True(ok)
+
+	suite.NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+
+	ok, err = suite.dbfs.HasTable("myTable")
+	suite.NoError(err)
+	suite.False(ok)
+}
+
+func (suite *TableSuite) TestDropTableRollback() {
+	suite.RunScript(`CREATE TABLE myTable (col1 INTEGER)`)
+
+	_, err := suite.engine.evaluateDropTable(suite.ctx, command.DropTable{
+		Name: "myTable",
+	})
+	suite.NoError(err)
+	suite.NoError(suite.engine.txmgr.Rollback(suite.ctx.tx))
+
+	ok, err := suite.dbfs.HasTable("myTable")
+	suite.NoError(err)
+	suite.True(ok)
+}
+
+func (suite *TableSuite) TestDropTableCreatedInTransaction() {
+	_, err := suite.engine.evaluateCreateTable(suite.ctx, command.CreateTable{
+		Name: "myTable",
+		ColumnDefs: []command.ColumnDef{
+			{Name: "col1", Type: types.Integer},
+		},
+	})
+	suite.NoError(err)
+
+	_, err = suite.engine.evaluateDropTable(suite.ctx, command.DropTable{
+		Name: "myTable",
+	})
+	suite.NoError(err)
+	suite.False(suite.ctx.tx.HasTable("myTable"))
+
+	suite.NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+
+	count, err := suite.dbfs.TableCount()
+	suite.NoError(err)
+	suite.Equal(0, count)
+}
+
+func (suite *TableSuite) TestDropTableIfExists() {
+	_, err := suite.engine.evaluateDropTable(suite.ctx, command.DropTable{
+		Name: "myTable",
+	})
+	suite.EqualError(err, "myTable: no such table")
+
+	result, err := suite.engine.evaluateDropTable(suite.ctx, command.DropTable{
+		IfExists: true,
+		Name:     "myTable",
+	})
+	suite.NoError(err)
+	suite.EqualTables(table.Empty, result)
+}
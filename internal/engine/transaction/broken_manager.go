@@ -43,10 +43,39 @@ func (m *brokenManager) Commit(tx *TX) error {
 		return fmt.Errorf("can only commit transactions with state %v, but got state %v", StatePending, tx.state)
 	}
 
+	if err := tx.performDeferredChecks(); err != nil {
+		return fmt.Errorf("deferred check: %w", err)
+	}
+
 	m.log.Debug().
 		Stringer("tx", tx.ID).
 		Msg("commit transaction")
 
+	// process all tables that must be removed from disk, before creating
+	// tables, since a table may have been dropped and re-created
+	{
+		for _, tableName := range tx.droppedTables {
+			m.log.Trace().
+				Stringer("tx", tx.ID).
+				Str("table", tableName).
+				Msg("drop table from file system")
+			if err := m.dbfs.DropTable(tableName); err != nil {
+				return fmt.Errorf("drop table: %w", err)
+			}
+		}
+	}
+	// rename tables after dropping tables, since a table may have been
+	// renamed to the name of a dropped table, but before creating tables,
+	// since a table may have been created with the old name of a renamed table
+	if len(tx.renamedTables) > 0 {
+		m.log.Trace().
+			Stringer("tx", tx.ID).
+			Int("tables", len(tx.renamedTables)).
+			Msg("rename tables on file system")
+		if err := m.dbfs.RenameTables(tx.renamedTables); err != nil {
+			return fmt.Errorf("rename tables: %w", err)
+		}
+	}
 	// process all tables that must be created on disk
 	{
 		for _, tableName := range tx.createdTables {
@@ -71,69 +100,84 @@ func (m *brokenManager) Commit(tx *TX) error {
 			}
 		}
 	}
+	// persist changes to views
+	if tx.viewsChanged {
+		m.log.Trace().
+			Stringer("tx", tx.ID).
+			Int("views", len(tx.views)).
+			Msg("persist views")
+		if err := m.dbfs.StoreViewsInfo(dbfs.ViewsInfo{Views: tx.views}); err != nil {
+			return fmt.Errorf("store views info: %w", err)
+		}
+	}
+	// persist changes to triggers
+	if tx.triggersChanged {
+		m.log.Trace().
+			Stringer("tx", tx.ID).
+			Int("triggers", len(tx.triggers)).
+			Msg("persist triggers")
+		if err := m.dbfs.StoreTriggersInfo(dbfs.TriggersInfo{Triggers: tx.triggers}); err != nil {
+			return fmt.Errorf("store triggers info: %w", err)
+		}
+	}
 	// persist all pages
 	{
-		// open all data files
-		dataPagedFiles := make(map[string]*dbfs.PagedFile)
-		for ref := range tx.dataPages {
-			if _, ok := dataPagedFiles[ref.table]; ok {
-				continue
-			}
-			tbl, err := m.dbfs.Table(ref.table)
-			if err != nil {
-				return fmt.Errorf("table: %w", err)
+		// open all data and index files
+		pagedFiles := make(map[fileref]*dbfs.PagedFile)
+		open := func(file fileref) error {
+			if _, ok := pagedFiles[file]; ok {
+				return nil
 			}
-			dataFile, err := tbl.DataFile()
+			pf, err := m.pagedFile(file)
 			if err != nil {
-				return fmt.Errorf("data file: %w", err)
+				return err
 			}
-			dataPagedFiles[ref.table] = dataFile
+			pagedFiles[file] = pf
+			return nil
 		}
-		for table := range tx.newlyAllocatedPages {
-			if _, ok := dataPagedFiles[table]; ok {
-				continue
-			}
-			tbl, err := m.dbfs.Table(table)
-			if err != nil {
-				return fmt.Errorf("table: %w", err)
+		for ref := range tx.pages {
+			if err := open(ref.fileref); err != nil {
+				return err
 			}
-			dataFile, err := tbl.DataFile()
-			if err != nil {
-				return fmt.Errorf("data file: %w", err)
+		}
+		for file := range tx.newlyAllocatedPages {
+			if err := open(file); err != nil {
+				return err
 			}
-			dataPagedFiles[table] = dataFile
 		}
 
-		// all data files are open, we assume that we can write without problems
-		for ref, p := range tx.dataPages { // probably more efficient in I/O when sorted by data file i.e. table
+		// all paged files are open, we assume that we can write without problems
+		for ref, p := range tx.pages { // probably more efficient in I/O when sorted by paged file
 			m.log.Trace().
 				Stringer("tx", tx.ID).
 				Str("table", ref.table).
+				Str("file", ref.file).
 				Uint32("page", ref.id).
-				Msg("write data page")
-			if err := dataPagedFiles[ref.table].StorePage(p); err != nil {
+				Msg("write page")
+			if err := pagedFiles[ref.fileref].StorePage(p); err != nil {
 				return fmt.Errorf("store page: %w", err) // this is a potential data integrity violation
 			}
 		}
 
-		for table, pages := range tx.newlyAllocatedPages {
+		for file, pages := range tx.newlyAllocatedPages {
 			for _, p := range pages {
 				m.log.Trace().
 					Stringer("tx", tx.ID).
-					Str("table", table).
+					Str("table", file.table).
+					Str("file", file.file).
 					Uint32("page", p.ID()).
 					Msg("allocate new page")
-				if _, err := dataPagedFiles[table].AllocatePageWithID(p.ID()); err != nil {
+				if _, err := pagedFiles[file].AllocatePageWithID(p.ID()); err != nil {
 					return fmt.Errorf("allocate with ID: %w", err)
 				}
-				if err := dataPagedFiles[table].StorePage(p); err != nil {
+				if err := pagedFiles[file].StorePage(p); err != nil {
 					return fmt.Errorf("store page: %w", err)
 				}
 			}
 		}
 
 		// close all opened paged files
-		for _, pf := range dataPagedFiles {
+		for _, pf := range pagedFiles {
 			if err := pf.Close(); err != nil {
 				return fmt.Errorf("close paged file: %w", err)
 			}
@@ -157,15 +201,38 @@ func (m *brokenManager) Rollback(tx *TX) error {
 	return nil
 }
 
-func (m *brokenManager) loadDataPage(table string, id page.ID) (*page.Page, error) {
-	tbl, err := m.dbfs.Table(table)
+// pagedFile opens the referenced paged file. The caller is responsible for
+// closing the file.
+func (m *brokenManager) pagedFile(file fileref) (*dbfs.PagedFile, error) {
+	tbl, err := m.dbfs.Table(file.table)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("table: %w", err)
+	}
+	switch file.file {
+	case dbfs.TableDataFile:
+		pf, err := tbl.DataFile()
+		if err != nil {
+			return nil, fmt.Errorf("data file: %w", err)
+		}
+		return pf, nil
+	case dbfs.TableIndexFile:
+		pf, err := tbl.IndexFile()
+		if err != nil {
+			return nil, fmt.Errorf("index file: %w", err)
+		}
+		return pf, nil
 	}
-	pf, err := tbl.DataFile()
+	return nil, fmt.Errorf("unknown paged file '%s'", file.file)
+}
+
+func (m *brokenManager) loadPage(file fileref, id page.ID) (*page.Page, error) {
+	pf, err := m.pagedFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("data file: %w", err)
+		return nil, err
 	}
+	defer func() {
+		_ = pf.Close()
+	}()
 	p, err := pf.LoadPage(id)
 	if err != nil {
 		return nil, fmt.Errorf("load page: %w", err)
@@ -173,15 +240,14 @@ func (m *brokenManager) loadDataPage(table string, id page.ID) (*page.Page, erro
 	return p, nil
 }
 
-func (m *brokenManager) unusedPageID(table string) (page.ID, error) {
-	tbl, err := m.dbfs.Table(table)
+func (m *brokenManager) unusedPageID(file fileref) (page.ID, error) {
+	pf, err := m.pagedFile(file)
 	if err != nil {
 		return 0, err
 	}
-	pf, err := tbl.DataFile()
-	if err != nil {
-		return 0, fmt.Errorf("data file: %w", err)
-	}
+	defer func() {
+		_ = pf.Close()
+	}()
 	unusedPageID, err := pf.FindUnusedPageID()
 	if err != nil {
 		return 0, fmt.Errorf("find unused page ID: %w", err)
@@ -210,15 +276,30 @@ func (m *brokenManager) loadTablesInfo() (*dbfs.TablesInfo, error) {
 	return &tablesInfo, nil
 }
 
-func (m *brokenManager) availableDataPages(table string) ([]page.ID, error) {
-	tbl, err := m.dbfs.Table(table)
+func (m *brokenManager) loadViewsInfo() (*dbfs.ViewsInfo, error) {
+	viewsInfo, err := m.dbfs.LoadViewsInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &viewsInfo, nil
+}
+
+func (m *brokenManager) loadTriggersInfo() (*dbfs.TriggersInfo, error) {
+	triggersInfo, err := m.dbfs.LoadTriggersInfo()
 	if err != nil {
 		return nil, err
 	}
-	pf, err := tbl.DataFile()
+	return &triggersInfo, nil
+}
+
+func (m *brokenManager) availablePages(file fileref) ([]page.ID, error) {
+	pf, err := m.pagedFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("data file: %w", err)
+		return nil, err
 	}
+	defer func() {
+		_ = pf.Close()
+	}()
 	return pf.Pages(), nil
 }
 
@@ -28,7 +28,9 @@ type Manager interface {
 	// transaction finishes.
 	Start() (*TX, error)
 	// Commit will apply all changes from the transaction to the database,
-	// or return an error and not apply any changes.
+	// or return an error and not apply any changes. The checks that were
+	// deferred with (*TX).DeferCheck are performed before any change is
+	// applied.
 	Commit(*TX) error
 	// Rollback will abort the transaction. No changes will be applied
 	// and the transaction will not be considered 'pending' anymore.
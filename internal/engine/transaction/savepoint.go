@@ -0,0 +1,160 @@
+package transaction
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/page"
+)
+
+// savepoint is the state of a transaction at the time the savepoint was set.
+// Pages are not copied when the savepoint is set. Instead, the data of a page
+// is journaled, when the page is accessed for the first time after the
+// savepoint was set, which is before the page can be modified.
+type savepoint struct {
+	// journal holds the data of all pages, that were accessed since the
+	// savepoint was set, as it was before the first access.
+	journal map[*page.Page][]byte
+
+	pages               map[pageref]*page.Page
+	newlyAllocatedPages map[fileref][]*page.Page
+	tableSchemas        map[string]schemaSnapshot
+	createdTables       []string
+	droppedTables       []string
+	renamedTables       map[string]string
+	views               map[string]dbfs.View
+	viewsChanged        bool
+	triggers            map[string]dbfs.Trigger
+	triggersChanged     bool
+	deferredChecks      []string
+}
+
+// schemaSnapshot is a schema file, that is cached in a transaction, together
+// with a copy of its content at the time a savepoint was set.
+type schemaSnapshot struct {
+	sf      *dbfs.SchemaFile
+	content *dbfs.SchemaFile
+}
+
+// Savepoint sets a savepoint in this transaction. All changes, that are made
+// after the savepoint was set, can be undone with RollbackToSavepoint, or
+// kept with ReleaseSavepoint. Savepoints can be nested, RollbackToSavepoint
+// and ReleaseSavepoint always refer to the savepoint that was set last.
+func (tx *TX) Savepoint() {
+	sp := &savepoint{
+		journal:             make(map[*page.Page][]byte),
+		pages:               make(map[pageref]*page.Page, len(tx.pages)),
+		newlyAllocatedPages: make(map[fileref][]*page.Page, len(tx.newlyAllocatedPages)),
+		tableSchemas:        make(map[string]schemaSnapshot, len(tx.tableSchemas)),
+		createdTables:       append([]string(nil), tx.createdTables...),
+		droppedTables:       append([]string(nil), tx.droppedTables...),
+		renamedTables:       make(map[string]string, len(tx.renamedTables)),
+		viewsChanged:        tx.viewsChanged,
+		triggersChanged:     tx.triggersChanged,
+		deferredChecks:      append([]string(nil), tx.deferredChecks...),
+	}
+	for ref, p := range tx.pages {
+		sp.pages[ref] = p
+	}
+	for file, pages := range tx.newlyAllocatedPages {
+		// pages are only ever appended, so the slice is not modified
+		sp.newlyAllocatedPages[file] = pages
+	}
+	for name, sf := range tx.tableSchemas {
+		sp.tableSchemas[name] = schemaSnapshot{sf, sf.Copy()}
+	}
+	for name, diskName := range tx.renamedTables {
+		sp.renamedTables[name] = diskName
+	}
+	if tx.views != nil {
+		sp.views = make(map[string]dbfs.View, len(tx.views))
+		for name, view := range tx.views {
+			sp.views[name] = view
+		}
+	}
+	if tx.triggers != nil {
+		sp.triggers = make(map[string]dbfs.Trigger, len(tx.triggers))
+		for name, trigger := range tx.triggers {
+			sp.triggers[name] = trigger
+		}
+	}
+	tx.savepoints = append(tx.savepoints, sp)
+}
+
+// RollbackToSavepoint undoes all changes, that were made since the savepoint,
+// that was set last, was set, and removes that savepoint. If no savepoint is
+// set, an error is returned.
+func (tx *TX) RollbackToSavepoint() error {
+	sp, err := tx.popSavepoint()
+	if err != nil {
+		return err
+	}
+
+	for p, data := range sp.journal {
+		p.Restore(data)
+	}
+	tx.pages = sp.pages
+	tx.newlyAllocatedPages = sp.newlyAllocatedPages
+	tx.tableSchemas = make(map[string]*dbfs.SchemaFile, len(sp.tableSchemas))
+	for name, snapshot := range sp.tableSchemas {
+		// schema files are restored in place, since they are referenced
+		// outside of the transaction
+		*snapshot.sf = *snapshot.content
+		tx.tableSchemas[name] = snapshot.sf
+	}
+	tx.createdTables = sp.createdTables
+	tx.droppedTables = sp.droppedTables
+	tx.renamedTables = sp.renamedTables
+	tx.views = sp.views
+	tx.viewsChanged = sp.viewsChanged
+	tx.triggers = sp.triggers
+	tx.triggersChanged = sp.triggersChanged
+	for _, name := range tx.deferredChecks[len(sp.deferredChecks):] {
+		delete(tx.deferredCheckFuncs, name)
+	}
+	tx.deferredChecks = sp.deferredChecks
+	return nil
+}
+
+// ReleaseSavepoint removes the savepoint, that was set last, and keeps all
+// changes that were made since it was set. If no savepoint is set, an error
+// is returned.
+func (tx *TX) ReleaseSavepoint() error {
+	sp, err := tx.popSavepoint()
+	if err != nil {
+		return err
+	}
+
+	// the enclosing savepoint must be able to undo the changes, that were
+	// made since the released savepoint was set
+	if len(tx.savepoints) > 0 {
+		parent := tx.savepoints[len(tx.savepoints)-1]
+		for p, data := range sp.journal {
+			if _, ok := parent.journal[p]; !ok {
+				parent.journal[p] = data
+			}
+		}
+	}
+	return nil
+}
+
+func (tx *TX) popSavepoint() (*savepoint, error) {
+	if len(tx.savepoints) == 0 {
+		return nil, fmt.Errorf("no savepoint is set")
+	}
+	sp := tx.savepoints[len(tx.savepoints)-1]
+	tx.savepoints = tx.savepoints[:len(tx.savepoints)-1]
+	return sp, nil
+}
+
+// journal records the data of the given page in the savepoint that was set
+// last, if the page was not accessed since that savepoint was set.
+func (tx *TX) journal(p *page.Page) {
+	if len(tx.savepoints) == 0 {
+		return
+	}
+	sp := tx.savepoints[len(tx.savepoints)-1]
+	if _, ok := sp.journal[p]; !ok {
+		sp.journal[p] = p.CopyOfData()
+	}
+}
@@ -9,9 +9,16 @@ import (
 	"github.com/xqueries/xdb/internal/id"
 )
 
-type pageref struct {
-	id    page.ID
+// fileref references a paged file of a table, which is either the data file
+// (dbfs.TableDataFile) or the index file (dbfs.TableIndexFile) of the table.
+type fileref struct {
 	table string
+	file  string
+}
+
+type pageref struct {
+	id page.ID
+	fileref
 }
 
 // secondaryStorage describes a component of a transaction,
@@ -21,20 +28,24 @@ type pageref struct {
 type secondaryStorage interface {
 	// loadTablesInfo loads the content of the tables.info file.
 	loadTablesInfo() (*dbfs.TablesInfo, error)
+	// loadViewsInfo loads the content of the views.info file.
+	loadViewsInfo() (*dbfs.ViewsInfo, error)
+	// loadTriggersInfo loads the content of the triggers.info file.
+	loadTriggersInfo() (*dbfs.TriggersInfo, error)
 	// loadSchemaFile loads the content of the schema file
 	// of the table with the given name.
 	loadSchemaFile(string) (*dbfs.SchemaFile, error)
-	// loadDataPage loads the page with the given ID from the
-	// data file of the table with the given name.
-	loadDataPage(string, page.ID) (*page.Page, error)
-
-	// unusedPageID returns a currently unused page ID that can be
-	// used to allocate pages within a transaction. The ID
-	// is exclusive to this transaction.
-	unusedPageID(string) (page.ID, error)
-	// availableDataPages returns a slice of all existing available
-	// pages of a data file of the table with the given name.
-	availableDataPages(string) ([]page.ID, error)
+	// loadPage loads the page with the given ID from the
+	// referenced paged file.
+	loadPage(fileref, page.ID) (*page.Page, error)
+
+	// unusedPageID returns a currently unused page ID of the
+	// referenced paged file, that can be used to allocate pages
+	// within a transaction. The ID is exclusive to this transaction.
+	unusedPageID(fileref) (page.ID, error)
+	// availablePages returns a slice of all existing available
+	// pages of the referenced paged file.
+	availablePages(fileref) ([]page.ID, error)
 	hasTable(string) (bool, error)
 }
 
@@ -58,15 +69,46 @@ type TX struct {
 	// will be created (on disk), if they are listed in this slice.
 	// This is expected to be sorted.
 	createdTables []string
-
-	newlyAllocatedPages map[string][]*page.Page
+	// droppedTables is a string slice containing all table names
+	// that were dropped in this transaction. The tables will be
+	// removed from disk, before any table is created, which allows
+	// a table to be dropped and re-created within the same transaction.
+	// This is expected to be sorted.
+	droppedTables []string
+	// renamedTables associates the current name of a table, that was renamed
+	// in this transaction, with the name of the table on disk. The tables
+	// will be renamed on disk after tables were dropped, but before any table
+	// is created. Everything else in this transaction references a renamed
+	// table by its current name.
+	renamedTables map[string]string
+
+	newlyAllocatedPages map[fileref][]*page.Page
 	// tableSchemas associates a table name with the schema file
 	// (of that table). If modified, the transaction manager will persist
 	// the changes upon transaction commit.
 	tableSchemas map[string]*dbfs.SchemaFile
-	// dataPages are potentially modified pages, that the transaction
-	// manager has to persist onto disk upon transaction commit.
-	dataPages map[pageref]*page.Page
+	// pages are potentially modified data and index pages, that the
+	// transaction manager has to persist onto disk upon transaction commit.
+	pages map[pageref]*page.Page
+	// views holds the definitions of all views, that this transaction has
+	// access to, by their name. It is loaded from disk on first access.
+	// If viewsChanged is set, the transaction manager will persist the
+	// views upon transaction commit.
+	views        map[string]dbfs.View
+	viewsChanged bool
+	// triggers holds the definitions of all triggers, like views holds the
+	// definitions of all views. If triggersChanged is set, the transaction
+	// manager will persist the triggers upon transaction commit.
+	triggers        map[string]dbfs.Trigger
+	triggersChanged bool
+	// deferredChecks are the names of the checks, that must succeed
+	// before this transaction can be committed, in the order in which
+	// they were registered. The checks are held by deferredCheckFuncs.
+	deferredChecks     []string
+	deferredCheckFuncs map[string]func() error
+	// savepoints are the savepoints, that are set in this transaction, in
+	// the order in which they were set.
+	savepoints []*savepoint
 }
 
 func newTransaction(secondaryStorage secondaryStorage) *TX {
@@ -74,9 +116,11 @@ func newTransaction(secondaryStorage secondaryStorage) *TX {
 		ID:                  id.Create(),
 		secondaryStorage:    secondaryStorage,
 		state:               StatePending,
-		newlyAllocatedPages: make(map[string][]*page.Page),
+		renamedTables:       make(map[string]string),
+		newlyAllocatedPages: make(map[fileref][]*page.Page),
 		tableSchemas:        make(map[string]*dbfs.SchemaFile),
-		dataPages:           make(map[pageref]*page.Page),
+		pages:               make(map[pageref]*page.Page),
+		deferredCheckFuncs:  make(map[string]func() error),
 	}
 }
 
@@ -91,25 +135,7 @@ func (tx TX) State() State {
 // are not written to disk yet.
 // This will cache loaded pages.
 func (tx *TX) DataPage(table string, id page.ID) (*page.Page, error) {
-	pr := pageref{id, table}
-	if cached, ok := tx.dataPages[pr]; ok {
-		return cached, nil
-	}
-
-	if newlyAllocatedPages, ok := tx.newlyAllocatedPages[table]; ok {
-		for _, newlyAllocatedPage := range newlyAllocatedPages {
-			if newlyAllocatedPage.ID() == id {
-				return newlyAllocatedPage, nil
-			}
-		}
-	}
-
-	p, err := tx.secondaryStorage.loadDataPage(table, id)
-	if err != nil {
-		return nil, fmt.Errorf("load data page from disk: %w", err)
-	}
-	tx.dataPages[pr] = p
-	return p, nil
+	return tx.page(fileref{table, dbfs.TableDataFile}, id)
 }
 
 // DataPageReadOnly will load the requested page from the given table in read-only
@@ -118,8 +144,8 @@ func (tx *TX) DataPage(table string, id page.ID) (*page.Page, error) {
 // a copy of the cached page will be returned. If not, the requested page will be loaded
 // from secondary storage, but will not be cached.
 func (tx *TX) DataPageReadOnly(table string, id page.ID) (*page.Page, error) {
-	pr := pageref{id, table}
-	if cached, ok := tx.dataPages[pr]; ok {
+	ref := pageref{id, fileref{table, dbfs.TableDataFile}}
+	if cached, ok := tx.pages[ref]; ok {
 		p, err := page.Load(cached.CopyOfData())
 		if err != nil {
 			return nil, fmt.Errorf("load: %w", err)
@@ -127,21 +153,56 @@ func (tx *TX) DataPageReadOnly(table string, id page.ID) (*page.Page, error) {
 		return p, nil
 	}
 
-	if newlyAllocatedPages, ok := tx.newlyAllocatedPages[table]; ok {
-		for _, newlyAllocatedPage := range newlyAllocatedPages {
-			if newlyAllocatedPage.ID() == id {
-				return newlyAllocatedPage, nil
-			}
-		}
+	if newlyAllocatedPage, ok := tx.newlyAllocatedPage(ref); ok {
+		return newlyAllocatedPage, nil
 	}
 
-	p, err := tx.secondaryStorage.loadDataPage(table, id)
+	p, err := tx.secondaryStorage.loadPage(tx.diskFile(ref.fileref), id)
 	if err != nil {
 		return nil, fmt.Errorf("load data page from disk: %w", err)
 	}
 	return p, nil
 }
 
+// IndexPage attempts to lookup a page with the given ID from the index
+// file of the table with the given name, like DataPage.
+func (tx *TX) IndexPage(table string, id page.ID) (*page.Page, error) {
+	return tx.page(fileref{table, dbfs.TableIndexFile}, id)
+}
+
+// page looks up the page with the given ID in the referenced file. This will
+// cache loaded pages.
+func (tx *TX) page(file fileref, id page.ID) (*page.Page, error) {
+	ref := pageref{id, file}
+	if cached, ok := tx.pages[ref]; ok {
+		tx.journal(cached)
+		return cached, nil
+	}
+
+	if newlyAllocatedPage, ok := tx.newlyAllocatedPage(ref); ok {
+		tx.journal(newlyAllocatedPage)
+		return newlyAllocatedPage, nil
+	}
+
+	p, err := tx.secondaryStorage.loadPage(tx.diskFile(file), id)
+	if err != nil {
+		return nil, fmt.Errorf("load %v page from disk: %w", file.file, err)
+	}
+	tx.pages[ref] = p
+	return p, nil
+}
+
+// newlyAllocatedPage returns the referenced page, if it was allocated in this
+// transaction.
+func (tx *TX) newlyAllocatedPage(ref pageref) (*page.Page, bool) {
+	for _, newlyAllocatedPage := range tx.newlyAllocatedPages[ref.fileref] {
+		if newlyAllocatedPage.ID() == ref.id {
+			return newlyAllocatedPage, true
+		}
+	}
+	return nil, false
+}
+
 // SchemaFile returns the schema file for the table with the given name.
 // This respects changes to the schema file that were performed in this transaction.
 // Schema files will be cached.
@@ -156,7 +217,7 @@ func (tx *TX) SchemaFile(table string) (*dbfs.SchemaFile, error) {
 		return &sf, nil
 	}
 
-	info, err := tx.secondaryStorage.loadSchemaFile(table)
+	info, err := tx.secondaryStorage.loadSchemaFile(tx.diskName(table))
 	if err != nil {
 		return nil, fmt.Errorf("load schema from disk: %w", err)
 	}
@@ -171,17 +232,110 @@ func (tx *TX) tableWasCreatedInThisTransaction(name string) bool {
 	return index < len(tx.createdTables) && tx.createdTables[index] == name
 }
 
+// diskName returns the name of the table with the given name on disk, which
+// only differs from the given name, if the table was renamed in this
+// transaction.
+func (tx *TX) diskName(table string) string {
+	if diskName, ok := tx.renamedTables[table]; ok {
+		return diskName
+	}
+	return table
+}
+
+// diskFile returns the reference to the given paged file on disk, see diskName.
+func (tx *TX) diskFile(file fileref) fileref {
+	return fileref{tx.diskName(file.table), file.file}
+}
+
+// tableWasRenamedAwayInThisTransaction indicates whether - within this
+// transaction - we renamed the table with the given name on disk, and no table
+// was renamed to that name.
+func (tx *TX) tableWasRenamedAwayInThisTransaction(name string) bool {
+	if _, ok := tx.renamedTables[name]; ok {
+		return false
+	}
+	for _, diskName := range tx.renamedTables {
+		if diskName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tableWasDroppedInThisTransaction indicates whether - within this transaction - we
+// already dropped a table with the given name.
+func (tx *TX) tableWasDroppedInThisTransaction(name string) bool {
+	index := sort.SearchStrings(tx.droppedTables, name)
+	return index < len(tx.droppedTables) && tx.droppedTables[index] == name
+}
+
 // HasTable indicates whether this transaction has access to a table with the given name.
 // This also accounts for tables that were created in this transaction and do not exist
-// on disk yet.
+// on disk yet, as well as tables that were dropped or renamed in this transaction and
+// still exist on disk.
 func (tx *TX) HasTable(name string) (bool, error) {
 	if tx.tableWasCreatedInThisTransaction(name) {
 		return true, nil
 	}
+	if _, ok := tx.renamedTables[name]; ok {
+		return true, nil
+	}
+	if tx.tableWasRenamedAwayInThisTransaction(name) {
+		return false, nil
+	}
+	if tx.tableWasDroppedInThisTransaction(name) {
+		return false, nil
+	}
 
 	return tx.secondaryStorage.hasTable(name)
 }
 
+// Tables returns the sorted names of all tables, that this transaction has access
+// to. This accounts for tables that were created, dropped or renamed in this
+// transaction.
+func (tx *TX) Tables() ([]string, error) {
+	info, err := tx.secondaryStorage.loadTablesInfo()
+	if err != nil {
+		return nil, fmt.Errorf("tables info: %w", err)
+	}
+
+	var names []string
+	for name := range info.Tables {
+		if !tx.tableWasDroppedInThisTransaction(name) && !tx.tableWasRenamedAwayInThisTransaction(name) {
+			names = append(names, name)
+		}
+	}
+	for name := range tx.renamedTables {
+		names = append(names, name)
+	}
+	names = append(names, tx.createdTables...)
+	sort.Strings(names)
+	return names, nil
+}
+
+// DeferCheck registers a check with the given name, that is performed when this
+// transaction is committed. If the check fails, the transaction is not committed.
+// If a check with the given name is already registered, this has no effect.
+func (tx *TX) DeferCheck(name string, check func() error) {
+	if _, ok := tx.deferredCheckFuncs[name]; ok {
+		return
+	}
+	tx.deferredChecks = append(tx.deferredChecks, name)
+	tx.deferredCheckFuncs[name] = check
+}
+
+// performDeferredChecks performs all checks, that were registered with
+// DeferCheck, in the order in which they were registered. The first error
+// of a check is returned.
+func (tx *TX) performDeferredChecks() error {
+	for _, name := range tx.deferredChecks {
+		if err := tx.deferredCheckFuncs[name](); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // CreateTable creates a table in this transaction. If such a table already exists, this will return
 // an error.
 func (tx *TX) CreateTable(name string) error {
@@ -199,39 +353,284 @@ func (tx *TX) CreateTable(name string) error {
 	return nil
 }
 
+// DropTable drops the table with the given name in this transaction. If no such
+// table exists, this will return an error. All changes to the table, that were
+// made in this transaction, are discarded.
+func (tx *TX) DropTable(name string) error {
+	if ok, err := tx.HasTable(name); !ok {
+		return fmt.Errorf("table does not exist in this transaction")
+	} else if err != nil {
+		return fmt.Errorf("has table: %w", err)
+	}
+
+	if tx.tableWasCreatedInThisTransaction(name) {
+		index := sort.SearchStrings(tx.createdTables, name)
+		tx.createdTables = append(tx.createdTables[:index], tx.createdTables[index+1:]...)
+	} else {
+		// a renamed table is dropped under its name on disk
+		diskName := tx.diskName(name)
+		delete(tx.renamedTables, name)
+		insertIndex := sort.SearchStrings(tx.droppedTables, diskName)
+		tx.droppedTables = append(tx.droppedTables[:insertIndex], append([]string{diskName}, tx.droppedTables[insertIndex:]...)...)
+	}
+
+	delete(tx.tableSchemas, name)
+	for ref := range tx.newlyAllocatedPages {
+		if ref.table == name {
+			delete(tx.newlyAllocatedPages, ref)
+		}
+	}
+	for ref := range tx.pages {
+		if ref.table == name {
+			delete(tx.pages, ref)
+		}
+	}
+
+	return nil
+}
+
+// RenameTable renames the table with the given name in this transaction. If no
+// such table exists, or a table with the new name already exists, this will
+// return an error. All changes to the table, that were made in this transaction,
+// are kept.
+func (tx *TX) RenameTable(name, newName string) error {
+	if ok, err := tx.HasTable(name); !ok {
+		return fmt.Errorf("table does not exist in this transaction")
+	} else if err != nil {
+		return fmt.Errorf("has table: %w", err)
+	}
+	if ok, err := tx.HasTable(newName); ok {
+		return fmt.Errorf("table already exists in this transaction")
+	} else if err != nil {
+		return fmt.Errorf("has table: %w", err)
+	}
+
+	if tx.tableWasCreatedInThisTransaction(name) {
+		index := sort.SearchStrings(tx.createdTables, name)
+		tx.createdTables = append(tx.createdTables[:index], tx.createdTables[index+1:]...)
+		insertIndex := sort.SearchStrings(tx.createdTables, newName)
+		tx.createdTables = append(tx.createdTables[:insertIndex], append([]string{newName}, tx.createdTables[insertIndex:]...)...)
+	} else {
+		diskName := tx.diskName(name)
+		delete(tx.renamedTables, name)
+		if diskName != newName {
+			tx.renamedTables[newName] = diskName
+		}
+	}
+
+	if sf, ok := tx.tableSchemas[name]; ok {
+		delete(tx.tableSchemas, name)
+		tx.tableSchemas[newName] = sf
+	}
+	for ref, pages := range tx.newlyAllocatedPages {
+		if ref.table == name {
+			delete(tx.newlyAllocatedPages, ref)
+			tx.newlyAllocatedPages[fileref{newName, ref.file}] = pages
+		}
+	}
+	for ref, p := range tx.pages {
+		if ref.table == name {
+			delete(tx.pages, ref)
+			tx.pages[pageref{ref.id, fileref{newName, ref.file}}] = p
+		}
+	}
+
+	return nil
+}
+
+// View returns the definition of the view with the given name, or false, if no
+// such view exists. This respects views that were created or dropped in this
+// transaction.
+func (tx *TX) View(name string) (dbfs.View, bool, error) {
+	if err := tx.loadViews(); err != nil {
+		return dbfs.View{}, false, err
+	}
+	view, ok := tx.views[name]
+	return view, ok, nil
+}
+
+// Views returns the names of all views, that this transaction has access to,
+// in sorted order.
+func (tx *TX) Views() ([]string, error) {
+	if err := tx.loadViews(); err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range tx.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateView creates a view with the given name and definition in this
+// transaction. If such a view already exists, this will return an error.
+func (tx *TX) CreateView(name string, view dbfs.View) error {
+	if err := tx.loadViews(); err != nil {
+		return err
+	}
+	if _, ok := tx.views[name]; ok {
+		return fmt.Errorf("view already exists in this transaction")
+	}
+	tx.views[name] = view
+	tx.viewsChanged = true
+	return nil
+}
+
+// DropView drops the view with the given name in this transaction. If no such
+// view exists, this will return an error.
+func (tx *TX) DropView(name string) error {
+	if err := tx.loadViews(); err != nil {
+		return err
+	}
+	if _, ok := tx.views[name]; !ok {
+		return fmt.Errorf("view does not exist in this transaction")
+	}
+	delete(tx.views, name)
+	tx.viewsChanged = true
+	return nil
+}
+
+// loadViews loads the definitions of all views from disk, if they were not
+// loaded yet.
+func (tx *TX) loadViews() error {
+	if tx.views != nil {
+		return nil
+	}
+	info, err := tx.secondaryStorage.loadViewsInfo()
+	if err != nil {
+		return fmt.Errorf("views info: %w", err)
+	}
+	tx.views = info.Views
+	if tx.views == nil {
+		tx.views = make(map[string]dbfs.View)
+	}
+	return nil
+}
+
+// Trigger returns the definition of the trigger with the given name, or false,
+// if no such trigger exists. This respects triggers that were created or
+// dropped in this transaction.
+func (tx *TX) Trigger(name string) (dbfs.Trigger, bool, error) {
+	if err := tx.loadTriggers(); err != nil {
+		return dbfs.Trigger{}, false, err
+	}
+	trigger, ok := tx.triggers[name]
+	return trigger, ok, nil
+}
+
+// Triggers returns the names of all triggers, that this transaction has access
+// to, in sorted order.
+func (tx *TX) Triggers() ([]string, error) {
+	if err := tx.loadTriggers(); err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range tx.triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateTrigger creates a trigger with the given name and definition in this
+// transaction. If such a trigger already exists, this will return an error.
+func (tx *TX) CreateTrigger(name string, trigger dbfs.Trigger) error {
+	if err := tx.loadTriggers(); err != nil {
+		return err
+	}
+	if _, ok := tx.triggers[name]; ok {
+		return fmt.Errorf("trigger already exists in this transaction")
+	}
+	tx.triggers[name] = trigger
+	tx.triggersChanged = true
+	return nil
+}
+
+// DropTrigger drops the trigger with the given name in this transaction. If no
+// such trigger exists, this will return an error.
+func (tx *TX) DropTrigger(name string) error {
+	if err := tx.loadTriggers(); err != nil {
+		return err
+	}
+	if _, ok := tx.triggers[name]; !ok {
+		return fmt.Errorf("trigger does not exist in this transaction")
+	}
+	delete(tx.triggers, name)
+	tx.triggersChanged = true
+	return nil
+}
+
+// loadTriggers loads the definitions of all triggers from disk, if they were
+// not loaded yet.
+func (tx *TX) loadTriggers() error {
+	if tx.triggers != nil {
+		return nil
+	}
+	info, err := tx.secondaryStorage.loadTriggersInfo()
+	if err != nil {
+		return fmt.Errorf("triggers info: %w", err)
+	}
+	tx.triggers = info.Triggers
+	if tx.triggers == nil {
+		tx.triggers = make(map[string]dbfs.Trigger)
+	}
+	return nil
+}
+
 // AllocateNewDataPage will attempt to allocate a new page in the data file of the table
 // with the given name. If the table does not exist, an error will be returned.
 func (tx *TX) AllocateNewDataPage(table string) (*page.Page, error) {
-	if ok, err := tx.HasTable(table); !ok {
+	return tx.allocateNewPage(fileref{table, dbfs.TableDataFile})
+}
+
+// AllocateNewIndexPage will attempt to allocate a new page in the index file of the table
+// with the given name. If the table does not exist, an error will be returned.
+func (tx *TX) AllocateNewIndexPage(table string) (*page.Page, error) {
+	return tx.allocateNewPage(fileref{table, dbfs.TableIndexFile})
+}
+
+func (tx *TX) allocateNewPage(file fileref) (*page.Page, error) {
+	if ok, err := tx.HasTable(file.table); !ok {
 		return nil, fmt.Errorf("table does not exixst in this transaction")
 	} else if err != nil {
 		return nil, fmt.Errorf("has table: %w", err)
 	}
 
-	var newID page.ID
+	// IDs of pages that were allocated in this transaction are not known to
+	// the secondary storage, so they must be skipped explicitly
+	used := make(map[page.ID]struct{})
+	for _, p := range tx.newlyAllocatedPages[file] {
+		used[p.ID()] = struct{}{}
+	}
 
-	if tx.tableWasCreatedInThisTransaction(table) {
-	outer:
-		for _, p := range tx.newlyAllocatedPages[table] {
-			for newID = page.ID(0); ; newID++ {
-				if newID != p.ID() {
-					break outer
-				}
-			}
-		}
-	} else {
+	var newID page.ID
+	if !tx.tableWasCreatedInThisTransaction(file.table) {
 		var err error
-		newID, err = tx.secondaryStorage.unusedPageID(table)
+		newID, err = tx.secondaryStorage.unusedPageID(tx.diskFile(file))
 		if err != nil {
 			return nil, fmt.Errorf("unused page ID: %w", err)
 		}
+		diskPages, err := tx.secondaryStorage.availablePages(tx.diskFile(file))
+		if err != nil {
+			return nil, fmt.Errorf("available pages: %w", err)
+		}
+		for _, id := range diskPages {
+			used[id] = struct{}{}
+		}
+	}
+	for {
+		if _, ok := used[newID]; !ok {
+			break
+		}
+		newID++
 	}
 
 	newPage, err := page.New(newID)
 	if err != nil {
 		return nil, fmt.Errorf("new: %w", err)
 	}
-	tx.newlyAllocatedPages[table] = append(tx.newlyAllocatedPages[table], newPage)
+	tx.newlyAllocatedPages[file] = append(tx.newlyAllocatedPages[file], newPage)
 	return newPage, nil
 }
 
@@ -245,15 +644,16 @@ func (tx *TX) ExistingDataPagesForTable(table string) ([]page.ID, error) {
 		return nil, fmt.Errorf("has table: %w", err)
 	}
 
+	file := fileref{table, dbfs.TableDataFile}
 	var ids []page.ID
-	for _, p := range tx.newlyAllocatedPages[table] {
+	for _, p := range tx.newlyAllocatedPages[file] {
 		ids = append(ids, p.ID())
 	}
 	if tx.tableWasCreatedInThisTransaction(table) {
 		return ids, nil
 	}
 
-	diskPages, err := tx.secondaryStorage.availableDataPages(table)
+	diskPages, err := tx.secondaryStorage.availablePages(tx.diskFile(file))
 	if err != nil {
 		return nil, fmt.Errorf("available data pages: %w", err)
 	}
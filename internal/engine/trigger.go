@@ -0,0 +1,424 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler"
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+const (
+	// newTable and oldTable are the names, through which the statements of
+	// a trigger reference the row after and before the change, that fired
+	// the trigger.
+	newTable = "new"
+	oldTable = "old"
+
+	// errRaiseIgnore is returned by RAISE(IGNORE). It abandons the trigger
+	// and the change of the row, that fired the trigger, and is never
+	// returned to the caller of the statement.
+	errRaiseIgnore Error = "raise ignore"
+)
+
+// namedTrigger is the definition of a trigger, together with its name.
+type namedTrigger struct {
+	name string
+	dbfs.Trigger
+}
+
+// firingTrigger is a trigger, whose statements are evaluated for a changed
+// row. The old row of an inserted row, and the new row of a deleted row hold
+// no values.
+type firingTrigger struct {
+	name string
+	old  table.RowWithColInfo
+	new  table.RowWithColInfo
+	// parent is the trigger, whose statements fired this trigger, or nil, if
+	// this trigger was fired by a statement outside of a trigger.
+	parent *firingTrigger
+}
+
+// isFiring returns whether the trigger with the given name is this trigger or
+// one of its parents.
+func (t *firingTrigger) isFiring(name string) bool {
+	for ; t != nil; t = t.parent {
+		if t.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of the given column of the NEW or OLD row of this
+// trigger. If the column is not qualified with NEW or OLD, false is returned.
+func (t *firingTrigger) value(name string) (types.Value, bool, error) {
+	qualifier, colName := table.SplitQualifiedName(name)
+	var row table.RowWithColInfo
+	switch {
+	case strings.EqualFold(qualifier, newTable):
+		row = t.new
+	case strings.EqualFold(qualifier, oldTable):
+		row = t.old
+	default:
+		return nil, false, nil
+	}
+	if row.Values == nil {
+		return nil, true, fmt.Errorf("trigger %v has no %v row", t.name, strings.ToUpper(qualifier))
+	}
+	value, ok := row.ValueForColName(colName)
+	if !ok {
+		return nil, true, ErrNoSuchColumn(name)
+	}
+	return value, true, nil
+}
+
+// evaluateCreateTrigger creates the trigger from the given command. BEFORE and
+// AFTER triggers can only be created on tables, and INSTEAD OF triggers only on
+// views. The condition and the statements of the trigger are stored as SQL
+// source, and are compiled again whenever the trigger fires.
+func (e Engine) evaluateCreateTrigger(ctx ExecutionContext, cmd command.CreateTrigger) (table.Table, error) {
+	defer e.profiler.Enter("create trigger").Exit()
+	tx := ctx.tx
+
+	if _, ok, err := tx.Trigger(cmd.Name); err != nil {
+		return nil, fmt.Errorf("trigger: %w", err)
+	} else if ok {
+		if cmd.IfNotExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", cmd.Name, ErrAlreadyExists)
+	}
+
+	if cmd.Timing == command.TriggerInsteadOf {
+		if _, ok, err := tx.View(cmd.Table); err != nil {
+			return nil, fmt.Errorf("view: %w", err)
+		} else if !ok {
+			return nil, fmt.Errorf("%v: %w", cmd.Table, ErrNoSuchView)
+		}
+	} else {
+		if ok, err := tx.HasTable(cmd.Table); err != nil {
+			return nil, fmt.Errorf("has table: %w", err)
+		} else if !ok {
+			return nil, fmt.Errorf("%v: %w", cmd.Table, ErrNoSuchTable)
+		}
+		sf, err := tx.SchemaFile(cmd.Table)
+		if err != nil {
+			return nil, fmt.Errorf("schema file: %w", err)
+		}
+		if _, err := columnIndices(sf.Columns, cmd.Cols); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.CreateTrigger(cmd.Name, dbfs.Trigger{
+		Table:      cmd.Table,
+		Timing:     triggerTiming(cmd.Timing),
+		Event:      triggerEvent(cmd.Event),
+		Columns:    cmd.Cols,
+		When:       cmd.WhenSource,
+		Statements: cmd.StatementSources,
+	}); err != nil {
+		return nil, fmt.Errorf("create trigger: %w", err)
+	}
+	return table.Empty, nil
+}
+
+func triggerTiming(timing command.TriggerTiming) dbfs.TriggerTiming {
+	switch timing {
+	case command.TriggerAfter:
+		return dbfs.After
+	case command.TriggerInsteadOf:
+		return dbfs.InsteadOf
+	default:
+		return dbfs.Before
+	}
+}
+
+func triggerEvent(event command.TriggerEvent) dbfs.TriggerEvent {
+	switch event {
+	case command.TriggerInsert:
+		return dbfs.Insert
+	case command.TriggerUpdate:
+		return dbfs.Update
+	default:
+		return dbfs.Delete
+	}
+}
+
+// evaluateDropTrigger drops the trigger from the given command.
+func (e Engine) evaluateDropTrigger(ctx ExecutionContext, cmd command.DropTrigger) (table.Table, error) {
+	defer e.profiler.Enter("drop trigger").Exit()
+
+	if _, ok, err := ctx.tx.Trigger(cmd.Name); err != nil {
+		return nil, fmt.Errorf("trigger: %w", err)
+	} else if !ok {
+		if cmd.IfExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", cmd.Name, ErrNoSuchTrigger)
+	}
+
+	if err := ctx.tx.DropTrigger(cmd.Name); err != nil {
+		return nil, fmt.Errorf("drop trigger: %w", err)
+	}
+	return table.Empty, nil
+}
+
+// updateTriggers updates the definitions of all triggers on the given table or
+// view with the given function. If the function returns false, the trigger is
+// dropped instead.
+func (e Engine) updateTriggers(ctx ExecutionContext, tableName string, update func(*dbfs.Trigger) bool) error {
+	names, err := ctx.tx.Triggers()
+	if err != nil {
+		return fmt.Errorf("triggers: %w", err)
+	}
+	for _, name := range names {
+		trigger, _, err := ctx.tx.Trigger(name)
+		if err != nil {
+			return fmt.Errorf("trigger: %w", err)
+		}
+		if trigger.Table != tableName {
+			continue
+		}
+		if err := ctx.tx.DropTrigger(name); err != nil {
+			return fmt.Errorf("drop trigger: %w", err)
+		}
+		if !update(&trigger) {
+			continue
+		}
+		if err := ctx.tx.CreateTrigger(name, trigger); err != nil {
+			return fmt.Errorf("create trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// renamedTriggers returns the definitions of all triggers, that are changed
+// by the given function, by their names. The function returns the renamed
+// definition of the given trigger, see renameTriggerSources. The triggers are
+// not changed, so that a rename can be rejected before anything was changed,
+// see storeTriggers.
+func renamedTriggers(ctx ExecutionContext, rename func(dbfs.Trigger) (dbfs.Trigger, error)) (map[string]dbfs.Trigger, error) {
+	names, err := ctx.tx.Triggers()
+	if err != nil {
+		return nil, fmt.Errorf("triggers: %w", err)
+	}
+	triggers := make(map[string]dbfs.Trigger)
+	for _, name := range names {
+		trigger, _, err := ctx.tx.Trigger(name)
+		if err != nil {
+			return nil, fmt.Errorf("trigger: %w", err)
+		}
+		renamed, err := rename(trigger)
+		if err != nil {
+			return nil, fmt.Errorf("trigger %v: %w", name, err)
+		}
+		if !reflect.DeepEqual(renamed, trigger) {
+			triggers[name] = renamed
+		}
+	}
+	return triggers, nil
+}
+
+// renameTriggerSources returns the given trigger, whose condition is renamed
+// with the given expression function, and whose statements are renamed with
+// the given statement function. The slices of the given trigger are not
+// modified.
+func renameTriggerSources(trigger dbfs.Trigger, renameExpr, renameStatement func(string) (string, error)) (dbfs.Trigger, error) {
+	if trigger.When != "" {
+		when, err := renameExpr(trigger.When)
+		if err != nil {
+			return dbfs.Trigger{}, fmt.Errorf("when %v: %w", trigger.When, err)
+		}
+		trigger.When = when
+	}
+	statements := make([]string, len(trigger.Statements))
+	for i, statement := range trigger.Statements {
+		renamed, err := renameStatement(statement)
+		if err != nil {
+			return dbfs.Trigger{}, fmt.Errorf("statement %v: %w", statement, err)
+		}
+		statements[i] = renamed
+	}
+	trigger.Statements = statements
+	return trigger, nil
+}
+
+// storeTriggers replaces the definitions of the given triggers by their
+// names.
+func storeTriggers(ctx ExecutionContext, triggers map[string]dbfs.Trigger) error {
+	for name, trigger := range triggers {
+		if err := ctx.tx.DropTrigger(name); err != nil {
+			return fmt.Errorf("drop trigger: %w", err)
+		}
+		if err := ctx.tx.CreateTrigger(name, trigger); err != nil {
+			return fmt.Errorf("create trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// triggersOn returns the triggers on the given table or view, that are fired
+// by the given event, in the order of their names. Triggers, that are firing
+// in the given context, are omitted, since triggers don't fire recursively.
+func triggersOn(ctx ExecutionContext, tableName string, event dbfs.TriggerEvent) ([]namedTrigger, error) {
+	names, err := ctx.tx.Triggers()
+	if err != nil {
+		return nil, fmt.Errorf("triggers: %w", err)
+	}
+	var triggers []namedTrigger
+	for _, name := range names {
+		trigger, _, err := ctx.tx.Trigger(name)
+		if err != nil {
+			return nil, fmt.Errorf("trigger: %w", err)
+		}
+		if trigger.Table == tableName && trigger.Event == event && !ctx.trigger.isFiring(name) {
+			triggers = append(triggers, namedTrigger{name, trigger})
+		}
+	}
+	return triggers, nil
+}
+
+// hasTiming returns whether any of the given triggers has the given timing.
+func hasTiming(triggers []namedTrigger, timing dbfs.TriggerTiming) bool {
+	for _, trigger := range triggers {
+		if trigger.Timing == timing {
+			return true
+		}
+	}
+	return false
+}
+
+// fireTriggers fires all given triggers with the given timing for the given
+// change of a row of a table with the given columns. Update triggers, that are
+// restricted to columns, only fire if one of those columns is among the given
+// updated columns. If a trigger evaluates RAISE(IGNORE), the remaining
+// triggers are not fired, and true is returned, indicating that the change
+// must be skipped.
+func (e Engine) fireTriggers(ctx ExecutionContext, triggers []namedTrigger, timing dbfs.TriggerTiming, cols []table.Col, change rowChange, updatedCols []string) (bool, error) {
+	for _, trigger := range triggers {
+		if trigger.Timing != timing || !updatesAny(trigger.Columns, updatedCols) {
+			continue
+		}
+
+		triggerCtx := ctx.firing(&firingTrigger{
+			name:   trigger.name,
+			old:    table.RowWithColInfo{Cols: cols, Row: change.old},
+			new:    table.RowWithColInfo{Cols: cols, Row: change.new},
+			parent: ctx.trigger,
+		})
+		if err := e.evaluateTrigger(triggerCtx, trigger); errors.Is(err, errRaiseIgnore) {
+			return true, nil
+		} else if err != nil {
+			return false, fmt.Errorf("trigger %v: %w", trigger.name, err)
+		}
+	}
+	return false, nil
+}
+
+// updatesAny returns whether any of the given columns of a trigger is among the
+// given updated columns. If the trigger has no columns, true is returned.
+func updatesAny(triggerCols, updatedCols []string) bool {
+	if len(triggerCols) == 0 {
+		return true
+	}
+	for _, col := range triggerCols {
+		for _, updated := range updatedCols {
+			if col == updated {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evaluateTrigger evaluates the condition of the given trigger, and if it is
+// met, all statements of the trigger in order. The rows of the results of the
+// statements are read, so that every expression of a query, such as a RAISE
+// function, is evaluated.
+func (e Engine) evaluateTrigger(ctx ExecutionContext, trigger namedTrigger) error {
+	defer e.profiler.Enter("trigger").Exit()
+
+	if trigger.When != "" {
+		when, err := compiler.CompileExpr(trigger.When)
+		if err != nil {
+			return fmt.Errorf("compile when: %w", err)
+		}
+		if ok, err := e.evaluateFilter(ctx, when); err != nil {
+			return fmt.Errorf("when: %w", err)
+		} else if !ok {
+			return nil
+		}
+	}
+
+	for _, statement := range trigger.Statements {
+		cmd, err := compiler.CompileStatement(statement)
+		if err != nil {
+			return fmt.Errorf("compile: %w", err)
+		}
+		result, err := e.evaluate(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if err := drainRows(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drainRows reads all rows of the given table, and discards them.
+func drainRows(tbl table.Table) error {
+	rows, err := tbl.Rows()
+	if err != nil {
+		return fmt.Errorf("rows: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for {
+		if _, err := rows.Next(); err == table.ErrEOT {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// evaluateRaise evaluates the given RAISE function, which always fails. Unless
+// the action is RaiseIgnore, the returned error holds the message of the
+// function, and wraps ErrRaise. RaiseRollback additionally rolls back the
+// transaction, and RaiseFail keeps the changes, that the statement made
+// before, see keptChanges.
+func (e Engine) evaluateRaise(ctx ExecutionContext, expr command.RaiseExpr) error {
+	if ctx.trigger == nil {
+		return fmt.Errorf("RAISE can only be used in the statements of a trigger")
+	}
+
+	switch expr.Action {
+	case command.RaiseIgnore:
+		return errRaiseIgnore
+	case command.RaiseRollback:
+		return e.rollback(ctx, fmt.Errorf("%v: %w", expr.Message, ErrRaise))
+	case command.RaiseFail:
+		// the changes of the statement, that were made before, are kept
+		return keptChanges{fmt.Errorf("%v: %w", expr.Message, ErrRaise)}
+	default:
+		// RaiseAbort undoes the changes of the statement
+		return fmt.Errorf("%v: %w", expr.Message, ErrRaise)
+	}
+}
+
+// triggerValue returns the value of the given column of the NEW or OLD row of
+// the trigger, that is firing in the given context. If no trigger is firing,
+// or the column is not qualified with NEW or OLD, false is returned.
+func triggerValue(ctx ExecutionContext, name string) (types.Value, bool, error) {
+	if ctx.trigger == nil {
+		return nil, false, nil
+	}
+	return ctx.trigger.value(name)
+}
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestTriggerSuite(t *testing.T) {
+	suite.Run(t, new(TriggerSuite))
+}
+
+type TriggerSuite struct {
+	EngineSuite
+}
+
+func (suite *TriggerSuite) SetupTest() {
+	suite.EngineSuite.SetupTest()
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 1, 'a'), (2, 2, 'b'), (3, 1, 'c')`))
+	suite.Require().NoError(suite.exec(`CREATE TABLE log (id INTEGER, what STRING)`))
+}
+
+func (suite *TriggerSuite) TestAfterInsert() {
+	suite.NoError(suite.exec(`CREATE TRIGGER logInsert AFTER INSERT ON items BEGIN INSERT INTO log VALUES (NEW.id, NEW.name); END`))
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (4, 1, 'd'), (5, 2, 'e')`))
+	suite.NoError(suite.exec(`INSERT INTO items (id, name) SELECT id + 10, name FROM items WHERE id = 1`))
+
+	suite.Equal([]table.Row{
+		logRow(4, "d"),
+		logRow(5, "e"),
+		logRow(11, "a"),
+	}, suite.selectRows(`SELECT * FROM log`))
+}
+
+func (suite *TriggerSuite) TestBeforeUpdate() {
+	suite.NoError(suite.exec(`CREATE TRIGGER checkGrp BEFORE UPDATE OF grp ON items WHEN NEW.grp > 5 BEGIN SELECT RAISE(ABORT, 'group too large'); END`))
+
+	err := suite.exec(`UPDATE items SET grp = 7 WHERE id = 2`)
+	suite.ErrorIs(err, ErrRaise)
+	suite.Contains(err.Error(), "group too large")
+	suite.NoError(suite.exec(`UPDATE items SET grp = 5 WHERE id = 2`))
+	// the trigger only fires for updates of grp
+	suite.NoError(suite.exec(`UPDATE items SET name = 'x' WHERE grp = 5`))
+
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(2, 5, "x"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM items`))
+}
+
+func (suite *TriggerSuite) TestAfterUpdate() {
+	suite.NoError(suite.exec(`CREATE TRIGGER logUpdate AFTER UPDATE ON items BEGIN INSERT INTO log VALUES (OLD.id, NEW.name); END`))
+	suite.NoError(suite.exec(`UPDATE items SET id = id + 10, name = 'x' WHERE grp = 1`))
+
+	suite.Equal([]table.Row{
+		logRow(1, "x"),
+		logRow(3, "x"),
+	}, suite.selectRows(`SELECT * FROM log`))
+}
+
+func (suite *TriggerSuite) TestRaiseAbortAndFail() {
+	suite.NoError(suite.exec(`CREATE TRIGGER logInsert AFTER INSERT ON items BEGIN INSERT INTO log VALUES (NEW.id, 'insert'); END`))
+	suite.NoError(suite.exec(`CREATE TRIGGER abortInsert AFTER INSERT ON items WHEN NEW.id = 5 BEGIN SELECT RAISE(ABORT, 'abort'); END`))
+	suite.NoError(suite.exec(`CREATE TRIGGER failInsert AFTER INSERT ON items WHEN NEW.id = 7 BEGIN SELECT RAISE(FAIL, 'fail'); END`))
+
+	// RAISE(ABORT) undoes all changes of the statement
+	suite.ErrorIs(suite.exec(`INSERT INTO items VALUES (4, 1, 'd'), (5, 1, 'e')`), ErrRaise)
+	// RAISE(FAIL) keeps the changes, that the statement made before
+	suite.ErrorIs(suite.exec(`INSERT INTO items VALUES (6, 1, 'f'), (7, 1, 'g')`), ErrRaise)
+	suite.commit()
+
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(2, 2, "b"),
+		itemRow(3, 1, "c"),
+		itemRow(6, 1, "f"),
+		itemRow(7, 1, "g"),
+	}, suite.selectRows(`SELECT * FROM items`))
+	// triggers fire in the order of their names, so failInsert fires before
+	// logInsert
+	suite.Equal([]table.Row{
+		logRow(6, "insert"),
+	}, suite.selectRows(`SELECT * FROM log`))
+}
+
+func (suite *TriggerSuite) TestRaiseIgnore() {
+	suite.NoError(suite.exec(`CREATE TRIGGER keepFirst BEFORE DELETE ON items WHEN OLD.grp = 1 BEGIN SELECT RAISE(IGNORE); END`))
+	suite.NoError(suite.exec(`CREATE TRIGGER logDelete AFTER DELETE ON items BEGIN INSERT INTO log VALUES (OLD.id, 'delete'); END`))
+
+	tbl, err := suite.evaluateStatement(`DELETE FROM items`)
+	suite.Require().NoError(err)
+	suite.Equal([]table.Row{intRow(1)}, suite.rows(tbl))
+
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`SELECT * FROM items`))
+	suite.Equal([]table.Row{
+		logRow(2, "delete"),
+	}, suite.selectRows(`SELECT * FROM log`))
+}
+
+func (suite *TriggerSuite) TestInsteadOf() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT id, name AS label FROM items WHERE grp = 1`))
+	suite.NoError(suite.exec(`CREATE TRIGGER insertGrouped INSTEAD OF INSERT ON grouped BEGIN INSERT INTO items VALUES (NEW.id, 1, NEW.label); END`))
+	suite.NoError(suite.exec(`CREATE TRIGGER updateGrouped INSTEAD OF UPDATE ON grouped BEGIN UPDATE items SET name = NEW.label WHERE id = OLD.id; END`))
+
+	suite.NoError(suite.exec(`INSERT INTO grouped VALUES (4, 'd')`))
+	tbl, err := suite.evaluateStatement(`UPDATE grouped SET label = 'z' WHERE id >= 3`)
+	suite.Require().NoError(err)
+	suite.Equal([]table.Row{intRow(2)}, suite.rows(tbl))
+	suite.Error(suite.exec(`DELETE FROM grouped`))
+
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(2, 2, "b"),
+		itemRow(3, 1, "z"),
+		itemRow(4, 1, "z"),
+	}, suite.selectRows(`SELECT * FROM items`))
+}
+
+func (suite *TriggerSuite) TestNoRecursion() {
+	// the trigger doesn't fire for the rows, that it inserts itself
+	suite.NoError(suite.exec(`CREATE TRIGGER copy AFTER INSERT ON items BEGIN INSERT INTO items VALUES (NEW.id + 100, NEW.grp, NEW.name); END`))
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (4, 1, 'd')`))
+
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+		intRow(4),
+		intRow(104),
+	}, suite.selectRows(`SELECT id FROM items`))
+}
+
+func (suite *TriggerSuite) TestCreateAndDrop() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT * FROM items WHERE grp = 1`))
+
+	suite.ErrorIs(suite.exec(`CREATE TRIGGER t BEFORE INSERT ON missing BEGIN DELETE FROM log; END`), ErrNoSuchTable)
+	suite.ErrorIs(suite.exec(`CREATE TRIGGER t BEFORE INSERT ON grouped BEGIN DELETE FROM log; END`), ErrNoSuchTable)
+	suite.ErrorIs(suite.exec(`CREATE TRIGGER t INSTEAD OF INSERT ON items BEGIN DELETE FROM log; END`), ErrNoSuchView)
+	suite.Error(suite.exec(`CREATE TRIGGER t BEFORE UPDATE OF missing ON items BEGIN DELETE FROM log; END`))
+
+	// RAISE fails outside of a trigger as well, but not with ErrRaise
+	tbl, err := suite.evaluateStatement(`SELECT RAISE(ABORT, 'outside of a trigger')`)
+	suite.Require().NoError(err)
+	err = drainRows(tbl)
+	suite.Error(err)
+	suite.NotErrorIs(err, ErrRaise)
+
+	suite.NoError(suite.exec(`CREATE TRIGGER t AFTER INSERT ON items BEGIN INSERT INTO log VALUES (NEW.id, 'insert'); END`))
+	suite.ErrorIs(suite.exec(`CREATE TRIGGER t AFTER DELETE ON items BEGIN DELETE FROM log; END`), ErrAlreadyExists)
+	suite.NoError(suite.exec(`CREATE TRIGGER IF NOT EXISTS t AFTER DELETE ON items BEGIN DELETE FROM log; END`))
+	suite.commit()
+
+	// the trigger is persisted, and follows its table
+	suite.NoError(suite.exec(`ALTER TABLE items RENAME TO things`))
+	suite.NoError(suite.exec(`INSERT INTO things VALUES (4, 1, 'd')`))
+	suite.Equal([]table.Row{logRow(4, "insert")}, suite.selectRows(`SELECT * FROM log`))
+
+	suite.NoError(suite.exec(`DROP TRIGGER t`))
+	suite.ErrorIs(suite.exec(`DROP TRIGGER t`), ErrNoSuchTrigger)
+	suite.NoError(suite.exec(`DROP TRIGGER IF EXISTS t`))
+	suite.NoError(suite.exec(`INSERT INTO things VALUES (5, 1, 'e')`))
+	suite.Len(suite.selectRows(`SELECT * FROM log`), 1)
+
+	// the triggers of a table are dropped with the table
+	suite.NoError(suite.exec(`CREATE TRIGGER t AFTER INSERT ON things BEGIN DELETE FROM log; END`))
+	suite.NoError(suite.exec(`DROP TABLE things`))
+	names, err := suite.ctx.tx.Triggers()
+	suite.NoError(err)
+	suite.Empty(names)
+}
+
+func logRow(id int64, what string) table.Row {
+	return table.Row{Values: []types.Value{types.NewInteger(id), types.NewString(what)}}
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 var (
 	// Date is the date type. Dates are comparable. A date that is later than
 	// another date is considered larger. The name of this type is "Date".
@@ -15,6 +17,31 @@ type DateType struct {
 	typ
 }
 
+// Serialize converts the given value to a byte slice, which can be deserialized
+// to obtain a different Value with the same value. The given value has to be a
+// DateValue. The result of this method can be used by DateType.Deserialize.
+func (t DateType) Serialize(v Value) ([]byte, error) {
+	if err := t.ensureHasThisType(v); err != nil {
+		return nil, err
+	}
+
+	val, ok := v.(DateValue)
+	if !ok {
+		return nil, ErrTypeMismatch(Date, v.Type())
+	}
+	return val.Value.MarshalBinary()
+}
+
+// Deserialize converts a given byte slice to a DateValue. The input has to be
+// one that could have been (or even was) generated by DateType.Serialize.
+func (t DateType) Deserialize(data []byte) (Value, error) {
+	var val time.Time
+	if err := val.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return NewDate(val), nil
+}
+
 // Compare compares two date values. For this to succeed, both values must be of
 // type DateValue and be not nil. A date later than another date is considered
 // larger. This method will return 1 if left>right, 0 if left==right, and -1 if
@@ -57,7 +57,7 @@ func (t IntegerType) Compare(left, right Value) (int, error) {
 
 	if leftInteger < rightInteger {
 		return -1, nil
-	} else if rightInteger > leftInteger {
+	} else if leftInteger > rightInteger {
 		return 1, nil
 	}
 	return 0, nil
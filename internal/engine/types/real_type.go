@@ -17,6 +17,32 @@ type RealType struct {
 	typ
 }
 
+// Serialize converts the given value to a byte slice, which can be deserialized
+// to obtain a different Value with the same value. The given value has to be a
+// RealValue. The result of this method can be used by RealType.Deserialize.
+func (t RealType) Serialize(v Value) ([]byte, error) {
+	if err := t.ensureHasThisType(v); err != nil {
+		return nil, err
+	}
+
+	val, ok := v.(RealValue)
+	if !ok {
+		return nil, ErrTypeMismatch(Real, v.Type())
+	}
+	payload := make([]byte, 8)
+	byteOrder.PutUint64(payload, math.Float64bits(val.Value))
+	return payload, nil
+}
+
+// Deserialize converts a given byte slice to a RealValue. The input has to be
+// one that could have been (or even was) generated by RealType.Serialize.
+func (t RealType) Deserialize(data []byte) (Value, error) {
+	if len(data) != 8 {
+		return nil, ErrDataSizeMismatch(8, len(data))
+	}
+	return NewReal(math.Float64frombits(byteOrder.Uint64(data))), nil
+}
+
 // Compare compares two real values. For this to succeed, both values must be of
 // type RealValue and be not nil.
 func (t RealType) Compare(left, right Value) (int, error) {
@@ -35,7 +61,7 @@ func (t RealType) Compare(left, right Value) (int, error) {
 
 	if leftReal < rightReal {
 		return -1, nil
-	} else if rightReal > leftReal {
+	} else if leftReal > rightReal {
 		return 1, nil
 	}
 	return 0, nil
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializer_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  Type
+		v    Value
+	}{
+		{"bool", Bool, NewBool(true)},
+		{"integer", Integer, NewInteger(-12345)},
+		{"real", Real, NewReal(-12.79)},
+		{"string", String, NewString("abc")},
+		{"date", Date, NewDate(time.Date(2020, 7, 2, 14, 3, 27, 0, time.UTC))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			serializer, ok := tt.typ.(Serializer)
+			assert.True(ok)
+
+			data, err := serializer.Serialize(tt.v)
+			assert.NoError(err)
+			got, err := serializer.Deserialize(data)
+			assert.NoError(err)
+			assert.Equal(tt.v, got)
+		})
+	}
+}
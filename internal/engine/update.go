@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// evaluateUpdate updates all rows in the table, that match the filter of the
+// given command. If the table has keys, updated rows must not conflict with
+// other rows on a key of the table, which is checked through the conflict
+// resolver of the table. How a conflict is resolved, depends on the UpdateOr
+// of the command. If the command has a RETURNING clause, the updated rows are
+// projected onto its columns, otherwise the amount of updated rows is
+// returned. The rows are read through the access path, that is chosen for the
+// table and the filter, see (Engine).chooseAccessPath. The BEFORE UPDATE
+// triggers of the table fire for every matching row, before its update is
+// checked for conflicts, and the AFTER UPDATE triggers fire for every updated
+// row, after all rows were written. If the table is a view, its INSTEAD OF
+// triggers fire instead, see (Engine).updateView.
+func (e Engine) evaluateUpdate(ctx ExecutionContext, c command.Update) (table.Table, error) {
+	defer e.profiler.Enter("update").Exit()
+
+	if err := ensureFilter(c.Filter); err != nil {
+		return nil, err
+	}
+
+	if query, view, ok, err := viewQuery(ctx, c.Table.QualifiedName()); err != nil {
+		return nil, err
+	} else if ok {
+		return e.updateView(ctx, c, query, view)
+	}
+
+	loaded, err := e.LoadTable(ctx.tx, c.Table.QualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("load table: %w", err)
+	}
+	tbl, ok := loaded.(*Table)
+	if !ok {
+		return nil, fmt.Errorf("table %v is not updatable", c.Table.QualifiedName())
+	}
+
+	sf, err := ctx.tx.SchemaFile(c.Table.QualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("schema file: %w", err)
+	}
+	cols := sf.Columns
+
+	// rows can only conflict, if the table has keys
+	var resolver *conflictResolver
+	if len(sf.Keys()) != 0 {
+		if resolver, err = e.newConflictResolver(ctx, tbl, sf); err != nil {
+			return nil, fmt.Errorf("conflict resolver: %w", err)
+		}
+	}
+
+	triggers, err := triggersOn(ctx, c.Table.QualifiedName(), dbfs.Update)
+	if err != nil {
+		return nil, err
+	}
+	updatedCols := setterCols(c.Updates)
+
+	// all matching records are read before the first row is updated, so that
+	// every row is updated at most once, and tables, that are not simple
+	// tables, don't hold any index hints
+	simple, _ := c.Table.(command.SimpleTable)
+	path, err := e.chooseAccessPath(ctx, tbl, simple, c.Filter)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := e.selectRecordsThrough(ctx, tbl, path, c.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []table.Row
+	var changes []rowChange
+	var updateErr error
+	for _, rec := range selected {
+		if resolver != nil && resolver.isDeleted(rec) {
+			// deleted by an UPDATE OR REPLACE
+			continue
+		}
+		rowCtx := ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: cols,
+			Row:  rec.row,
+		})
+		row, err := e.applyUpdateSetters(rowCtx, cols, rec.row, c.Updates)
+		if err != nil {
+			return nil, err
+		}
+		change := rowChange{old: rec.row, new: row}
+		if ignored, err := e.fireTriggers(ctx, triggers, dbfs.Before, cols, change, updatedCols); err != nil {
+			return nil, err
+		} else if ignored {
+			continue
+		}
+
+		conflict := checkRowConflict(cols, row)
+		if conflict == nil {
+			conflict = tbl.checkConstraints(row)
+		}
+		if conflict == nil && resolver != nil {
+			if err := resolver.Update(rec, row, c.UpdateOr); isConflict(err) {
+				conflict = err
+			} else if err != nil {
+				return nil, err
+			}
+		} else if conflict == nil {
+			rec.row = row
+			if err := tbl.replaceRecord(rec); err != nil {
+				return nil, fmt.Errorf("replace record: %w", err)
+			}
+		}
+		if conflict != nil {
+			if c.UpdateOr == command.UpdateOrIgnore {
+				continue
+			}
+			if c.UpdateOr == command.UpdateOrRollback {
+				return nil, e.rollback(ctx, conflict)
+			}
+			updateErr = conflict
+			break
+		}
+
+		updated = append(updated, row)
+		changes = append(changes, change)
+	}
+	// an UPDATE OR FAIL keeps the rows, that were updated before a conflict,
+	// all other conflicts undo the update, since UpdateOrUnknown defaults to
+	// UpdateOrAbort, and the conflict couldn't be resolved by UpdateOrReplace
+	if updateErr != nil && c.UpdateOr != command.UpdateOrFail {
+		return nil, updateErr
+	}
+
+	// the resolver also holds the rows, that were deleted by an UPDATE OR
+	// REPLACE
+	written := changes
+	if resolver != nil {
+		written = resolver.changes
+	}
+	if err := e.enforceForeignKeys(ctx, c.Table.QualifiedName(), written); err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if _, err := e.fireTriggers(ctx, triggers, dbfs.After, cols, change, updatedCols); err != nil {
+			return nil, err
+		}
+	}
+	if updateErr != nil {
+		return nil, keptChanges{updateErr}
+	}
+
+	if len(c.Returning) != 0 {
+		return e.evaluateReturning(ctx, cols, updated, c.Returning)
+	}
+	return affectedRows(len(updated)), nil
+}
+
+// applyUpdateSetters evaluates the given update setters in the given context,
+// and returns a copy of the given row, where the updated columns hold the new
+// values. All expressions are evaluated against the original row.
+func (e Engine) applyUpdateSetters(ctx ExecutionContext, cols []table.Col, row table.Row, setters []command.UpdateSetter) (table.Row, error) {
+	values := make([]types.Value, len(row.Values))
+	copy(values, row.Values)
+
+	for _, setter := range setters {
+		val, err := e.evaluateExpression(ctx, setter.Value)
+		if err != nil {
+			return table.Row{}, fmt.Errorf("update setter: %w", err)
+		}
+		for _, colName := range setter.Cols {
+			index := -1
+			for i, col := range cols {
+				if col.QualifiedName == colName {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				return table.Row{}, ErrNoSuchColumn(colName)
+			}
+			values[index] = val
+		}
+	}
+	return table.Row{Values: values}, nil
+}
+
+// setterCols returns the names of all columns, that are assigned by the given
+// update setters.
+func setterCols(setters []command.UpdateSetter) []string {
+	var cols []string
+	for _, setter := range setters {
+		cols = append(cols, setter.Cols...)
+	}
+	return cols
+}
+
+// checkRowConflict returns an error if the given row can not be stored in a
+// table with the given columns.
+func checkRowConflict(cols []table.Col, row table.Row) error {
+	for i, col := range cols {
+		if val := row.Values[i]; !val.Is(col.Type) {
+			return fmt.Errorf("column %v: %w", col.QualifiedName, types.ErrTypeMismatch(col.Type, val.Type()))
+		}
+	}
+	return nil
+}
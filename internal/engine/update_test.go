@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/transaction"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestUpdateSuite(t *testing.T) {
+	suite.Run(t, new(UpdateSuite))
+}
+
+type UpdateSuite struct {
+	EngineSuite
+}
+
+func (suite *UpdateSuite) TestUpdateWithFilter() {
+	suite.setupMyTable()
+
+	result, err := suite.engine.evaluateUpdate(suite.ctx, command.Update{
+		Table: command.SimpleTable{Table: "myTable"},
+		Updates: []command.UpdateSetter{
+			{Cols: []string{"name"}, Value: command.ConstantLiteral{Value: "updated"}},
+		},
+		Filter: command.EqualityExpr{
+			BinaryBase: command.BinaryBase{
+				Left:  command.ColumnReference{Name: "id"},
+				Right: command.ConstantLiteral{Value: "2", Numeric: true},
+			},
+		},
+	})
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(1), result)
+
+	suite.ElementsMatch([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString("updated")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.scanMyTable())
+}
+
+func (suite *UpdateSuite) TestUpdateAll() {
+	suite.setupMyTable()
+
+	result, err := suite.engine.evaluateUpdate(suite.ctx, command.Update{
+		Table: command.SimpleTable{Table: "myTable"},
+		Updates: []command.UpdateSetter{
+			{
+				Cols: []string{"id"},
+				Value: command.AddExpression{
+					BinaryBase: command.BinaryBase{
+						Left:  command.ColumnReference{Name: "id"},
+						Right: command.ConstantLiteral{Value: "10", Numeric: true},
+					},
+				},
+			},
+		},
+		Filter: command.ConstantBooleanExpr{Value: true},
+	})
+	suite.NoError(err)
+	suite.EqualTables(affectedRows(3), result)
+
+	suite.ElementsMatch([]table.Row{
+		{Values: []types.Value{types.NewInteger(11), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(12), types.NewString("b")}},
+		{Values: []types.Value{types.NewInteger(13), types.NewString("c")}},
+	}, suite.scanMyTable())
+}
+
+func (suite *UpdateSuite) TestUpdateRecordThatDoesNotFitIntoPage() {
+	suite.setupMyTable()
+
+	long := make([]byte, 10000)
+	for i := range long {
+		long[i] = 'x'
+	}
+
+	// the first row is moved to a new page, because the page can't hold
+	// both long values
+	for _, id := range []string{"1", "2"} {
+		_, err := suite.engine.evaluateUpdate(suite.ctx, command.Update{
+			Table: command.SimpleTable{Table: "myTable"},
+			Updates: []command.UpdateSetter{
+				{Cols: []string{"name"}, Value: command.ConstantLiteral{Value: string(long)}},
+			},
+			Filter: command.EqualityExpr{
+				BinaryBase: command.BinaryBase{
+					Left:  command.ColumnReference{Name: "id"},
+					Right: command.ConstantLiteral{Value: id, Numeric: true},
+				},
+			},
+		})
+		suite.NoError(err)
+	}
+
+	suite.ElementsMatch([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString(string(long))}},
+		{Values: []types.Value{types.NewInteger(2), types.NewString(string(long))}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.scanMyTable())
+}
+
+func (suite *UpdateSuite) TestUpdateOr() {
+	// setting the name to the integer id conflicts with the string column,
+	// and only rows with an id of 2 or higher are updated
+	conflictingUpdate := func(or command.UpdateOr) (table.Table, error) {
+		return suite.engine.evaluateUpdate(suite.ctx, command.Update{
+			UpdateOr: or,
+			Table:    command.SimpleTable{Table: "myTable"},
+			Updates: []command.UpdateSetter{
+				{Cols: []string{"name"}, Value: command.ColumnReference{Name: "id"}},
+			},
+			Filter: command.GreaterThanOrEqualToExpr{
+				BinaryBase: command.BinaryBase{
+					Left:  command.ColumnReference{Name: "id"},
+					Right: command.ConstantLiteral{Value: "2", Numeric: true},
+				},
+			},
+		})
+	}
+
+	suite.Run("ignore", func() {
+		suite.SetupTest()
+		suite.setupMyTable()
+		result, err := conflictingUpdate(command.UpdateOrIgnore)
+		suite.NoError(err)
+		suite.EqualTables(affectedRows(0), result)
+	})
+	suite.Run("default", func() {
+		suite.SetupTest()
+		suite.setupMyTable()
+		_, err := conflictingUpdate(command.UpdateOrUnknown)
+		suite.EqualError(err, "column name: type mismatch: want String, got Integer")
+		suite.Equal(transaction.StatePending, suite.ctx.tx.State())
+	})
+	suite.Run("abort", func() {
+		suite.SetupTest()
+		suite.setupMyTable()
+		_, err := conflictingUpdate(command.UpdateOrAbort)
+		suite.EqualError(err, "column name: type mismatch: want String, got Integer")
+		suite.Equal(transaction.StatePending, suite.ctx.tx.State())
+	})
+	suite.Run("fail", func() {
+		suite.SetupTest()
+		suite.setupMyTable()
+		_, err := conflictingUpdate(command.UpdateOrFail)
+		suite.Error(err)
+		suite.Equal(transaction.StatePending, suite.ctx.tx.State())
+	})
+	suite.Run("fail keeps prior updates", func() {
+		suite.SetupTest()
+		suite.createMyTable(`id INTEGER UNIQUE, name STRING`, `(1, 'a'), (2, 'b'), (20, 'c')`)
+		// the second row conflicts with the third row
+		suite.ErrorIs(suite.exec(`UPDATE OR FAIL myTable SET id = id * 10`), ErrUniqueViolation)
+		suite.ElementsMatch([]table.Row{
+			{Values: []types.Value{types.NewInteger(10), types.NewString("a")}},
+			{Values: []types.Value{types.NewInteger(2), types.NewString("b")}},
+			{Values: []types.Value{types.NewInteger(20), types.NewString("c")}},
+		}, suite.scanMyTable())
+	})
+	suite.Run("rollback", func() {
+		suite.SetupTest()
+		suite.setupMyTable()
+		_, err := conflictingUpdate(command.UpdateOrRollback)
+		suite.Error(err)
+		suite.Equal(transaction.StateRolledBack, suite.ctx.tx.State())
+	})
+}
+
+func (suite *UpdateSuite) TestUpdateUnknownColumn() {
+	suite.setupMyTable()
+
+	_, err := suite.engine.evaluateUpdate(suite.ctx, command.Update{
+		Table: command.SimpleTable{Table: "myTable"},
+		Updates: []command.UpdateSetter{
+			{Cols: []string{"unknown"}, Value: command.ConstantLiteral{Value: "a"}},
+		},
+		Filter: command.ConstantBooleanExpr{Value: true},
+	})
+	suite.EqualError(err, "no column with name or alias 'unknown'")
+}
+
+// setupMyTable creates the table myTable with the columns id and name,
+// and inserts three rows into it.
+func (suite *UpdateSuite) setupMyTable() {
+	suite.createMyTable(`id INTEGER, name STRING`, `(1, 'a'), (2, 'b'), (3, 'c')`)
+}
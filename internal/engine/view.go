@@ -0,0 +1,366 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xqueries/xdb/internal/compiler"
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/dbfs"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// evaluateCreateView creates the view from the given command. The query of the
+// view is evaluated once, to determine the columns of the view, and is stored
+// as SQL source, which is compiled again whenever the view is referenced.
+func (e Engine) evaluateCreateView(ctx ExecutionContext, cmd command.CreateView) (table.Table, error) {
+	defer e.profiler.Enter("create view").Exit()
+	tx := ctx.tx
+
+	if _, ok, err := tx.View(cmd.Name); err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	} else if ok {
+		if cmd.IfNotExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", cmd.Name, ErrAlreadyExists)
+	}
+	if ok, err := tx.HasTable(cmd.Name); err != nil {
+		return nil, fmt.Errorf("has table: %w", err)
+	} else if ok {
+		return nil, fmt.Errorf("table %v: %w", cmd.Name, ErrAlreadyExists)
+	}
+
+	result, err := e.evaluateList(ctx, cmd.Select)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+	cols, err := result.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	if _, err := viewColumnNames(cmd.Name, cmd.Columns, cols); err != nil {
+		return nil, err
+	}
+
+	// only explicit column names are stored, since the names of the columns
+	// of the query change, if a table, that the query selects from, is
+	// created again with other columns
+	if err := tx.CreateView(cmd.Name, dbfs.View{
+		Query:   cmd.Query,
+		Columns: cmd.Columns,
+	}); err != nil {
+		return nil, fmt.Errorf("create view: %w", err)
+	}
+	return table.Empty, nil
+}
+
+// evaluateDropView drops the view from the given command. Views, that
+// reference the dropped view, are not dropped, but can not be queried
+// until a view with that name is created again.
+func (e Engine) evaluateDropView(ctx ExecutionContext, cmd command.DropView) (table.Table, error) {
+	defer e.profiler.Enter("drop view").Exit()
+
+	name := cmd.Name
+	if cmd.Schema != "" {
+		name = cmd.Schema + "." + name
+	}
+
+	if _, ok, err := ctx.tx.View(name); err != nil {
+		return nil, fmt.Errorf("view: %w", err)
+	} else if !ok {
+		if cmd.IfExists {
+			return table.Empty, nil
+		}
+		return nil, fmt.Errorf("%v: %w", name, ErrNoSuchView)
+	}
+
+	if err := ctx.tx.DropView(name); err != nil {
+		return nil, fmt.Errorf("drop view: %w", err)
+	}
+	// the triggers of a view are dropped with the view
+	if err := e.updateTriggers(ctx, name, func(*dbfs.Trigger) bool { return false }); err != nil {
+		return nil, err
+	}
+	return table.Empty, nil
+}
+
+// renamedViews returns the definitions of all views, whose queries are
+// changed by the given function, by their names. The views are not changed,
+// so that a rename can be rejected before anything was changed, see
+// storeViews.
+func renamedViews(ctx ExecutionContext, rename func(query string) (string, error)) (map[string]dbfs.View, error) {
+	names, err := ctx.tx.Views()
+	if err != nil {
+		return nil, fmt.Errorf("views: %w", err)
+	}
+	views := make(map[string]dbfs.View)
+	for _, name := range names {
+		view, _, err := ctx.tx.View(name)
+		if err != nil {
+			return nil, fmt.Errorf("view: %w", err)
+		}
+		query, err := rename(view.Query)
+		if err != nil {
+			return nil, fmt.Errorf("view %v: %w", name, err)
+		}
+		if query != view.Query {
+			views[name] = dbfs.View{
+				Query:   query,
+				Columns: view.Columns,
+			}
+		}
+	}
+	return views, nil
+}
+
+// storeViews replaces the definitions of the given views by their names.
+func storeViews(ctx ExecutionContext, views map[string]dbfs.View) error {
+	for name, view := range views {
+		if err := ctx.tx.DropView(name); err != nil {
+			return fmt.Errorf("drop view: %w", err)
+		}
+		if err := ctx.tx.CreateView(name, view); err != nil {
+			return fmt.Errorf("create view: %w", err)
+		}
+	}
+	return nil
+}
+
+// viewQuery returns the compiled query of the view with the given name,
+// together with the definition of the view, or false, if there is no such
+// view.
+func viewQuery(ctx ExecutionContext, name string) (command.List, dbfs.View, bool, error) {
+	view, ok, err := ctx.tx.View(name)
+	if err != nil {
+		return nil, dbfs.View{}, false, fmt.Errorf("view: %w", err)
+	} else if !ok {
+		return nil, dbfs.View{}, false, nil
+	}
+
+	query, err := compiler.CompileQuery(view.Query)
+	if err != nil {
+		return nil, dbfs.View{}, false, fmt.Errorf("compile view %v: %w", name, err)
+	}
+	return query, view, true, nil
+}
+
+// scanView returns the rows of the given view, whose query is evaluated in
+// the given context. The columns of the result are named like the columns
+// of the view, see viewColumnNames.
+func (e Engine) scanView(ctx ExecutionContext, name string, query command.List, view dbfs.View) (table.Table, error) {
+	defer e.profiler.Enter("scan view").Exit()
+
+	result, err := e.evaluateList(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("view %v: %w", name, err)
+	}
+	cols, err := result.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	names, err := viewColumnNames(name, view.Columns, cols)
+	if err != nil {
+		return nil, err
+	}
+	return table.NewRenamedCol(result, names), nil
+}
+
+// viewColumnNames returns the names of the columns of the view with the given
+// name and the given explicit column names, whose query has the given
+// columns. If there are no explicit column names, the columns are named like
+// the columns of the query. An error is returned, if the amount of explicit
+// column names doesn't match the amount of columns, or if two columns have
+// the same name.
+func viewColumnNames(view string, explicit []string, cols []table.Col) ([]string, error) {
+	names := explicit
+	if len(names) == 0 {
+		for _, col := range cols {
+			name := col.Alias
+			if name == "" {
+				_, name = table.SplitQualifiedName(col.QualifiedName)
+			}
+			names = append(names, name)
+		}
+	} else if len(names) != len(cols) {
+		return nil, fmt.Errorf("view %v has %d column names, but its query has %d columns", view, len(names), len(cols))
+	}
+	seen := make(map[string]struct{})
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("view %v has more than one column with name %v", view, name)
+		}
+		seen[name] = struct{}{}
+	}
+	return names, nil
+}
+
+// insertIntoView fires the INSTEAD OF INSERT triggers of the given view for
+// every row, that the given insert would insert into the view. Columns, that
+// are not assigned a value, are NULL in the NEW row of the triggers.
+func (e Engine) insertIntoView(ctx ExecutionContext, c command.Insert, query command.List, view dbfs.View) (table.Table, error) {
+	name := c.Table.QualifiedName()
+	if c.Upsert != nil || len(c.Returning) != 0 {
+		return nil, fmt.Errorf("upsert or returning on view %v: %w", name, ErrUnsupported)
+	}
+	triggers, err := insteadOfTriggers(ctx, name, dbfs.Insert)
+	if err != nil {
+		return nil, err
+	}
+	viewTable, err := e.scanView(ctx, name, query, view)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := viewTable.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+
+	defaults := make([]types.Value, len(cols))
+	for i, col := range cols {
+		defaults[i] = types.NewNull(col.Type)
+	}
+	inserter := triggeringInserter{
+		e:        e,
+		ctx:      ctx,
+		triggers: triggers,
+		timing:   dbfs.InsteadOf,
+		cols:     cols,
+	}
+	if c.DefaultValues {
+		if err := inserter.Insert(table.Row{Values: defaults}); err != nil {
+			return nil, err
+		}
+		return table.Empty, nil
+	}
+	if err := e.insertInput(ctx, c, cols, defaults, inserter); err != nil {
+		return nil, err
+	}
+	return table.Empty, nil
+}
+
+// updateView fires the INSTEAD OF UPDATE triggers of the given view for every
+// row of the view, that matches the filter of the given update. The NEW row of
+// the triggers is the row with the updates applied. The amount of rows, that
+// were not skipped by RAISE(IGNORE), is returned.
+func (e Engine) updateView(ctx ExecutionContext, c command.Update, query command.List, view dbfs.View) (table.Table, error) {
+	name := c.Table.QualifiedName()
+	if len(c.Returning) != 0 {
+		return nil, fmt.Errorf("returning on view %v: %w", name, ErrUnsupported)
+	}
+	triggers, err := insteadOfTriggers(ctx, name, dbfs.Update)
+	if err != nil {
+		return nil, err
+	}
+	cols, rows, err := e.viewRows(ctx, name, query, view)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCols := setterCols(c.Updates)
+	updated := 0
+	for _, row := range rows {
+		rowCtx := ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: cols,
+			Row:  row,
+		})
+		if ok, err := e.evaluateFilter(rowCtx, c.Filter); err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		} else if !ok {
+			continue
+		}
+		newRow, err := e.applyUpdateSetters(rowCtx, cols, row, c.Updates)
+		if err != nil {
+			return nil, err
+		}
+		if ignored, err := e.fireTriggers(ctx, triggers, dbfs.InsteadOf, cols, rowChange{old: row, new: newRow}, updatedCols); err != nil {
+			return nil, err
+		} else if !ignored {
+			updated++
+		}
+	}
+	return affectedRows(updated), nil
+}
+
+// deleteFromView fires the INSTEAD OF DELETE triggers of the given view for
+// every row of the view, that matches the filter of the given delete. The
+// amount of rows, that were not skipped by RAISE(IGNORE), is returned.
+func (e Engine) deleteFromView(ctx ExecutionContext, c command.Delete, query command.List, view dbfs.View) (table.Table, error) {
+	name := c.Table.QualifiedName()
+	if len(c.Returning) != 0 {
+		return nil, fmt.Errorf("returning on view %v: %w", name, ErrUnsupported)
+	}
+	triggers, err := insteadOfTriggers(ctx, name, dbfs.Delete)
+	if err != nil {
+		return nil, err
+	}
+	cols, rows, err := e.viewRows(ctx, name, query, view)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := 0
+	for _, row := range rows {
+		rowCtx := ctx.IntermediateRow(table.RowWithColInfo{
+			Cols: cols,
+			Row:  row,
+		})
+		if ok, err := e.evaluateFilter(rowCtx, c.Filter); err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		} else if !ok {
+			continue
+		}
+		if ignored, err := e.fireTriggers(ctx, triggers, dbfs.InsteadOf, cols, rowChange{old: row}, nil); err != nil {
+			return nil, err
+		} else if !ignored {
+			deleted++
+		}
+	}
+	return affectedRows(deleted), nil
+}
+
+// insteadOfTriggers returns the INSTEAD OF triggers of the given view, that
+// are fired by the given event. A view without such triggers can not be
+// changed by the event.
+func insteadOfTriggers(ctx ExecutionContext, name string, event dbfs.TriggerEvent) ([]namedTrigger, error) {
+	triggers, err := triggersOn(ctx, name, event)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTiming(triggers, dbfs.InsteadOf) {
+		return nil, fmt.Errorf("view %v can not be changed without an INSTEAD OF %v trigger", name, strings.ToUpper(string(event)))
+	}
+	return triggers, nil
+}
+
+// viewRows returns the columns and all rows of the given view. The rows are
+// read completely, so that the triggers, that fire for the rows, can change
+// the tables of the view.
+func (e Engine) viewRows(ctx ExecutionContext, name string, query command.List, view dbfs.View) ([]table.Col, []table.Row, error) {
+	viewTable, err := e.scanView(ctx, name, query, view)
+	if err != nil {
+		return nil, nil, err
+	}
+	cols, err := viewTable.Cols()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cols: %w", err)
+	}
+
+	iterator, err := viewTable.Rows()
+	if err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+	defer func() { _ = iterator.Close() }()
+	var result []table.Row
+	for {
+		next, err := iterator.Next()
+		if err == table.ErrEOT {
+			return cols, result, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, next)
+	}
+}
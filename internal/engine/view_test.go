@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestViewSuite(t *testing.T) {
+	suite.Run(t, new(ViewSuite))
+}
+
+type ViewSuite struct {
+	EngineSuite
+}
+
+func (suite *ViewSuite) SetupTest() {
+	suite.EngineSuite.SetupTest()
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 1, 'a'), (2, 2, 'b'), (3, 1, 'c')`))
+}
+
+func (suite *ViewSuite) TestCreateView() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT id, name AS label FROM items WHERE grp = 1`))
+	suite.ErrorIs(suite.exec(`CREATE VIEW grouped AS SELECT * FROM items`), ErrAlreadyExists)
+	suite.NoError(suite.exec(`CREATE VIEW IF NOT EXISTS grouped AS SELECT * FROM items`))
+
+	tbl, err := suite.evaluateStatement(`SELECT * FROM grouped`)
+	suite.Require().NoError(err)
+	cols, err := tbl.Cols()
+	suite.NoError(err)
+	suite.Equal([]table.Col{
+		{QualifiedName: "id", Type: types.Integer},
+		{QualifiedName: "label", Type: types.String},
+	}, cols)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.rows(tbl))
+
+	// the view reflects changes to the underlying table
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (4, 1, 'd')`))
+	suite.Equal([]table.Row{
+		intRow(3),
+		intRow(4),
+	}, suite.selectRows(`SELECT id FROM grouped WHERE label > 'b'`))
+}
+
+func (suite *ViewSuite) TestColumnNames() {
+	suite.NoError(suite.exec(`CREATE VIEW named (itemID, itemName) AS SELECT id, name FROM items`))
+	suite.Equal([]table.Row{
+		intRow(2),
+	}, suite.selectRows(`SELECT itemID FROM named WHERE itemName = 'b'`))
+
+	suite.Error(suite.exec(`CREATE VIEW wrong (a) AS SELECT id, name FROM items`))
+	suite.Error(suite.exec(`CREATE VIEW duplicate AS SELECT id, id FROM items`))
+	suite.Error(suite.exec(`CREATE VIEW missing AS SELECT * FROM other`))
+}
+
+func (suite *ViewSuite) TestRecreatedTable() {
+	suite.NoError(suite.exec(`CREATE VIEW everything AS SELECT * FROM items`))
+	suite.NoError(suite.exec(`CREATE VIEW named (itemID, itemName) AS SELECT id, name FROM items`))
+	suite.NoError(suite.exec(`DROP TABLE items`))
+	suite.NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, label STRING)`))
+	suite.NoError(suite.exec(`INSERT INTO items VALUES (1, 'a')`))
+
+	// the columns of the view are named like the columns of the new table
+	tbl, err := suite.evaluateStatement(`SELECT * FROM everything`)
+	suite.Require().NoError(err)
+	cols, err := tbl.Cols()
+	suite.NoError(err)
+	suite.Equal([]table.Col{
+		{QualifiedName: "id", Type: types.Integer},
+		{QualifiedName: "label", Type: types.String},
+	}, cols)
+
+	// the query of the view selects a column, that doesn't exist anymore
+	_, err = suite.evaluateStatement(`SELECT * FROM named`)
+	suite.Error(err)
+}
+
+func (suite *ViewSuite) TestNestedAndJoined() {
+	suite.NoError(suite.exec(`CREATE TABLE groups_ (id INTEGER PRIMARY KEY, title STRING)`))
+	suite.NoError(suite.exec(`INSERT INTO groups_ VALUES (1, 'one'), (2, 'two')`))
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT id, grp, name FROM items WHERE grp = 1`))
+	suite.NoError(suite.exec(`CREATE VIEW groupNames AS SELECT name FROM grouped`))
+
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a")}},
+		{Values: []types.Value{types.NewString("c")}},
+	}, suite.selectRows(`SELECT * FROM groupNames`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewString("a"), types.NewString("one")}},
+		{Values: []types.Value{types.NewString("c"), types.NewString("one")}},
+	}, suite.selectRows(`SELECT v.name, g.title FROM grouped v JOIN groups_ g ON v.grp = g.id`))
+}
+
+func (suite *ViewSuite) TestDropView() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT * FROM items WHERE grp = 1`))
+	suite.NoError(suite.exec(`DROP VIEW grouped`))
+	suite.ErrorIs(suite.exec(`DROP VIEW grouped`), ErrNoSuchView)
+	suite.NoError(suite.exec(`DROP VIEW IF EXISTS grouped`))
+	_, err := suite.evaluateStatement(`SELECT * FROM grouped`)
+	suite.Error(err)
+
+	// views and tables share their names
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT * FROM items WHERE grp = 1`))
+	suite.ErrorIs(suite.exec(`CREATE TABLE grouped (id INTEGER)`), ErrAlreadyExists)
+	suite.ErrorIs(suite.exec(`ALTER TABLE items RENAME TO grouped`), ErrAlreadyExists)
+	suite.ErrorIs(suite.exec(`CREATE VIEW items AS SELECT * FROM grouped`), ErrAlreadyExists)
+}
+
+func (suite *ViewSuite) TestPersistence() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped (itemID, itemName) AS SELECT id, name FROM items WHERE grp = 1`))
+	suite.NoError(suite.engine.txmgr.Commit(suite.ctx.tx))
+
+	tx, err := suite.engine.txmgr.Start()
+	suite.Require().NoError(err)
+	suite.ctx = newEmptyExecutionContext(tx)
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.selectRows(`SELECT * FROM grouped`))
+
+	// a dropped view is only dropped, if the transaction is committed
+	suite.NoError(suite.exec(`DROP VIEW grouped`))
+	suite.NoError(suite.engine.txmgr.Rollback(suite.ctx.tx))
+	tx, err = suite.engine.txmgr.Start()
+	suite.Require().NoError(err)
+	suite.ctx = newEmptyExecutionContext(tx)
+	suite.Len(suite.selectRows(`SELECT * FROM grouped`), 2)
+}
+
+func (suite *ViewSuite) TestExplain() {
+	suite.NoError(suite.exec(`CREATE VIEW grouped AS SELECT * FROM items WHERE grp = 1`))
+	tbl, err := suite.evaluateStatement(`EXPLAIN SELECT * FROM grouped`)
+	suite.Require().NoError(err)
+	rows := suite.rows(tbl)
+	suite.Require().Len(rows, 2)
+	// the view is estimated like its query, which selects half of the rows
+	suite.Equal(types.NewInteger(2), rows[1].Values[3])
+}
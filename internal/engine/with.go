@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/xqueries/xdb/internal/compiler/command"
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+// commonTable is a common table of a WITH clause, that can be referenced by
+// its name in an execution context.
+type commonTable struct {
+	command.CommonTable
+	// ctx is the context, in which the input of the table is evaluated. It
+	// holds the common tables, that are defined before this table.
+	ctx ExecutionContext
+	// rows are the rows of the table, if the table is materialized and was
+	// already scanned, or if the table is the working table of a recursion.
+	rows table.Table
+}
+
+// evaluateWith evaluates the input of the given command in a context, in which
+// the common tables of the command can be referenced by their names. The
+// input of a common table is not evaluated, until the table is scanned.
+func (e Engine) evaluateWith(ctx ExecutionContext, with command.With) (table.Table, error) {
+	defer e.profiler.Enter("with").Exit()
+
+	for _, tbl := range with.Tables {
+		ctx = ctx.withCommonTable(&commonTable{
+			CommonTable: tbl,
+			ctx:         ctx,
+		})
+	}
+	return e.evaluate(ctx, with.Input)
+}
+
+// scanCommonTable returns the rows of the given common table. If the table is
+// materialized, its input is only evaluated for the first scan, and the rows
+// are kept for all further scans, as they are read, see materializedTable.
+// Otherwise, the input is evaluated again for every scan.
+func (e Engine) scanCommonTable(tbl *commonTable) (table.Table, error) {
+	defer e.profiler.Enter("scan common table").Exit()
+
+	if tbl.rows != nil {
+		return tbl.rows, nil
+	}
+
+	var result table.Table
+	if recursion, ok := tbl.Input.(command.Recursion); ok {
+		recursive, err := e.evaluateRecursion(tbl, recursion)
+		if err != nil {
+			return nil, fmt.Errorf("common table %v: %w", tbl.Name, err)
+		}
+		result = recursive
+	} else {
+		query, err := e.evaluateList(tbl.ctx, tbl.Input)
+		if err != nil {
+			return nil, fmt.Errorf("common table %v: %w", tbl.Name, err)
+		}
+		cols, err := query.Cols()
+		if err != nil {
+			return nil, fmt.Errorf("cols: %w", err)
+		}
+		names, err := commonTableColNames(tbl, cols)
+		if err != nil {
+			return nil, err
+		}
+		result = table.NewRenamedCol(query, names)
+	}
+	if !tbl.Materialized {
+		return result, nil
+	}
+
+	materialized, err := newMaterializedTable(result)
+	if err != nil {
+		return nil, fmt.Errorf("common table %v: %w", tbl.Name, err)
+	}
+	tbl.rows = materialized
+	return tbl.rows, nil
+}
+
+// evaluateRecursion returns the rows of the given recursive common table,
+// whose input is the given recursion. The iterations of the recursion are
+// evaluated while the returned table is read, see recursiveTable, so a
+// recursion, that never ends, can be read with a LIMIT.
+func (e Engine) evaluateRecursion(tbl *commonTable, recursion command.Recursion) (table.Table, error) {
+	defer e.profiler.Enter("recursion").Exit()
+
+	initial, err := e.evaluateList(tbl.ctx, recursion.Initial)
+	if err != nil {
+		return nil, fmt.Errorf("initial: %w", err)
+	}
+	initialCols, err := initial.Cols()
+	if err != nil {
+		return nil, fmt.Errorf("cols: %w", err)
+	}
+	names, err := commonTableColNames(tbl, initialCols)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]table.Col, len(initialCols))
+	for i, col := range initialCols {
+		cols[i] = table.Col{
+			QualifiedName: names[i],
+			Type:          col.Type,
+		}
+	}
+
+	return recursiveTable{
+		e:         e,
+		tbl:       tbl,
+		recursion: recursion,
+		initial:   initial,
+		cols:      cols,
+	}, nil
+}
+
+// containsValues returns whether any of the given candidates is equal to the
+// given values, see (Engine).valuesEqual.
+func (e Engine) containsValues(candidates [][]types.Value, vals []types.Value) bool {
+	for _, candidate := range candidates {
+		if e.valuesEqual(candidate, vals) {
+			return true
+		}
+	}
+	return false
+}
+
+// commonTableColNames returns the names of the columns of the given common
+// table, whose input has the given columns. If the common table doesn't name
+// its columns, they are named after the columns of the input.
+func commonTableColNames(tbl *commonTable, cols []table.Col) ([]string, error) {
+	if len(tbl.Cols) == 0 {
+		return resultColNames(cols), nil
+	}
+	if len(tbl.Cols) != len(cols) {
+		return nil, fmt.Errorf("common table %v has %d columns, but its query has %d columns", tbl.Name, len(tbl.Cols), len(cols))
+	}
+	return tbl.Cols, nil
+}
+
+// resultColNames returns the names, under which the given columns of the
+// result of a query can be referenced, which are the aliases of the columns,
+// or their names without qualifier.
+func resultColNames(cols []table.Col) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Alias
+		if names[i] == "" {
+			_, names[i] = table.SplitQualifiedName(col.QualifiedName)
+		}
+	}
+	return names
+}
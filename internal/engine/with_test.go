@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/xqueries/xdb/internal/engine/table"
+	"github.com/xqueries/xdb/internal/engine/types"
+)
+
+func TestWithSuite(t *testing.T) {
+	suite.Run(t, new(WithSuite))
+}
+
+type WithSuite struct {
+	EngineSuite
+}
+
+func (suite *WithSuite) SetupTest() {
+	suite.EngineSuite.SetupTest()
+	suite.Require().NoError(suite.exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, grp INTEGER, name STRING)`))
+	suite.Require().NoError(suite.exec(`INSERT INTO items VALUES (1, 1, 'a'), (2, 2, 'b'), (3, 1, 'c')`))
+}
+
+func (suite *WithSuite) TestWith() {
+	suite.Equal([]table.Row{
+		itemRow(1, 1, "a"),
+		itemRow(3, 1, "c"),
+	}, suite.selectRows(`WITH grouped AS (SELECT * FROM items WHERE grp = 1) SELECT * FROM grouped`))
+
+	// named columns, and tables that reference preceding tables
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.selectRows(`WITH labels (itemID, label) AS (SELECT id, name FROM items), later AS (SELECT * FROM labels WHERE itemID > 2) SELECT later.itemID, later.label FROM later`))
+
+	// a common table hides a table with the same name
+	suite.Equal([]table.Row{
+		intRow(2),
+	}, suite.selectRows(`WITH items AS (SELECT id FROM items WHERE grp = 2) SELECT * FROM items`))
+
+	suite.Error(suite.exec(`WITH labels (itemID) AS (SELECT id, name FROM items) SELECT * FROM labels`))
+}
+
+func (suite *WithSuite) TestMaterialized() {
+	// the common table is referenced twice, and therefore materialized
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewInteger(1)}},
+		{Values: []types.Value{types.NewInteger(1), types.NewInteger(3)}},
+		{Values: []types.Value{types.NewInteger(3), types.NewInteger(1)}},
+		{Values: []types.Value{types.NewInteger(3), types.NewInteger(3)}},
+	}, suite.selectRows(`WITH grouped AS (SELECT id FROM items WHERE grp = 1) SELECT a.id, b.id FROM grouped a JOIN grouped b`))
+}
+
+func (suite *WithSuite) TestRecursive() {
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+		intRow(4),
+		intRow(5),
+	}, suite.selectRows(`WITH RECURSIVE cnt (x) AS (VALUES (1) UNION ALL SELECT x + 1 FROM cnt WHERE x < 5) SELECT x FROM cnt`))
+
+	// a hierarchy with a cycle, which ends, since visited rows are discarded
+	suite.NoError(suite.exec(`CREATE TABLE edges (parent INTEGER, child INTEGER)`))
+	suite.NoError(suite.exec(`INSERT INTO edges VALUES (1, 2), (2, 3), (2, 4), (4, 1), (5, 6)`))
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+		intRow(4),
+	}, suite.selectRows(`WITH RECURSIVE reachable (node) AS (VALUES (1) UNION SELECT edges.child FROM edges JOIN reachable ON edges.parent = reachable.node) SELECT node FROM reachable ORDER BY node`))
+}
+
+func (suite *WithSuite) TestRecursiveLimit() {
+	// the recursion never ends, but only the iterations are evaluated, whose
+	// rows are read
+	suite.Equal([]table.Row{
+		intRow(1),
+		intRow(2),
+		intRow(3),
+	}, suite.selectRows(`WITH RECURSIVE c (x) AS (VALUES (1) UNION ALL SELECT x + 1 FROM c) SELECT x FROM c LIMIT 3`))
+
+	suite.Equal([]table.Row{
+		intRow(4),
+		intRow(5),
+	}, suite.selectRows(`WITH RECURSIVE c (x) AS (VALUES (1) UNION SELECT x + 1 FROM c) SELECT x FROM c LIMIT 2 OFFSET 3`))
+}
+
+func (suite *WithSuite) TestModify() {
+	suite.NoError(suite.exec(`CREATE TABLE archive (id INTEGER, name STRING)`))
+	suite.NoError(suite.exec(`WITH grouped AS (SELECT id, name FROM items WHERE grp = 1) INSERT INTO archive SELECT * FROM grouped`))
+	suite.NoError(suite.exec(`WITH archived AS (SELECT id FROM archive) DELETE FROM items WHERE id IN (SELECT id FROM archived)`))
+	suite.NoError(suite.exec(`WITH RECURSIVE cnt (x) AS (VALUES (10) UNION ALL SELECT x + 1 FROM cnt WHERE x < 11) UPDATE items SET grp = (SELECT max(x) FROM cnt)`))
+
+	suite.Equal([]table.Row{
+		itemRow(2, 11, "b"),
+	}, suite.selectRows(`SELECT * FROM items`))
+	suite.Equal([]table.Row{
+		{Values: []types.Value{types.NewInteger(1), types.NewString("a")}},
+		{Values: []types.Value{types.NewInteger(3), types.NewString("c")}},
+	}, suite.selectRows(`SELECT * FROM archive`))
+}
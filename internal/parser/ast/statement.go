@@ -54,6 +54,7 @@ type (
 		NewColumnName token.Token
 		Add           token.Token
 		ColumnDef     *ColumnDef
+		Drop          token.Token
 	}
 
 	// AnalyzeStmt as in the SQLite grammar.
@@ -212,6 +213,7 @@ type (
 		QualifiedTableName *QualifiedTableName
 		Where              token.Token
 		Expr               *Expr
+		ReturningClause    *ReturningClause
 	}
 
 	// DeleteStmtLimited as in the SQLite grammar.
@@ -315,6 +317,7 @@ type (
 		Default                  token.Token
 		ParenthesizedExpressions []*ParenthesizedExpressions
 		UpsertClause             *UpsertClause
+		ReturningClause          *ReturningClause
 	}
 
 	// ParenthesizedExpressions as in the SQLite grammar.
@@ -324,6 +327,12 @@ type (
 		RightParen token.Token
 	}
 
+	// ReturningClause as in the SQLite grammar.
+	ReturningClause struct {
+		Returning    token.Token
+		ResultColumn []*ResultColumn
+	}
+
 	// ReIndexStmt as in the SQLite grammar.
 	ReIndexStmt struct {
 		ReIndex          token.Token
@@ -407,6 +416,7 @@ type (
 		UpdateSetter       []*UpdateSetter
 		Where              token.Token
 		Expr               *Expr
+		ReturningClause    *ReturningClause
 	}
 
 	// UpdateSetter as in the SQLite grammar.
@@ -60,6 +60,70 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"alter rename table with schema",
+			"ALTER TABLE main.users RENAME TO admins",
+			&ast.SQLStmt{
+				AlterTableStmt: &ast.AlterTableStmt{
+					Alter:        token.New(1, 1, 0, 5, token.KeywordAlter, "ALTER"),
+					Table:        token.New(1, 7, 6, 5, token.KeywordTable, "TABLE"),
+					SchemaName:   token.New(1, 13, 12, 4, token.Literal, "main"),
+					Period:       token.New(1, 17, 16, 1, token.Literal, "."),
+					TableName:    token.New(1, 18, 17, 5, token.Literal, "users"),
+					Rename:       token.New(1, 24, 23, 6, token.KeywordRename, "RENAME"),
+					To:           token.New(1, 31, 30, 2, token.KeywordTo, "TO"),
+					NewTableName: token.New(1, 34, 33, 6, token.Literal, "admins"),
+				},
+			},
+		},
+		{
+			"alter drop column",
+			"ALTER TABLE users DROP COLUMN name",
+			&ast.SQLStmt{
+				AlterTableStmt: &ast.AlterTableStmt{
+					Alter:      token.New(1, 1, 0, 5, token.KeywordAlter, "ALTER"),
+					Table:      token.New(1, 7, 6, 5, token.KeywordTable, "TABLE"),
+					TableName:  token.New(1, 13, 12, 5, token.Literal, "users"),
+					Drop:       token.New(1, 19, 18, 4, token.KeywordDrop, "DROP"),
+					Column:     token.New(1, 24, 23, 6, token.KeywordColumn, "COLUMN"),
+					ColumnName: token.New(1, 31, 30, 4, token.Literal, "name"),
+				},
+			},
+		},
+		{
+			"alter drop column implicit",
+			"ALTER TABLE users DROP name",
+			&ast.SQLStmt{
+				AlterTableStmt: &ast.AlterTableStmt{
+					Alter:      token.New(1, 1, 0, 5, token.KeywordAlter, "ALTER"),
+					Table:      token.New(1, 7, 6, 5, token.KeywordTable, "TABLE"),
+					TableName:  token.New(1, 13, 12, 5, token.Literal, "users"),
+					Drop:       token.New(1, 19, 18, 4, token.KeywordDrop, "DROP"),
+					ColumnName: token.New(1, 24, 23, 4, token.Literal, "name"),
+				},
+			},
+		},
+		{
+			"alter add column without constraints",
+			"ALTER TABLE users ADD COLUMN foo INTEGER",
+			&ast.SQLStmt{
+				AlterTableStmt: &ast.AlterTableStmt{
+					Alter:     token.New(1, 1, 0, 5, token.KeywordAlter, "ALTER"),
+					Table:     token.New(1, 7, 6, 5, token.KeywordTable, "TABLE"),
+					TableName: token.New(1, 13, 12, 5, token.Literal, "users"),
+					Add:       token.New(1, 19, 18, 3, token.KeywordAdd, "ADD"),
+					Column:    token.New(1, 23, 22, 6, token.KeywordColumn, "COLUMN"),
+					ColumnDef: &ast.ColumnDef{
+						ColumnName: token.New(1, 30, 29, 3, token.Literal, "foo"),
+						TypeName: &ast.TypeName{
+							Name: []token.Token{
+								token.New(1, 34, 33, 7, token.Literal, "INTEGER"),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			"alter add column with two constraints",
 			"ALTER TABLE users ADD COLUMN foo VARCHAR(15) CONSTRAINT pk PRIMARY KEY AUTOINCREMENT CONSTRAINT nn NOT NULL",
@@ -962,6 +1026,38 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"DELETE with WHERE and returning clause",
+			"DELETE FROM myTable WHERE myLiteral RETURNING myTable.*, myLiteral",
+			&ast.SQLStmt{
+				DeleteStmt: &ast.DeleteStmt{
+					Delete: token.New(1, 1, 0, 6, token.KeywordDelete, "DELETE"),
+					From:   token.New(1, 8, 7, 4, token.KeywordFrom, "FROM"),
+					QualifiedTableName: &ast.QualifiedTableName{
+						TableName: token.New(1, 13, 12, 7, token.Literal, "myTable"),
+					},
+					Where: token.New(1, 21, 20, 5, token.KeywordWhere, "WHERE"),
+					Expr: &ast.Expr{
+						LiteralValue: token.New(1, 27, 26, 9, token.Literal, "myLiteral"),
+					},
+					ReturningClause: &ast.ReturningClause{
+						Returning: token.New(1, 37, 36, 9, token.KeywordReturning, "RETURNING"),
+						ResultColumn: []*ast.ResultColumn{
+							{
+								TableName: token.New(1, 47, 46, 7, token.Literal, "myTable"),
+								Period:    token.New(1, 54, 53, 1, token.Literal, "."),
+								Asterisk:  token.New(1, 55, 54, 1, token.BinaryOperator, "*"),
+							},
+							{
+								Expr: &ast.Expr{
+									LiteralValue: token.New(1, 58, 57, 9, token.Literal, "myLiteral"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			"DELETE with schema name and table name",
 			"DELETE FROM mySchema.myTable",
@@ -4708,6 +4804,26 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`CREATE TABLE with single basic column-def and WITHOUT ROWID`,
+			"CREATE TABLE myTable (myColumn) WITHOUT ROWID",
+			&ast.SQLStmt{
+				CreateTableStmt: &ast.CreateTableStmt{
+					Create:    token.New(1, 1, 0, 6, token.KeywordCreate, "CREATE"),
+					Table:     token.New(1, 8, 7, 5, token.KeywordTable, "TABLE"),
+					TableName: token.New(1, 14, 13, 7, token.Literal, "myTable"),
+					LeftParen: token.New(1, 22, 21, 1, token.Delimiter, "("),
+					ColumnDef: []*ast.ColumnDef{
+						{
+							ColumnName: token.New(1, 23, 22, 8, token.Literal, "myColumn"),
+						},
+					},
+					RightParen: token.New(1, 31, 30, 1, token.Delimiter, ")"),
+					Without:    token.New(1, 33, 32, 7, token.KeywordWithout, "WITHOUT"),
+					Rowid:      token.New(1, 41, 40, 5, token.Literal, "ROWID"),
+				},
+			},
+		},
 		{
 			`CREATE TABLE with multiple basic column-def`,
 			"CREATE TABLE myTable (myColumn1,myColumn2)",
@@ -4787,6 +4903,45 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`CREATE TABLE with single basic column-def and multiple table-constraints`,
+			"CREATE TABLE myTable (myColumn1,CHECK (myExpr),PRIMARY KEY (myExpr))",
+			&ast.SQLStmt{
+				CreateTableStmt: &ast.CreateTableStmt{
+					Create:    token.New(1, 1, 0, 6, token.KeywordCreate, "CREATE"),
+					Table:     token.New(1, 8, 7, 5, token.KeywordTable, "TABLE"),
+					TableName: token.New(1, 14, 13, 7, token.Literal, "myTable"),
+					LeftParen: token.New(1, 22, 21, 1, token.Delimiter, "("),
+					ColumnDef: []*ast.ColumnDef{
+						{
+							ColumnName: token.New(1, 23, 22, 9, token.Literal, "myColumn1"),
+						},
+					},
+					TableConstraint: []*ast.TableConstraint{
+						{
+							Check:     token.New(1, 33, 32, 5, token.KeywordCheck, "CHECK"),
+							LeftParen: token.New(1, 39, 38, 1, token.Delimiter, "("),
+							Expr: &ast.Expr{
+								LiteralValue: token.New(1, 40, 39, 6, token.Literal, "myExpr"),
+							},
+							RightParen: token.New(1, 46, 45, 1, token.Delimiter, ")"),
+						},
+						{
+							Primary:   token.New(1, 48, 47, 7, token.KeywordPrimary, "PRIMARY"),
+							Key:       token.New(1, 56, 55, 3, token.KeywordKey, "KEY"),
+							LeftParen: token.New(1, 60, 59, 1, token.Delimiter, "("),
+							IndexedColumn: []*ast.IndexedColumn{
+								{
+									ColumnName: token.New(1, 61, 60, 6, token.Literal, "myExpr"),
+								},
+							},
+							RightParen: token.New(1, 67, 66, 1, token.Delimiter, ")"),
+						},
+					},
+					RightParen: token.New(1, 68, 67, 1, token.Delimiter, ")"),
+				},
+			},
+		},
 		{
 			`CREATE TABLE with single basic column-def and table-constraint and PRIMARY KEY with single indexed-column`,
 			"CREATE TABLE myTable (myColumn1,PRIMARY KEY (myExpr))",
@@ -6156,6 +6311,37 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`INSERT with returning clause`,
+			"INSERT INTO myTable VALUES (myExpr) RETURNING *",
+			&ast.SQLStmt{
+				InsertStmt: &ast.InsertStmt{
+					Insert:    token.New(1, 1, 0, 6, token.KeywordInsert, "INSERT"),
+					Into:      token.New(1, 8, 7, 4, token.KeywordInto, "INTO"),
+					TableName: token.New(1, 13, 12, 7, token.Literal, "myTable"),
+					Values:    token.New(1, 21, 20, 6, token.KeywordValues, "VALUES"),
+					ParenthesizedExpressions: []*ast.ParenthesizedExpressions{
+						{
+							LeftParen: token.New(1, 28, 27, 1, token.Delimiter, "("),
+							Exprs: []*ast.Expr{
+								{
+									LiteralValue: token.New(1, 29, 28, 6, token.Literal, "myExpr"),
+								},
+							},
+							RightParen: token.New(1, 35, 34, 1, token.Delimiter, ")"),
+						},
+					},
+					ReturningClause: &ast.ReturningClause{
+						Returning: token.New(1, 37, 36, 9, token.KeywordReturning, "RETURNING"),
+						ResultColumn: []*ast.ResultColumn{
+							{
+								Asterisk: token.New(1, 47, 46, 1, token.BinaryOperator, "*"),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			`INSERT with basic upsert clause`,
 			"INSERT INTO myTable VALUES (myExpr) ON CONFLICT DO NOTHING",
@@ -6185,6 +6371,55 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`INSERT with upsert clause and returning clause`,
+			"INSERT INTO myTable VALUES (myExpr) ON CONFLICT DO UPDATE SET myCol = myNewCol RETURNING myCol",
+			&ast.SQLStmt{
+				InsertStmt: &ast.InsertStmt{
+					Insert:    token.New(1, 1, 0, 6, token.KeywordInsert, "INSERT"),
+					Into:      token.New(1, 8, 7, 4, token.KeywordInto, "INTO"),
+					TableName: token.New(1, 13, 12, 7, token.Literal, "myTable"),
+					Values:    token.New(1, 21, 20, 6, token.KeywordValues, "VALUES"),
+					ParenthesizedExpressions: []*ast.ParenthesizedExpressions{
+						{
+							LeftParen: token.New(1, 28, 27, 1, token.Delimiter, "("),
+							Exprs: []*ast.Expr{
+								{
+									LiteralValue: token.New(1, 29, 28, 6, token.Literal, "myExpr"),
+								},
+							},
+							RightParen: token.New(1, 35, 34, 1, token.Delimiter, ")"),
+						},
+					},
+					UpsertClause: &ast.UpsertClause{
+						On:       token.New(1, 37, 36, 2, token.KeywordOn, "ON"),
+						Conflict: token.New(1, 40, 39, 8, token.KeywordConflict, "CONFLICT"),
+						Do:       token.New(1, 49, 48, 2, token.KeywordDo, "DO"),
+						Update:   token.New(1, 52, 51, 6, token.KeywordUpdate, "UPDATE"),
+						Set:      token.New(1, 59, 58, 3, token.KeywordSet, "SET"),
+						UpdateSetter: []*ast.UpdateSetter{
+							{
+								ColumnName: token.New(1, 63, 62, 5, token.Literal, "myCol"),
+								Assign:     token.New(1, 69, 68, 1, token.BinaryOperator, "="),
+								Expr: &ast.Expr{
+									LiteralValue: token.New(1, 71, 70, 8, token.Literal, "myNewCol"),
+								},
+							},
+						},
+					},
+					ReturningClause: &ast.ReturningClause{
+						Returning: token.New(1, 80, 79, 9, token.KeywordReturning, "RETURNING"),
+						ResultColumn: []*ast.ResultColumn{
+							{
+								Expr: &ast.Expr{
+									LiteralValue: token.New(1, 90, 89, 5, token.Literal, "myCol"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			`INSERT with upsert clause with single update setter with column-name`,
 			"INSERT INTO myTable VALUES (myExpr) ON CONFLICT DO UPDATE SET myCol = myNewCol",
@@ -6987,6 +7222,40 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			`UPDATE with returning clause`,
+			"UPDATE myTable SET myCol = myNewCol RETURNING myCol AS c",
+			&ast.SQLStmt{
+				UpdateStmt: &ast.UpdateStmt{
+					Update: token.New(1, 1, 0, 6, token.KeywordUpdate, "UPDATE"),
+					QualifiedTableName: &ast.QualifiedTableName{
+						TableName: token.New(1, 8, 7, 7, token.Literal, "myTable"),
+					},
+					Set: token.New(1, 16, 15, 3, token.KeywordSet, "SET"),
+					UpdateSetter: []*ast.UpdateSetter{
+						{
+							ColumnName: token.New(1, 20, 19, 5, token.Literal, "myCol"),
+							Assign:     token.New(1, 26, 25, 1, token.BinaryOperator, "="),
+							Expr: &ast.Expr{
+								LiteralValue: token.New(1, 28, 27, 8, token.Literal, "myNewCol"),
+							},
+						},
+					},
+					ReturningClause: &ast.ReturningClause{
+						Returning: token.New(1, 37, 36, 9, token.KeywordReturning, "RETURNING"),
+						ResultColumn: []*ast.ResultColumn{
+							{
+								Expr: &ast.Expr{
+									LiteralValue: token.New(1, 47, 46, 5, token.Literal, "myCol"),
+								},
+								As:          token.New(1, 53, 52, 2, token.KeywordAs, "AS"),
+								ColumnAlias: token.New(1, 56, 55, 1, token.Literal, "c"),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			`UPDATE with ROLLBACK`,
 			"UPDATE OR ROLLBACK myTable SET myCol = myNewCol",
@@ -9692,6 +9961,77 @@ func TestSingleStatementParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"SELECT stmt with join of aliased tables",
+			"SELECT * FROM items AS i JOIN prices p",
+			&ast.SQLStmt{
+				SelectStmt: &ast.SelectStmt{
+					SelectCore: []*ast.SelectCore{
+						{
+							Select: token.New(1, 1, 0, 6, token.KeywordSelect, "SELECT"),
+							ResultColumn: []*ast.ResultColumn{
+								{
+									Asterisk: token.New(1, 8, 7, 1, token.BinaryOperator, "*"),
+								},
+							},
+							From: token.New(1, 10, 9, 4, token.KeywordFrom, "FROM"),
+							JoinClause: &ast.JoinClause{
+								TableOrSubquery: &ast.TableOrSubquery{
+									TableName:  token.New(1, 15, 14, 5, token.Literal, "items"),
+									As:         token.New(1, 21, 20, 2, token.KeywordAs, "AS"),
+									TableAlias: token.New(1, 24, 23, 1, token.Literal, "i"),
+								},
+								JoinClausePart: []*ast.JoinClausePart{
+									{
+										JoinOperator: &ast.JoinOperator{
+											Join: token.New(1, 26, 25, 4, token.KeywordJoin, "JOIN"),
+										},
+										TableOrSubquery: &ast.TableOrSubquery{
+											TableName:  token.New(1, 31, 30, 6, token.Literal, "prices"),
+											TableAlias: token.New(1, 38, 37, 1, token.Literal, "p"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"SELECT stmt with ORDER BY at the end of the statement",
+			"SELECT * FROM users ORDER BY id DESC",
+			&ast.SQLStmt{
+				SelectStmt: &ast.SelectStmt{
+					SelectCore: []*ast.SelectCore{
+						{
+							Select: token.New(1, 1, 0, 6, token.KeywordSelect, "SELECT"),
+							ResultColumn: []*ast.ResultColumn{
+								{
+									Asterisk: token.New(1, 8, 7, 1, token.BinaryOperator, "*"),
+								},
+							},
+							From: token.New(1, 10, 9, 4, token.KeywordFrom, "FROM"),
+							TableOrSubquery: []*ast.TableOrSubquery{
+								{
+									TableName: token.New(1, 15, 14, 5, token.Literal, "users"),
+								},
+							},
+						},
+					},
+					Order: token.New(1, 21, 20, 5, token.KeywordOrder, "ORDER"),
+					By:    token.New(1, 27, 26, 2, token.KeywordBy, "BY"),
+					OrderingTerm: []*ast.OrderingTerm{
+						{
+							Expr: &ast.Expr{
+								LiteralValue: token.New(1, 30, 29, 2, token.Literal, "id"),
+							},
+							Desc: token.New(1, 33, 32, 4, token.KeywordDesc, "DESC"),
+						},
+					},
+				},
+			},
+		},
 		{
 			`Compulsory Expr condition 1`,
 			"SELECT 0 LIKE 2 ESCAPE 3 FROM y",
@@ -255,6 +255,16 @@ someTable
 				token.New(1, 84, 83, 0, token.EOF, ""),
 			},
 		},
+		{
+			"zero at end of input",
+			"1 0",
+			ruleset.Default,
+			[]token.Token{
+				token.New(1, 1, 0, 1, token.LiteralNumeric, "1"),
+				token.New(1, 3, 2, 1, token.LiteralNumeric, "0"),
+				token.New(1, 4, 3, 0, token.EOF, ""),
+			},
+		},
 		{
 			"asc desc regression",
 			"ASC DESC",
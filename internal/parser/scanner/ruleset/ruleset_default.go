@@ -205,7 +205,7 @@ func defaultNumericLiteralRule(s RuneScanner) (token.Type, bool) {
 		s.ConsumeRune()
 		next, ok = s.Lookahead()
 		if !ok {
-			return token.Unknown, false
+			return token.LiteralNumeric, true
 		}
 		if next == 'x' {
 			s.ConsumeRune()
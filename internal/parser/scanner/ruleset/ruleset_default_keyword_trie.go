@@ -5546,6 +5546,10 @@ func scanKeywordRE(s RuneScanner) (token.Type, bool) {
 	case 'S', 's':
 		s.ConsumeRune()
 		return scanKeywordRES(s)
+
+	case 'T', 't':
+		s.ConsumeRune()
+		return scanKeywordRET(s)
 	}
 	return token.Unknown, false
 }
@@ -6086,6 +6090,94 @@ func scanKeywordRESTRICT(s RuneScanner) (token.Type, bool) {
 	return token.KeywordRestrict, true
 }
 
+func scanKeywordRET(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'U', 'u':
+		s.ConsumeRune()
+		return scanKeywordRETU(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETU(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'R', 'r':
+		s.ConsumeRune()
+		return scanKeywordRETUR(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETUR(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'N', 'n':
+		s.ConsumeRune()
+		return scanKeywordRETURN(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETURN(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'I', 'i':
+		s.ConsumeRune()
+		return scanKeywordRETURNI(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETURNI(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'N', 'n':
+		s.ConsumeRune()
+		return scanKeywordRETURNIN(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETURNIN(s RuneScanner) (token.Type, bool) {
+	next, ok := s.Lookahead()
+	if !ok {
+		return token.Unknown, false
+	}
+	switch next {
+
+	case 'G', 'g':
+		s.ConsumeRune()
+		return scanKeywordRETURNING(s)
+	}
+	return token.Unknown, false
+}
+
+func scanKeywordRETURNING(s RuneScanner) (token.Type, bool) {
+	return token.KeywordReturning, true
+}
+
 func scanKeywordRI(s RuneScanner) (token.Type, bool) {
 	next, ok := s.Lookahead()
 	if !ok {
@@ -138,6 +138,7 @@ const (
 	KeywordRename
 	KeywordReplace
 	KeywordRestrict
+	KeywordReturning
 	KeywordRight
 	KeywordRollback
 	KeywordRow
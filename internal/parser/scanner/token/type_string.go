@@ -128,46 +128,47 @@ func _() {
 	_ = x[KeywordRename-117]
 	_ = x[KeywordReplace-118]
 	_ = x[KeywordRestrict-119]
-	_ = x[KeywordRight-120]
-	_ = x[KeywordRollback-121]
-	_ = x[KeywordRow-122]
-	_ = x[KeywordRows-123]
-	_ = x[KeywordSavepoint-124]
-	_ = x[KeywordSelect-125]
-	_ = x[KeywordSet-126]
-	_ = x[KeywordStored-127]
-	_ = x[KeywordTable-128]
-	_ = x[KeywordTemp-129]
-	_ = x[KeywordTemporary-130]
-	_ = x[KeywordThen-131]
-	_ = x[KeywordTies-132]
-	_ = x[KeywordTo-133]
-	_ = x[KeywordTransaction-134]
-	_ = x[KeywordTrigger-135]
-	_ = x[KeywordUnbounded-136]
-	_ = x[KeywordUnion-137]
-	_ = x[KeywordUnique-138]
-	_ = x[KeywordUpdate-139]
-	_ = x[KeywordUsing-140]
-	_ = x[KeywordVacuum-141]
-	_ = x[KeywordValues-142]
-	_ = x[KeywordView-143]
-	_ = x[KeywordVirtual-144]
-	_ = x[KeywordWhen-145]
-	_ = x[KeywordWhere-146]
-	_ = x[KeywordWindow-147]
-	_ = x[KeywordWith-148]
-	_ = x[KeywordWithout-149]
-	_ = x[Literal-150]
-	_ = x[LiteralNumeric-151]
-	_ = x[UnaryOperator-152]
-	_ = x[BinaryOperator-153]
-	_ = x[Delimiter-154]
+	_ = x[KeywordReturning-120]
+	_ = x[KeywordRight-121]
+	_ = x[KeywordRollback-122]
+	_ = x[KeywordRow-123]
+	_ = x[KeywordRows-124]
+	_ = x[KeywordSavepoint-125]
+	_ = x[KeywordSelect-126]
+	_ = x[KeywordSet-127]
+	_ = x[KeywordStored-128]
+	_ = x[KeywordTable-129]
+	_ = x[KeywordTemp-130]
+	_ = x[KeywordTemporary-131]
+	_ = x[KeywordThen-132]
+	_ = x[KeywordTies-133]
+	_ = x[KeywordTo-134]
+	_ = x[KeywordTransaction-135]
+	_ = x[KeywordTrigger-136]
+	_ = x[KeywordUnbounded-137]
+	_ = x[KeywordUnion-138]
+	_ = x[KeywordUnique-139]
+	_ = x[KeywordUpdate-140]
+	_ = x[KeywordUsing-141]
+	_ = x[KeywordVacuum-142]
+	_ = x[KeywordValues-143]
+	_ = x[KeywordView-144]
+	_ = x[KeywordVirtual-145]
+	_ = x[KeywordWhen-146]
+	_ = x[KeywordWhere-147]
+	_ = x[KeywordWindow-148]
+	_ = x[KeywordWith-149]
+	_ = x[KeywordWithout-150]
+	_ = x[Literal-151]
+	_ = x[LiteralNumeric-152]
+	_ = x[UnaryOperator-153]
+	_ = x[BinaryOperator-154]
+	_ = x[Delimiter-155]
 }
 
-const _Type_name = "UnknownErrorEOFStatementSeparatorKeywordAbortKeywordActionKeywordAddKeywordAfterKeywordAllKeywordAlterKeywordAlwaysKeywordAnalyzeKeywordAndKeywordAsKeywordAscKeywordAttachKeywordAutoincrementKeywordBeforeKeywordBeginKeywordBetweenKeywordByKeywordCascadeKeywordCaseKeywordCastKeywordCheckKeywordCollateKeywordColumnKeywordCommitKeywordConflictKeywordConstraintKeywordCreateKeywordCrossKeywordCurrentKeywordCurrentDateKeywordCurrentTimeKeywordCurrentTimestampKeywordDatabaseKeywordDefaultKeywordDeferrableKeywordDeferredKeywordDeleteKeywordDescKeywordDetachKeywordDistinctKeywordDoKeywordDropKeywordEachKeywordElseKeywordEndKeywordEscapeKeywordExceptKeywordExcludeKeywordExclusiveKeywordExistsKeywordExplainKeywordFailKeywordFilterKeywordFirstKeywordFollowingKeywordForKeywordForeignKeywordFromKeywordFullKeywordGeneratedKeywordGlobKeywordGroupKeywordGroupsKeywordHavingKeywordIfKeywordIgnoreKeywordImmediateKeywordInKeywordIndexKeywordIndexedKeywordInitiallyKeywordInnerKeywordInsertKeywordInsteadKeywordIntersectKeywordIntoKeywordIsKeywordIsnullKeywordJoinKeywordKeyKeywordLastKeywordLeftKeywordLikeKeywordLimitKeywordMatchKeywordNaturalKeywordNoKeywordNotKeywordNothingKeywordNotnullKeywordNullKeywordNullsKeywordOfKeywordOffsetKeywordOnKeywordOrKeywordOrderKeywordOthersKeywordOuterKeywordOverKeywordPartitionKeywordPlanKeywordPragmaKeywordPrecedingKeywordPrimaryKeywordQueryKeywordRaiseKeywordRangeKeywordRecursiveKeywordReferencesKeywordRegexpKeywordReIndexKeywordReleaseKeywordRenameKeywordReplaceKeywordRestrictKeywordRightKeywordRollbackKeywordRowKeywordRowsKeywordSavepointKeywordSelectKeywordSetKeywordStoredKeywordTableKeywordTempKeywordTemporaryKeywordThenKeywordTiesKeywordToKeywordTransactionKeywordTriggerKeywordUnboundedKeywordUnionKeywordUniqueKeywordUpdateKeywordUsingKeywordVacuumKeywordValuesKeywordViewKeywordVirtualKeywordWhenKeywordWhereKeywordWindowKeywordWithKeywordWithoutLiteralLiteralNumericUnaryOperatorBinaryOperatorDelimiter"
+const _Type_name = "UnknownErrorEOFStatementSeparatorKeywordAbortKeywordActionKeywordAddKeywordAfterKeywordAllKeywordAlterKeywordAlwaysKeywordAnalyzeKeywordAndKeywordAsKeywordAscKeywordAttachKeywordAutoincrementKeywordBeforeKeywordBeginKeywordBetweenKeywordByKeywordCascadeKeywordCaseKeywordCastKeywordCheckKeywordCollateKeywordColumnKeywordCommitKeywordConflictKeywordConstraintKeywordCreateKeywordCrossKeywordCurrentKeywordCurrentDateKeywordCurrentTimeKeywordCurrentTimestampKeywordDatabaseKeywordDefaultKeywordDeferrableKeywordDeferredKeywordDeleteKeywordDescKeywordDetachKeywordDistinctKeywordDoKeywordDropKeywordEachKeywordElseKeywordEndKeywordEscapeKeywordExceptKeywordExcludeKeywordExclusiveKeywordExistsKeywordExplainKeywordFailKeywordFilterKeywordFirstKeywordFollowingKeywordForKeywordForeignKeywordFromKeywordFullKeywordGeneratedKeywordGlobKeywordGroupKeywordGroupsKeywordHavingKeywordIfKeywordIgnoreKeywordImmediateKeywordInKeywordIndexKeywordIndexedKeywordInitiallyKeywordInnerKeywordInsertKeywordInsteadKeywordIntersectKeywordIntoKeywordIsKeywordIsnullKeywordJoinKeywordKeyKeywordLastKeywordLeftKeywordLikeKeywordLimitKeywordMatchKeywordNaturalKeywordNoKeywordNotKeywordNothingKeywordNotnullKeywordNullKeywordNullsKeywordOfKeywordOffsetKeywordOnKeywordOrKeywordOrderKeywordOthersKeywordOuterKeywordOverKeywordPartitionKeywordPlanKeywordPragmaKeywordPrecedingKeywordPrimaryKeywordQueryKeywordRaiseKeywordRangeKeywordRecursiveKeywordReferencesKeywordRegexpKeywordReIndexKeywordReleaseKeywordRenameKeywordReplaceKeywordRestrictKeywordReturningKeywordRightKeywordRollbackKeywordRowKeywordRowsKeywordSavepointKeywordSelectKeywordSetKeywordStoredKeywordTableKeywordTempKeywordTemporaryKeywordThenKeywordTiesKeywordToKeywordTransactionKeywordTriggerKeywordUnboundedKeywordUnionKeywordUniqueKeywordUpdateKeywordUsingKeywordVacuumKeywordValuesKeywordViewKeywordVirtualKeywordWhenKeywordWhereKeywordWindowKeywordWithKeywordWithoutLiteralLiteralNumericUnaryOperatorBinaryOperatorDelimiter"
 
-var _Type_index = [...]uint16{0, 7, 12, 15, 33, 45, 58, 68, 80, 90, 102, 115, 129, 139, 148, 158, 171, 191, 204, 216, 230, 239, 253, 264, 275, 287, 301, 314, 327, 342, 359, 372, 384, 398, 416, 434, 457, 472, 486, 503, 518, 531, 542, 555, 570, 579, 590, 601, 612, 622, 635, 648, 662, 678, 691, 705, 716, 729, 741, 757, 767, 781, 792, 803, 819, 830, 842, 855, 868, 877, 890, 906, 915, 927, 941, 957, 969, 982, 996, 1012, 1023, 1032, 1045, 1056, 1066, 1077, 1088, 1099, 1111, 1123, 1137, 1146, 1156, 1170, 1184, 1195, 1207, 1216, 1229, 1238, 1247, 1259, 1272, 1284, 1295, 1311, 1322, 1335, 1351, 1365, 1377, 1389, 1401, 1417, 1434, 1447, 1461, 1475, 1488, 1502, 1517, 1529, 1544, 1554, 1565, 1581, 1594, 1604, 1617, 1629, 1640, 1656, 1667, 1678, 1687, 1705, 1719, 1735, 1747, 1760, 1773, 1785, 1798, 1811, 1822, 1836, 1847, 1859, 1872, 1883, 1897, 1904, 1918, 1931, 1945, 1954}
+var _Type_index = [...]uint16{0, 7, 12, 15, 33, 45, 58, 68, 80, 90, 102, 115, 129, 139, 148, 158, 171, 191, 204, 216, 230, 239, 253, 264, 275, 287, 301, 314, 327, 342, 359, 372, 384, 398, 416, 434, 457, 472, 486, 503, 518, 531, 542, 555, 570, 579, 590, 601, 612, 622, 635, 648, 662, 678, 691, 705, 716, 729, 741, 757, 767, 781, 792, 803, 819, 830, 842, 855, 868, 877, 890, 906, 915, 927, 941, 957, 969, 982, 996, 1012, 1023, 1032, 1045, 1056, 1066, 1077, 1088, 1099, 1111, 1123, 1137, 1146, 1156, 1170, 1184, 1195, 1207, 1216, 1229, 1238, 1247, 1259, 1272, 1284, 1295, 1311, 1322, 1335, 1351, 1365, 1377, 1389, 1401, 1417, 1434, 1447, 1461, 1475, 1488, 1502, 1517, 1533, 1545, 1560, 1570, 1581, 1597, 1610, 1620, 1633, 1645, 1656, 1672, 1683, 1694, 1703, 1721, 1735, 1751, 1763, 1776, 1789, 1801, 1814, 1827, 1838, 1852, 1863, 1875, 1888, 1899, 1913, 1920, 1934, 1947, 1961, 1970}
 
 func (i Type) String() string {
 	if i >= Type(len(_Type_index)-1) {
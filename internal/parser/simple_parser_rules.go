@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/xqueries/xdb/internal/parser/ast"
 	"github.com/xqueries/xdb/internal/parser/scanner/token"
 )
@@ -170,6 +172,11 @@ func (p *simpleParser) parseAlterTableStmt(r reporter) (stmt *ast.AlterTableStmt
 			stmt.TableName = tableName
 			p.consumeToken()
 		}
+
+		next, ok = p.lookahead(r)
+		if !ok {
+			return
+		}
 	} else {
 		stmt.TableName = schemaOrTableName
 	}
@@ -273,6 +280,32 @@ func (p *simpleParser) parseAlterTableStmt(r reporter) (stmt *ast.AlterTableStmt
 		default:
 			r.unexpectedToken(token.KeywordColumn, token.Literal)
 		}
+	case token.KeywordDrop:
+		stmt.Drop = next
+		p.consumeToken()
+
+		next, ok = p.lookahead(r)
+		if !ok {
+			return
+		}
+		if next.Type() == token.KeywordColumn {
+			stmt.Column = next
+			p.consumeToken()
+
+			next, ok = p.lookahead(r)
+			if !ok {
+				return
+			}
+		}
+		if next.Type() != token.Literal {
+			r.unexpectedToken(token.Literal)
+			p.consumeToken()
+			return
+		}
+		stmt.ColumnName = next
+		p.consumeToken()
+	default:
+		r.unexpectedToken(token.KeywordRename, token.KeywordAdd, token.KeywordDrop)
 	}
 
 	return
@@ -328,7 +361,7 @@ func (p *simpleParser) parseTypeName(r reporter) (name *ast.TypeName) {
 		r.unexpectedToken(token.Literal)
 	}
 	for {
-		if next, ok := p.lookahead(r); ok && next.Type() == token.Literal {
+		if next, ok := p.optionalLookahead(r); ok && next.Type() == token.Literal {
 			name.Name = append(name.Name, next)
 			p.consumeToken()
 		} else {
@@ -336,7 +369,7 @@ func (p *simpleParser) parseTypeName(r reporter) (name *ast.TypeName) {
 		}
 	}
 
-	if next, ok := p.lookahead(r); ok && next.Type() == token.Delimiter && next.Value() == "(" {
+	if next, ok := p.optionalLookahead(r); ok && next.Type() == token.Delimiter && next.Value() == "(" {
 		name.LeftParen = next
 		p.consumeToken()
 
@@ -454,7 +487,7 @@ func (p *simpleParser) parseColumnConstraint(r reporter) (constr *ast.ColumnCons
 		}
 
 		// ASC, DESC
-		next, ok = p.lookahead(r)
+		next, ok = p.optionalLookahead(r)
 		if !ok {
 			return
 		}
@@ -2601,6 +2634,10 @@ func (p *simpleParser) parseCreateTableStmt(createToken, tempToken, temporaryTok
 					}
 					if next.Value() == "," {
 						p.consumeToken()
+						next, ok = p.lookahead(r)
+						if !ok {
+							return
+						}
 					}
 					if next.Value() == ")" {
 						stmt.RightParen = next
@@ -2618,6 +2655,22 @@ func (p *simpleParser) parseCreateTableStmt(createToken, tempToken, temporaryTok
 				break
 			}
 		}
+		next, ok = p.optionalLookahead(r)
+		if !ok || next.Type() != token.KeywordWithout {
+			return
+		}
+		stmt.Without = next
+		p.consumeToken()
+		next, ok = p.lookahead(r)
+		if !ok {
+			return
+		}
+		if next.Type() == token.Literal && strings.EqualFold(next.Value(), "ROWID") {
+			stmt.Rowid = next
+			p.consumeToken()
+		} else {
+			r.unexpectedToken(token.Literal)
+		}
 	case token.KeywordAs:
 		stmt.As = next
 		p.consumeToken()
@@ -3212,6 +3265,14 @@ func (p *simpleParser) parseDeleteStmtHelper(withClause *ast.WithClause, r repor
 			r.expectedExpression()
 		}
 	}
+
+	next, ok = p.optionalLookahead(r)
+	if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
+		return
+	}
+	if next.Type() == token.KeywordReturning {
+		deleteStmt.ReturningClause = p.parseReturningClause(r)
+	}
 	return
 }
 
@@ -3505,8 +3566,8 @@ func (p *simpleParser) parseSelectStmt(withClause *ast.WithClause, r reporter) (
 		}
 		for {
 			stmt.OrderingTerm = append(stmt.OrderingTerm, p.parseOrderingTerm(r))
-			next, ok = p.lookahead(r)
-			if !ok {
+			next, ok = p.optionalLookahead(r)
+			if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
 				return
 			}
 			if next.Value() == "," {
@@ -3776,8 +3837,8 @@ func (p *simpleParser) parseSelectCore(r reporter) (stmt *ast.SelectCore) {
 				if expression != nil {
 					stmt.Expr2 = append(stmt.Expr2, expression)
 				}
-				next, ok = p.lookahead(r)
-				if !ok {
+				next, ok = p.optionalLookahead(r)
+				if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
 					return
 				}
 				if next.Value() == "," {
@@ -3787,10 +3848,6 @@ func (p *simpleParser) parseSelectCore(r reporter) (stmt *ast.SelectCore) {
 				}
 			}
 
-			next, ok = p.lookahead(r)
-			if !ok {
-				return
-			}
 			if next.Type() == token.KeywordHaving {
 				stmt.Having = next
 				p.consumeToken()
@@ -4480,8 +4537,7 @@ func (p *simpleParser) parseTableOrSubquery(r reporter) (stmt *ast.TableOrSubque
 				} else {
 					r.unexpectedToken(token.Literal)
 				}
-			}
-			if next.Type() == token.Literal {
+			} else if next.Type() == token.Literal {
 				stmt.TableAlias = next
 				p.consumeToken()
 			}
@@ -4570,8 +4626,7 @@ func (p *simpleParser) parseTableOrSubquery(r reporter) (stmt *ast.TableOrSubque
 						stmt.TableAlias = next
 						p.consumeToken()
 					}
-				}
-				if next.Type() == token.Literal {
+				} else if next.Type() == token.Literal {
 					stmt.TableAlias = next
 					p.consumeToken()
 				}
@@ -5131,13 +5186,58 @@ func (p *simpleParser) parseInsertStmt(withClause *ast.WithClause, r reporter) (
 	}
 	if next.Type() == token.KeywordOn {
 		stmt.UpsertClause = p.parseUpsertClause(r)
+
+		next, ok = p.optionalLookahead(r)
+		if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
+			return
+		}
+	}
+	if next.Type() == token.KeywordReturning {
+		stmt.ReturningClause = p.parseReturningClause(r)
 	} else {
-		r.unexpectedToken(token.KeywordOn)
+		r.unexpectedToken(token.KeywordOn, token.KeywordReturning)
 	}
 
 	return
 }
 
+// parseReturningClause parses returning-clause as defined in:
+// https://sqlite.org/syntax/returning-clause.html
+func (p *simpleParser) parseReturningClause(r reporter) (clause *ast.ReturningClause) {
+	clause = &ast.ReturningClause{}
+	next, ok := p.lookahead(r)
+	if !ok {
+		return
+	}
+	if next.Type() == token.KeywordReturning {
+		clause.Returning = next
+		p.consumeToken()
+	} else {
+		r.unexpectedToken(token.KeywordReturning)
+		return
+	}
+
+	for {
+		resCol := p.parseResultColumn(r)
+		if resCol != nil {
+			clause.ResultColumn = append(clause.ResultColumn, resCol)
+		} else {
+			r.expectedExpression()
+			r.unexpectedToken(token.Literal)
+		}
+
+		next, ok = p.optionalLookahead(r)
+		if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
+			return
+		}
+		if next.Value() == "," {
+			p.consumeToken()
+		} else {
+			return
+		}
+	}
+}
+
 // parseUpsertClause parses upsert-clause as defined in:
 // https://sqlite.org/syntax/upsert-clause.html
 func (p *simpleParser) parseUpsertClause(r reporter) (clause *ast.UpsertClause) {
@@ -5236,7 +5336,7 @@ func (p *simpleParser) parseUpsertClause(r reporter) (clause *ast.UpsertClause)
 			if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
 				return
 			}
-			if next.Type() == token.KeywordWhere {
+			if next.Type() == token.KeywordWhere || next.Type() == token.KeywordReturning {
 				break
 			}
 			if next.Value() == "," {
@@ -5398,7 +5498,7 @@ func (p *simpleParser) parseUpdateStmtHelper(withClause *ast.WithClause, r repor
 					if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
 						return
 					}
-					if next.Type() == token.KeywordWhere || next.Type() == token.KeywordOrder || next.Type() == token.KeywordLimit {
+					if next.Type() == token.KeywordWhere || next.Type() == token.KeywordReturning || next.Type() == token.KeywordOrder || next.Type() == token.KeywordLimit {
 						break
 					}
 					if next.Value() == "," {
@@ -5420,6 +5520,14 @@ func (p *simpleParser) parseUpdateStmtHelper(withClause *ast.WithClause, r repor
 					}
 				}
 
+				next, ok = p.optionalLookahead(r)
+				if !ok || next.Type() == token.EOF || next.Type() == token.StatementSeparator {
+					return
+				}
+				if next.Type() == token.KeywordReturning {
+					updateStmt.ReturningClause = p.parseReturningClause(r)
+				}
+
 			} else {
 				r.unexpectedToken(token.KeywordSet)
 			}
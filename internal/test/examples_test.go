@@ -122,3 +122,287 @@ INSERT INTO 'myTable' VALUES
 
 	t.Logf("profile:\n%v", p.Profile().String())
 }
+
+func TestExample09(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example09",
+		SetupSQL: `
+CREATE TABLE items (id INTEGER, name STRING);
+CREATE TABLE prices (id INTEGER, price INTEGER);
+INSERT INTO items VALUES (1, "apple"), (2, "pear"), (3, "plum");
+INSERT INTO prices VALUES (1, 30), (3, 45)`,
+		Statement: `SELECT i.*, p.price FROM items AS i LEFT JOIN prices p ON i.id = p.id`,
+	})
+}
+
+func TestExample10(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example10",
+		SetupSQL: `
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "a"), (2, "b"), (3, "c"), (4, "d"), (5, "e")`,
+		Statement: `SELECT name FROM myTable LIMIT 2 OFFSET 1`,
+	})
+}
+
+func TestExample11(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example11",
+		SetupSQL: `
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "b"), (2, "c"), (3, "a"), (4, "b")`,
+		Statement: `SELECT name AS n FROM myTable ORDER BY n DESC, id LIMIT 3`,
+	})
+}
+
+func TestExample12(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example12",
+		SetupSQL: `
+CREATE TABLE sales (region STRING, product STRING, amount INTEGER);
+INSERT INTO sales VALUES
+("north", "apple", 10),
+("north", "pear", 5),
+("north", "apple", 7),
+("south", "apple", 3),
+("east", "plum", 8)`,
+		Statement: `SELECT region, COUNT(*), COUNT(DISTINCT product), SUM(amount), AVG(amount), MIN(product), MAX(amount) AS most FROM sales GROUP BY region HAVING SUM(amount) > 5 ORDER BY SUM(amount) DESC`,
+	})
+}
+
+func TestExample13(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example13",
+		SetupSQL: `
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "b"), (2, "a"), (3, "b"), (4, "c"), (5, "a")`,
+		Statement: `SELECT DISTINCT name FROM myTable ORDER BY name`,
+	})
+}
+
+func TestExample14(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example14",
+		SetupSQL: `
+CREATE TABLE tableA (id INTEGER, name STRING);
+CREATE TABLE tableB (id INTEGER, name STRING);
+INSERT INTO tableA VALUES (1, "a"), (2, "b"), (3, "c");
+INSERT INTO tableB VALUES (2, "b"), (4, "d"), (4, "d")`,
+		Statement: `SELECT id, name FROM tableA UNION SELECT id, name FROM tableB EXCEPT SELECT id, name FROM tableA WHERE id = 1 ORDER BY 1 DESC`,
+	})
+}
+
+func TestExample15(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example15",
+		SetupSQL: `
+CREATE TABLE tableA (id INTEGER, name STRING);
+CREATE TABLE tableB (id INTEGER, amount INTEGER);
+INSERT INTO tableA VALUES (1, "a"), (2, "b"), (3, "c");
+INSERT INTO tableB VALUES (1, 10), (2, 20)`,
+		Statement: `EXPLAIN SELECT name, amount FROM tableA JOIN tableB ON tableA.id = tableB.id WHERE amount > 10 ORDER BY name LIMIT 1`,
+	})
+}
+
+func TestExample16(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example16",
+		SetupSQL: `
+CREATE TABLE myTable (id INTEGER, name STRING);
+INSERT INTO myTable VALUES (1, "a"), (2, "b"), (3, "c")`,
+		Statement: `EXPLAIN QUERY PLAN SELECT DISTINCT name FROM myTable WHERE id > 1`,
+	})
+}
+
+func TestExample17(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example17",
+		SetupSQL: `
+CREATE TABLE myTable (id INTEGER, name STRING DEFAULT 'unnamed', price REAL DEFAULT 9.5, note STRING);
+INSERT INTO myTable (id, note) VALUES (1, "first"), (2, "second");
+INSERT INTO myTable (name, id) VALUES ('third', 3);
+INSERT INTO myTable DEFAULT VALUES`,
+		Statement: `SELECT * FROM myTable`,
+	})
+}
+
+func TestExample18(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example18",
+		SetupSQL: `
+CREATE TABLE items (id INTEGER, price REAL, label STRING);
+INSERT INTO items VALUES (1, 2.0, '10'), (2, 4.0, '20.5');
+CREATE TABLE copies (price INTEGER, label REAL, id STRING);
+INSERT INTO copies SELECT price, label, id FROM items;
+INSERT INTO items SELECT * FROM items WHERE id = 2`,
+		Statement: `SELECT * FROM items JOIN copies`,
+	})
+}
+
+func TestExample19(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example19",
+		SetupSQL: `
+CREATE TABLE stock (item STRING PRIMARY KEY, amount INTEGER, sku INTEGER UNIQUE);
+INSERT INTO stock VALUES ('apple', 5, 100), ('pear', 2, 200);
+INSERT INTO stock VALUES ('apple', 3, 100), ('plum', 7, 300) ON CONFLICT (item) DO UPDATE SET amount = amount + excluded.amount;
+INSERT OR IGNORE INTO stock VALUES ('kiwi', 1, 200);
+INSERT OR REPLACE INTO stock VALUES ('pear', 4, 201)`,
+		Statement: `SELECT * FROM stock`,
+	})
+}
+
+func TestExample20(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example20",
+		SetupSQL: `
+CREATE TABLE accounts (id INTEGER PRIMARY KEY, owner STRING, balance INTEGER DEFAULT 0);
+INSERT INTO accounts VALUES (1, 'alice', 100), (2, 'bob', 50), (3, 'carol', 0)`,
+		Statement: `UPDATE accounts SET balance = balance - 20 WHERE balance >= 20 RETURNING id, owner, balance, balance * 2 AS doubled`,
+	})
+}
+
+func TestExample21(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example21",
+		SetupSQL: `
+CREATE TABLE orders (customer STRING NOT NULL, item STRING, quantity INTEGER CHECK (quantity > 0), PRIMARY KEY (customer, item), CONSTRAINT small_orders CHECK (quantity <= 10));
+INSERT INTO orders VALUES ('alice', 'apple', 3), ('bob', 'apple', 10);
+INSERT OR IGNORE INTO orders VALUES ('alice', 'pear', 0), ('carol', 'plum', 11), ('carol', 'kiwi', 1), ('alice', 'apple', 5);
+INSERT OR IGNORE INTO orders (item, quantity) VALUES ('pear', 2)`,
+		Statement: `SELECT * FROM orders`,
+	})
+}
+
+func TestExample22(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example22",
+		SetupSQL: `
+CREATE TABLE seats (id INTEGER PRIMARY KEY, flight STRING, seat STRING, passenger STRING UNIQUE, UNIQUE (flight, seat));
+INSERT INTO seats VALUES (1, 'LH100', '1A', 'alice'), (2, 'LH100', '1B', 'bob'), (3, 'LH200', '1A', 'carol');
+INSERT OR IGNORE INTO seats VALUES (4, 'LH100', '1A', 'dave'), (5, 'LH200', '2C', 'bob'), (6, 'LH200', '2C', 'erin');
+UPDATE OR REPLACE seats SET seat = '1A' WHERE passenger = 'erin'`,
+		Statement: `SELECT * FROM seats`,
+	})
+}
+
+func TestExample23(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example23",
+		SetupSQL: `
+CREATE TABLE authors (id INTEGER PRIMARY KEY, name STRING);
+CREATE TABLE books (id INTEGER PRIMARY KEY, title STRING, author INTEGER REFERENCES authors ON DELETE CASCADE ON UPDATE CASCADE);
+INSERT INTO authors VALUES (1, 'tolkien'), (2, 'pratchett');
+INSERT INTO books VALUES (1, 'the hobbit', 1), (2, 'mort', 2), (3, 'guards! guards!', 2);
+UPDATE authors SET id = 3 WHERE id = 2;
+DELETE FROM authors WHERE id = 1`,
+		Statement: `SELECT * FROM books`,
+	})
+}
+
+func TestExample24(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example24",
+		SetupSQL: `
+CREATE TABLE users (id INTEGER PRIMARY KEY, email STRING, age INTEGER);
+INSERT INTO users VALUES (1, 'a@example.com', 30), (2, 'b@example.com', 25);
+CREATE UNIQUE INDEX users_email ON users (email);
+CREATE INDEX users_age ON users (age, email);
+INSERT INTO users VALUES (3, 'c@example.com', 30);
+INSERT OR IGNORE INTO users VALUES (4, 'a@example.com', 40);
+UPDATE users SET email = 'd@example.com' WHERE id = 2;
+DELETE FROM users WHERE id = 1;
+DROP INDEX users_age`,
+		Statement: `SELECT * FROM users`,
+	})
+}
+
+func TestExample25(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example25",
+		SetupSQL: `
+CREATE TABLE measurements (id INTEGER PRIMARY KEY, sensor STRING, value REAL);
+INSERT INTO measurements VALUES (1, 'b', 2.5), (2, 'a', 0.5), (3, 'c', -1.0), (4, 'a', 7.25), (5, 'b', 3.0);
+CREATE INDEX measurements_value ON measurements (value);
+DELETE FROM measurements WHERE value < 0.0`,
+		Statement: `SELECT * FROM measurements WHERE value BETWEEN 0.5 AND 3.0`,
+	})
+}
+
+func TestExample26(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example26",
+		SetupSQL: `
+CREATE TABLE stock (warehouse STRING, item INTEGER, amount INTEGER, PRIMARY KEY (warehouse, item)) WITHOUT ROWID;
+INSERT INTO stock VALUES ('north', 3, 10), ('south', 1, 4), ('north', 1, 7), ('east', 2, 1), ('south', 2, 0);
+UPDATE stock SET item = 4 WHERE warehouse = 'east';
+DELETE FROM stock WHERE amount = 0`,
+		Statement: `SELECT * FROM stock`,
+	})
+}
+
+func TestExample27(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example27",
+		SetupSQL: `
+CREATE TABLE people (id INTEGER PRIMARY KEY, name STRING, nickname STRING);
+INSERT INTO people VALUES (1, 'Alice', 'al'), (2, 'Bob', 'bobby');
+ALTER TABLE people ADD COLUMN age INTEGER NOT NULL DEFAULT 30;
+INSERT INTO people VALUES (3, 'Carol', 'caz', 41);
+ALTER TABLE people DROP COLUMN nickname;
+ALTER TABLE people RENAME COLUMN name TO firstname;
+ALTER TABLE people RENAME TO persons`,
+		Statement: `SELECT * FROM persons`,
+	})
+}
+
+func TestExample28(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example28",
+		SetupSQL: `
+CREATE TABLE orders (id INTEGER PRIMARY KEY, customer STRING, amount INTEGER);
+INSERT INTO orders VALUES (1, 'alice', 20), (2, 'bob', 5), (3, 'alice', 12), (4, 'carol', 30);
+CREATE VIEW large_orders (orderID, customer, total) AS SELECT id, customer, amount FROM orders WHERE amount > 10;
+CREATE VIEW tmp AS SELECT * FROM orders;
+DROP VIEW tmp;
+DROP VIEW IF EXISTS tmp`,
+		Statement: `SELECT customer, total FROM large_orders WHERE orderID > 1`,
+	})
+}
+
+func TestExample29(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example29",
+		SetupSQL: `
+CREATE TABLE accounts (id INTEGER PRIMARY KEY, balance INTEGER);
+CREATE TABLE audit (account INTEGER, old_balance INTEGER, new_balance INTEGER);
+CREATE TRIGGER no_overdraft BEFORE UPDATE OF balance ON accounts WHEN NEW.balance < 0 BEGIN SELECT RAISE(ABORT, 'overdraft'); END;
+CREATE TRIGGER audit_update AFTER UPDATE ON accounts BEGIN INSERT INTO audit VALUES (OLD.id, OLD.balance, NEW.balance); END;
+INSERT INTO accounts VALUES (1, 100), (2, 50);
+UPDATE accounts SET balance = balance - 30 WHERE id = 1;
+UPDATE accounts SET balance = balance + 30 WHERE id = 2`,
+		Statement: `SELECT * FROM audit`,
+	})
+}
+
+func TestExample30(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example30",
+		SetupSQL: `
+CREATE TABLE customers (id INTEGER PRIMARY KEY, name STRING);
+CREATE TABLE orders (id INTEGER PRIMARY KEY, customer INTEGER, total INTEGER);
+INSERT INTO customers VALUES (1, 'alice'), (2, 'bob'), (3, 'carol');
+INSERT INTO orders VALUES (1, 1, 20), (2, 1, 35), (3, 3, 10)`,
+		Statement: `SELECT c.name, (SELECT max(total) FROM orders WHERE orders.customer = c.id) AS largest FROM (SELECT id, name FROM customers) AS c WHERE EXISTS (SELECT * FROM orders WHERE orders.customer = c.id)`,
+	})
+}
+
+func TestExample31(t *testing.T) {
+	RunAndCompare(t, Testcase{
+		Name: "example31",
+		SetupSQL: `
+CREATE TABLE employees (id INTEGER PRIMARY KEY, name STRING, manager INTEGER);
+INSERT INTO employees VALUES (1, 'alice', 0), (2, 'bob', 1), (3, 'carol', 1), (4, 'dave', 2), (5, 'erin', 4), (6, 'frank', 3)`,
+		Statement: `WITH RECURSIVE reports (id, name, depth) AS (SELECT id, name, 0 FROM employees WHERE id = 2 UNION ALL SELECT employees.id, employees.name, reports.depth + 1 FROM employees JOIN reports ON employees.manager = reports.id) SELECT name, depth FROM reports ORDER BY depth`,
+	})
+}
@@ -122,6 +122,7 @@ var (
 		"RENAME":            token.KeywordRename,
 		"REPLACE":           token.KeywordReplace,
 		"RESTRICT":          token.KeywordRestrict,
+		"RETURNING":         token.KeywordReturning,
 		"RIGHT":             token.KeywordRight,
 		"ROLLBACK":          token.KeywordRollback,
 		"ROW":               token.KeywordRow,